@@ -0,0 +1,143 @@
+package api4
+
+import (
+	"net/http"
+
+	"im/model"
+)
+
+func (api *API) InitAddress() {
+	api.BaseRoutes.Addresses.Handle("", api.ApiSessionRequired(getUserAddresses)).Methods("GET")
+	api.BaseRoutes.Addresses.Handle("", api.ApiSessionRequired(createAddress)).Methods("POST")
+
+	api.BaseRoutes.Address.Handle("", api.ApiSessionRequired(getAddress)).Methods("GET")
+	api.BaseRoutes.Address.Handle("/patch", api.ApiSessionRequired(patchAddress)).Methods("PUT")
+	api.BaseRoutes.Address.Handle("", api.ApiSessionRequired(deleteAddress)).Methods("DELETE")
+}
+
+func getUserAddresses(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToUser(c.App.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	addresses, err := c.App.GetUserAddresses(c.Params.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.AddressesToJson(addresses)))
+}
+
+func createAddress(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	address := model.AddressFromJson(r.Body)
+	if address == nil {
+		c.SetInvalidParam("address")
+		return
+	}
+
+	if !c.App.SessionHasPermissionToUser(c.App.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	address.Id = ""
+	address.UserId = c.Params.UserId
+
+	raddress, err := c.App.CreateAddress(address)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(raddress.ToJson()))
+}
+
+func getAddress(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequireAddressId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToUser(c.App.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	address, err := c.App.GetAddressForUser(c.Params.UserId, c.Params.AddressId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(address.ToJson()))
+}
+
+func patchAddress(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequireAddressId()
+	if c.Err != nil {
+		return
+	}
+
+	patch := model.AddressPatchFromJson(r.Body)
+	if patch == nil {
+		c.SetInvalidParam("address")
+		return
+	}
+
+	if !c.App.SessionHasPermissionToUser(c.App.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	address, err := c.App.GetAddressForUser(c.Params.UserId, c.Params.AddressId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	raddress, err := c.App.PatchAddress(address, patch)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(raddress.ToJson()))
+}
+
+func deleteAddress(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequireAddressId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToUser(c.App.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	address, err := c.App.GetAddressForUser(c.Params.UserId, c.Params.AddressId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if err := c.App.DeleteAddress(address); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
@@ -103,6 +103,9 @@ type Routes struct {
 	Offices *mux.Router // 'api/v4/offices'
 	Office  *mux.Router // 'api/v4/offices/{office_id:[A-Za-z0-9_-]+}'
 
+	Addresses *mux.Router // 'api/v4/users/{user_id:[A-Za-z0-9]+}/addresses'
+	Address   *mux.Router // 'api/v4/users/{user_id:[A-Za-z0-9]+}/addresses/{address_id:[A-Za-z0-9]+}'
+
 	Orders *mux.Router // 'api/v4/orders'
 	Order  *mux.Router // 'api/v4/orders/{order_id:[A-Za-z0-9_-]+}'
 
@@ -120,6 +123,27 @@ type Routes struct {
 	Applications *mux.Router // 'api/v4/applications'
 	Application  *mux.Router // 'api/v4/applications/{application_id:[A-Za-z0-9_-]+}'
 
+	Staff       *mux.Router // 'api/v4/applications/{app_id:[A-Za-z0-9]+}/staff'
+	StaffMember *mux.Router // 'api/v4/applications/{app_id:[A-Za-z0-9]+}/staff/{user_id:[A-Za-z0-9]+}'
+
+	WebhookSubscriptions *mux.Router // 'api/v4/applications/{app_id:[A-Za-z0-9]+}/webhooks'
+	WebhookSubscription  *mux.Router // 'api/v4/applications/{app_id:[A-Za-z0-9]+}/webhooks/{hook_id:[A-Za-z0-9]+}'
+
+	Campaigns *mux.Router // 'api/v4/applications/{app_id:[A-Za-z0-9]+}/campaigns'
+	Campaign  *mux.Router // 'api/v4/applications/{app_id:[A-Za-z0-9]+}/campaigns/{campaign_id:[A-Za-z0-9]+}'
+
+	LifecycleTriggers *mux.Router // 'api/v4/applications/{app_id:[A-Za-z0-9]+}/lifecycle_triggers'
+	LifecycleTrigger  *mux.Router // 'api/v4/applications/{app_id:[A-Za-z0-9]+}/lifecycle_triggers/{trigger_id:[A-Za-z0-9]+}'
+
+	LoyaltyTiers *mux.Router // 'api/v4/applications/{app_id:[A-Za-z0-9]+}/loyalty_tiers'
+	LoyaltyTier  *mux.Router // 'api/v4/applications/{app_id:[A-Za-z0-9]+}/loyalty_tiers/{tier_id:[A-Za-z0-9]+}'
+
+	ReferralPayouts *mux.Router // 'api/v4/applications/{app_id:[A-Za-z0-9]+}/referral_payouts'
+	ReferralPayout  *mux.Router // 'api/v4/applications/{app_id:[A-Za-z0-9]+}/referral_payouts/{payout_id:[A-Za-z0-9]+}'
+
+	CardBindings *mux.Router // 'api/v4/users/{user_id:[A-Za-z0-9]+}/card_bindings'
+	CardBinding  *mux.Router // 'api/v4/users/{user_id:[A-Za-z0-9]+}/card_bindings/{binding_id:[A-Za-z0-9]+}'
+
 	Notifications *mux.Router // 'api/v4/notifications'
 	Metrics       *mux.Router // 'api/v4/metrics'
 
@@ -223,6 +247,9 @@ func Init(configservice configservice.ConfigService, globalOptionsFunc app.AppOp
 	api.BaseRoutes.Offices = api.BaseRoutes.ApiRoot.PathPrefix("/offices").Subrouter()
 	api.BaseRoutes.Office = api.BaseRoutes.Offices.PathPrefix("/{office_id:[A-Za-z0-9]+}").Subrouter()
 
+	api.BaseRoutes.Addresses = api.BaseRoutes.User.PathPrefix("/addresses").Subrouter()
+	api.BaseRoutes.Address = api.BaseRoutes.Addresses.PathPrefix("/{address_id:[A-Za-z0-9]+}").Subrouter()
+
 	api.BaseRoutes.Orders = api.BaseRoutes.ApiRoot.PathPrefix("/orders").Subrouter()
 	api.BaseRoutes.Order = api.BaseRoutes.Orders.PathPrefix("/{order_id:[A-Za-z0-9]+}").Subrouter()
 
@@ -240,6 +267,27 @@ func Init(configservice configservice.ConfigService, globalOptionsFunc app.AppOp
 	api.BaseRoutes.Applications = api.BaseRoutes.ApiRoot.PathPrefix("/applications").Subrouter()
 	api.BaseRoutes.Application = api.BaseRoutes.Applications.PathPrefix("/{app_id:[A-Za-z0-9]+}").Subrouter()
 
+	api.BaseRoutes.Staff = api.BaseRoutes.Application.PathPrefix("/staff").Subrouter()
+	api.BaseRoutes.StaffMember = api.BaseRoutes.Staff.PathPrefix("/{user_id:[A-Za-z0-9]+}").Subrouter()
+
+	api.BaseRoutes.WebhookSubscriptions = api.BaseRoutes.Application.PathPrefix("/webhooks").Subrouter()
+	api.BaseRoutes.WebhookSubscription = api.BaseRoutes.WebhookSubscriptions.PathPrefix("/{hook_id:[A-Za-z0-9]+}").Subrouter()
+
+	api.BaseRoutes.Campaigns = api.BaseRoutes.Application.PathPrefix("/campaigns").Subrouter()
+	api.BaseRoutes.Campaign = api.BaseRoutes.Campaigns.PathPrefix("/{campaign_id:[A-Za-z0-9]+}").Subrouter()
+
+	api.BaseRoutes.LifecycleTriggers = api.BaseRoutes.Application.PathPrefix("/lifecycle_triggers").Subrouter()
+	api.BaseRoutes.LifecycleTrigger = api.BaseRoutes.LifecycleTriggers.PathPrefix("/{trigger_id:[A-Za-z0-9]+}").Subrouter()
+
+	api.BaseRoutes.LoyaltyTiers = api.BaseRoutes.Application.PathPrefix("/loyalty_tiers").Subrouter()
+	api.BaseRoutes.LoyaltyTier = api.BaseRoutes.LoyaltyTiers.PathPrefix("/{tier_id:[A-Za-z0-9]+}").Subrouter()
+
+	api.BaseRoutes.ReferralPayouts = api.BaseRoutes.Application.PathPrefix("/referral_payouts").Subrouter()
+	api.BaseRoutes.ReferralPayout = api.BaseRoutes.ReferralPayouts.PathPrefix("/{payout_id:[A-Za-z0-9]+}").Subrouter()
+
+	api.BaseRoutes.CardBindings = api.BaseRoutes.User.PathPrefix("/card_bindings").Subrouter()
+	api.BaseRoutes.CardBinding = api.BaseRoutes.CardBindings.PathPrefix("/{binding_id:[A-Za-z0-9]+}").Subrouter()
+
 	api.BaseRoutes.Notifications = api.BaseRoutes.ApiRoot.PathPrefix("/notifications").Subrouter()
 	api.BaseRoutes.Metrics = api.BaseRoutes.ApiRoot.PathPrefix("/metrics").Subrouter()
 
@@ -286,6 +334,20 @@ func Init(configservice configservice.ConfigService, globalOptionsFunc app.AppOp
 	api.InitExtra()
 	api.InitBasket()
 	api.InitApplication()
+	api.InitStaff()
+	api.InitCourier()
+	api.InitAddress()
+	api.InitWebhookSubscription()
+	api.InitPos()
+	api.InitCampaign()
+	api.InitLifecycleTrigger()
+	api.InitLoyaltyTier()
+	api.InitReferralPayout()
+	api.InitCardBinding()
+	api.InitOrderPayment()
+	api.InitPrintTicket()
+	api.InitApplicationConfig()
+	api.InitMockAcquirer()
 	api.InitNotification()
 	api.InitMetric()
 	api.InitBuilder()
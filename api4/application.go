@@ -41,13 +41,13 @@ func testPaymentConnection(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 
 	if application.AqType == model.SBERBANK_AQUIRING_TYPE { //foodexp-api	foodexp
-		var sber payment.SberBankBackend
+		sber := payment.SberBankBackend{Metrics: c.App.Metrics}
 		if err := sber.TestConnection(sberbank.ClientConfig{UserName: application.AqUsername, Password: application.AqPassword}); err != nil {
 			c.Err = err
 			return
 		}
 	} else if application.AqType == model.ALFABANK_AQUIRING_TYPE { // yktours-api	yktours*?1
-		var alfa payment.AlfaBankBackend
+		alfa := payment.AlfaBankBackend{Metrics: c.App.Metrics}
 		if err := alfa.TestConnection(alfabank.ClientConfig{UserName: application.AqUsername, Password: application.AqPassword}); err != nil {
 			c.Err = err
 			return
@@ -0,0 +1,110 @@
+package api4
+
+import (
+	"net/http"
+	"strconv"
+
+	"im/model"
+)
+
+func (api *API) InitApplicationConfig() {
+	api.BaseRoutes.Application.Handle("/config", api.ApiSessionRequired(getApplicationConfig)).Methods("GET")
+	api.BaseRoutes.Application.Handle("/config", api.ApiSessionRequired(updateApplicationConfig)).Methods("PUT")
+	api.BaseRoutes.Application.Handle("/config/history", api.ApiSessionRequired(getApplicationConfigHistory)).Methods("GET")
+	api.BaseRoutes.Application.Handle("/config/diff", api.ApiSessionRequired(getApplicationConfigDiff)).Methods("GET")
+}
+
+func requireApplicationConfigPermission(c *Context) {
+	c.RequireAppId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+	}
+}
+
+func getApplicationConfig(c *Context, w http.ResponseWriter, r *http.Request) {
+	requireApplicationConfigPermission(c)
+	if c.Err != nil {
+		return
+	}
+
+	config, err := c.App.GetApplicationConfig(c.Params.AppId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(config.ToJson()))
+}
+
+func updateApplicationConfig(c *Context, w http.ResponseWriter, r *http.Request) {
+	requireApplicationConfigPermission(c)
+	if c.Err != nil {
+		return
+	}
+
+	config := model.ApplicationConfigFromJson(r.Body)
+	if config == nil {
+		c.SetInvalidParam("config")
+		return
+	}
+
+	version, err := c.App.SaveApplicationConfig(c.Params.AppId, config)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("version=" + strconv.FormatInt(version.Version, 10))
+	w.Write([]byte(version.ToJson()))
+}
+
+func getApplicationConfigHistory(c *Context, w http.ResponseWriter, r *http.Request) {
+	requireApplicationConfigPermission(c)
+	if c.Err != nil {
+		return
+	}
+
+	versions, err := c.App.GetApplicationConfigHistory(c.Params.AppId, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.ApplicationConfigVersionListToJson(versions)))
+}
+
+// getApplicationConfigDiff compares the versions given by from and to, a missing version is the
+// active configuration.
+func getApplicationConfigDiff(c *Context, w http.ResponseWriter, r *http.Request) {
+	requireApplicationConfigPermission(c)
+	if c.Err != nil {
+		return
+	}
+
+	versions := map[string]int64{"from": 0, "to": 0}
+	for name := range versions {
+		value := r.URL.Query().Get(name)
+		if len(value) == 0 {
+			continue
+		}
+
+		version, parseError := strconv.ParseInt(value, 10, 64)
+		if parseError != nil || version < 0 {
+			c.SetInvalidParam(name)
+			return
+		}
+		versions[name] = version
+	}
+
+	changes, err := c.App.DiffApplicationConfigVersions(c.Params.AppId, versions["from"], versions["to"])
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.ApplicationConfigChangeListToJson(changes)))
+}
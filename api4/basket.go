@@ -63,7 +63,7 @@ func getDiscountLimits(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	discountLimits, err := c.App.GetDiscountLimits(productIds)
+	discountLimits, err := c.App.GetDiscountLimits(c.App.Session.UserId, productIds)
 	if err != nil {
 		c.Err = err
 		return
@@ -0,0 +1,292 @@
+package api4
+
+import (
+	"net/http"
+
+	"im/model"
+)
+
+func (api *API) InitCampaign() {
+	api.BaseRoutes.Campaigns.Handle("", api.ApiSessionRequired(getCampaigns)).Methods("GET")
+	api.BaseRoutes.Campaigns.Handle("", api.ApiSessionRequired(createCampaign)).Methods("POST")
+	api.BaseRoutes.Campaigns.Handle("/preview", api.ApiSessionRequired(previewCampaignSegment)).Methods("POST")
+
+	api.BaseRoutes.Campaign.Handle("", api.ApiSessionRequired(getCampaign)).Methods("GET")
+	api.BaseRoutes.Campaign.Handle("/patch", api.ApiSessionRequired(patchCampaign)).Methods("PUT")
+	api.BaseRoutes.Campaign.Handle("", api.ApiSessionRequired(deleteCampaign)).Methods("DELETE")
+	api.BaseRoutes.Campaign.Handle("/schedule", api.ApiSessionRequired(scheduleCampaign)).Methods("POST")
+	api.BaseRoutes.Campaign.Handle("/send", api.ApiSessionRequired(sendCampaign)).Methods("POST")
+	api.BaseRoutes.Campaign.Handle("/cancel", api.ApiSessionRequired(cancelCampaign)).Methods("POST")
+	api.BaseRoutes.Campaign.Handle("/stats", api.ApiSessionRequired(getCampaignStats)).Methods("GET")
+	api.BaseRoutes.Campaign.Handle("/recipients", api.ApiSessionRequired(getCampaignRecipients)).Methods("GET")
+
+	// called by the mobile app when the customer opens the campaign push
+	api.BaseRoutes.Campaign.Handle("/opened", api.ApiSessionRequired(markCampaignOpened)).Methods("POST")
+}
+
+// getCampaignForApp loads the campaign from the url and checks that it belongs to the application.
+func getCampaignForApp(c *Context) *model.Campaign {
+	c.RequireAppId().RequireCampaignId()
+	if c.Err != nil {
+		return nil
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return nil
+	}
+
+	campaign, err := c.App.GetCampaign(c.Params.CampaignId)
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	if campaign.AppId != c.Params.AppId {
+		c.SetInvalidUrlParam("campaign_id")
+		return nil
+	}
+
+	return campaign
+}
+
+func getCampaigns(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	campaigns, err := c.App.GetCampaignsForApp(c.Params.AppId, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.CampaignListToJson(campaigns)))
+}
+
+func createCampaign(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId()
+	if c.Err != nil {
+		return
+	}
+
+	campaign := model.CampaignFromJson(r.Body)
+	if campaign == nil {
+		c.SetInvalidParam("campaign")
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	campaign.AppId = c.Params.AppId
+	campaign.CreatorId = c.App.Session.UserId
+
+	rcampaign, err := c.App.CreateCampaign(campaign)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("name=" + rcampaign.Name)
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(rcampaign.ToJson()))
+}
+
+func previewCampaignSegment(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId()
+	if c.Err != nil {
+		return
+	}
+
+	segment := model.CampaignSegmentFromJson(r.Body)
+	if segment == nil {
+		c.SetInvalidParam("segment")
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	count, err := c.App.PreviewCampaignSegment(c.Params.AppId, segment)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.StringInterfaceToJson(map[string]interface{}{"total": count})))
+}
+
+func getCampaign(c *Context, w http.ResponseWriter, r *http.Request) {
+	campaign := getCampaignForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	w.Write([]byte(campaign.ToJson()))
+}
+
+func patchCampaign(c *Context, w http.ResponseWriter, r *http.Request) {
+	campaign := getCampaignForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	patch := model.CampaignPatchFromJson(r.Body)
+	if patch == nil {
+		c.SetInvalidParam("campaign")
+		return
+	}
+
+	rcampaign, err := c.App.PatchCampaign(campaign.Id, patch)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("")
+
+	w.Write([]byte(rcampaign.ToJson()))
+}
+
+func deleteCampaign(c *Context, w http.ResponseWriter, r *http.Request) {
+	campaign := getCampaignForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	if err := c.App.DeleteCampaign(campaign.Id); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("name=" + campaign.Name)
+
+	ReturnStatusOK(w)
+}
+
+func scheduleCampaign(c *Context, w http.ResponseWriter, r *http.Request) {
+	campaign := getCampaignForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	props := model.StringInterfaceFromJson(r.Body)
+	sendAt, ok := props["send_at"].(float64)
+	if !ok || sendAt <= 0 {
+		c.SetInvalidParam("send_at")
+		return
+	}
+
+	rcampaign, err := c.App.ScheduleCampaign(campaign.Id, int64(sendAt))
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("name=" + rcampaign.Name)
+
+	w.Write([]byte(rcampaign.ToJson()))
+}
+
+func sendCampaign(c *Context, w http.ResponseWriter, r *http.Request) {
+	campaign := getCampaignForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	rcampaign, err := c.App.SendCampaignNow(campaign.Id)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("name=" + rcampaign.Name)
+
+	w.Write([]byte(rcampaign.ToJson()))
+}
+
+func cancelCampaign(c *Context, w http.ResponseWriter, r *http.Request) {
+	campaign := getCampaignForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	rcampaign, err := c.App.CancelCampaign(campaign.Id)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("name=" + rcampaign.Name)
+
+	w.Write([]byte(rcampaign.ToJson()))
+}
+
+func getCampaignStats(c *Context, w http.ResponseWriter, r *http.Request) {
+	campaign := getCampaignForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	stats, err := c.App.GetCampaignStats(campaign.Id)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(stats.ToJson()))
+}
+
+func getCampaignRecipients(c *Context, w http.ResponseWriter, r *http.Request) {
+	campaign := getCampaignForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+	case model.CAMPAIGN_RECIPIENT_STATUS_PENDING:
+	case model.CAMPAIGN_RECIPIENT_STATUS_SENT:
+	case model.CAMPAIGN_RECIPIENT_STATUS_FAILED:
+	case model.CAMPAIGN_RECIPIENT_STATUS_SKIPPED:
+	default:
+		c.SetInvalidUrlParam("status")
+		return
+	}
+
+	recipients, err := c.App.GetCampaignRecipients(campaign.Id, status, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.CampaignRecipientListToJson(recipients)))
+}
+
+func markCampaignOpened(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId().RequireCampaignId()
+	if c.Err != nil {
+		return
+	}
+
+	// only the recipients of the campaign have a row to update, so no further check is needed
+	if err := c.App.MarkCampaignOpened(c.Params.CampaignId, c.App.Session.UserId); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
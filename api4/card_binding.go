@@ -0,0 +1,190 @@
+package api4
+
+import (
+	"net/http"
+
+	"im/model"
+	"im/utils"
+)
+
+func (api *API) InitCardBinding() {
+	api.BaseRoutes.CardBindings.Handle("", api.ApiSessionRequired(getUserCardBindings)).Methods("GET")
+
+	api.BaseRoutes.CardBinding.Handle("", api.ApiSessionRequired(deleteCardBinding)).Methods("DELETE")
+	api.BaseRoutes.CardBinding.Handle("/verify", api.ApiSessionRequired(verifyCardBinding)).Methods("POST")
+}
+
+// getCardBindingsUserApplication checks that the session is the customer from the url or an
+// administrator of their application, and returns that application.
+func getCardBindingsUserApplication(c *Context) *model.Application {
+	c.RequireUserId()
+	if c.Err != nil {
+		return nil
+	}
+
+	user, err := c.App.GetUser(c.Params.UserId)
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	if c.App.Session.UserId != user.Id && !c.App.SessionHasPermissionToApplication(c.App.Session, user.AppId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return nil
+	}
+
+	application, err := c.App.GetApplication(user.AppId)
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	return application
+}
+
+// getCardBindingForUser loads the binding from the url and checks that it belongs to the customer.
+func getCardBindingForUser(c *Context) *model.CardBinding {
+	c.RequireBindingId()
+	if c.Err != nil {
+		return nil
+	}
+
+	binding, err := c.App.GetCardBinding(c.Params.BindingId)
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	if binding.UserId != c.Params.UserId {
+		c.SetInvalidUrlParam("binding_id")
+		return nil
+	}
+
+	return binding
+}
+
+func getUserCardBindings(c *Context, w http.ResponseWriter, r *http.Request) {
+	application := getCardBindingsUserApplication(c)
+	if c.Err != nil {
+		return
+	}
+
+	var bindings []*model.CardBinding
+	var err *model.AppError
+
+	if r.URL.Query().Get("refresh") == "true" {
+		bindings, err = c.App.SyncUserCardBindings(c.Params.UserId, application)
+	} else {
+		bindings, err = c.App.GetUserCardBindings(c.Params.UserId)
+	}
+
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.CardBindingListToJson(bindings)))
+}
+
+func deleteCardBinding(c *Context, w http.ResponseWriter, r *http.Request) {
+	application := getCardBindingsUserApplication(c)
+	if c.Err != nil {
+		return
+	}
+
+	binding := getCardBindingForUser(c)
+	if c.Err != nil {
+		return
+	}
+
+	if err := c.App.DeleteCardBinding(binding, application); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
+
+func verifyCardBinding(c *Context, w http.ResponseWriter, r *http.Request) {
+	application := getCardBindingsUserApplication(c)
+	if c.Err != nil {
+		return
+	}
+
+	binding := getCardBindingForUser(c)
+	if c.Err != nil {
+		return
+	}
+
+	binding, err := c.App.VerifyCardBinding(binding, application)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(binding.ToJson()))
+}
+
+func payOrderWithCardBinding(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireOrderId()
+	if c.Err != nil {
+		return
+	}
+
+	req := model.CardBindingPaymentRequestFromJson(r.Body)
+	if req == nil || len(req.BindingId) != 26 {
+		c.SetInvalidParam("binding_id")
+		return
+	}
+
+	order, err := c.App.GetOrder(c.Params.OrderId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if order.UserId != c.App.Session.UserId {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	binding, err := c.App.GetCardBinding(req.BindingId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	user, err := c.App.GetUser(order.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	application, err := c.App.GetApplication(user.AppId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	payment, err := c.App.PayOrderWithCardBinding(order, binding, application, utils.GetIpAddress(r), req.Cvc)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	// without 3-D Secure the card is charged right away, otherwise the acquirer sends the customer
+	// back to the status url of the order
+	if len(payment.AcsUrl) == 0 {
+		updatePaymentOrderStatus(c, order.Id)
+		if c.Err != nil {
+			return
+		}
+
+		if payment.Order, err = c.App.GetOrder(order.Id); err != nil {
+			c.Err = err
+			return
+		}
+	}
+
+	w.Write([]byte(payment.ToJson()))
+}
@@ -0,0 +1,236 @@
+package api4
+
+import (
+	"net/http"
+
+	"im/model"
+)
+
+func (api *API) InitCourier() {
+	api.BaseRoutes.Order.Handle("/courier", api.ApiSessionRequired(assignCourier)).Methods("POST")
+	api.BaseRoutes.Order.Handle("/courier/location", api.ApiSessionRequired(getOrderCourierLocation)).Methods("GET")
+	api.BaseRoutes.Order.Handle("/handover", api.ApiSessionRequired(getOrderHandoverCode)).Methods("GET")
+	api.BaseRoutes.Order.Handle("/handover", api.ApiSessionRequired(confirmOrderHandover)).Methods("POST")
+
+	api.BaseRoutes.User.Handle("/location", api.ApiSessionRequired(updateCourierLocation)).Methods("PUT")
+	api.BaseRoutes.User.Handle("/deliveries", api.ApiSessionRequired(getCourierOrders)).Methods("GET")
+}
+
+func assignCourier(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireOrderId()
+	if c.Err != nil {
+		return
+	}
+
+	props := model.MapFromJson(r.Body)
+	courierId := props["courier_id"]
+	if len(courierId) > 0 && len(courierId) != 26 {
+		c.SetInvalidParam("courier_id")
+		return
+	}
+
+	order, err := c.App.GetOrder(c.Params.OrderId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	customer, err := c.App.GetUser(order.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, customer.AppId, model.PERMISSION_MANAGE_ORDERS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_ORDERS)
+		return
+	}
+
+	rorder, err := c.App.AssignCourier(order.Id, courierId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("order_id=" + rorder.Id + " courier_id=" + rorder.CourierId)
+
+	w.Write([]byte(rorder.ToJson()))
+}
+
+func getOrderCourierLocation(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireOrderId()
+	if c.Err != nil {
+		return
+	}
+
+	order, err := c.App.GetOrder(c.Params.OrderId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if canView, _ := c.App.SessionCanViewOrder(c.App.Session, order); !canView {
+		c.SetPermissionError(model.PERMISSION_VIEW_ORDERS)
+		return
+	}
+
+	location, err := c.App.GetOrderCourierLocation(order)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(location.ToJson()))
+}
+
+func getOrderHandoverCode(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireOrderId()
+	if c.Err != nil {
+		return
+	}
+
+	order, err := c.App.GetOrder(c.Params.OrderId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	// only the customer knows the code, the courier has to ask for it on delivery
+	if order.UserId != c.App.Session.UserId {
+		c.SetPermissionError(model.PERMISSION_VIEW_ORDERS)
+		return
+	}
+
+	code, err := c.App.GetOrderHandoverCode(order.Id)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.MapToJson(map[string]string{"code": code})))
+}
+
+func confirmOrderHandover(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireOrderId()
+	if c.Err != nil {
+		return
+	}
+
+	props := model.MapFromJson(r.Body)
+	code := props["code"]
+	if len(code) == 0 {
+		c.SetInvalidParam("code")
+		return
+	}
+
+	order, err := c.App.GetOrder(c.Params.OrderId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	customer, err := c.App.GetUser(order.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, customer.AppId, model.PERMISSION_VIEW_ASSIGNED_ORDERS) {
+		c.SetPermissionError(model.PERMISSION_VIEW_ASSIGNED_ORDERS)
+		return
+	}
+
+	rorder, err := c.App.ConfirmOrderHandover(order.Id, c.App.Session.UserId, code)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("order_id=" + rorder.Id)
+
+	w.Write([]byte(rorder.ToJson()))
+}
+
+func updateCourierLocation(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	location := model.CourierLocationFromJson(r.Body)
+	if location == nil {
+		c.SetInvalidParam("location")
+		return
+	}
+
+	if c.Params.UserId != c.App.Session.UserId {
+		c.SetPermissionError(model.PERMISSION_VIEW_ASSIGNED_ORDERS)
+		return
+	}
+
+	user, err := c.App.GetUser(c.Params.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, user.AppId, model.PERMISSION_VIEW_ASSIGNED_ORDERS) {
+		c.SetPermissionError(model.PERMISSION_VIEW_ASSIGNED_ORDERS)
+		return
+	}
+
+	location.UserId = c.Params.UserId
+
+	location, err = c.App.UpdateCourierLocation(location)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(location.ToJson()))
+}
+
+func getCourierOrders(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	user, err := c.App.GetUser(c.Params.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if c.Params.UserId != c.App.Session.UserId &&
+		!c.App.SessionHasPermissionToApplication(c.App.Session, user.AppId, model.PERMISSION_VIEW_ORDERS) {
+		c.SetPermissionError(model.PERMISSION_VIEW_ORDERS)
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, user.AppId, model.PERMISSION_VIEW_ASSIGNED_ORDERS) &&
+		!c.App.SessionHasPermissionToApplication(c.App.Session, user.AppId, model.PERMISSION_VIEW_ORDERS) {
+		c.SetPermissionError(model.PERMISSION_VIEW_ASSIGNED_ORDERS)
+		return
+	}
+
+	orders, err := c.App.GetCourierOrders(c.Params.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	list := model.NewOrderList()
+	for _, order := range orders {
+		list.AddItem(order)
+		list.AddOrder(order.Id)
+	}
+
+	list = c.App.PrepareOrderListForClient(list)
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, user.AppId, model.PERMISSION_VIEW_ORDER_CUSTOMER) {
+		list.SanitizeCustomer()
+	}
+
+	w.Write([]byte(list.ToJson()))
+}
@@ -0,0 +1,160 @@
+package api4
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"im/model"
+	"im/web"
+)
+
+// isCursorRequest tells whether the list is asked for by cursor. The lists keep the page and
+// the before and after ids of the older clients unless the limit is given.
+func isCursorRequest(r *http.Request) bool {
+	return len(r.URL.Query().Get("limit")) > 0
+}
+
+// cursorParams reads the sort, the cursor and the limit of a cursor page. The cursor must have
+// been made for the same sort.
+func cursorParams(c *Context, r *http.Request, columns map[string]string) (*model.CursorSort, *model.PageCursor, int) {
+	query := r.URL.Query()
+
+	sort, ok := model.ParseCursorSort(query.Get("sort"), columns, "create_at")
+	if !ok {
+		c.SetInvalidUrlParam("sort")
+		return nil, nil, 0
+	}
+
+	var after *model.PageCursor
+	if value := query.Get("after"); len(value) > 0 {
+		after = model.DecodePageCursor(value)
+		if after == nil || after.Sort != sort.Key() {
+			c.SetInvalidUrlParam("after")
+			return nil, nil, 0
+		}
+	}
+
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit < 0 {
+		c.SetInvalidUrlParam("limit")
+		return nil, nil, 0
+	} else if limit == 0 {
+		limit = web.PER_PAGE_DEFAULT
+	} else if limit > web.PER_PAGE_MAXIMUM {
+		limit = web.PER_PAGE_MAXIMUM
+	}
+
+	return sort, after, limit
+}
+
+// cursorRangeParams reads the creation range in milliseconds and the amount range of a list.
+func cursorRangeParams(c *Context, r *http.Request) (createdFrom int64, createdTo int64, amountFrom *float64, amountTo *float64) {
+	query := r.URL.Query()
+
+	for name, value := range map[string]*int64{"created_from": &createdFrom, "created_to": &createdTo} {
+		if s := query.Get(name); len(s) > 0 {
+			millis, err := strconv.ParseInt(s, 10, 64)
+			if err != nil || millis < 0 {
+				c.SetInvalidUrlParam(name)
+				return
+			}
+			*value = millis
+		}
+	}
+
+	for name, value := range map[string]**float64{"amount_from": &amountFrom, "amount_to": &amountTo} {
+		if s := query.Get(name); len(s) > 0 {
+			amount, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				c.SetInvalidUrlParam(name)
+				return
+			}
+			*value = &amount
+		}
+	}
+
+	return
+}
+
+func orderCursorOptions(c *Context, r *http.Request) *model.OrderCursorOptions {
+	sort, after, limit := cursorParams(c, r, model.ORDER_CURSOR_SORT_COLUMNS)
+	if c.Err != nil {
+		return nil
+	}
+
+	createdFrom, createdTo, amountFrom, amountTo := cursorRangeParams(c, r)
+	if c.Err != nil {
+		return nil
+	}
+
+	query := r.URL.Query()
+
+	var statuses []string
+	for _, status := range strings.Split(query.Get("statuses"), ",") {
+		if status = strings.TrimSpace(status); len(status) > 0 {
+			statuses = append(statuses, status)
+		}
+	}
+
+	officeId := query.Get("office_id")
+	if len(officeId) > 0 && !model.IsValidId(officeId) {
+		c.SetInvalidUrlParam("office_id")
+		return nil
+	}
+
+	return &model.OrderCursorOptions{
+		Statuses:    statuses,
+		CreatedFrom: createdFrom,
+		CreatedTo:   createdTo,
+		OfficeId:    officeId,
+		PaySystemId: query.Get("pay_system_id"),
+		AmountFrom:  amountFrom,
+		AmountTo:    amountTo,
+		Terms:       strings.TrimSpace(query.Get("terms")),
+		Sort:        sort,
+		After:       after,
+		Limit:       limit,
+	}
+}
+
+func transactionCursorOptions(c *Context, r *http.Request) *model.TransactionCursorOptions {
+	sort, after, limit := cursorParams(c, r, model.TRANSACTION_CURSOR_SORT_COLUMNS)
+	if c.Err != nil {
+		return nil
+	}
+
+	createdFrom, createdTo, amountFrom, amountTo := cursorRangeParams(c, r)
+	if c.Err != nil {
+		return nil
+	}
+
+	query := r.URL.Query()
+
+	status := query.Get("status")
+	if len(status) > 0 && status != model.TRANSACTION_STATUS_ACTIVE && status != model.TRANSACTION_STATUS_INACTIVE {
+		c.SetInvalidUrlParam("status")
+		return nil
+	}
+
+	officeId := query.Get("office_id")
+	if len(officeId) > 0 && !model.IsValidId(officeId) {
+		c.SetInvalidUrlParam("office_id")
+		return nil
+	}
+
+	return &model.TransactionCursorOptions{
+		Status:      status,
+		Type:        query.Get("type"),
+		CreatedFrom: createdFrom,
+		CreatedTo:   createdTo,
+		OfficeId:    officeId,
+		PaySystemId: query.Get("pay_system_id"),
+		AmountFrom:  amountFrom,
+		AmountTo:    amountTo,
+		Terms:       strings.TrimSpace(query.Get("terms")),
+		Sort:        sort,
+		After:       after,
+		Limit:       limit,
+	}
+}
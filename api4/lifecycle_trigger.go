@@ -0,0 +1,158 @@
+package api4
+
+import (
+	"net/http"
+
+	"im/model"
+)
+
+func (api *API) InitLifecycleTrigger() {
+	api.BaseRoutes.LifecycleTriggers.Handle("", api.ApiSessionRequired(getLifecycleTriggers)).Methods("GET")
+	api.BaseRoutes.LifecycleTriggers.Handle("", api.ApiSessionRequired(createLifecycleTrigger)).Methods("POST")
+
+	api.BaseRoutes.LifecycleTrigger.Handle("", api.ApiSessionRequired(getLifecycleTrigger)).Methods("GET")
+	api.BaseRoutes.LifecycleTrigger.Handle("/patch", api.ApiSessionRequired(patchLifecycleTrigger)).Methods("PUT")
+	api.BaseRoutes.LifecycleTrigger.Handle("", api.ApiSessionRequired(deleteLifecycleTrigger)).Methods("DELETE")
+	api.BaseRoutes.LifecycleTrigger.Handle("/logs", api.ApiSessionRequired(getLifecycleTriggerLogs)).Methods("GET")
+}
+
+// getLifecycleTriggerForApp loads the trigger from the url and checks that it belongs to the application.
+func getLifecycleTriggerForApp(c *Context) *model.LifecycleTrigger {
+	c.RequireAppId().RequireTriggerId()
+	if c.Err != nil {
+		return nil
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return nil
+	}
+
+	trigger, err := c.App.GetLifecycleTrigger(c.Params.TriggerId)
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	if trigger.AppId != c.Params.AppId {
+		c.SetInvalidUrlParam("trigger_id")
+		return nil
+	}
+
+	return trigger
+}
+
+func getLifecycleTriggers(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	triggers, err := c.App.GetLifecycleTriggersForApp(c.Params.AppId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.LifecycleTriggerListToJson(triggers)))
+}
+
+func createLifecycleTrigger(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId()
+	if c.Err != nil {
+		return
+	}
+
+	trigger := model.LifecycleTriggerFromJson(r.Body)
+	if trigger == nil {
+		c.SetInvalidParam("trigger")
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	trigger.AppId = c.Params.AppId
+	trigger.CreatorId = c.App.Session.UserId
+
+	rtrigger, err := c.App.CreateLifecycleTrigger(trigger)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("name=" + rtrigger.Name + " event=" + rtrigger.Event)
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(rtrigger.ToJson()))
+}
+
+func getLifecycleTrigger(c *Context, w http.ResponseWriter, r *http.Request) {
+	trigger := getLifecycleTriggerForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	w.Write([]byte(trigger.ToJson()))
+}
+
+func patchLifecycleTrigger(c *Context, w http.ResponseWriter, r *http.Request) {
+	trigger := getLifecycleTriggerForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	patch := model.LifecycleTriggerPatchFromJson(r.Body)
+	if patch == nil {
+		c.SetInvalidParam("trigger")
+		return
+	}
+
+	rtrigger, err := c.App.PatchLifecycleTrigger(trigger.Id, patch)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("")
+
+	w.Write([]byte(rtrigger.ToJson()))
+}
+
+func deleteLifecycleTrigger(c *Context, w http.ResponseWriter, r *http.Request) {
+	trigger := getLifecycleTriggerForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	if err := c.App.DeleteLifecycleTrigger(trigger.Id); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("name=" + trigger.Name)
+
+	ReturnStatusOK(w)
+}
+
+func getLifecycleTriggerLogs(c *Context, w http.ResponseWriter, r *http.Request) {
+	trigger := getLifecycleTriggerForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	logs, err := c.App.GetLifecycleTriggerLogs(trigger.Id, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.LifecycleTriggerLogListToJson(logs)))
+}
@@ -0,0 +1,171 @@
+package api4
+
+import (
+	"net/http"
+
+	"im/model"
+)
+
+func (api *API) InitLoyaltyTier() {
+	api.BaseRoutes.LoyaltyTiers.Handle("", api.ApiSessionRequired(getLoyaltyTiers)).Methods("GET")
+	api.BaseRoutes.LoyaltyTiers.Handle("", api.ApiSessionRequired(createLoyaltyTier)).Methods("POST")
+
+	api.BaseRoutes.LoyaltyTier.Handle("", api.ApiSessionRequired(getLoyaltyTier)).Methods("GET")
+	api.BaseRoutes.LoyaltyTier.Handle("/patch", api.ApiSessionRequired(patchLoyaltyTier)).Methods("PUT")
+	api.BaseRoutes.LoyaltyTier.Handle("", api.ApiSessionRequired(deleteLoyaltyTier)).Methods("DELETE")
+
+	api.BaseRoutes.User.Handle("/tier", api.ApiSessionRequired(getUserTier)).Methods("GET")
+}
+
+// getLoyaltyTierForApp loads the tier from the url and checks that it belongs to the application.
+func getLoyaltyTierForApp(c *Context) *model.LoyaltyTier {
+	c.RequireAppId().RequireTierId()
+	if c.Err != nil {
+		return nil
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return nil
+	}
+
+	tier, err := c.App.GetLoyaltyTier(c.Params.TierId)
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	if tier.AppId != c.Params.AppId {
+		c.SetInvalidUrlParam("tier_id")
+		return nil
+	}
+
+	return tier
+}
+
+func getLoyaltyTiers(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	tiers, err := c.App.GetLoyaltyTiersForApp(c.Params.AppId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.LoyaltyTierListToJson(tiers)))
+}
+
+func createLoyaltyTier(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId()
+	if c.Err != nil {
+		return
+	}
+
+	tier := model.LoyaltyTierFromJson(r.Body)
+	if tier == nil {
+		c.SetInvalidParam("tier")
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	tier.AppId = c.Params.AppId
+
+	rtier, err := c.App.CreateLoyaltyTier(tier)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("name=" + rtier.Name)
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(rtier.ToJson()))
+}
+
+func getLoyaltyTier(c *Context, w http.ResponseWriter, r *http.Request) {
+	tier := getLoyaltyTierForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	w.Write([]byte(tier.ToJson()))
+}
+
+func patchLoyaltyTier(c *Context, w http.ResponseWriter, r *http.Request) {
+	tier := getLoyaltyTierForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	patch := model.LoyaltyTierPatchFromJson(r.Body)
+	if patch == nil {
+		c.SetInvalidParam("tier")
+		return
+	}
+
+	rtier, err := c.App.PatchLoyaltyTier(tier.Id, patch)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("")
+
+	w.Write([]byte(rtier.ToJson()))
+}
+
+func deleteLoyaltyTier(c *Context, w http.ResponseWriter, r *http.Request) {
+	tier := getLoyaltyTierForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	if err := c.App.DeleteLoyaltyTier(tier.Id); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("name=" + tier.Name)
+
+	ReturnStatusOK(w)
+}
+
+func getUserTier(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if c.App.Session.UserId != c.Params.UserId {
+		user, err := c.App.GetUser(c.Params.UserId)
+		if err != nil {
+			c.Err = err
+			return
+		}
+
+		if !c.App.SessionHasPermissionToApplication(c.App.Session, user.AppId, model.PERMISSION_MANAGE_TEAM) {
+			c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+			return
+		}
+	}
+
+	userTier, err := c.App.GetUserTier(c.Params.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(userTier.ToJson()))
+}
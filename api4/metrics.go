@@ -15,6 +15,13 @@ func (api *API) InitMetric() {
 	api.BaseRoutes.Metrics.Handle("/bonuses", api.ApiSessionRequired(metricsForBonuses)).Methods("GET")
 	api.BaseRoutes.Metrics.Handle("/spy", api.ApiSessionRequired(metricsForSpy)).Methods("GET")
 
+	api.BaseRoutes.Metrics.Handle("/cohorts", api.ApiSessionRequired(reportCohortRetention)).Methods("GET")
+	api.BaseRoutes.Metrics.Handle("/rfm", api.ApiSessionRequired(reportRfm)).Methods("GET")
+	api.BaseRoutes.Metrics.Handle("/products", api.ApiSessionRequired(reportProductSales)).Methods("GET")
+	api.BaseRoutes.Metrics.Handle("/categories", api.ApiSessionRequired(reportCategorySales)).Methods("GET")
+	api.BaseRoutes.Metrics.Handle("/bonus_liability", api.ApiSessionRequired(reportBonusLiability)).Methods("GET")
+	api.BaseRoutes.Metrics.Handle("/average_check", api.ApiSessionRequired(reportAverageCheck)).Methods("GET")
+
 }
 
 func metricsForSpy(c *Context, w http.ResponseWriter, r *http.Request) {
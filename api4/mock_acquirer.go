@@ -0,0 +1,19 @@
+package api4
+
+import (
+	"net/http"
+)
+
+func (api *API) InitMockAcquirer() {
+	// the mock acquirer the payments go to in the sandbox mode, see App.PaymentSandboxEndpoint
+	api.BaseRoutes.ApiRoot.PathPrefix("/mock/acquirer").Handler(api.ApiHandler(serveMockAcquirer))
+}
+
+func serveMockAcquirer(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !c.App.IsMockAcquirerEnabled() {
+		http.NotFound(w, r)
+		return
+	}
+
+	c.App.MockAcquirer().ServeHTTP(w, r)
+}
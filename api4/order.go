@@ -10,6 +10,8 @@ import (
 	"im/utils"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 func (api *API) InitOrder() {
@@ -23,9 +25,14 @@ func (api *API) InitOrder() {
 	api.BaseRoutes.Order.Handle("/cancel", api.ApiHandler(cancelOrder)).Methods("GET")
 	api.BaseRoutes.Order.Handle("/prepayment", api.ApiHandler(getPaymentOrderUrl)).Methods("GET")
 	api.BaseRoutes.Order.Handle("/status", api.ApiHandler(getPaymentOrderStatus)).Methods("GET")
+	api.BaseRoutes.Order.Handle("/pay_with_binding", api.ApiSessionRequired(payOrderWithCardBinding)).Methods("POST")
+	api.BaseRoutes.Order.Handle("/pay_with_wallet", api.ApiSessionRequired(payOrderWithWallet)).Methods("POST")
+	api.BaseRoutes.Order.Handle("/documents", api.ApiSessionRequired(getOrderDocuments)).Methods("GET")
 	api.BaseRoutes.Order.Handle("", api.ApiHandler(updateOrder)).Methods("PUT")
 	api.BaseRoutes.Order.Handle("", api.ApiHandler(deleteOrder)).Methods("DELETE")
 	api.BaseRoutes.User.Handle("/orders", api.ApiSessionRequired(getUserOrders)).Methods("GET")
+	api.BaseRoutes.User.Handle("/orders/tracking", api.ApiSessionRequired(pollUserOrderTracking)).Methods("GET")
+	api.BaseRoutes.Application.Handle("/orders/board", api.ApiSessionRequired(getOrderBoard)).Methods("GET")
 
 }
 
@@ -82,9 +89,19 @@ func getOrdersStats(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stats, err := c.App.GetOrdersStats(model.OrderCountOptions{
+	options := model.OrderCountOptions{
 		AppId: user.AppId,
-	})
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, user.AppId, model.PERMISSION_VIEW_ORDERS) {
+		if !c.App.SessionHasPermissionToApplication(c.App.Session, user.AppId, model.PERMISSION_VIEW_ASSIGNED_ORDERS) {
+			c.SetPermissionError(model.PERMISSION_VIEW_ORDERS)
+			return
+		}
+		options.CourierId = c.App.Session.UserId
+	}
+
+	stats, err := c.App.GetOrdersStats(options)
 
 	if err != nil {
 		c.Err = err
@@ -119,6 +136,36 @@ func getAllOrders(c *Context, w http.ResponseWriter, r *http.Request) {
 		AppId:   user.AppId,
 	}
 
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, user.AppId, model.PERMISSION_VIEW_ORDERS) {
+		if !c.App.SessionHasPermissionToApplication(c.App.Session, user.AppId, model.PERMISSION_VIEW_ASSIGNED_ORDERS) {
+			c.SetPermissionError(model.PERMISSION_VIEW_ORDERS)
+			return
+		}
+		orderGetOptions.CourierId = c.App.Session.UserId
+	}
+
+	if isCursorRequest(r) {
+		options := orderCursorOptions(c, r)
+		if c.Err != nil {
+			return
+		}
+		options.AppId = user.AppId
+		options.CourierId = orderGetOptions.CourierId
+
+		list, err = c.App.GetOrdersByCursor(options)
+		if err != nil {
+			c.Err = err
+			return
+		}
+
+		if !c.App.SessionHasPermissionToApplication(c.App.Session, user.AppId, model.PERMISSION_VIEW_ORDER_CUSTOMER) {
+			list.SanitizeCustomer()
+		}
+
+		w.Write([]byte(list.ToJson()))
+		return
+	}
+
 	switch typeOrder {
 	case model.ORDER_STADY_CURRENT:
 		orderGetOptions.Status = model.ORDER_STADY_CURRENT
@@ -137,7 +184,41 @@ func getAllOrders(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Write([]byte(c.App.PrepareOrderListForClient(list).ToJson()))
+	list = c.App.PrepareOrderListForClient(list)
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, user.AppId, model.PERMISSION_VIEW_ORDER_CUSTOMER) {
+		list.SanitizeCustomer()
+	}
+
+	w.Write([]byte(list.ToJson()))
+}
+
+// getOrderBoard returns the open orders grouped by status for the kitchen displays. The board
+// is kept up to date with the order events of the websocket.
+func getOrderBoard(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_VIEW_ORDERS) {
+		c.SetPermissionError(model.PERMISSION_VIEW_ORDERS)
+		return
+	}
+
+	officeId := r.URL.Query().Get("office_id")
+	if len(officeId) > 0 && !model.IsValidId(officeId) {
+		c.SetInvalidUrlParam("office_id")
+		return
+	}
+
+	board, err := c.App.GetOrderBoard(c.Params.AppId, officeId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(board.ToJson()))
 }
 
 func getOrder(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -153,10 +234,46 @@ func getOrder(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	canView, canViewCustomer := c.App.SessionCanViewOrder(c.App.Session, order)
+	if !canView {
+		c.SetPermissionError(model.PERMISSION_VIEW_ORDERS)
+		return
+	}
+
+	if !canViewCustomer {
+		order.SanitizeCustomer()
+	}
+
 	w.Write([]byte(order.ToJson()))
 
 }
 
+func getOrderDocuments(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireOrderId()
+	if c.Err != nil {
+		return
+	}
+
+	order, err := c.App.GetOrder(c.Params.OrderId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if canView, _ := c.App.SessionCanViewOrder(c.App.Session, order); !canView {
+		c.SetPermissionError(model.PERMISSION_VIEW_ORDERS)
+		return
+	}
+
+	documents, err := c.App.GetOrderDocuments(order.Id)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.OrderDocumentListToJson(documents)))
+}
+
 func getPaymentOrderUrl(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequireOrderId()
 	if c.Err != nil {
@@ -186,19 +303,27 @@ func getPaymentOrderUrl(c *Context, w http.ResponseWriter, r *http.Request) {
 		application = app
 	}
 
+	// the acquirer saves the card of the customer when the order is registered with the client id.
+	// Anyone with the payment link may pay, so the card is only saved for the customer themselves.
+	var clientId string
+	if r.URL.Query().Get("save_card") == "true" && len(c.App.Session.UserId) > 0 && c.App.Session.UserId == order.UserId {
+		clientId = order.UserId
+	}
+
 	var siteURL string
 	siteURL = *c.App.Config().ServiceSettings.SiteURL
 	sandboxMode := *c.App.Config().ServiceSettings.EnableDeveloper
 
 	if application.AqType == model.SBERBANK_AQUIRING_TYPE { //foodexp-api	foodexp
-		var sber payment.SberBankBackend
-		if response, err := sber.RegisterOrder(order, sberbank.ClientConfig{
+		sber := payment.SberBankBackend{Metrics: c.App.Metrics}
+		if response, err := sber.RegisterOrder(order, c.App.BuildOrderReceipt(order, application), clientId, sberbank.ClientConfig{
 			UserName:           application.AqUsername,
 			Password:           application.AqPassword,
 			Currency:           currency.RUB,
 			Language:           "ru",
 			SessionTimeoutSecs: 1200,
 			SandboxMode:        sandboxMode,
+			SandboxEndpoint:    c.App.PaymentSandboxEndpoint(),
 			SiteURL:            siteURL,
 		}); err != nil {
 			c.Err = err
@@ -211,14 +336,15 @@ func getPaymentOrderUrl(c *Context, w http.ResponseWriter, r *http.Request) {
 			w.Write([]byte(response.ToJson()))
 		}
 	} else if application.AqType == model.ALFABANK_AQUIRING_TYPE { // yktours-api	yktours*?1
-		var alfa payment.AlfaBankBackend
-		if response, err := alfa.RegisterOrder(order, alfabank.ClientConfig{
+		alfa := payment.AlfaBankBackend{Metrics: c.App.Metrics}
+		if response, err := alfa.RegisterOrder(order, c.App.BuildOrderReceipt(order, application), clientId, alfabank.ClientConfig{
 			UserName:           application.AqUsername,
 			Password:           application.AqPassword,
 			Currency:           currency.RUB,
 			Language:           "ru",
 			SessionTimeoutSecs: 1200,
 			SandboxMode:        sandboxMode,
+			SandboxEndpoint:    c.App.PaymentSandboxEndpoint(),
 			SiteURL:            siteURL,
 		}); err != nil {
 			c.Err = err
@@ -250,6 +376,17 @@ func updateOrder(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	order, err := c.App.GetOrder(c.Params.OrderId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if permission := c.App.MissingPermissionToPatchOrder(c.App.Session, order, patch); permission != nil {
+		c.SetPermissionError(permission)
+		return
+	}
+
 	rorder, err := c.App.UpdateOrder(c.Params.OrderId, patch, false)
 	if err != nil {
 		c.Err = err
@@ -339,6 +476,29 @@ func getUserOrders(c *Context, w http.ResponseWriter, r *http.Request) {
 	var list *model.OrderList
 	var err *model.AppError
 	//etag := ""
+
+	if isCursorRequest(r) {
+		if c.App.Session.UserId != c.Params.UserId && !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_VIEW_ORDERS) {
+			c.SetPermissionError(model.PERMISSION_VIEW_ORDERS)
+			return
+		}
+
+		options := orderCursorOptions(c, r)
+		if c.Err != nil {
+			return
+		}
+		options.AppId = c.Params.AppId
+		options.UserId = c.Params.UserId
+
+		if list, err = c.App.GetOrdersByCursor(options); err != nil {
+			c.Err = err
+			return
+		}
+
+		w.Write([]byte(list.ToJson()))
+		return
+	}
+
 	sort := r.URL.Query().Get("sort")
 	orderGetOptions := &model.OrderGetOptions{
 		Sort:    sort,
@@ -358,132 +518,243 @@ func getUserOrders(c *Context, w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(list.ToJson()))
 }
 
-func getPaymentOrderStatus(c *Context, w http.ResponseWriter, r *http.Request) {
-	c.RequireOrderId()
+// pollUserOrderTracking is the long poll fallback of the order tracking websocket events. It
+// answers at once with the orders changed after since, or waits for the next change.
+func pollUserOrderTracking(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
 	if c.Err != nil {
 		return
 	}
-	c.App.Srv.Go(func() {
-		order, err := c.App.GetOrder(c.Params.OrderId)
 
-		if err != nil {
-			c.Err = err
-			mlog.Warn(err.Error())
-			return
+	if c.Params.UserId != c.App.Session.UserId {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	query := r.URL.Query()
+
+	var orderIds []string
+	for _, orderId := range strings.Split(query.Get("order_ids"), ",") {
+		if orderId = strings.TrimSpace(orderId); len(orderId) > 0 {
+			orderIds = append(orderIds, orderId)
 		}
+	}
+	if len(orderIds) == 0 || len(orderIds) > model.ORDER_TRACKING_MAX_ORDERS {
+		c.SetInvalidUrlParam("order_ids")
+		return
+	}
 
-		var appId string
-		if user, err := c.App.GetUser(order.UserId); err != nil {
-			c.Err = err
-			mlog.Warn(err.Error())
+	var since int64
+	if s := query.Get("since"); len(s) > 0 {
+		var parseError error
+		if since, parseError = strconv.ParseInt(s, 10, 64); parseError != nil {
+			c.SetInvalidUrlParam("since")
 			return
-		} else {
-			appId = user.AppId
 		}
+	}
 
-		var application *model.Application
-		if app, err := c.App.GetApplication(appId); err != nil {
-			c.Err = err
-			mlog.Warn(err.Error())
+	timeout := model.ORDER_TRACKING_POLL_TIMEOUT_DEFAULT
+	if s := query.Get("timeout"); len(s) > 0 {
+		var parseError error
+		if timeout, parseError = strconv.Atoi(s); parseError != nil || timeout < 0 {
+			c.SetInvalidUrlParam("timeout")
 			return
-		} else {
-			application = app
 		}
+		if timeout > model.ORDER_TRACKING_POLL_TIMEOUT_MAXIMUM {
+			timeout = model.ORDER_TRACKING_POLL_TIMEOUT_MAXIMUM
+		}
+	}
 
-		var msg string
-		var siteURL string
-		siteURL = *c.App.Config().ServiceSettings.SiteURL
-		sandboxMode := *c.App.Config().ServiceSettings.EnableDeveloper
+	trackings, err := c.App.WaitForOrderTrackings(c.App.Session.UserId, orderIds, since, time.Duration(timeout)*time.Second)
+	if err != nil {
+		c.Err = err
+		return
+	}
 
-		if order.PaySystemId == model.SBERBANK_AQUIRING_TYPE { // foodexp-api	foodexp
-			var sber payment.SberBankBackend
-			if response, err := sber.GetOrderStatus(order, sberbank.ClientConfig{
-				UserName:           application.AqUsername,
-				Password:           application.AqPassword,
-				Currency:           currency.RUB,
-				Language:           "ru",
-				SessionTimeoutSecs: 1200,
-				SandboxMode:        sandboxMode,
-				SiteURL:            siteURL,
-			}); err != nil {
-				mlog.Warn(err.Error())
-			} else {
-				if response.OrderStatus == payment.SBERBANK_ORDER_STATUS_PAYED {
-					c.App.UpdateOrder(order.Id, &model.OrderPatch{Status: model.NewString(model.ORDER_STATUS_AWAITING_FULFILLMENT)}, false)
+	w.Write([]byte(model.OrderTrackingListToJson(trackings)))
+}
 
-					msg = "Оплата банковской картой "
-					msg += response.CardAuthInfo.MaskedPan
-					msg += ". № заказа " + order.FormatOrderNumber()
+func getPaymentOrderStatus(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireOrderId()
+	if c.Err != nil {
+		return
+	}
+	c.App.Srv.Go(func() {
+		updatePaymentOrderStatus(c, c.Params.OrderId)
+	})
 
-					post := &model.Post{
-						UserId:   order.UserId,
-						Message:  msg,
-						CreateAt: model.GetMillis() + 1,
-						Type:     model.POST_WITH_TRANSACTION,
-					}
+	ReturnStatusOK(w)
+}
 
-					c.App.CreatePostWithTransaction(post, false)
-				} else {
-					msg = "Оплата банковской картой не произведена"
-					msg += ". № заказа " + order.FormatOrderNumber()
+// updatePaymentOrderStatus asks the acquirer whether the order was paid and lets the customer know.
+func updatePaymentOrderStatus(c *Context, orderId string) {
+	order, err := c.App.GetOrder(orderId)
 
-					post := &model.Post{
-						UserId:   order.UserId,
-						Message:  msg,
-						CreateAt: model.GetMillis() + 1,
-						Type:     model.POST_WITH_TRANSACTION,
+	if err != nil {
+		c.Err = err
+		mlog.Warn(err.Error())
+		return
+	}
+
+	var appId string
+	if user, err := c.App.GetUser(order.UserId); err != nil {
+		c.Err = err
+		mlog.Warn(err.Error())
+		return
+	} else {
+		appId = user.AppId
+	}
+
+	var application *model.Application
+	if app, err := c.App.GetApplication(appId); err != nil {
+		c.Err = err
+		mlog.Warn(err.Error())
+		return
+	} else {
+		application = app
+	}
+
+	var msg string
+	var siteURL string
+	siteURL = *c.App.Config().ServiceSettings.SiteURL
+	sandboxMode := *c.App.Config().ServiceSettings.EnableDeveloper
+
+	if order.PaySystemId == model.SBERBANK_AQUIRING_TYPE { // foodexp-api	foodexp
+		sber := payment.SberBankBackend{Metrics: c.App.Metrics}
+		if response, err := sber.GetOrderStatus(order, sberbank.ClientConfig{
+			UserName:           application.AqUsername,
+			Password:           application.AqPassword,
+			Currency:           currency.RUB,
+			Language:           "ru",
+			SessionTimeoutSecs: 1200,
+			SandboxMode:        sandboxMode,
+			SandboxEndpoint:    c.App.PaymentSandboxEndpoint(),
+			SiteURL:            siteURL,
+		}); err != nil {
+			mlog.Warn(err.Error())
+		} else {
+			if response.OrderStatus == payment.SBERBANK_ORDER_STATUS_PAYED {
+				if !order.Payed {
+					if err := c.App.RequestOrderReceipt(order, application); err != nil {
+						mlog.Warn(err.Error())
 					}
 
-					c.App.CreatePostWithTransaction(post, false)
+					// the card is saved once the order registered with the client id is paid
+					if _, err := c.App.SyncUserCardBindings(order.UserId, application); err != nil {
+						mlog.Warn(err.Error())
+					}
 				}
-			}
-		} else if order.PaySystemId == model.ALFABANK_AQUIRING_TYPE { // yktours-api	yktours*?1
-			var alfa payment.AlfaBankBackend
-			if response, err := alfa.GetOrderStatus(order, alfabank.ClientConfig{
-				UserName:           application.AqUsername,
-				Password:           application.AqPassword,
-				Currency:           currency.RUB,
-				Language:           "ru",
-				SessionTimeoutSecs: 1200,
-				SandboxMode:        sandboxMode,
-				SiteURL:            siteURL,
-			}); err != nil {
-				mlog.Warn(err.Error())
+
+				c.App.UpdateOrder(order.Id, &model.OrderPatch{Status: model.NewString(model.ORDER_STATUS_AWAITING_FULFILLMENT)}, false)
+				if err := c.App.SetOrderCardMask(order.Id, response.CardAuthInfo.MaskedPan); err != nil {
+					mlog.Warn(err.Error())
+				}
+
+				// the order is paid once the card and the other tenders cover it
+				if _, err := c.App.AddOrderPayment(order, &model.OrderPayment{
+					Tender:      model.ORDER_PAYMENT_TENDER_CARD,
+					Amount:      float64(response.Amount) / 100,
+					PaySystemId: order.PaySystemId,
+					Reference:   order.PaySystemCode,
+				}); err != nil {
+					mlog.Warn(err.Error())
+				}
+
+				msg = "Оплата банковской картой "
+				msg += response.CardAuthInfo.MaskedPan
+				msg += ". № заказа " + order.FormatOrderNumber()
+
+				post := &model.Post{
+					UserId:   order.UserId,
+					Message:  msg,
+					CreateAt: model.GetMillis() + 1,
+					Type:     model.POST_WITH_TRANSACTION,
+				}
+
+				c.App.CreatePostWithTransaction(post, false)
 			} else {
-				if response.OrderStatus == payment.ALFABANK_ORDER_STATUS_PAYED {
-					c.App.UpdateOrder(order.Id, &model.OrderPatch{Status: model.NewString(model.ORDER_STATUS_AWAITING_FULFILLMENT)}, false)
+				msg = "Оплата банковской картой не произведена"
+				msg += ". № заказа " + order.FormatOrderNumber()
+
+				post := &model.Post{
+					UserId:   order.UserId,
+					Message:  msg,
+					CreateAt: model.GetMillis() + 1,
+					Type:     model.POST_WITH_TRANSACTION,
+				}
 
-					msg = "Оплата банковской картой "
-					msg += response.CardAuthInfo.MaskedPan
-					msg += ". № заказа " + order.FormatOrderNumber()
+				c.App.CreatePostWithTransaction(post, false)
+			}
+		}
+	} else if order.PaySystemId == model.ALFABANK_AQUIRING_TYPE { // yktours-api	yktours*?1
+		alfa := payment.AlfaBankBackend{Metrics: c.App.Metrics}
+		if response, err := alfa.GetOrderStatus(order, alfabank.ClientConfig{
+			UserName:           application.AqUsername,
+			Password:           application.AqPassword,
+			Currency:           currency.RUB,
+			Language:           "ru",
+			SessionTimeoutSecs: 1200,
+			SandboxMode:        sandboxMode,
+			SandboxEndpoint:    c.App.PaymentSandboxEndpoint(),
+			SiteURL:            siteURL,
+		}); err != nil {
+			mlog.Warn(err.Error())
+		} else {
+			if response.OrderStatus == payment.ALFABANK_ORDER_STATUS_PAYED {
+				if !order.Payed {
+					if err := c.App.RequestOrderReceipt(order, application); err != nil {
+						mlog.Warn(err.Error())
+					}
 
-					post := &model.Post{
-						UserId:   order.UserId,
-						Message:  msg,
-						CreateAt: model.GetMillis() + 1,
-						Type:     model.POST_WITH_TRANSACTION,
+					// the card is saved once the order registered with the client id is paid
+					if _, err := c.App.SyncUserCardBindings(order.UserId, application); err != nil {
+						mlog.Warn(err.Error())
 					}
+				}
 
-					c.App.CreatePostWithTransaction(post, false)
+				c.App.UpdateOrder(order.Id, &model.OrderPatch{Status: model.NewString(model.ORDER_STATUS_AWAITING_FULFILLMENT)}, false)
+				if err := c.App.SetOrderCardMask(order.Id, response.CardAuthInfo.MaskedPan); err != nil {
+					mlog.Warn(err.Error())
+				}
 
-				} else {
-					msg = "Оплата банковской картой не произведена"
-					msg += ". № заказа " + order.FormatOrderNumber()
+				// the order is paid once the card and the other tenders cover it
+				if _, err := c.App.AddOrderPayment(order, &model.OrderPayment{
+					Tender:      model.ORDER_PAYMENT_TENDER_CARD,
+					Amount:      float64(response.Amount) / 100,
+					PaySystemId: order.PaySystemId,
+					Reference:   order.PaySystemCode,
+				}); err != nil {
+					mlog.Warn(err.Error())
+				}
 
-					post := &model.Post{
-						UserId:   order.UserId,
-						Message:  msg,
-						CreateAt: model.GetMillis() + 1,
-						Type:     model.POST_WITH_TRANSACTION,
-					}
+				msg = "Оплата банковской картой "
+				msg += response.CardAuthInfo.MaskedPan
+				msg += ". № заказа " + order.FormatOrderNumber()
 
-					c.App.CreatePostWithTransaction(post, false)
+				post := &model.Post{
+					UserId:   order.UserId,
+					Message:  msg,
+					CreateAt: model.GetMillis() + 1,
+					Type:     model.POST_WITH_TRANSACTION,
 				}
+
+				c.App.CreatePostWithTransaction(post, false)
+
+			} else {
+				msg = "Оплата банковской картой не произведена"
+				msg += ". № заказа " + order.FormatOrderNumber()
+
+				post := &model.Post{
+					UserId:   order.UserId,
+					Message:  msg,
+					CreateAt: model.GetMillis() + 1,
+					Type:     model.POST_WITH_TRANSACTION,
+				}
+
+				c.App.CreatePostWithTransaction(post, false)
 			}
 		}
-	})
-
-	ReturnStatusOK(w)
+	}
 }
 
 func cancelOrder(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -548,7 +819,7 @@ func cancelOrder(c *Context, w http.ResponseWriter, r *http.Request) {
 		sandboxMode := *c.App.Config().ServiceSettings.EnableDeveloper
 
 		if order.PaySystemId == model.SBERBANK_AQUIRING_TYPE { // foodexp-api	foodexp
-			var sber payment.SberBankBackend
+			sber := payment.SberBankBackend{Metrics: c.App.Metrics}
 			if response, err := sber.GetReverseOrderResponse(order, sberbank.ClientConfig{
 				UserName:           application.AqUsername,
 				Password:           application.AqPassword,
@@ -556,6 +827,7 @@ func cancelOrder(c *Context, w http.ResponseWriter, r *http.Request) {
 				Language:           "ru",
 				SessionTimeoutSecs: 1200,
 				SandboxMode:        sandboxMode,
+				SandboxEndpoint:    c.App.PaymentSandboxEndpoint(),
 				SiteURL:            siteURL,
 			}); err != nil {
 				c.Err = err
@@ -599,7 +871,7 @@ func cancelOrder(c *Context, w http.ResponseWriter, r *http.Request) {
 				}
 			}
 		} else if order.PaySystemId == model.ALFABANK_AQUIRING_TYPE { // yktours-api	yktours*?1
-			var alfa payment.AlfaBankBackend
+			alfa := payment.AlfaBankBackend{Metrics: c.App.Metrics}
 			if response, err := alfa.GetReverseOrderResponse(order, alfabank.ClientConfig{
 				UserName:           application.AqUsername,
 				Password:           application.AqPassword,
@@ -607,6 +879,7 @@ func cancelOrder(c *Context, w http.ResponseWriter, r *http.Request) {
 				Language:           "ru",
 				SessionTimeoutSecs: 1200,
 				SandboxMode:        sandboxMode,
+				SandboxEndpoint:    c.App.PaymentSandboxEndpoint(),
 				SiteURL:            siteURL,
 			}); err != nil {
 				c.Err = err
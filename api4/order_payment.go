@@ -0,0 +1,122 @@
+package api4
+
+import (
+	"fmt"
+	"net/http"
+
+	"im/model"
+)
+
+func (api *API) InitOrderPayment() {
+	api.BaseRoutes.Order.Handle("/payments", api.ApiSessionRequired(getOrderPayments)).Methods("GET")
+	api.BaseRoutes.Order.Handle("/payments", api.ApiSessionRequired(createOrderPayment)).Methods("POST")
+	api.BaseRoutes.Order.Handle("/refund", api.ApiSessionRequired(refundOrder)).Methods("POST")
+}
+
+// getOrderForCashier loads the order from the url and checks that the session may take
+// payments for it.
+func getOrderForCashier(c *Context) *model.Order {
+	c.RequireOrderId()
+	if c.Err != nil {
+		return nil
+	}
+
+	order, err := c.App.GetOrder(c.Params.OrderId)
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	customer, err := c.App.GetUser(order.UserId)
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, customer.AppId, model.PERMISSION_EDIT_ORDER_PAYMENT) {
+		c.SetPermissionError(model.PERMISSION_EDIT_ORDER_PAYMENT)
+		return nil
+	}
+
+	return order
+}
+
+func getOrderPayments(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireOrderId()
+	if c.Err != nil {
+		return
+	}
+
+	order, err := c.App.GetOrder(c.Params.OrderId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if order.UserId != c.App.Session.UserId {
+		customer, err := c.App.GetUser(order.UserId)
+		if err != nil {
+			c.Err = err
+			return
+		}
+
+		if !c.App.SessionHasPermissionToApplication(c.App.Session, customer.AppId, model.PERMISSION_VIEW_ORDERS) {
+			c.SetPermissionError(model.PERMISSION_VIEW_ORDERS)
+			return
+		}
+	}
+
+	w.Write([]byte(model.OrderPaymentListToJson(order.Payments)))
+}
+
+func createOrderPayment(c *Context, w http.ResponseWriter, r *http.Request) {
+	req := model.OrderPaymentRequestFromJson(r.Body)
+	if req == nil || !model.IsInPersonOrderPaymentTender(req.Tender) {
+		c.SetInvalidParam("tender")
+		return
+	}
+
+	if req.Amount <= 0 {
+		c.SetInvalidParam("amount")
+		return
+	}
+
+	order := getOrderForCashier(c)
+	if c.Err != nil {
+		return
+	}
+
+	orderPayment, err := c.App.AddInPersonOrderPayment(order, req, c.App.Session.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit(fmt.Sprintf("order_id=%s tender=%s amount=%.2f", order.Id, orderPayment.Tender, orderPayment.Amount))
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(orderPayment.ToJson()))
+}
+
+func refundOrder(c *Context, w http.ResponseWriter, r *http.Request) {
+	req := model.OrderRefundRequestFromJson(r.Body)
+	if req == nil || req.Amount <= 0 {
+		c.SetInvalidParam("amount")
+		return
+	}
+
+	order := getOrderForCashier(c)
+	if c.Err != nil {
+		return
+	}
+
+	payments, err := c.App.RefundOrder(order, req.Amount)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit(fmt.Sprintf("order_id=%s amount=%.2f", order.Id, req.Amount))
+
+	w.Write([]byte(model.OrderPaymentListToJson(payments)))
+}
@@ -0,0 +1,254 @@
+package api4
+
+import (
+	"net/http"
+	"strings"
+
+	"im/model"
+)
+
+func (api *API) InitPos() {
+	api.BaseRoutes.Application.Handle("/pos/mappings", api.ApiSessionRequired(getPosProductMappings)).Methods("GET")
+	api.BaseRoutes.Application.Handle("/pos/mappings", api.ApiSessionRequired(savePosProductMapping)).Methods("POST")
+	api.BaseRoutes.Application.Handle("/pos/mappings/{product_id:[A-Za-z0-9]+}", api.ApiSessionRequired(deletePosProductMapping)).Methods("DELETE")
+	api.BaseRoutes.Application.Handle("/pos/orders", api.ApiSessionRequired(getPosOrderSyncs)).Methods("GET")
+	api.BaseRoutes.Application.Handle("/pos/key", api.ApiSessionRequired(regeneratePosApiKey)).Methods("POST")
+
+	// status feedback from the POS, authenticated with the POS api key of the application
+	api.BaseRoutes.Application.Handle("/pos/status", api.ApiHandler(updateOrderFromPos)).Methods("POST")
+
+	api.BaseRoutes.Order.Handle("/pos", api.ApiSessionRequired(getPosOrderSync)).Methods("GET")
+	api.BaseRoutes.Order.Handle("/pos/sync", api.ApiSessionRequired(exportOrderToPos)).Methods("POST")
+}
+
+func getPosProductMappings(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	mappings, err := c.App.GetPosProductMappings(c.Params.AppId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.PosProductMappingListToJson(mappings)))
+}
+
+func savePosProductMapping(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId()
+	if c.Err != nil {
+		return
+	}
+
+	mapping := model.PosProductMappingFromJson(r.Body)
+	if mapping == nil {
+		c.SetInvalidParam("mapping")
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	mapping.AppId = c.Params.AppId
+
+	rmapping, err := c.App.SavePosProductMapping(mapping)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("product_id=" + rmapping.ProductId + " external_id=" + rmapping.ExternalId)
+
+	w.Write([]byte(rmapping.ToJson()))
+}
+
+func deletePosProductMapping(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId().RequireProductId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	if err := c.App.DeletePosProductMapping(c.Params.AppId, c.Params.ProductId); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("product_id=" + c.Params.ProductId)
+
+	ReturnStatusOK(w)
+}
+
+func getPosOrderSyncs(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId()
+	if c.Err != nil {
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+	case model.POS_SYNC_STATUS_PENDING:
+	case model.POS_SYNC_STATUS_SYNCED:
+	case model.POS_SYNC_STATUS_FAILED:
+	default:
+		c.SetInvalidUrlParam("status")
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_ORDERS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_ORDERS)
+		return
+	}
+
+	syncs, err := c.App.GetPosOrderSyncs(c.Params.AppId, status, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.PosOrderSyncListToJson(syncs)))
+}
+
+func getPosOrderSync(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireOrderId()
+	if c.Err != nil {
+		return
+	}
+
+	order, err := c.App.GetOrder(c.Params.OrderId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	customer, err := c.App.GetUser(order.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, customer.AppId, model.PERMISSION_MANAGE_ORDERS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_ORDERS)
+		return
+	}
+
+	sync, err := c.App.GetPosOrderSync(order.Id)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(sync.ToJson()))
+}
+
+func exportOrderToPos(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireOrderId()
+	if c.Err != nil {
+		return
+	}
+
+	order, err := c.App.GetOrder(c.Params.OrderId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	customer, err := c.App.GetUser(order.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, customer.AppId, model.PERMISSION_MANAGE_ORDERS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_ORDERS)
+		return
+	}
+
+	sync, err := c.App.ExportOrderToPos(order)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if sync == nil {
+		c.Err = model.NewAppError("exportOrderToPos", "api.pos.export_order.not_configured.app_error", nil, "app_id="+customer.AppId, http.StatusBadRequest)
+		return
+	}
+
+	c.LogAudit("order_id=" + order.Id + " status=" + sync.Status)
+
+	w.Write([]byte(sync.ToJson()))
+}
+
+func updateOrderFromPos(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId()
+	if c.Err != nil {
+		return
+	}
+
+	status := model.PosOrderStatusFromJson(r.Body)
+	if status == nil || (len(status.ExternalId) == 0 && len(status.OrderId) != 26) {
+		c.SetInvalidParam("status")
+		return
+	}
+
+	application, err := c.App.GetApplication(c.Params.AppId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	apiKey := r.Header.Get(model.HEADER_AUTH)
+	if len(apiKey) > len(model.HEADER_BEARER) && strings.ToUpper(apiKey[0:len(model.HEADER_BEARER)]) == model.HEADER_BEARER {
+		apiKey = strings.TrimSpace(apiKey[len(model.HEADER_BEARER):])
+	}
+
+	if !c.App.IsValidPosApiKey(application, apiKey) {
+		c.Err = model.NewAppError("updateOrderFromPos", "api.pos.update_order.invalid_key.app_error", nil, "app_id="+application.Id, http.StatusUnauthorized)
+		return
+	}
+
+	order, err := c.App.UpdateOrderFromPos(application.Id, status)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.MapToJson(map[string]string{"order_id": order.Id, "status": order.Status})))
+}
+
+func regeneratePosApiKey(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	key, err := c.App.RegeneratePosApiKey(c.Params.AppId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("app_id=" + c.Params.AppId)
+
+	w.Write([]byte(model.MapToJson(map[string]string{"key": key})))
+}
@@ -0,0 +1,207 @@
+package api4
+
+import (
+	"net/http"
+	"strings"
+
+	"im/model"
+)
+
+func (api *API) InitPrintTicket() {
+	// the print agent of the office, authenticated with the print agent key of the office
+	api.BaseRoutes.Office.Handle("/print/queue", api.ApiHandler(getPrintQueue)).Methods("GET")
+	api.BaseRoutes.Office.Handle("/print/tickets/{ticket_id:[A-Za-z0-9]+}/ack", api.ApiHandler(ackPrintTicket)).Methods("POST")
+
+	api.BaseRoutes.Office.Handle("/print/key", api.ApiSessionRequired(regeneratePrintAgentKey)).Methods("POST")
+
+	api.BaseRoutes.Order.Handle("/print", api.ApiSessionRequired(getOrderPrintTickets)).Methods("GET")
+	api.BaseRoutes.Order.Handle("/print", api.ApiSessionRequired(reprintOrderTicket)).Methods("POST")
+	api.BaseRoutes.Order.Handle("/print/{ticket_id:[A-Za-z0-9]+}/pdf", api.ApiSessionRequired(getPrintTicketPdf)).Methods("GET")
+}
+
+// requirePrintAgent returns the office when the request carries the key of its print agent.
+func requirePrintAgent(c *Context, r *http.Request) *model.Office {
+	c.RequireOfficeId()
+	if c.Err != nil {
+		return nil
+	}
+
+	office, err := c.App.GetOffice(c.Params.OfficeId)
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	key := r.Header.Get(model.HEADER_AUTH)
+	if len(key) > len(model.HEADER_BEARER) && strings.ToUpper(key[0:len(model.HEADER_BEARER)]) == model.HEADER_BEARER {
+		key = strings.TrimSpace(key[len(model.HEADER_BEARER):])
+	}
+
+	if !c.App.IsValidPrintAgentKey(office, key) {
+		c.Err = model.NewAppError("requirePrintAgent", "api.print_ticket.invalid_key.app_error", nil, "office_id="+office.Id, http.StatusUnauthorized)
+		return nil
+	}
+
+	return office
+}
+
+func getPrintQueue(c *Context, w http.ResponseWriter, r *http.Request) {
+	office := requirePrintAgent(c, r)
+	if c.Err != nil {
+		return
+	}
+
+	tickets, err := c.App.TakePrintQueue(office)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.PrintTicketListToJson(tickets)))
+}
+
+func ackPrintTicket(c *Context, w http.ResponseWriter, r *http.Request) {
+	office := requirePrintAgent(c, r)
+	c.RequireTicketId()
+	if c.Err != nil {
+		return
+	}
+
+	ack := model.PrintTicketAckFromJson(r.Body)
+	if ack == nil || !ack.IsValid() {
+		c.SetInvalidParam("ack")
+		return
+	}
+
+	ticket, err := c.App.AckPrintTicket(office.Id, c.Params.TicketId, ack)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(ticket.ToJson()))
+}
+
+func regeneratePrintAgentKey(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireOfficeId()
+	if c.Err != nil {
+		return
+	}
+
+	office, err := c.App.GetOffice(c.Params.OfficeId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, office.AppId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	key, err := c.App.RegeneratePrintAgentKey(office.Id)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("office_id=" + office.Id)
+
+	w.Write([]byte(model.MapToJson(map[string]string{"key": key})))
+}
+
+// orderForPrinting returns the order when the session may print it, with the permission the
+// staff needs in the application of the customer.
+func orderForPrinting(c *Context, permission *model.Permission) *model.Order {
+	c.RequireOrderId()
+	if c.Err != nil {
+		return nil
+	}
+
+	order, err := c.App.GetOrder(c.Params.OrderId)
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	customer, err := c.App.GetUser(order.UserId)
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, customer.AppId, permission) {
+		c.SetPermissionError(permission)
+		return nil
+	}
+
+	return order
+}
+
+func getOrderPrintTickets(c *Context, w http.ResponseWriter, r *http.Request) {
+	order := orderForPrinting(c, model.PERMISSION_VIEW_ORDERS)
+	if c.Err != nil {
+		return
+	}
+
+	tickets, err := c.App.GetOrderPrintTickets(order.Id)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.PrintTicketListToJson(tickets)))
+}
+
+func reprintOrderTicket(c *Context, w http.ResponseWriter, r *http.Request) {
+	order := orderForPrinting(c, model.PERMISSION_MANAGE_ORDERS)
+	if c.Err != nil {
+		return
+	}
+
+	officeId := r.URL.Query().Get("office_id")
+	if len(officeId) > 0 && len(officeId) != 26 {
+		c.SetInvalidUrlParam("office_id")
+		return
+	}
+
+	tickets, err := c.App.QueueOrderTickets(order, model.PRINT_TICKET_REASON_REPRINT, officeId, c.App.Session.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("order_id=" + order.Id)
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(model.PrintTicketListToJson(tickets)))
+}
+
+func getPrintTicketPdf(c *Context, w http.ResponseWriter, r *http.Request) {
+	order := orderForPrinting(c, model.PERMISSION_VIEW_ORDERS)
+	c.RequireTicketId()
+	if c.Err != nil {
+		return
+	}
+
+	ticket, err := c.App.GetPrintTicket(c.Params.TicketId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if ticket.OrderId != order.Id {
+		c.SetInvalidUrlParam("ticket_id")
+		return
+	}
+
+	content, err := c.App.RenderPrintTicket(ticket, model.PRINT_FORMAT_PDF)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", "inline; filename=\"ticket_"+ticket.Id+".pdf\"")
+	w.Write(content)
+}
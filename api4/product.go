@@ -5,6 +5,7 @@ import (
 	"im/mlog"
 	"im/model"
 	"im/utils"
+	"im/web"
 	"net/http"
 )
 
@@ -117,42 +118,34 @@ func searchProducts(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	params := model.ProductSearchFromJson(r.Body)
-	if params.Terms == nil || len(*params.Terms) == 0 {
-		c.SetInvalidParam("terms")
+	if params == nil {
+		c.SetInvalidParam("search")
 		return
 	}
-	terms := *params.Terms
 
-	var categoryId string = ""
-	if params.CategoryId != nil {
-		categoryId = *params.CategoryId
+	searchParams := params.ToParams()
+	if len(searchParams.Terms) == 0 && len(searchParams.CategoryId) == 0 && len(searchParams.Tags) == 0 {
+		c.SetInvalidParam("terms")
+		return
 	}
 
-	timeZoneOffset := 0
-	if params.TimeZoneOffset != nil {
-		timeZoneOffset = *params.TimeZoneOffset
+	if searchParams.PerPage <= 0 || searchParams.PerPage > web.PER_PAGE_MAXIMUM {
+		searchParams.PerPage = web.PER_PAGE_MAXIMUM
 	}
 
-	page := 0
-	if params.Page != nil {
-		page = *params.Page
+	var appId string
+	if params.AppId != nil {
+		appId = *params.AppId
 	}
 
-	perPage := 60
-	if params.PerPage != nil {
-		perPage = *params.PerPage
-	}
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
 
-	if results, err := c.App.SearchProducts(terms, categoryId, timeZoneOffset, page, perPage); err != nil {
-		var pl model.ProductList
-		pl.MakeNonNil()
-		w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-		w.Write([]byte(pl.ToJson()))
+	if results, err := c.App.SearchProducts(appId, searchParams); err != nil {
+		mlog.Warn("Failed to search products", mlog.String("app_id", appId), mlog.Err(err))
 
-		/*c.Err = err
-		return*/
+		results = &model.ProductSearchResults{ProductList: model.NewProductList()}
+		w.Write([]byte(results.ToJson()))
 	} else {
-		w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
 		w.Write([]byte(results.ToJson()))
 	}
 }
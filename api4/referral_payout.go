@@ -0,0 +1,110 @@
+package api4
+
+import (
+	"net/http"
+
+	"im/model"
+)
+
+func (api *API) InitReferralPayout() {
+	api.BaseRoutes.ReferralPayouts.Handle("", api.ApiSessionRequired(getReferralPayouts)).Methods("GET")
+
+	api.BaseRoutes.ReferralPayout.Handle("", api.ApiSessionRequired(getReferralPayout)).Methods("GET")
+	api.BaseRoutes.ReferralPayout.Handle("/approve", api.ApiSessionRequired(approveReferralPayout)).Methods("POST")
+	api.BaseRoutes.ReferralPayout.Handle("/reject", api.ApiSessionRequired(rejectReferralPayout)).Methods("POST")
+}
+
+// getReferralPayoutForApp loads the payout from the url and checks that it belongs to the application.
+func getReferralPayoutForApp(c *Context) *model.ReferralPayout {
+	c.RequireAppId().RequirePayoutId()
+	if c.Err != nil {
+		return nil
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return nil
+	}
+
+	payout, err := c.App.GetReferralPayout(c.Params.PayoutId)
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	if payout.AppId != c.Params.AppId {
+		c.SetInvalidUrlParam("payout_id")
+		return nil
+	}
+
+	return payout
+}
+
+func getReferralPayouts(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId()
+	if c.Err != nil {
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if len(status) > 0 && !model.IsValidReferralPayoutStatus(status) {
+		c.SetInvalidParam("status")
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	payouts, err := c.App.GetReferralPayoutsForApp(c.Params.AppId, status, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.ReferralPayoutListToJson(payouts)))
+}
+
+func getReferralPayout(c *Context, w http.ResponseWriter, r *http.Request) {
+	payout := getReferralPayoutForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	w.Write([]byte(payout.ToJson()))
+}
+
+func approveReferralPayout(c *Context, w http.ResponseWriter, r *http.Request) {
+	payout := getReferralPayoutForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	rpayout, err := c.App.ApproveReferralPayout(payout.Id, c.App.Session.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("payout_id=" + rpayout.Id + " status=" + rpayout.Status)
+
+	w.Write([]byte(rpayout.ToJson()))
+}
+
+func rejectReferralPayout(c *Context, w http.ResponseWriter, r *http.Request) {
+	payout := getReferralPayoutForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	rpayout, err := c.App.RejectReferralPayout(payout.Id, c.App.Session.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("payout_id=" + rpayout.Id)
+
+	w.Write([]byte(rpayout.ToJson()))
+}
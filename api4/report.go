@@ -0,0 +1,244 @@
+package api4
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"time"
+
+	"im/model"
+)
+
+const (
+	REPORT_DEFAULT_PERIOD = 90 * 24 * time.Hour
+	REPORT_DEFAULT_LIMIT  = 20
+	REPORT_MAX_LIMIT      = 1000
+)
+
+// reportOptionsFromRequest reads the period and the scope of the report from the query and
+// checks that the session may see the reports of the application. The period defaults to the
+// last 90 days, days are counted in the time zone of the server like the other metrics.
+func reportOptionsFromRequest(c *Context, r *http.Request) *model.ReportOptions {
+	c.RequireAppId()
+	if c.Err != nil {
+		return nil
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return nil
+	}
+
+	_, offset := time.Now().Zone()
+	options := &model.ReportOptions{
+		AppId:    c.Params.AppId,
+		ExpireAt: model.GetMillis(),
+		Offset:   offset,
+		Sort:     model.REPORT_SORT_REVENUE,
+		Limit:    REPORT_DEFAULT_LIMIT,
+	}
+
+	query := r.URL.Query()
+	if val := query.Get("expire_at"); len(val) > 0 {
+		expireAt, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || expireAt <= 0 {
+			c.SetInvalidParam("expire_at")
+			return nil
+		}
+		options.ExpireAt = expireAt
+	}
+
+	options.BeginAt = options.ExpireAt - int64(REPORT_DEFAULT_PERIOD/time.Millisecond)
+	if val := query.Get("begin_at"); len(val) > 0 {
+		beginAt, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || beginAt <= 0 || beginAt > options.ExpireAt {
+			c.SetInvalidParam("begin_at")
+			return nil
+		}
+		options.BeginAt = beginAt
+	}
+
+	if options.ExpireAt-options.BeginAt > model.REPORT_MAX_DAYS*model.REPORT_DAY_MILLIS {
+		c.SetInvalidParam("begin_at")
+		return nil
+	}
+
+	if val := query.Get("sort"); len(val) > 0 {
+		if val != model.REPORT_SORT_REVENUE && val != model.REPORT_SORT_QUANTITY {
+			c.SetInvalidParam("sort")
+			return nil
+		}
+		options.Sort = val
+	}
+
+	if val := query.Get("limit"); len(val) > 0 {
+		limit, err := strconv.Atoi(val)
+		if err != nil || limit <= 0 || limit > REPORT_MAX_LIMIT {
+			c.SetInvalidParam("limit")
+			return nil
+		}
+		options.Limit = limit
+	}
+
+	if officeId := query.Get("office_id"); len(officeId) > 0 {
+		office, err := c.App.GetOffice(officeId)
+		if err != nil {
+			c.Err = err
+			return nil
+		}
+
+		if office.AppId != c.Params.AppId {
+			c.SetInvalidParam("office_id")
+			return nil
+		}
+		options.OfficeId = office.Id
+	}
+
+	return options
+}
+
+func wantsCsv(r *http.Request) bool {
+	return r.URL.Query().Get("format") == "csv"
+}
+
+// writeCsvReport sends the rows as a csv file. The byte order mark lets spreadsheet programs
+// open the cyrillic names right.
+func writeCsvReport(c *Context, w http.ResponseWriter, name string, rows [][]string) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+name+".csv\"")
+	w.Write([]byte("\xEF\xBB\xBF"))
+
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(rows); err != nil {
+		c.LogError(model.NewAppError("writeCsvReport", "api.report.write_csv.app_error", nil, err.Error(), http.StatusInternalServerError))
+	}
+}
+
+func reportCohortRetention(c *Context, w http.ResponseWriter, r *http.Request) {
+	options := reportOptionsFromRequest(c, r)
+	if c.Err != nil {
+		return
+	}
+
+	weeks := model.REPORT_DEFAULT_COHORT_WEEKS
+	if val := r.URL.Query().Get("weeks"); len(val) > 0 {
+		var err error
+		if weeks, err = strconv.Atoi(val); err != nil || weeks <= 0 || weeks > model.REPORT_MAX_COHORT_WEEKS {
+			c.SetInvalidParam("weeks")
+			return
+		}
+	}
+
+	cohorts, err := c.App.GetCohortRetention(*options, weeks)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if wantsCsv(r) {
+		writeCsvReport(c, w, "cohorts", model.CohortRetentionListToCsv(cohorts, weeks))
+		return
+	}
+
+	w.Write([]byte(model.CohortRetentionListToJson(cohorts)))
+}
+
+func reportRfm(c *Context, w http.ResponseWriter, r *http.Request) {
+	options := reportOptionsFromRequest(c, r)
+	if c.Err != nil {
+		return
+	}
+
+	report, err := c.App.GetRfmReport(*options)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if wantsCsv(r) {
+		writeCsvReport(c, w, "rfm", report.ToCsv())
+		return
+	}
+
+	w.Write([]byte(report.ToJson()))
+}
+
+func reportProductSales(c *Context, w http.ResponseWriter, r *http.Request) {
+	options := reportOptionsFromRequest(c, r)
+	if c.Err != nil {
+		return
+	}
+
+	rows, err := c.App.GetProductSalesReport(*options)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if wantsCsv(r) {
+		writeCsvReport(c, w, "products", model.SalesReportToCsv(rows))
+		return
+	}
+
+	w.Write([]byte(model.SalesReportToJson(rows)))
+}
+
+func reportCategorySales(c *Context, w http.ResponseWriter, r *http.Request) {
+	options := reportOptionsFromRequest(c, r)
+	if c.Err != nil {
+		return
+	}
+
+	rows, err := c.App.GetCategorySalesReport(*options)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if wantsCsv(r) {
+		writeCsvReport(c, w, "categories", model.SalesReportToCsv(rows))
+		return
+	}
+
+	w.Write([]byte(model.SalesReportToJson(rows)))
+}
+
+func reportBonusLiability(c *Context, w http.ResponseWriter, r *http.Request) {
+	options := reportOptionsFromRequest(c, r)
+	if c.Err != nil {
+		return
+	}
+
+	list, err := c.App.GetBonusLiabilityReport(*options)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if wantsCsv(r) {
+		writeCsvReport(c, w, "bonus_liability", model.BonusLiabilityListToCsv(list))
+		return
+	}
+
+	w.Write([]byte(model.BonusLiabilityListToJson(list)))
+}
+
+func reportAverageCheck(c *Context, w http.ResponseWriter, r *http.Request) {
+	options := reportOptionsFromRequest(c, r)
+	if c.Err != nil {
+		return
+	}
+
+	list, err := c.App.GetAverageCheckReport(*options)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if wantsCsv(r) {
+		writeCsvReport(c, w, "average_check", model.HourlyCheckListToCsv(list))
+		return
+	}
+
+	w.Write([]byte(model.HourlyCheckListToJson(list)))
+}
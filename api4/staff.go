@@ -0,0 +1,234 @@
+package api4
+
+import (
+	"net/http"
+
+	"im/model"
+)
+
+func (api *API) InitStaff() {
+	api.BaseRoutes.Staff.Handle("", api.ApiSessionRequired(getStaff)).Methods("GET")
+	api.BaseRoutes.Staff.Handle("/invite", api.ApiSessionRequired(inviteStaff)).Methods("POST")
+
+	api.BaseRoutes.StaffMember.Handle("", api.ApiSessionRequired(getStaffMember)).Methods("GET")
+	api.BaseRoutes.StaffMember.Handle("", api.ApiSessionRequired(removeStaff)).Methods("DELETE")
+	api.BaseRoutes.StaffMember.Handle("/roles", api.ApiSessionRequired(updateStaffRoles)).Methods("PUT")
+	api.BaseRoutes.StaffMember.Handle("/offices/{office_id:[A-Za-z0-9]+}", api.ApiSessionRequired(assignStaffToOffice)).Methods("POST")
+	api.BaseRoutes.StaffMember.Handle("/offices/{office_id:[A-Za-z0-9]+}", api.ApiSessionRequired(unassignStaffFromOffice)).Methods("DELETE")
+
+	api.BaseRoutes.Office.Handle("/staff", api.ApiSessionRequired(getOfficeStaff)).Methods("GET")
+}
+
+func getStaff(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_STAFF) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_STAFF)
+		return
+	}
+
+	staff, err := c.App.GetStaff(c.Params.AppId, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.StaffListToJson(staff)))
+}
+
+func getStaffMember(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId().RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if c.Params.UserId != c.App.Session.UserId &&
+		!c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_STAFF) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_STAFF)
+		return
+	}
+
+	staff, err := c.App.GetStaffMember(c.Params.AppId, c.Params.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(staff.ToJson()))
+}
+
+func inviteStaff(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId()
+	if c.Err != nil {
+		return
+	}
+
+	invite := model.StaffInviteFromJson(r.Body)
+	if invite == nil {
+		c.SetInvalidParam("invite")
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_STAFF) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_STAFF)
+		return
+	}
+
+	staff, err := c.App.InviteStaff(c.Params.AppId, invite)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("invited staff user_id=" + staff.UserId)
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(staff.ToJson()))
+}
+
+func updateStaffRoles(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId().RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	props := model.MapFromJson(r.Body)
+
+	newRoles := props["roles"]
+	if !model.IsValidStaffRoles(newRoles) {
+		c.SetInvalidParam("roles")
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_STAFF) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_STAFF)
+		return
+	}
+
+	requireStaffTeamAdminPermission(c)
+	if c.Err != nil {
+		return
+	}
+
+	staff, err := c.App.UpdateStaffRoles(c.Params.AppId, c.Params.UserId, newRoles)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("user_id=" + c.Params.UserId + " roles=" + newRoles)
+
+	w.Write([]byte(staff.ToJson()))
+}
+
+func removeStaff(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId().RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_STAFF) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_STAFF)
+		return
+	}
+
+	requireStaffTeamAdminPermission(c)
+	if c.Err != nil {
+		return
+	}
+
+	if err := c.App.RemoveStaff(c.Params.AppId, c.Params.UserId, c.App.Session.UserId); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("user_id=" + c.Params.UserId)
+
+	ReturnStatusOK(w)
+}
+
+func assignStaffToOffice(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId().RequireUserId().RequireOfficeId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_STAFF) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_STAFF)
+		return
+	}
+
+	if _, err := c.App.GetStaffMember(c.Params.AppId, c.Params.UserId); err != nil {
+		c.Err = err
+		return
+	}
+
+	if err := c.App.AssignStaffToOffice(c.Params.AppId, c.Params.UserId, c.Params.OfficeId); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
+
+func unassignStaffFromOffice(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId().RequireUserId().RequireOfficeId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_STAFF) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_STAFF)
+		return
+	}
+
+	if err := c.App.UnassignStaffFromOffice(c.Params.AppId, c.Params.UserId, c.Params.OfficeId); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
+
+func getOfficeStaff(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireOfficeId()
+	if c.Err != nil {
+		return
+	}
+
+	office, err := c.App.GetOffice(c.Params.OfficeId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, office.AppId, model.PERMISSION_MANAGE_STAFF) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_STAFF)
+		return
+	}
+
+	staff, err := c.App.GetStaffForOffice(office)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.StaffListToJson(staff)))
+}
+
+// requireStaffTeamAdminPermission refuses to manage a staff member who administers the team of
+// the application to anyone who may manage the staff but not the team.
+func requireStaffTeamAdminPermission(c *Context) {
+	isAdmin, err := c.App.IsStaffTeamAdmin(c.Params.AppId, c.Params.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if isAdmin && !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+	}
+}
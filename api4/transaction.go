@@ -41,8 +41,14 @@ func validateTransactionForOrderUser(c *Context, w http.ResponseWriter, r *http.
 		return
 	} else {
 
-		discount := order.Price * (float64(app.MaxDiscount) / 100)
-		cashback := order.Price * (app.Cashback / 100)
+		userId := order.UserId
+		if len(userId) == 0 {
+			userId = c.App.Session.UserId
+		}
+		rateCashback, rateDiscount := c.App.GetCustomerRates(userId, app)
+
+		discount := order.Price * (rateDiscount / 100)
+		cashback := order.Price * (rateCashback / 100)
 
 		b, _ := json.Marshal(struct {
 			DiscountValue int64 `json:"discount_value"`
@@ -229,6 +235,11 @@ func getAllTransactions(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if isCursorRequest(r) {
+		getAllTransactionsByCursor(c, w, r)
+		return
+	}
+
 	afterTransaction := r.URL.Query().Get("after")
 	beforeTransaction := r.URL.Query().Get("before")
 	sinceString := r.URL.Query().Get("since")
@@ -277,6 +288,40 @@ func getAllTransactions(c *Context, w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(list.ToJson()))
 }
 
+// getAllTransactionsByCursor returns a cursor page of the transactions of the application of
+// the session user. Unlike the older listing it needs a session.
+func getAllTransactionsByCursor(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.SessionRequired()
+	if c.Err != nil {
+		return
+	}
+
+	user, err := c.App.GetUser(c.App.Session.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, user.AppId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	options := transactionCursorOptions(c, r)
+	if c.Err != nil {
+		return
+	}
+	options.AppId = user.AppId
+
+	list, err := c.App.GetTransactionsByCursor(options)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(list.ToJson()))
+}
+
 func getTransaction(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequireTransactionId()
 	if c.Err != nil {
@@ -387,6 +432,29 @@ func getUserTransactions(c *Context, w http.ResponseWriter, r *http.Request) {
 	var list *model.TransactionList
 	var err *model.AppError
 	//etag := ""
+
+	if isCursorRequest(r) {
+		if c.App.Session.UserId != c.Params.UserId && !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_TEAM) {
+			c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+			return
+		}
+
+		options := transactionCursorOptions(c, r)
+		if c.Err != nil {
+			return
+		}
+		options.AppId = c.Params.AppId
+		options.UserId = c.Params.UserId
+
+		if list, err = c.App.GetTransactionsByCursor(options); err != nil {
+			c.Err = err
+			return
+		}
+
+		w.Write([]byte(list.ToJson()))
+		return
+	}
+
 	sort := r.URL.Query().Get("sort")
 	transactionGetOptions := &model.TransactionGetOptions{
 		Sort:    sort,
@@ -1256,6 +1256,10 @@ func attachDeviceId(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := c.App.RecordUserDevice(c.App.Session.UserId, deviceId); err != nil {
+		mlog.Warn(err.Error())
+	}
+
 	if err := c.App.AttachAppId(c.App.Session.Id, c.Params.AppId, c.App.Session.ExpiresAt); err != nil {
 		c.Err = err
 		return
@@ -0,0 +1,69 @@
+package api4
+
+import (
+	"net/http"
+
+	"im/model"
+	"im/utils"
+)
+
+func payOrderWithWallet(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireOrderId()
+	if c.Err != nil {
+		return
+	}
+
+	req := model.WalletPaymentRequestFromJson(r.Body)
+	if req == nil || !model.IsValidWallet(req.Wallet) {
+		c.SetInvalidParam("wallet")
+		return
+	}
+
+	if len(req.PaymentToken) == 0 {
+		c.SetInvalidParam("payment_token")
+		return
+	}
+
+	order, err := c.App.GetOrder(c.Params.OrderId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if order.UserId != c.App.Session.UserId {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	user, err := c.App.GetUser(order.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	application, err := c.App.GetApplication(user.AppId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if _, err := c.App.PayOrderWithWallet(order, application, req.Wallet, req.PaymentToken, utils.GetIpAddress(r)); err != nil {
+		c.Err = err
+		return
+	}
+
+	// the acquirer charges the token right away, the order goes through the same paid and
+	// declined path as the card payments
+	updatePaymentOrderStatus(c, order.Id)
+	if c.Err != nil {
+		return
+	}
+
+	order, err = c.App.GetOrder(order.Id)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(order.ToJson()))
+}
@@ -0,0 +1,231 @@
+package api4
+
+import (
+	"net/http"
+
+	"im/model"
+)
+
+func (api *API) InitWebhookSubscription() {
+	api.BaseRoutes.WebhookSubscriptions.Handle("", api.ApiSessionRequired(getWebhookSubscriptions)).Methods("GET")
+	api.BaseRoutes.WebhookSubscriptions.Handle("", api.ApiSessionRequired(createWebhookSubscription)).Methods("POST")
+
+	api.BaseRoutes.WebhookSubscription.Handle("", api.ApiSessionRequired(getWebhookSubscription)).Methods("GET")
+	api.BaseRoutes.WebhookSubscription.Handle("/patch", api.ApiSessionRequired(patchWebhookSubscription)).Methods("PUT")
+	api.BaseRoutes.WebhookSubscription.Handle("", api.ApiSessionRequired(deleteWebhookSubscription)).Methods("DELETE")
+	api.BaseRoutes.WebhookSubscription.Handle("/regenerate_secret", api.ApiSessionRequired(regenerateWebhookSubscriptionSecret)).Methods("POST")
+
+	api.BaseRoutes.WebhookSubscription.Handle("/deliveries", api.ApiSessionRequired(getWebhookDeliveries)).Methods("GET")
+	api.BaseRoutes.WebhookSubscription.Handle("/deliveries/{delivery_id:[A-Za-z0-9]+}/replay", api.ApiSessionRequired(replayWebhookDelivery)).Methods("POST")
+}
+
+// getWebhookSubscriptionForApp loads the subscription from the url and checks that it belongs to the application.
+func getWebhookSubscriptionForApp(c *Context) *model.WebhookSubscription {
+	c.RequireAppId().RequireHookId()
+	if c.Err != nil {
+		return nil
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_OUTGOING_WEBHOOKS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_OUTGOING_WEBHOOKS)
+		return nil
+	}
+
+	subscription, err := c.App.GetWebhookSubscription(c.Params.HookId)
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	if subscription.AppId != c.Params.AppId {
+		c.SetInvalidUrlParam("hook_id")
+		return nil
+	}
+
+	return subscription
+}
+
+func getWebhookSubscriptions(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_OUTGOING_WEBHOOKS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_OUTGOING_WEBHOOKS)
+		return
+	}
+
+	subscriptions, err := c.App.GetWebhookSubscriptionsForApp(c.Params.AppId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	for _, subscription := range subscriptions {
+		subscription.Sanitize()
+	}
+
+	w.Write([]byte(model.WebhookSubscriptionListToJson(subscriptions)))
+}
+
+func createWebhookSubscription(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireAppId()
+	if c.Err != nil {
+		return
+	}
+
+	subscription := model.WebhookSubscriptionFromJson(r.Body)
+	if subscription == nil {
+		c.SetInvalidParam("webhook")
+		return
+	}
+
+	if !c.App.SessionHasPermissionToApplication(c.App.Session, c.Params.AppId, model.PERMISSION_MANAGE_OUTGOING_WEBHOOKS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_OUTGOING_WEBHOOKS)
+		return
+	}
+
+	subscription.Id = ""
+	subscription.AppId = c.Params.AppId
+	subscription.CreatorId = c.App.Session.UserId
+
+	rsubscription, err := c.App.CreateWebhookSubscription(subscription)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("webhook_id=" + rsubscription.Id)
+
+	// the secret is returned only here and on regeneration
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(rsubscription.ToJson()))
+}
+
+func getWebhookSubscription(c *Context, w http.ResponseWriter, r *http.Request) {
+	subscription := getWebhookSubscriptionForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	subscription.Sanitize()
+	w.Write([]byte(subscription.ToJson()))
+}
+
+func patchWebhookSubscription(c *Context, w http.ResponseWriter, r *http.Request) {
+	patch := model.WebhookSubscriptionPatchFromJson(r.Body)
+	if patch == nil {
+		c.SetInvalidParam("webhook")
+		return
+	}
+
+	subscription := getWebhookSubscriptionForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	rsubscription, err := c.App.PatchWebhookSubscription(subscription, patch)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("webhook_id=" + rsubscription.Id)
+
+	rsubscription.Sanitize()
+	w.Write([]byte(rsubscription.ToJson()))
+}
+
+func deleteWebhookSubscription(c *Context, w http.ResponseWriter, r *http.Request) {
+	subscription := getWebhookSubscriptionForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	if err := c.App.DeleteWebhookSubscription(subscription); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("webhook_id=" + subscription.Id)
+
+	ReturnStatusOK(w)
+}
+
+func regenerateWebhookSubscriptionSecret(c *Context, w http.ResponseWriter, r *http.Request) {
+	subscription := getWebhookSubscriptionForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	rsubscription, err := c.App.RegenerateWebhookSubscriptionSecret(subscription)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("webhook_id=" + rsubscription.Id)
+
+	w.Write([]byte(rsubscription.ToJson()))
+}
+
+func getWebhookDeliveries(c *Context, w http.ResponseWriter, r *http.Request) {
+	subscription := getWebhookSubscriptionForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+	case model.WEBHOOK_DELIVERY_STATUS_PENDING:
+	case model.WEBHOOK_DELIVERY_STATUS_SUCCESS:
+	case model.WEBHOOK_DELIVERY_STATUS_FAILED:
+	case model.WEBHOOK_DELIVERY_STATUS_DEAD:
+	default:
+		c.SetInvalidUrlParam("status")
+		return
+	}
+
+	deliveries, err := c.App.GetWebhookDeliveries(subscription.Id, status, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.WebhookDeliveryListToJson(deliveries)))
+}
+
+func replayWebhookDelivery(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireDeliveryId()
+	if c.Err != nil {
+		return
+	}
+
+	subscription := getWebhookSubscriptionForApp(c)
+	if c.Err != nil {
+		return
+	}
+
+	delivery, err := c.App.GetWebhookDelivery(c.Params.DeliveryId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if delivery.SubscriptionId != subscription.Id {
+		c.SetInvalidUrlParam("delivery_id")
+		return
+	}
+
+	rdelivery, err := c.App.ReplayWebhookDelivery(delivery)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("delivery_id=" + rdelivery.Id)
+
+	w.Write([]byte(rdelivery.ToJson()))
+}
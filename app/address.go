@@ -0,0 +1,152 @@
+package app
+
+import (
+	"net/http"
+
+	"im/mlog"
+	"im/model"
+	"im/services/geocoder"
+)
+
+func (a *App) GetAddress(addressId string) (*model.Address, *model.AppError) {
+	result := <-a.Srv.Store.Address().Get(addressId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.Address), nil
+}
+
+// GetAddressForUser returns the address only when it belongs to the user.
+func (a *App) GetAddressForUser(userId, addressId string) (*model.Address, *model.AppError) {
+	address, err := a.GetAddress(addressId)
+	if err != nil {
+		return nil, err
+	}
+
+	if address.UserId != userId {
+		return nil, model.NewAppError("GetAddressForUser", "app.address.get.other_user.app_error", nil, "id="+addressId, http.StatusNotFound)
+	}
+
+	return address, nil
+}
+
+func (a *App) GetUserAddresses(userId string) ([]*model.Address, *model.AppError) {
+	result := <-a.Srv.Store.Address().GetByUserId(userId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.([]*model.Address), nil
+}
+
+func (a *App) CreateAddress(address *model.Address) (*model.Address, *model.AppError) {
+	if err := a.GeocodeAddress(address); err != nil {
+		return nil, err
+	}
+
+	result := <-a.Srv.Store.Address().Save(address)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.Address), nil
+}
+
+func (a *App) PatchAddress(address *model.Address, patch *model.AddressPatch) (*model.Address, *model.AppError) {
+	address = address.Clone()
+	address.Patch(patch)
+
+	// the old coordinates point to the previous place unless the client has sent new ones
+	if patch.HasLocationChanges() && patch.Latitude == nil && patch.Longitude == nil {
+		address.Latitude = 0
+		address.Longitude = 0
+	}
+
+	if err := a.GeocodeAddress(address); err != nil {
+		return nil, err
+	}
+
+	result := <-a.Srv.Store.Address().Update(address)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.Address), nil
+}
+
+func (a *App) DeleteAddress(address *model.Address) *model.AppError {
+	if result := <-a.Srv.Store.Address().Delete(address.Id, model.GetMillis()); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// GeocodeAddress fills in the coordinates of the address when the client has not sent them.
+// Nothing is done when no geocoder is configured.
+func (a *App) GeocodeAddress(address *model.Address) *model.AppError {
+	if address.HasLocation() || a.Srv.Geocoder == nil || !a.Srv.Geocoder.IsEnabled() {
+		return nil
+	}
+
+	query := address.GeocodeQuery()
+	if len(query) == 0 {
+		return nil
+	}
+
+	result, err := a.Srv.Geocoder.Geocode(query)
+	if err == geocoder.ErrNotFound {
+		return model.NewAppError("GeocodeAddress", "app.address.geocode.not_found.app_error", nil, "query="+query, http.StatusBadRequest)
+	} else if err != nil {
+		mlog.Warn("Failed to geocode address", mlog.String("query", query), mlog.Err(err))
+		return model.NewAppError("GeocodeAddress", "app.address.geocode.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	address.Latitude = result.Latitude
+	address.Longitude = result.Longitude
+
+	if len(address.City) == 0 {
+		address.City = result.City
+	}
+
+	return nil
+}
+
+// prepareOrderAddress resolves the delivery address of a new order, either from the
+// address book or from the structured address sent with the order, and copies it into the order.
+func (a *App) prepareOrderAddress(order *model.Order) *model.AppError {
+	if len(order.AddressId) > 0 {
+		address, err := a.GetAddressForUser(order.UserId, order.AddressId)
+		if err != nil {
+			return err
+		}
+
+		order.SetDeliveryAddress(address)
+	} else if order.DeliveryAddress != nil {
+		address := order.DeliveryAddress
+		address.Id = ""
+		address.UserId = order.UserId
+
+		// the address is not saved, it is checked the way the address book checks it
+		address.PreSave()
+		if err := address.IsValid(); err != nil {
+			return err
+		}
+		address.Id = ""
+
+		if err := a.GeocodeAddress(address); err != nil {
+			return err
+		}
+
+		order.SetDeliveryAddress(address)
+	}
+
+	if len(order.Phone) == 0 {
+		if user, err := a.GetUser(order.UserId); err == nil {
+			order.Phone = user.Phone
+		}
+	}
+
+	return nil
+}
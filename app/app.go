@@ -34,6 +34,8 @@ type App struct {
 
 	Elasticsearch einterfaces.ElasticsearchInterface
 
+	Metrics einterfaces.MetricsInterface
+
 	HTTPService httpservice.HTTPService
 	ImageProxy  *imageproxy.ImageProxy
 	Timezones   *timezones.Timezones
@@ -62,6 +64,7 @@ func (a *App) configOrLicenseListener() {
 
 func (s *Server) initJobs() {
 	s.Jobs = jobs.NewJobServer(s, s.Store)
+	s.Jobs.Metrics = s.Metrics
 
 	if jobsElasticsearchAggregatorInterface != nil {
 		s.Jobs.ElasticsearchAggregator = jobsElasticsearchAggregatorInterface(s.FakeApp())
@@ -69,6 +72,15 @@ func (s *Server) initJobs() {
 	if jobsElasticsearchIndexerInterface != nil {
 		s.Jobs.ElasticsearchIndexer = jobsElasticsearchIndexerInterface(s.FakeApp())
 	}
+	if jobsCampaignInterface != nil {
+		s.Jobs.Campaign = jobsCampaignInterface(s.FakeApp())
+	}
+	if jobsLifecycleTriggersInterface != nil {
+		s.Jobs.LifecycleTriggers = jobsLifecycleTriggersInterface(s.FakeApp())
+	}
+	if jobsLoyaltyTiersInterface != nil {
+		s.Jobs.LoyaltyTiers = jobsLoyaltyTiersInterface(s.FakeApp())
+	}
 
 	s.Jobs.Workers = s.Jobs.InitWorkers()
 	s.Jobs.Schedulers = s.Jobs.InitSchedulers()
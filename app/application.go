@@ -136,6 +136,8 @@ func (a *App) PatchApplication(id string, patch *model.ApplicationPatch) (*model
 		return nil, result.Err
 	}
 
+	a.syncApplicationConfig(newApplication)
+
 	if newApplication.Email != oldApplication.Email {
 		if ruser, err := a.GetUserApplicationByEmail(oldApplication.Email, oldApplication.Id); err != nil {
 			return nil, err
@@ -219,6 +221,8 @@ func (a *App) UpdateApplication(id string, patch *model.ApplicationPatch, safeUp
 		return nil, result.Err
 	}
 
+	a.syncApplicationConfig(newApplication)
+
 	rapplication := result.Data.(*model.Application)
 	rapplication = a.PrepareApplicationForClient(rapplication, false)
 
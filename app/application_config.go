@@ -0,0 +1,315 @@
+package app
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+
+	"im/mlog"
+	"im/model"
+)
+
+// ApplicationConfigListener is called with the old and the new configuration once the
+// configuration of an application changes, on this node or on another node of the cluster.
+type ApplicationConfigListener func(appId string, oldConfig, newConfig *model.ApplicationConfig)
+
+// ApplicationConfigs keeps the active configurations of the applications and the listeners of
+// their changes.
+type ApplicationConfigs struct {
+	configs   sync.Map
+	listeners sync.Map
+}
+
+func NewApplicationConfigs() *ApplicationConfigs {
+	return &ApplicationConfigs{}
+}
+
+func (c *ApplicationConfigs) get(appId string) *model.ApplicationConfig {
+	if value, ok := c.configs.Load(appId); ok {
+		return value.(*model.ApplicationConfig)
+	}
+
+	return nil
+}
+
+// set makes the configuration the active one and returns the one it replaced, nil when the
+// configuration of the application was not loaded yet.
+func (c *ApplicationConfigs) set(appId string, config *model.ApplicationConfig) *model.ApplicationConfig {
+	old := c.get(appId)
+	c.configs.Store(appId, config)
+	return old
+}
+
+// invokeListeners synchronously notifies all listeners about the configuration change.
+func (c *ApplicationConfigs) invokeListeners(appId string, oldConfig, newConfig *model.ApplicationConfig) {
+	c.listeners.Range(func(key, value interface{}) bool {
+		listener := value.(ApplicationConfigListener)
+		listener(appId, oldConfig, newConfig)
+
+		return true
+	})
+}
+
+// AddApplicationConfigListener registers a function to call when the configuration of any of
+// the applications changes. It returns a unique ID for RemoveApplicationConfigListener.
+func (s *Server) AddApplicationConfigListener(listener ApplicationConfigListener) string {
+	id := model.NewId()
+	s.applicationConfigs.listeners.Store(id, listener)
+	return id
+}
+
+func (a *App) AddApplicationConfigListener(listener ApplicationConfigListener) string {
+	return a.Srv.AddApplicationConfigListener(listener)
+}
+
+// Removes a listener function by the unique ID returned when AddApplicationConfigListener was called
+func (s *Server) RemoveApplicationConfigListener(id string) {
+	s.applicationConfigs.listeners.Delete(id)
+}
+
+func (a *App) RemoveApplicationConfigListener(id string) {
+	a.Srv.RemoveApplicationConfigListener(id)
+}
+
+// GetApplicationConfig returns the active configuration of the application. An application
+// with no saved versions gets the configuration made out of its columns.
+func (a *App) GetApplicationConfig(appId string) (*model.ApplicationConfig, *model.AppError) {
+	if config := a.Srv.applicationConfigs.get(appId); config != nil {
+		return config.Clone(), nil
+	}
+
+	config, err := a.loadApplicationConfig(appId)
+	if err != nil {
+		return nil, err
+	}
+
+	a.Srv.applicationConfigs.set(appId, config)
+
+	return config.Clone(), nil
+}
+
+func (a *App) loadApplicationConfig(appId string) (*model.ApplicationConfig, *model.AppError) {
+	result := <-a.Srv.Store.ApplicationConfig().GetLatest(appId)
+	if result.Err == nil {
+		config := result.Data.(*model.ApplicationConfigVersion).Config
+		config.SetDefaults()
+		return config, nil
+	}
+
+	if result.Err.StatusCode != http.StatusNotFound {
+		return nil, result.Err
+	}
+
+	result = <-a.Srv.Store.Application().Get(appId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return model.ApplicationConfigFromApplication(result.Data.(*model.Application)), nil
+}
+
+// SaveApplicationConfig validates the configuration and saves it as the next version, the
+// settings kept in the columns of the application are updated with it first. A configuration
+// with no changes is not saved again. The listeners are notified once both are saved.
+func (a *App) SaveApplicationConfig(appId string, config *model.ApplicationConfig) (*model.ApplicationConfigVersion, *model.AppError) {
+	config = config.Clone()
+	config.SetDefaults()
+	if err := config.IsValid(); err != nil {
+		return nil, err
+	}
+
+	result := <-a.Srv.Store.Application().Get(appId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	application := result.Data.(*model.Application)
+	if application.DeleteAt != 0 {
+		return nil, model.NewAppError("SaveApplicationConfig", "app.application_config.save.deleted.app_error", nil, "app_id="+appId, http.StatusBadRequest)
+	}
+
+	oldConfig, err := a.GetApplicationConfig(appId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(model.DiffApplicationConfigs(oldConfig, config)) == 0 {
+		return a.getLatestApplicationConfigVersion(appId, oldConfig)
+	}
+
+	original := application.Clone()
+	config.ApplyToApplication(application)
+	if result := <-a.Srv.Store.Application().Update(application); result.Err != nil {
+		return nil, result.Err
+	}
+
+	version, err := a.saveApplicationConfigVersion(appId, config)
+	if err != nil {
+		// the columns go back to the active configuration, so they do not get ahead of it
+		if result := <-a.Srv.Store.Application().Update(original); result.Err != nil {
+			mlog.Error("Failed to restore the settings of the application", mlog.String("app_id", appId), mlog.Err(result.Err))
+		}
+		return nil, err
+	}
+
+	a.setApplicationConfig(appId, oldConfig, config)
+
+	return version, nil
+}
+
+// syncApplicationConfig saves a version for the settings changed through the columns of the
+// application, by a patch of the application.
+func (a *App) syncApplicationConfig(application *model.Application) {
+	oldConfig, err := a.GetApplicationConfig(application.Id)
+	if err != nil {
+		mlog.Warn("Failed to get the configuration of the application", mlog.String("app_id", application.Id), mlog.Err(err))
+		return
+	}
+
+	config := oldConfig.Clone()
+	config.LoadFromApplication(application)
+	if len(model.DiffApplicationConfigs(oldConfig, config)) == 0 {
+		return
+	}
+
+	if err := config.IsValid(); err != nil {
+		mlog.Warn("The application has an invalid configuration", mlog.String("app_id", application.Id), mlog.Err(err))
+		return
+	}
+
+	if _, err := a.saveApplicationConfigVersion(application.Id, config); err != nil {
+		mlog.Error("Failed to save the configuration of the application", mlog.String("app_id", application.Id), mlog.Err(err))
+		return
+	}
+
+	a.setApplicationConfig(application.Id, oldConfig, config)
+}
+
+func (a *App) saveApplicationConfigVersion(appId string, config *model.ApplicationConfig) (*model.ApplicationConfigVersion, *model.AppError) {
+	version := &model.ApplicationConfigVersion{
+		AppId:     appId,
+		Config:    config,
+		CreatorId: a.Session.UserId,
+	}
+
+	result := <-a.Srv.Store.ApplicationConfig().Save(version)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.ApplicationConfigVersion), nil
+}
+
+// setApplicationConfig makes the saved configuration the active one and notifies the listeners
+// of this node and the other nodes of the cluster.
+func (a *App) setApplicationConfig(appId string, oldConfig, config *model.ApplicationConfig) {
+	a.Srv.applicationConfigs.set(appId, config.Clone())
+	a.Srv.applicationConfigs.invokeListeners(appId, oldConfig, config.Clone())
+
+	if a.Cluster != nil {
+		a.Cluster.SendClusterMessage(&model.ClusterMessage{
+			Event:    model.CLUSTER_EVENT_UPDATE_APPLICATION_CONFIG,
+			SendType: model.CLUSTER_SEND_BEST_EFFORT,
+			Data:     appId,
+		})
+	}
+}
+
+// getLatestApplicationConfigVersion returns the active version, or the unsaved configuration
+// of an application that has no versions yet.
+func (a *App) getLatestApplicationConfigVersion(appId string, config *model.ApplicationConfig) (*model.ApplicationConfigVersion, *model.AppError) {
+	result := <-a.Srv.Store.ApplicationConfig().GetLatest(appId)
+	if result.Err != nil {
+		if result.Err.StatusCode == http.StatusNotFound {
+			return &model.ApplicationConfigVersion{AppId: appId, Config: config}, nil
+		}
+		return nil, result.Err
+	}
+
+	version := result.Data.(*model.ApplicationConfigVersion)
+	version.Config.SetDefaults()
+
+	return version, nil
+}
+
+// ReloadApplicationConfigSkipClusterSend reads the active configuration of the application
+// saved by another node and notifies the listeners when it changed.
+func (a *App) ReloadApplicationConfigSkipClusterSend(appId string) {
+	config, err := a.loadApplicationConfig(appId)
+	if err != nil {
+		mlog.Warn("Failed to reload the configuration of the application", mlog.String("app_id", appId), mlog.Err(err))
+		return
+	}
+
+	oldConfig := a.Srv.applicationConfigs.set(appId, config)
+	if oldConfig == nil {
+		return
+	}
+
+	if len(model.DiffApplicationConfigs(oldConfig, config)) > 0 {
+		a.Srv.applicationConfigs.invokeListeners(appId, oldConfig, config.Clone())
+	}
+}
+
+func (a *App) GetApplicationConfigHistory(appId string, page int, perPage int) ([]*model.ApplicationConfigVersion, *model.AppError) {
+	if perPage > model.APPLICATION_CONFIG_HISTORY_MAX {
+		perPage = model.APPLICATION_CONFIG_HISTORY_MAX
+	}
+
+	result := <-a.Srv.Store.ApplicationConfig().GetHistory(appId, page*perPage, perPage)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	versions := result.Data.([]*model.ApplicationConfigVersion)
+	for _, version := range versions {
+		version.Config.SetDefaults()
+	}
+
+	return versions, nil
+}
+
+func (a *App) GetApplicationConfigVersion(appId string, version int64) (*model.ApplicationConfigVersion, *model.AppError) {
+	result := <-a.Srv.Store.ApplicationConfig().GetVersion(appId, version)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	configVersion := result.Data.(*model.ApplicationConfigVersion)
+	configVersion.Config.SetDefaults()
+
+	return configVersion, nil
+}
+
+// DiffApplicationConfigVersions lists the settings changed from one version to another, the
+// version 0 stands for the active configuration.
+func (a *App) DiffApplicationConfigVersions(appId string, from int64, to int64) ([]*model.ApplicationConfigChange, *model.AppError) {
+	if from < 0 || to < 0 {
+		return nil, model.NewAppError("DiffApplicationConfigVersions", "app.application_config.diff.version.app_error", nil, "from="+strconv.FormatInt(from, 10)+", to="+strconv.FormatInt(to, 10), http.StatusBadRequest)
+	}
+
+	fromConfig, err := a.getApplicationConfigByVersion(appId, from)
+	if err != nil {
+		return nil, err
+	}
+
+	toConfig, err := a.getApplicationConfigByVersion(appId, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.DiffApplicationConfigs(fromConfig, toConfig), nil
+}
+
+func (a *App) getApplicationConfigByVersion(appId string, version int64) (*model.ApplicationConfig, *model.AppError) {
+	if version == 0 {
+		return a.GetApplicationConfig(appId)
+	}
+
+	configVersion, err := a.GetApplicationConfigVersion(appId, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return configVersion.Config, nil
+}
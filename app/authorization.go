@@ -32,6 +32,21 @@ func (a *App) SessionHasPermissionToTeam(session model.Session, teamId string, p
 	return a.RolesGrantPermission(session.GetUserRoles(), permission.Id)
 }
 
+// SessionHasPermissionToApplication checks the permission against the roles the session
+// user holds in the team of the application.
+func (a *App) SessionHasPermissionToApplication(session model.Session, appId string, permission *model.Permission) bool {
+	if appId == "" {
+		return false
+	}
+
+	team, err := a.GetApplicationTeam(appId)
+	if err != nil {
+		return a.RolesGrantPermission(session.GetUserRoles(), permission.Id)
+	}
+
+	return a.SessionHasPermissionToTeam(session, team.Id, permission)
+}
+
 func (a *App) SessionHasPermissionToChannel(session model.Session, channelId string, permission *model.Permission) bool {
 	if channelId == "" {
 		return false
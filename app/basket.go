@@ -28,22 +28,34 @@ func (a *App) GetBasketForOrder(order *model.Order) []*model.Basket {
 	return basket
 }
 
-func (a *App) PrepareBasketListForClient(originalList []*model.Basket, isNewBasket bool) []*model.Basket {
+func (a *App) PrepareBasketListForClient(originalList []*model.Basket, userId string, isNewBasket bool) []*model.Basket {
+	return a.prepareBasketListForClient(originalList, userId, isNewBasket, a.NewBatchLoader())
+}
+
+func (a *App) PrepareBasketForClient(originalBasket *model.Basket, userId string, isNewBasket bool) *model.Basket {
+	return a.prepareBasketListForClient([]*model.Basket{originalBasket}, userId, isNewBasket, a.NewBatchLoader())[0]
+}
+
+func (a *App) prepareBasketListForClient(originalList []*model.Basket, userId string, isNewBasket bool, loader *BatchLoader) []*model.Basket {
+	productIds := make([]string, 0, len(originalList))
+	for _, originalBasket := range originalList {
+		productIds = append(productIds, originalBasket.ProductId)
+	}
+
+	products := loader.Products(productIds)
+
 	var list []*model.Basket
 	for _, originalBasket := range originalList {
-		basket := a.PrepareBasketForClient(originalBasket, isNewBasket)
+		basket := a.prepareBasketForClient(originalBasket, products[originalBasket.ProductId], userId, isNewBasket, loader)
 		list = append(list, basket)
 	}
 
 	return list
 }
 
-func (a *App) PrepareBasketForClient(originalBasket *model.Basket, isNewBasket bool) *model.Basket {
+func (a *App) prepareBasketForClient(originalBasket *model.Basket, product *model.Product, userId string, isNewBasket bool, loader *BatchLoader) *model.Basket {
 	basket := originalBasket.Clone()
-
-	if product, err := a.GetProduct(basket.ProductId); err == nil {
-		basket.Product = product
-	}
+	basket.Product = product
 
 	if isNewBasket && basket.Product != nil {
 
@@ -51,10 +63,11 @@ func (a *App) PrepareBasketForClient(originalBasket *model.Basket, isNewBasket b
 		basket.Currency = basket.Product.Currency
 		basket.Name = basket.Product.Name
 
-		if application, err := a.GetApplication(basket.Product.AppId); err != nil || basket.Product.PrivateRule {
+		if application, err := loader.Application(basket.Product.AppId); err != nil || basket.Product.PrivateRule {
 			basket.Cashback = basket.Product.Cashback
 		} else {
-			basket.Cashback = math.Floor(basket.Price * (application.Cashback / 100))
+			cashback, _ := loader.CustomerRates(userId, application)
+			basket.Cashback = math.Floor(basket.Price * (cashback / 100))
 		}
 	}
 
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"im/mlog"
+	"im/model"
+)
+
+// BatchLoader loads what the orders and the products of one request refer to. Each kind of
+// object is loaded for the whole list at once and kept until the request is done, so a list
+// takes the same number of queries whatever its length.
+type BatchLoader struct {
+	app *App
+
+	products     map[string]*model.Product
+	applications map[string]*model.Application
+	rates        map[string]customerRates
+}
+
+type customerRates struct {
+	cashback    float64
+	maxDiscount float64
+}
+
+func (a *App) NewBatchLoader() *BatchLoader {
+	return &BatchLoader{
+		app:          a,
+		products:     make(map[string]*model.Product),
+		applications: make(map[string]*model.Application),
+		rates:        make(map[string]customerRates),
+	}
+}
+
+// Products returns the products prepared for the client, keyed by id. Deleted and unknown
+// products are left out.
+func (l *BatchLoader) Products(productIds []string) map[string]*model.Product {
+	var missing []string
+	seen := make(map[string]bool, len(productIds))
+	for _, productId := range productIds {
+		if _, ok := l.products[productId]; ok || seen[productId] {
+			continue
+		}
+
+		seen[productId] = true
+		missing = append(missing, productId)
+	}
+
+	if len(missing) > 0 {
+		result := <-l.app.Srv.Store.Product().GetProductsByIds(missing, true)
+		if result.Err != nil {
+			mlog.Warn("Failed to load the products", mlog.Err(result.Err))
+		} else {
+			for _, product := range l.app.prepareProductsForClient(result.Data.([]*model.Product)) {
+				l.products[product.Id] = product
+			}
+		}
+	}
+
+	products := make(map[string]*model.Product, len(productIds))
+	for _, productId := range productIds {
+		if product, ok := l.products[productId]; ok {
+			products[productId] = product
+		}
+	}
+
+	return products
+}
+
+func (l *BatchLoader) Application(appId string) (*model.Application, *model.AppError) {
+	if application, ok := l.applications[appId]; ok {
+		return application, nil
+	}
+
+	application, err := l.app.GetApplication(appId)
+	if err != nil {
+		return nil, err
+	}
+
+	l.applications[appId] = application
+	return application, nil
+}
+
+// CustomerRates is GetCustomerRates that looks up the tier of the customer once per request.
+func (l *BatchLoader) CustomerRates(userId string, application *model.Application) (cashback float64, maxDiscount float64) {
+	key := userId + application.Id
+	if rates, ok := l.rates[key]; ok {
+		return rates.cashback, rates.maxDiscount
+	}
+
+	cashback, maxDiscount = l.app.GetCustomerRates(userId, application)
+	l.rates[key] = customerRates{cashback: cashback, maxDiscount: maxDiscount}
+
+	return cashback, maxDiscount
+}
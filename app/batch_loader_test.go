@@ -0,0 +1,254 @@
+package app
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"im/config"
+	"im/model"
+	"im/store"
+)
+
+const (
+	// the baskets, the payments, the posts, the customers and the products of the orders, then
+	// what prepareProductsForClient loads for the products
+	PREPARE_ORDERS_STORE_CALLS   = 9
+	PREPARE_PRODUCTS_STORE_CALLS = 4
+)
+
+var benchmarkListSizes = []int{1, 10, 100}
+
+// countingStore answers the queries made to prepare the orders and the products from memory and
+// counts them, any other query panics.
+type countingStore struct {
+	store.Store
+
+	calls      int64
+	orders     []*model.Order
+	baskets    []*model.Basket
+	products   []*model.Product
+	users      []*model.User
+	categories []*model.Category
+}
+
+func newCountingStore(n int) *countingStore {
+	s := &countingStore{}
+
+	for i := 0; i < n; i++ {
+		category := &model.Category{Id: model.NewId(), Name: fmt.Sprintf("Category %d", i)}
+		user := &model.User{Id: model.NewId(), Username: fmt.Sprintf("customer%d", i)}
+		order := &model.Order{Id: model.NewId(), UserId: user.Id}
+
+		s.categories = append(s.categories, category)
+		s.users = append(s.users, user)
+		s.orders = append(s.orders, order)
+
+		for j := 0; j < 2; j++ {
+			product := &model.Product{
+				Id:         model.NewId(),
+				AppId:      model.NewId(),
+				CategoryId: category.Id,
+				Name:       fmt.Sprintf("Product %d.%d", i, j),
+				Price:      100,
+			}
+
+			s.products = append(s.products, product)
+			s.baskets = append(s.baskets, &model.Basket{
+				Id:        model.NewId(),
+				OrderId:   order.Id,
+				ProductId: product.Id,
+				Quantity:  1,
+			})
+		}
+	}
+
+	return s
+}
+
+func (s *countingStore) count(data interface{}) store.StoreChannel {
+	atomic.AddInt64(&s.calls, 1)
+	return store.Do(func(result *store.StoreResult) {
+		result.Data = data
+	})
+}
+
+func (s *countingStore) Basket() store.BasketStore {
+	return countingBasketStore{s: s}
+}
+
+func (s *countingStore) OrderPayment() store.OrderPaymentStore {
+	return countingOrderPaymentStore{s: s}
+}
+
+func (s *countingStore) Post() store.PostStore {
+	return countingPostStore{s: s}
+}
+
+func (s *countingStore) User() store.UserStore {
+	return countingUserStore{s: s}
+}
+
+func (s *countingStore) Product() store.ProductStore {
+	return countingProductStore{s: s}
+}
+
+func (s *countingStore) FileInfo() store.FileInfoStore {
+	return countingFileInfoStore{s: s}
+}
+
+func (s *countingStore) ProductOffice() store.ProductOfficeStore {
+	return countingProductOfficeStore{s: s}
+}
+
+func (s *countingStore) Extra() store.ExtraStore {
+	return countingExtraStore{s: s}
+}
+
+func (s *countingStore) Category() store.CategoryStore {
+	return countingCategoryStore{s: s}
+}
+
+type countingBasketStore struct {
+	store.BasketStore
+	s *countingStore
+}
+
+func (b countingBasketStore) GetByOrderIds(orderIds []string) store.StoreChannel {
+	return b.s.count(b.s.baskets)
+}
+
+type countingOrderPaymentStore struct {
+	store.OrderPaymentStore
+	s *countingStore
+}
+
+func (p countingOrderPaymentStore) GetForOrders(orderIds []string) store.StoreChannel {
+	return p.s.count([]*model.OrderPayment{})
+}
+
+type countingPostStore struct {
+	store.PostStore
+	s *countingStore
+}
+
+func (p countingPostStore) FindPostsWithOrders(orderIds []string) store.StoreChannel {
+	return p.s.count([]*model.Post{})
+}
+
+type countingUserStore struct {
+	store.UserStore
+	s *countingStore
+}
+
+func (u countingUserStore) GetProfileByIds(userIds []string, allowFromCache bool) store.StoreChannel {
+	return u.s.count(u.s.users)
+}
+
+type countingProductStore struct {
+	store.ProductStore
+	s *countingStore
+}
+
+func (p countingProductStore) GetProductsByIds(productIds []string, allowFromCache bool) store.StoreChannel {
+	return p.s.count(p.s.products)
+}
+
+type countingFileInfoStore struct {
+	store.FileInfoStore
+	s *countingStore
+}
+
+func (f countingFileInfoStore) GetForMetadataIds(metadataIds []string) store.StoreChannel {
+	return f.s.count([]*model.FileInfo{})
+}
+
+type countingProductOfficeStore struct {
+	store.ProductOfficeStore
+	s *countingStore
+}
+
+func (o countingProductOfficeStore) GetOfficesForProducts(productIds []string) store.StoreChannel {
+	return o.s.count(map[string][]*model.Office{})
+}
+
+type countingExtraStore struct {
+	store.ExtraStore
+	s *countingStore
+}
+
+func (e countingExtraStore) GetExtraProductsForProducts(productIds []string) store.StoreChannel {
+	return e.s.count(map[string][]*model.Product{})
+}
+
+type countingCategoryStore struct {
+	store.CategoryStore
+	s *countingStore
+}
+
+func (c countingCategoryStore) GetCategoriesByIds(categoryIds []string) store.StoreChannel {
+	return c.s.count(c.s.categories)
+}
+
+func newBenchmarkApp(b *testing.B, s store.Store) *App {
+	configStore, err := config.NewMemoryStore()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return &App{
+		Srv: &Server{
+			Store:       s,
+			configStore: configStore,
+		},
+	}
+}
+
+// checkStoreCalls fails the benchmark when the number of queries grows with the list.
+func checkStoreCalls(b *testing.B, s *countingStore, n int, expected int) {
+	calls := atomic.LoadInt64(&s.calls)
+	b.ReportMetric(float64(calls)/float64(b.N), "calls/op")
+
+	if calls != int64(expected*b.N) {
+		b.Fatalf("%d store calls to prepare %d items %d times, expected %d per run", calls, n, b.N, expected)
+	}
+}
+
+func BenchmarkPrepareOrdersForClient(b *testing.B) {
+	for _, n := range benchmarkListSizes {
+		b.Run(fmt.Sprintf("orders=%d", n), func(b *testing.B) {
+			s := newCountingStore(n)
+			a := newBenchmarkApp(b, s)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if orders := a.prepareOrdersForClient(s.orders, false, a.NewBatchLoader()); len(orders) != n {
+					b.Fatalf("prepared %d orders, expected %d", len(orders), n)
+				}
+			}
+			b.StopTimer()
+
+			checkStoreCalls(b, s, n, PREPARE_ORDERS_STORE_CALLS)
+		})
+	}
+}
+
+func BenchmarkPrepareProductsForClient(b *testing.B) {
+	for _, n := range benchmarkListSizes {
+		b.Run(fmt.Sprintf("products=%d", n), func(b *testing.B) {
+			s := newCountingStore(n)
+			a := newBenchmarkApp(b, s)
+			products := s.products[:n]
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if prepared := a.prepareProductsForClient(products); len(prepared) != n {
+					b.Fatalf("prepared %d products, expected %d", len(prepared), n)
+				}
+			}
+			b.StopTimer()
+
+			checkStoreCalls(b, s, n, PREPARE_PRODUCTS_STORE_CALLS)
+		})
+	}
+}
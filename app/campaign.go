@@ -0,0 +1,366 @@
+package app
+
+import (
+	"net/http"
+	"time"
+
+	"im/mlog"
+	"im/model"
+)
+
+func (a *App) GetCampaign(campaignId string) (*model.Campaign, *model.AppError) {
+	result := <-a.Srv.Store.Campaign().Get(campaignId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.Campaign), nil
+}
+
+func (a *App) GetCampaignsForApp(appId string, page, perPage int) ([]*model.Campaign, *model.AppError) {
+	result := <-a.Srv.Store.Campaign().GetForApp(appId, page*perPage, perPage)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.([]*model.Campaign), nil
+}
+
+func (a *App) validateCampaignPromo(campaign *model.Campaign) *model.AppError {
+	if len(campaign.PromoId) == 0 {
+		return nil
+	}
+
+	result := <-a.Srv.Store.Promo().Get(campaign.PromoId)
+	if result.Err != nil {
+		return result.Err
+	}
+
+	if result.Data.(*model.Promo).AppId != campaign.AppId {
+		return model.NewAppError("validateCampaignPromo", "app.campaign.promo.other_app.app_error", nil, "promo_id="+campaign.PromoId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (a *App) CreateCampaign(campaign *model.Campaign) (*model.Campaign, *model.AppError) {
+	campaign.Status = model.CAMPAIGN_STATUS_DRAFT
+	campaign.Total = 0
+	campaign.StartedAt = 0
+	campaign.CompletedAt = 0
+
+	if err := a.validateCampaignPromo(campaign); err != nil {
+		return nil, err
+	}
+
+	result := <-a.Srv.Store.Campaign().Save(campaign)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.Campaign), nil
+}
+
+func (a *App) PatchCampaign(campaignId string, patch *model.CampaignPatch) (*model.Campaign, *model.AppError) {
+	campaign, err := a.GetCampaign(campaignId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !campaign.IsEditable() {
+		return nil, model.NewAppError("PatchCampaign", "app.campaign.patch.not_editable.app_error", nil, "id="+campaignId+", status="+campaign.Status, http.StatusBadRequest)
+	}
+
+	campaign.Patch(patch)
+
+	if err := a.validateCampaignPromo(campaign); err != nil {
+		return nil, err
+	}
+
+	result := <-a.Srv.Store.Campaign().Update(campaign)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.Campaign), nil
+}
+
+func (a *App) DeleteCampaign(campaignId string) *model.AppError {
+	campaign, err := a.GetCampaign(campaignId)
+	if err != nil {
+		return err
+	}
+
+	if campaign.Status == model.CAMPAIGN_STATUS_SENDING {
+		return model.NewAppError("DeleteCampaign", "app.campaign.delete.sending.app_error", nil, "id="+campaignId, http.StatusBadRequest)
+	}
+
+	if result := <-a.Srv.Store.Campaign().Delete(campaignId, model.GetMillis()); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func (a *App) updateCampaignStatus(campaign *model.Campaign, status string) (*model.Campaign, *model.AppError) {
+	campaign.Status = status
+	result := <-a.Srv.Store.Campaign().Update(campaign)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.Campaign), nil
+}
+
+// ScheduleCampaign puts the campaign in the queue of the campaign job. A zero sendAt sends
+// it on the next run of the job.
+func (a *App) ScheduleCampaign(campaignId string, sendAt int64) (*model.Campaign, *model.AppError) {
+	campaign, err := a.GetCampaign(campaignId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !campaign.IsEditable() {
+		return nil, model.NewAppError("ScheduleCampaign", "app.campaign.schedule.not_editable.app_error", nil, "id="+campaignId+", status="+campaign.Status, http.StatusBadRequest)
+	}
+
+	if sendAt == 0 {
+		sendAt = model.GetMillis()
+	}
+	campaign.SendAt = sendAt
+
+	return a.updateCampaignStatus(campaign, model.CAMPAIGN_STATUS_SCHEDULED)
+}
+
+// SendCampaignNow schedules the campaign and creates the job right away instead of waiting
+// for the scheduler.
+func (a *App) SendCampaignNow(campaignId string) (*model.Campaign, *model.AppError) {
+	campaign, err := a.ScheduleCampaign(campaignId, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := a.Srv.Jobs.CreateJob(model.JOB_TYPE_CAMPAIGN, nil); err != nil {
+		mlog.Error("Failed to create campaign job", mlog.String("campaign_id", campaignId), mlog.Err(err))
+	}
+
+	return campaign, nil
+}
+
+// CancelCampaign stops the campaign. A campaign being sent stops after the current batch,
+// the customers already notified stay in the statistics.
+func (a *App) CancelCampaign(campaignId string) (*model.Campaign, *model.AppError) {
+	campaign, err := a.GetCampaign(campaignId)
+	if err != nil {
+		return nil, err
+	}
+
+	switch campaign.Status {
+	case model.CAMPAIGN_STATUS_COMPLETED, model.CAMPAIGN_STATUS_CANCELED:
+		return nil, model.NewAppError("CancelCampaign", "app.campaign.cancel.finished.app_error", nil, "id="+campaignId+", status="+campaign.Status, http.StatusBadRequest)
+	}
+
+	return a.updateCampaignStatus(campaign, model.CAMPAIGN_STATUS_CANCELED)
+}
+
+func (a *App) PreviewCampaignSegment(appId string, segment *model.CampaignSegment) (int64, *model.AppError) {
+	if segment != nil {
+		if err := segment.IsValid(); err != nil {
+			return 0, err
+		}
+	}
+
+	result := <-a.Srv.Store.Campaign().CountSegment(appId, segment)
+	if result.Err != nil {
+		return 0, result.Err
+	}
+
+	return result.Data.(int64), nil
+}
+
+func (a *App) GetCampaignStats(campaignId string) (*model.CampaignStats, *model.AppError) {
+	result := <-a.Srv.Store.CampaignRecipient().GetStats(campaignId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.CampaignStats), nil
+}
+
+func (a *App) GetCampaignRecipients(campaignId, status string, page, perPage int) ([]*model.CampaignRecipient, *model.AppError) {
+	result := <-a.Srv.Store.CampaignRecipient().GetForCampaign(campaignId, status, page*perPage, perPage)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.([]*model.CampaignRecipient), nil
+}
+
+func (a *App) MarkCampaignOpened(campaignId, userId string) *model.AppError {
+	if result := <-a.Srv.Store.CampaignRecipient().SetOpened(campaignId, userId, model.GetMillis()); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func (a *App) HasDueCampaigns() (bool, *model.AppError) {
+	result := <-a.Srv.Store.Campaign().GetDue(model.GetMillis())
+	if result.Err != nil {
+		return false, result.Err
+	}
+
+	return len(result.Data.([]*model.Campaign)) > 0, nil
+}
+
+// ProcessDueCampaigns sends every campaign whose time has come. It is run by the campaign job.
+func (a *App) ProcessDueCampaigns() *model.AppError {
+	result := <-a.Srv.Store.Campaign().GetDue(model.GetMillis())
+	if result.Err != nil {
+		return result.Err
+	}
+
+	for _, campaign := range result.Data.([]*model.Campaign) {
+		if err := a.ProcessCampaign(campaign); err != nil {
+			mlog.Error("Failed to send campaign", mlog.String("campaign_id", campaign.Id), mlog.Err(err))
+		}
+	}
+
+	return nil
+}
+
+// ProcessCampaign materialises the recipients of a scheduled campaign and sends the pushes
+// in batches. A campaign left in the sending state is resumed from its pending recipients.
+func (a *App) ProcessCampaign(campaign *model.Campaign) *model.AppError {
+	var err *model.AppError
+
+	if campaign.Status == model.CAMPAIGN_STATUS_SCHEDULED {
+		if campaign, err = a.materializeCampaignRecipients(campaign); err != nil {
+			return err
+		}
+	}
+
+	for {
+		result := <-a.Srv.Store.CampaignRecipient().GetPending(campaign.Id, campaign.BatchSize)
+		if result.Err != nil {
+			return result.Err
+		}
+
+		recipients := result.Data.([]*model.CampaignRecipient)
+		if len(recipients) == 0 {
+			break
+		}
+
+		for _, recipient := range recipients {
+			a.sendCampaignPush(campaign, recipient)
+
+			if result := <-a.Srv.Store.CampaignRecipient().Update(recipient); result.Err != nil {
+				return result.Err
+			}
+		}
+
+		// the campaign may have been canceled while the batch was being sent
+		if campaign, err = a.GetCampaign(campaign.Id); err != nil {
+			return err
+		}
+		if campaign.Status != model.CAMPAIGN_STATUS_SENDING {
+			return nil
+		}
+
+		if len(recipients) < campaign.BatchSize {
+			break
+		}
+
+		time.Sleep(time.Duration(campaign.BatchInterval) * time.Millisecond)
+	}
+
+	campaign.CompletedAt = model.GetMillis()
+	_, err = a.updateCampaignStatus(campaign, model.CAMPAIGN_STATUS_COMPLETED)
+	return err
+}
+
+// materializeCampaignRecipients fixes the audience of the campaign at the moment it starts,
+// customers joining the segment later do not receive it.
+func (a *App) materializeCampaignRecipients(campaign *model.Campaign) (*model.Campaign, *model.AppError) {
+	// a previous run may have stopped in the middle of the materialisation
+	if result := <-a.Srv.Store.CampaignRecipient().DeleteForCampaign(campaign.Id); result.Err != nil {
+		return nil, result.Err
+	}
+
+	var total int64
+	afterId := ""
+	for {
+		result := <-a.Srv.Store.Campaign().GetSegmentUserIds(campaign.AppId, campaign.Segment, afterId, model.CAMPAIGN_MAX_BATCH_SIZE)
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		userIds := result.Data.([]string)
+		if len(userIds) == 0 {
+			break
+		}
+
+		recipients := make([]*model.CampaignRecipient, 0, len(userIds))
+		for _, userId := range userIds {
+			recipients = append(recipients, &model.CampaignRecipient{
+				CampaignId: campaign.Id,
+				UserId:     userId,
+			})
+		}
+
+		if result := <-a.Srv.Store.CampaignRecipient().SaveMultiple(recipients); result.Err != nil {
+			return nil, result.Err
+		}
+
+		total += int64(len(userIds))
+		afterId = userIds[len(userIds)-1]
+
+		if len(userIds) < model.CAMPAIGN_MAX_BATCH_SIZE {
+			break
+		}
+	}
+
+	campaign.Total = total
+	campaign.StartedAt = model.GetMillis()
+	return a.updateCampaignStatus(campaign, model.CAMPAIGN_STATUS_SENDING)
+}
+
+func (a *App) sendCampaignPush(campaign *model.Campaign, recipient *model.CampaignRecipient) {
+	user, err := a.GetUser(recipient.UserId)
+	if err != nil {
+		recipient.SetFailed(model.CAMPAIGN_RECIPIENT_STATUS_FAILED, err.Error())
+		return
+	}
+
+	if user.DeleteAt > 0 || user.BlockedAt > 0 {
+		recipient.SetFailed(model.CAMPAIGN_RECIPIENT_STATUS_SKIPPED, "user is not active")
+		return
+	}
+
+	if user.NotifyProps[model.PUSH_NOTIFY_PROP] != model.USER_NOTIFY_ALL {
+		recipient.SetFailed(model.CAMPAIGN_RECIPIENT_STATUS_SKIPPED, "push notifications are disabled")
+		return
+	}
+
+	if sessions, err := a.getMobileAppSessions(user.Id); err != nil {
+		recipient.SetFailed(model.CAMPAIGN_RECIPIENT_STATUS_FAILED, err.Error())
+		return
+	} else if len(sessions) == 0 {
+		recipient.SetFailed(model.CAMPAIGN_RECIPIENT_STATUS_SKIPPED, "no mobile device")
+		return
+	}
+
+	channel, err := a.getCustomerChannel(user.Id)
+	if err != nil {
+		recipient.SetFailed(model.CAMPAIGN_RECIPIENT_STATUS_FAILED, err.Error())
+		return
+	}
+
+	a.SendCustomNotifications(user, channel, campaign.Message, NotificationPayload{
+		Type:       model.CAMPAIGN_PUSH_TYPE,
+		PromoId:    campaign.PromoId,
+		CampaignId: campaign.Id,
+	})
+
+	recipient.SetSent()
+}
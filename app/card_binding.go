@@ -0,0 +1,195 @@
+package app
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"im/mlog"
+	"im/model"
+	"im/services/payment"
+)
+
+func (a *App) GetCardBinding(bindingId string) (*model.CardBinding, *model.AppError) {
+	result := <-a.Srv.Store.CardBinding().Get(bindingId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.CardBinding), nil
+}
+
+func (a *App) GetUserCardBindings(userId string) ([]*model.CardBinding, *model.AppError) {
+	result := <-a.Srv.Store.CardBinding().GetForUser(userId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.([]*model.CardBinding), nil
+}
+
+// SyncUserCardBindings brings the saved cards of the customer in line with the acquirer of the
+// application: new cards are saved and the cards the acquirer no longer knows are deleted.
+func (a *App) SyncUserCardBindings(userId string, application *model.Application) ([]*model.CardBinding, *model.AppError) {
+	var remote []*model.CardBinding
+	var err *model.AppError
+
+	switch application.AqType {
+	case model.SBERBANK_AQUIRING_TYPE:
+		sber := payment.SberBankBackend{Metrics: a.Metrics}
+		remote, err = sber.GetBindings(userId, a.sberbankClientConfig(application))
+	case model.ALFABANK_AQUIRING_TYPE:
+		alfa := payment.AlfaBankBackend{Metrics: a.Metrics}
+		remote, err = alfa.GetBindings(userId, a.alfabankClientConfig(application))
+	default:
+		return a.GetUserCardBindings(userId)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	local, err := a.GetUserCardBindings(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := model.GetMillis()
+	known := make(map[string]bool, len(remote))
+	bindings := make([]*model.CardBinding, 0, len(remote))
+
+	for _, binding := range remote {
+		binding.VerifiedAt = now
+
+		result := <-a.Srv.Store.CardBinding().Save(binding)
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		known[binding.BindingId] = true
+		bindings = append(bindings, result.Data.(*model.CardBinding))
+	}
+
+	for _, binding := range local {
+		if binding.PaySystemId != application.AqType || known[binding.BindingId] {
+			continue
+		}
+
+		if result := <-a.Srv.Store.CardBinding().Delete(binding.Id, now); result.Err != nil {
+			mlog.Warn("Failed to delete the card binding", mlog.String("binding_id", binding.Id), mlog.Err(result.Err))
+		}
+	}
+
+	return bindings, nil
+}
+
+// VerifyCardBinding asks the acquirer whether the card can still be charged.
+func (a *App) VerifyCardBinding(binding *model.CardBinding, application *model.Application) (*model.CardBinding, *model.AppError) {
+	bindings, err := a.SyncUserCardBindings(binding.UserId, application)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, b := range bindings {
+		if b.Id != binding.Id {
+			continue
+		}
+
+		if b.IsExpired(time.Now()) {
+			return nil, model.NewAppError("VerifyCardBinding", "app.card_binding.verify.expired.app_error", nil, "id="+binding.Id, http.StatusBadRequest)
+		}
+
+		return b, nil
+	}
+
+	return nil, model.NewAppError("VerifyCardBinding", "app.card_binding.verify.not_found.app_error", nil, "id="+binding.Id, http.StatusNotFound)
+}
+
+// DeleteCardBinding unbinds the card at the acquirer and forgets it.
+func (a *App) DeleteCardBinding(binding *model.CardBinding, application *model.Application) *model.AppError {
+	var err *model.AppError
+
+	switch binding.PaySystemId {
+	case model.SBERBANK_AQUIRING_TYPE:
+		sber := payment.SberBankBackend{Metrics: a.Metrics}
+		err = sber.UnBindCard(binding.BindingId, a.sberbankClientConfig(application))
+	case model.ALFABANK_AQUIRING_TYPE:
+		alfa := payment.AlfaBankBackend{Metrics: a.Metrics}
+		err = alfa.UnBindCard(binding.BindingId, a.alfabankClientConfig(application))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if result := <-a.Srv.Store.CardBinding().Delete(binding.Id, model.GetMillis()); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// PayOrderWithCardBinding registers the order with the acquirer and charges the bound card. The
+// payment is empty when it went through, otherwise the client has to pass 3-D Secure.
+func (a *App) PayOrderWithCardBinding(order *model.Order, binding *model.CardBinding, application *model.Application, ip string, cvc string) (*model.CardBindingPayment, *model.AppError) {
+	if order.UserId != binding.UserId {
+		return nil, model.NewAppError("PayOrderWithCardBinding", "app.card_binding.pay.user.app_error", nil, "order_id="+order.Id+", binding_id="+binding.Id, http.StatusForbidden)
+	}
+
+	if order.Payed || order.Canceled {
+		return nil, model.NewAppError("PayOrderWithCardBinding", "app.card_binding.pay.order_status.app_error", nil, "order_id="+order.Id, http.StatusBadRequest)
+	}
+
+	if binding.PaySystemId != application.AqType {
+		return nil, model.NewAppError("PayOrderWithCardBinding", "app.card_binding.pay.pay_system.app_error", nil, "binding_id="+binding.Id+", pay_system_id="+binding.PaySystemId, http.StatusBadRequest)
+	}
+
+	if binding.IsExpired(time.Now()) {
+		return nil, model.NewAppError("PayOrderWithCardBinding", "app.card_binding.pay.expired.app_error", nil, "binding_id="+binding.Id, http.StatusBadRequest)
+	}
+
+	receipt := a.BuildOrderReceipt(order, application)
+
+	var payOrder func(order *model.Order) (*model.CardBindingPayment, *model.AppError)
+	var mdOrder string
+
+	switch application.AqType {
+	case model.SBERBANK_AQUIRING_TYPE:
+		sber := payment.SberBankBackend{Metrics: a.Metrics}
+		config := a.sberbankClientConfig(application)
+
+		response, err := sber.RegisterOrder(order, receipt, binding.UserId, config)
+		if err != nil {
+			return nil, err
+		}
+
+		mdOrder = response.OrderId
+		payOrder = func(order *model.Order) (*model.CardBindingPayment, *model.AppError) {
+			return sber.PaymentOrderBinding(order, binding.BindingId, ip, cvc, config)
+		}
+	case model.ALFABANK_AQUIRING_TYPE:
+		alfa := payment.AlfaBankBackend{Metrics: a.Metrics}
+		config := a.alfabankClientConfig(application)
+
+		response, err := alfa.RegisterOrder(order, receipt, binding.UserId, config)
+		if err != nil {
+			return nil, err
+		}
+
+		mdOrder = response.OrderId
+		payOrder = func(order *model.Order) (*model.CardBindingPayment, *model.AppError) {
+			return alfa.PaymentOrderBinding(order, binding.BindingId, ip, cvc, config)
+		}
+	}
+
+	order, err := a.UpdateOrder(order.Id, &model.OrderPatch{
+		PaySystemId:       model.NewString(application.AqType),
+		PaySystemCode:     model.NewString(mdOrder),
+		PaySystemOrderNum: model.NewString(strconv.FormatInt(model.GetMillis(), 10)),
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return payOrder(order)
+}
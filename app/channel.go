@@ -2059,6 +2059,23 @@ func (a *App) FindOpennedChannel(userId string) (*model.Channel, *model.AppError
 	}
 }
 
+// getCustomerChannel returns the support channel of the customer, creating it when there is none open.
+func (a *App) getCustomerChannel(userId string) (*model.Channel, *model.AppError) {
+	if channel, _ := a.FindOpennedChannel(userId); channel != nil {
+		a.AddChannelMemberIfNeeded(userId, channel)
+		return channel, nil
+	}
+
+	channel, err := a.CreateUnresolvedChannel(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	<-a.Srv.Store.ChannelMemberHistory().LogJoinEvent(userId, channel.Id, model.GetMillis())
+
+	return channel, nil
+}
+
 func (a *App) CreateUnresolvedChannel(userId string) (*model.Channel, *model.AppError) {
 
 	/*atc, _ := a.WaitForOpennedChannel(userId)
@@ -2122,21 +2139,14 @@ func (a *App) GetOrCreateDeferredChannel(userId string) (*model.Channel, *model.
 	}
 
 	var channelMemberIds []string
-	var members []*model.TeamMember
 
 	var team *model.Team
 	if team, err = a.GetTeamByName(user.AppId); err != nil {
 		return nil, err
 	}
 
-	if members, err = a.GetTeamMembers(team.Id, 0, 50); err != nil {
+	if channelMemberIds, err = a.getCustomerChannelMemberIds(team.Id); err != nil {
 		return nil, err
-	} else {
-
-		for _, member := range members {
-			channelMemberIds = append(channelMemberIds, member.UserId)
-		}
-
 	}
 
 	result := <-a.Srv.Store.Channel().GetDeferredChannelForUser(userId)
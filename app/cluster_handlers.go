@@ -16,6 +16,7 @@ func (a *App) RegisterAllClusterMessageHandlers() {
 	a.Cluster.RegisterClusterMessageHandler(model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_CHANNEL, a.ClusterInvalidateCacheForChannelHandler)
 	a.Cluster.RegisterClusterMessageHandler(model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_USER, a.ClusterInvalidateCacheForUserHandler)
 	a.Cluster.RegisterClusterMessageHandler(model.CLUSTER_EVENT_CLEAR_SESSION_CACHE_FOR_USER, a.ClusterClearSessionCacheForUserHandler)
+	a.Cluster.RegisterClusterMessageHandler(model.CLUSTER_EVENT_UPDATE_APPLICATION_CONFIG, a.ClusterUpdateApplicationConfigHandler)
 }
 
 func (a *App) ClusterPublishHandler(msg *model.ClusterMessage) {
@@ -59,3 +60,7 @@ func (a *App) ClusterInvalidateCacheForUserHandler(msg *model.ClusterMessage) {
 func (a *App) ClusterClearSessionCacheForUserHandler(msg *model.ClusterMessage) {
 	a.ClearSessionCacheForUserSkipClusterSend(msg.Data)
 }
+
+func (a *App) ClusterUpdateApplicationConfigHandler(msg *model.ClusterMessage) {
+	a.ReloadApplicationConfigSkipClusterSend(msg.Data)
+}
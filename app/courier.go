@@ -0,0 +1,312 @@
+package app
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"sort"
+
+	"im/model"
+)
+
+const (
+	NEAREST_COURIER_OFFICES_LIMIT = 200
+)
+
+func (a *App) isCourier(member *model.TeamMember) bool {
+	if member == nil || member.DeleteAt != 0 {
+		return false
+	}
+
+	for _, role := range member.GetRoles() {
+		if role == model.APPLICATION_COURIER_ROLE_ID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AssignCourier gives the order to the courier. When courierId is empty the least busy
+// courier of the office nearest to the delivery address is picked.
+func (a *App) AssignCourier(orderId, courierId string) (*model.Order, *model.AppError) {
+	result := <-a.Srv.Store.Order().Get(orderId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	order := result.Data.(*model.Order)
+
+	if order.DeleteAt != 0 || order.Status == model.ORDER_STATUS_SHIPPED ||
+		order.Status == model.ORDER_STATUS_DECLINED || order.Status == model.ORDER_STATUS_REFUNDED {
+		return nil, model.NewAppError("AssignCourier", "app.courier.assign.closed.app_error", nil, "order_id="+orderId, http.StatusBadRequest)
+	}
+
+	customer, err := a.GetUser(order.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	team, err := a.GetApplicationTeam(customer.AppId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(courierId) == 0 {
+		if courierId, err = a.findNearestCourier(team.Id, customer.AppId, order); err != nil {
+			return nil, err
+		}
+	} else {
+		member, err := a.GetTeamMember(team.Id, courierId)
+		if err != nil {
+			return nil, err
+		}
+
+		if !a.isCourier(member) {
+			return nil, model.NewAppError("AssignCourier", "app.courier.assign.not_courier.app_error", nil, "user_id="+courierId, http.StatusBadRequest)
+		}
+	}
+
+	previousCourierId := order.CourierId
+
+	order.CourierId = courierId
+	order.CourierAssignedAt = model.GetMillis()
+	order.HandoverCode = model.NewHandoverCode()
+	order.UpdateAt = order.CourierAssignedAt
+
+	if result := <-a.Srv.Store.Order().Update(order); result.Err != nil {
+		return nil, result.Err
+	}
+
+	rorder := a.PrepareOrderForClient(order, false)
+	a.UpdatePostWithOrder(rorder, false)
+	a.publishOrderEvent(order, model.WEBSOCKET_EVENT_ORDER_UPDATED)
+	a.publishOrderTracking(order, model.ORDER_TRACKING_TYPE_COURIER)
+
+	a.sendCourierAssignedEvent(order, order.UserId)
+	a.sendCourierAssignedEvent(order, courierId)
+	if len(previousCourierId) > 0 && previousCourierId != courierId {
+		a.sendCourierAssignedEvent(order, previousCourierId)
+	}
+
+	if order.IsOutForDelivery() {
+		a.sendCourierLocationToCustomer(order)
+	}
+
+	return rorder, nil
+}
+
+// findNearestCourier walks the offices of the application from the nearest to the
+// delivery address and returns the courier with the fewest active deliveries in the
+// first office that has any courier.
+func (a *App) findNearestCourier(teamId, appId string, order *model.Order) (string, *model.AppError) {
+	list, err := a.GetAllOfficesPage(0, NEAREST_COURIER_OFFICES_LIMIT, &appId)
+	if err != nil {
+		return "", err
+	}
+
+	var offices []*model.Office
+	distances := make(map[string]float64)
+	for _, officeId := range list.Order {
+		office := list.Offices[officeId]
+		if office == nil || !office.Active || office.DeleteAt != 0 {
+			continue
+		}
+
+		offices = append(offices, office)
+
+		if latitude, longitude, ok := office.GetLocation(); ok && order.HasLocation() {
+			distances[office.Id] = model.GeoDistance(order.Latitude, order.Longitude, latitude, longitude)
+		}
+	}
+
+	// offices without coordinates go last
+	sort.SliceStable(offices, func(i, j int) bool {
+		di, iok := distances[offices[i].Id]
+		dj, jok := distances[offices[j].Id]
+		if iok != jok {
+			return iok
+		}
+		return di < dj
+	})
+
+	for _, office := range offices {
+		result := <-a.Srv.Store.StaffOffice().GetForOffice(office.Id)
+		if result.Err != nil {
+			return "", result.Err
+		}
+
+		var userIds []string
+		for _, so := range result.Data.([]*model.StaffOffice) {
+			userIds = append(userIds, so.UserId)
+		}
+
+		if len(userIds) == 0 {
+			continue
+		}
+
+		members, err := a.GetTeamMembersByIds(teamId, userIds)
+		if err != nil {
+			return "", err
+		}
+
+		var courierIds []string
+		for _, member := range members {
+			if a.isCourier(member) {
+				courierIds = append(courierIds, member.UserId)
+			}
+		}
+
+		if len(courierIds) == 0 {
+			continue
+		}
+
+		result = <-a.Srv.Store.Order().GetActiveForCouriers(courierIds)
+		if result.Err != nil {
+			return "", result.Err
+		}
+
+		load := make(map[string]int)
+		for _, o := range result.Data.([]*model.Order) {
+			load[o.CourierId]++
+		}
+
+		courierId := courierIds[0]
+		for _, id := range courierIds[1:] {
+			if load[id] < load[courierId] {
+				courierId = id
+			}
+		}
+
+		return courierId, nil
+	}
+
+	return "", model.NewAppError("findNearestCourier", "app.courier.find_nearest.not_found.app_error", nil, "order_id="+order.Id, http.StatusBadRequest)
+}
+
+// GetCourierOrders returns the orders the courier still has to deliver.
+func (a *App) GetCourierOrders(courierId string) ([]*model.Order, *model.AppError) {
+	result := <-a.Srv.Store.Order().GetActiveForCouriers([]string{courierId})
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.([]*model.Order), nil
+}
+
+// UpdateCourierLocation stores the position reported by the courier and sends it to
+// the customers whose orders the courier is carrying right now.
+func (a *App) UpdateCourierLocation(location *model.CourierLocation) (*model.CourierLocation, *model.AppError) {
+	result := <-a.Srv.Store.CourierLocation().Save(location)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	location = result.Data.(*model.CourierLocation)
+
+	orders, err := a.GetCourierOrders(location.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, order := range orders {
+		if order.IsOutForDelivery() {
+			a.sendCourierLocationEvent(order, location)
+			a.publishOrderTracking(order, model.ORDER_TRACKING_TYPE_LOCATION)
+		}
+	}
+
+	return location, nil
+}
+
+func (a *App) GetCourierLocation(courierId string) (*model.CourierLocation, *model.AppError) {
+	result := <-a.Srv.Store.CourierLocation().Get(courierId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.CourierLocation), nil
+}
+
+// GetOrderCourierLocation returns the position of the courier carrying the order.
+func (a *App) GetOrderCourierLocation(order *model.Order) (*model.CourierLocation, *model.AppError) {
+	if !order.IsOutForDelivery() {
+		return nil, model.NewAppError("GetOrderCourierLocation", "app.courier.get_order_location.not_shipping.app_error", nil, "order_id="+order.Id, http.StatusNotFound)
+	}
+
+	return a.GetCourierLocation(order.CourierId)
+}
+
+// GetOrderHandoverCode returns the code the customer has to tell the courier.
+func (a *App) GetOrderHandoverCode(orderId string) (string, *model.AppError) {
+	result := <-a.Srv.Store.Order().Get(orderId)
+	if result.Err != nil {
+		return "", result.Err
+	}
+	order := result.Data.(*model.Order)
+
+	if len(order.CourierId) == 0 || len(order.HandoverCode) == 0 {
+		return "", model.NewAppError("GetOrderHandoverCode", "app.courier.get_handover_code.not_assigned.app_error", nil, "order_id="+orderId, http.StatusNotFound)
+	}
+
+	return order.HandoverCode, nil
+}
+
+// ConfirmOrderHandover closes the delivery once the courier enters the code received from the customer.
+func (a *App) ConfirmOrderHandover(orderId, courierId, code string) (*model.Order, *model.AppError) {
+	result := <-a.Srv.Store.Order().Get(orderId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	order := result.Data.(*model.Order)
+
+	if order.CourierId != courierId {
+		return nil, model.NewAppError("ConfirmOrderHandover", "app.courier.confirm_handover.other_courier.app_error", nil, "order_id="+orderId, http.StatusForbidden)
+	}
+
+	if !order.IsOutForDelivery() {
+		return nil, model.NewAppError("ConfirmOrderHandover", "app.courier.confirm_handover.not_shipping.app_error", nil, "order_id="+orderId+", status="+order.Status, http.StatusBadRequest)
+	}
+
+	if len(order.HandoverCode) == 0 || subtle.ConstantTimeCompare([]byte(order.HandoverCode), []byte(code)) != 1 {
+		return nil, model.NewAppError("ConfirmOrderHandover", "app.courier.confirm_handover.invalid_code.app_error", nil, "order_id="+orderId, http.StatusBadRequest)
+	}
+
+	order.HandoverAt = model.GetMillis()
+	order.UpdateAt = order.HandoverAt
+
+	if result := <-a.Srv.Store.Order().Update(order); result.Err != nil {
+		return nil, result.Err
+	}
+
+	if err := a.SetOrderShipped(orderId); err != nil {
+		return nil, err
+	}
+
+	return a.GetOrder(orderId)
+}
+
+func (a *App) sendCourierAssignedEvent(order *model.Order, userId string) {
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_COURIER_ASSIGNED, "", "", userId, nil)
+	message.Add("order_id", order.Id)
+	message.Add("courier_id", order.CourierId)
+
+	a.Srv.Go(func() {
+		a.Publish(message)
+	})
+}
+
+func (a *App) sendCourierLocationEvent(order *model.Order, location *model.CourierLocation) {
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_COURIER_LOCATION, "", "", order.UserId, nil)
+	message.Add("order_id", order.Id)
+	message.Add("location", location.ToJson())
+
+	a.Srv.Go(func() {
+		a.Publish(message)
+	})
+}
+
+// sendCourierLocationToCustomer sends the last known courier position right away, so
+// the customer does not have to wait for the next update from the courier.
+func (a *App) sendCourierLocationToCustomer(order *model.Order) {
+	if location, err := a.GetCourierLocation(order.CourierId); err == nil {
+		a.sendCourierLocationEvent(order, location)
+	}
+}
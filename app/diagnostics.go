@@ -438,6 +438,9 @@ func (a *App) trackConfig() {
 		"channel_index_shards":              *cfg.ElasticsearchSettings.ChannelIndexShards,
 		"user_index_replicas":               *cfg.ElasticsearchSettings.UserIndexReplicas,
 		"user_index_shards":                 *cfg.ElasticsearchSettings.UserIndexShards,
+		"product_index_replicas":            *cfg.ElasticsearchSettings.ProductIndexReplicas,
+		"product_index_shards":              *cfg.ElasticsearchSettings.ProductIndexShards,
+		"product_synonyms":                  len(cfg.ElasticsearchSettings.ProductSynonyms),
 		"isdefault_index_prefix":            isDefault(*cfg.ElasticsearchSettings.IndexPrefix, model.ELASTICSEARCH_SETTINGS_DEFAULT_INDEX_PREFIX),
 		"live_indexing_batch_size":          *cfg.ElasticsearchSettings.LiveIndexingBatchSize,
 		"bulk_indexing_time_window_seconds": *cfg.ElasticsearchSettings.BulkIndexingTimeWindowSeconds,
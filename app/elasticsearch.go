@@ -9,6 +9,10 @@ import (
 	"im/model"
 )
 
+const (
+	PRODUCT_INDEXING_BATCH_SIZE = 200
+)
+
 func (a *App) TestElasticsearch(cfg *model.Config) *model.AppError {
 	if *cfg.ElasticsearchSettings.Password == model.FAKE_SETTING {
 		if *cfg.ElasticsearchSettings.ConnectionUrl == *a.Config().ElasticsearchSettings.ConnectionUrl && *cfg.ElasticsearchSettings.Username == *a.Config().ElasticsearchSettings.Username {
@@ -44,10 +48,46 @@ func (a *App) PurgeElasticsearchIndexes() *model.AppError {
 	return nil
 }
 
-func indexProducts(products map[string]*model.Product, esInterface einterfaces.ElasticsearchInterface) {
-	for _, product := range products {
-		if err := esInterface.IndexProduct(product, product.AppId); err != nil {
-			mlog.Error("Encountered error indexing product", mlog.String("product_id", product.Id), mlog.Err(err))
+func (a *App) GetProductAppIds() ([]string, *model.AppError) {
+	result := <-a.Srv.Store.Product().GetAppIds()
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.([]string), nil
+}
+
+// ReindexAppProducts recreates the product index of the application and fills it from the
+// database in batches. It returns the number of the indexed products.
+func (a *App) ReindexAppProducts(appId string) (int, *model.AppError) {
+	esI := a.Elasticsearch
+	if esI == nil {
+		return 0, model.NewAppError("ReindexAppProducts", "ent.elasticsearch.test_config.license.error", nil, "", http.StatusNotImplemented)
+	}
+
+	if err := esI.DeleteProductIndex(appId); err != nil {
+		return 0, err
+	}
+
+	if err := esI.CreateProductIndex(appId); err != nil {
+		return 0, err
+	}
+
+	indexed := 0
+	for {
+		result := <-a.Srv.Store.Product().GetAllPageByApp(indexed, PRODUCT_INDEXING_BATCH_SIZE, model.ColumnOrder{Column: "CreateAt", Type: "ASC"}, appId)
+		if result.Err != nil {
+			return indexed, result.Err
+		}
+
+		products := result.Data.(*model.ProductList).ToSlice()
+		if err := esI.BulkIndexProducts(products, appId); err != nil {
+			return indexed, err
+		}
+
+		indexed += len(products)
+		if len(products) < PRODUCT_INDEXING_BATCH_SIZE {
+			return indexed, nil
 		}
 	}
 }
@@ -79,8 +119,8 @@ func (a *App) CreateElasticsearchIndexes() *model.AppError {
 		return err
 	}
 
-	if list, _ := a.GetProductsList(); list != nil {
-		go indexProducts(list.Products, esI)
+	if _, err := a.Srv.Jobs.CreateJob(model.JOB_TYPE_ELASTICSEARCH_PRODUCT_INDEXING, nil); err != nil {
+		mlog.Error("Failed to create the product indexing job", mlog.Err(err))
 	}
 
 	// TODO page & per_page ?
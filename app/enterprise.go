@@ -17,6 +17,12 @@ func RegisterElasticsearchInterface(f func(*App) einterfaces.ElasticsearchInterf
 	elasticsearchInterface = f
 }
 
+var metricsInterface func(*App) einterfaces.MetricsInterface
+
+func RegisterMetricsInterface(f func(*App) einterfaces.MetricsInterface) {
+	metricsInterface = f
+}
+
 var jobsElasticsearchAggregatorInterface func(*App) ejobs.ElasticsearchAggregatorInterface
 
 func RegisterJobsElasticsearchAggregatorInterface(f func(*App) ejobs.ElasticsearchAggregatorInterface) {
@@ -29,6 +35,24 @@ func RegisterJobsElasticsearchIndexerInterface(f func(*App) ejobs.ElasticsearchI
 	jobsElasticsearchIndexerInterface = f
 }
 
+var jobsCampaignInterface func(*App) ejobs.CampaignJobInterface
+
+func RegisterJobsCampaignInterface(f func(*App) ejobs.CampaignJobInterface) {
+	jobsCampaignInterface = f
+}
+
+var jobsLifecycleTriggersInterface func(*App) ejobs.LifecycleTriggersJobInterface
+
+func RegisterJobsLifecycleTriggersInterface(f func(*App) ejobs.LifecycleTriggersJobInterface) {
+	jobsLifecycleTriggersInterface = f
+}
+
+var jobsLoyaltyTiersInterface func(*App) ejobs.LoyaltyTiersJobInterface
+
+func RegisterJobsLoyaltyTiersInterface(f func(*App) ejobs.LoyaltyTiersJobInterface) {
+	jobsLoyaltyTiersInterface = f
+}
+
 func (s *Server) initEnterprise() {
 
 	if elasticsearchInterface != nil {
@@ -38,4 +62,8 @@ func (s *Server) initEnterprise() {
 	if clusterInterface != nil {
 		s.Cluster = clusterInterface(s)
 	}
+
+	if metricsInterface != nil {
+		s.Metrics = metricsInterface(s.FakeApp())
+	}
 }
@@ -0,0 +1,180 @@
+package app
+
+import (
+	"net/http"
+
+	"im/mlog"
+	"im/model"
+)
+
+const LIFECYCLE_TRIGGER_BATCH_SIZE = 500
+
+func (a *App) GetLifecycleTrigger(triggerId string) (*model.LifecycleTrigger, *model.AppError) {
+	result := <-a.Srv.Store.LifecycleTrigger().Get(triggerId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.LifecycleTrigger), nil
+}
+
+func (a *App) GetLifecycleTriggersForApp(appId string) ([]*model.LifecycleTrigger, *model.AppError) {
+	result := <-a.Srv.Store.LifecycleTrigger().GetForApp(appId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.([]*model.LifecycleTrigger), nil
+}
+
+func (a *App) CreateLifecycleTrigger(trigger *model.LifecycleTrigger) (*model.LifecycleTrigger, *model.AppError) {
+	result := <-a.Srv.Store.LifecycleTrigger().Save(trigger)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.LifecycleTrigger), nil
+}
+
+func (a *App) PatchLifecycleTrigger(triggerId string, patch *model.LifecycleTriggerPatch) (*model.LifecycleTrigger, *model.AppError) {
+	trigger, err := a.GetLifecycleTrigger(triggerId)
+	if err != nil {
+		return nil, err
+	}
+
+	trigger.Patch(patch)
+
+	result := <-a.Srv.Store.LifecycleTrigger().Update(trigger)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.LifecycleTrigger), nil
+}
+
+func (a *App) DeleteLifecycleTrigger(triggerId string) *model.AppError {
+	if result := <-a.Srv.Store.LifecycleTrigger().Delete(triggerId, model.GetMillis()); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func (a *App) GetLifecycleTriggerLogs(triggerId string, page, perPage int) ([]*model.LifecycleTriggerLog, *model.AppError) {
+	result := <-a.Srv.Store.LifecycleTriggerLog().GetForTrigger(triggerId, page*perPage, perPage)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.([]*model.LifecycleTriggerLog), nil
+}
+
+func (a *App) HasEnabledLifecycleTriggers() (bool, *model.AppError) {
+	result := <-a.Srv.Store.LifecycleTrigger().GetEnabled()
+	if result.Err != nil {
+		return false, result.Err
+	}
+
+	return len(result.Data.([]*model.LifecycleTrigger)) > 0, nil
+}
+
+// ProcessLifecycleTriggers evaluates every enabled trigger. It is run by the lifecycle job,
+// customers left over by the batch limit are picked up on the next run.
+func (a *App) ProcessLifecycleTriggers() *model.AppError {
+	result := <-a.Srv.Store.LifecycleTrigger().GetEnabled()
+	if result.Err != nil {
+		return result.Err
+	}
+
+	for _, trigger := range result.Data.([]*model.LifecycleTrigger) {
+		if err := a.ProcessLifecycleTrigger(trigger); err != nil {
+			mlog.Error("Failed to process lifecycle trigger", mlog.String("trigger_id", trigger.Id), mlog.Err(err))
+		}
+	}
+
+	return nil
+}
+
+func (a *App) ProcessLifecycleTrigger(trigger *model.LifecycleTrigger) *model.AppError {
+	result := <-a.Srv.Store.LifecycleTrigger().GetCandidates(trigger, model.GetMillis(), LIFECYCLE_TRIGGER_BATCH_SIZE)
+	if result.Err != nil {
+		return result.Err
+	}
+
+	for _, candidate := range result.Data.([]*model.LifecycleTriggerCandidate) {
+		log := &model.LifecycleTriggerLog{
+			TriggerId: trigger.Id,
+			UserId:    candidate.UserId,
+			SubjectId: candidate.SubjectId,
+		}
+
+		// the log is written first, so a failed action is not retried and the customer is never
+		// notified twice about the same event
+		if result := <-a.Srv.Store.LifecycleTriggerLog().Save(log); result.Err != nil {
+			if result.Err.StatusCode != http.StatusBadRequest {
+				return result.Err
+			}
+			continue
+		}
+
+		if err := a.fireLifecycleTrigger(trigger, candidate); err != nil {
+			log.SetError(err.Error())
+			if result := <-a.Srv.Store.LifecycleTriggerLog().Update(log); result.Err != nil {
+				mlog.Error("Failed to update lifecycle trigger log", mlog.String("trigger_id", trigger.Id), mlog.Err(result.Err))
+			}
+		}
+	}
+
+	return nil
+}
+
+func (a *App) fireLifecycleTrigger(trigger *model.LifecycleTrigger, candidate *model.LifecycleTriggerCandidate) *model.AppError {
+	user, err := a.GetUser(candidate.UserId)
+	if err != nil {
+		return err
+	}
+
+	if trigger.Bonus > 0 {
+		transaction := &model.Transaction{
+			AppId:       user.AppId,
+			UserId:      user.Id,
+			Description: trigger.Name,
+			Value:       trigger.Bonus,
+			Type:        model.TRANSACTION_TYPE_BONUS,
+		}
+
+		if _, err := a.AccrualTransaction(transaction); err != nil {
+			return err
+		}
+	}
+
+	if !trigger.Push && !trigger.Post {
+		return nil
+	}
+
+	channel, err := a.getCustomerChannel(user.Id)
+	if err != nil {
+		return err
+	}
+
+	if trigger.Post {
+		post := &model.Post{
+			UserId:    user.Id,
+			ChannelId: channel.Id,
+			Message:   trigger.Message,
+			Type:      model.POST_SYSTEM_GENERIC,
+		}
+
+		if _, err := a.CreatePost(post, channel, false); err != nil {
+			return err
+		}
+	}
+
+	if trigger.Push && user.NotifyProps[model.PUSH_NOTIFY_PROP] == model.USER_NOTIFY_ALL {
+		a.SendCustomNotifications(user, channel, trigger.Message, NotificationPayload{
+			Type: model.LIFECYCLE_PUSH_TYPE,
+		})
+	}
+
+	return nil
+}
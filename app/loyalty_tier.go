@@ -0,0 +1,281 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"im/mlog"
+	"im/model"
+)
+
+const LOYALTY_TIER_RECALCULATION_BATCH_SIZE = 1000
+
+func (a *App) GetLoyaltyTier(tierId string) (*model.LoyaltyTier, *model.AppError) {
+	result := <-a.Srv.Store.LoyaltyTier().Get(tierId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.LoyaltyTier), nil
+}
+
+func (a *App) GetLoyaltyTiersForApp(appId string) ([]*model.LoyaltyTier, *model.AppError) {
+	result := <-a.Srv.Store.LoyaltyTier().GetForApp(appId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.([]*model.LoyaltyTier), nil
+}
+
+func (a *App) CreateLoyaltyTier(tier *model.LoyaltyTier) (*model.LoyaltyTier, *model.AppError) {
+	tiers, err := a.GetLoyaltyTiersForApp(tier.AppId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tiers) >= model.LOYALTY_TIER_MAX_TIERS_PER_APP {
+		return nil, model.NewAppError("CreateLoyaltyTier", "app.loyalty_tier.create.too_many.app_error", nil, "app_id="+tier.AppId, http.StatusBadRequest)
+	}
+
+	result := <-a.Srv.Store.LoyaltyTier().Save(tier)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.LoyaltyTier), nil
+}
+
+func (a *App) PatchLoyaltyTier(tierId string, patch *model.LoyaltyTierPatch) (*model.LoyaltyTier, *model.AppError) {
+	tier, err := a.GetLoyaltyTier(tierId)
+	if err != nil {
+		return nil, err
+	}
+
+	tier.Patch(patch)
+
+	result := <-a.Srv.Store.LoyaltyTier().Update(tier)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.LoyaltyTier), nil
+}
+
+// DeleteLoyaltyTier removes the tier. Its customers fall back to the rates of the application
+// until the next recalculation moves them to another tier.
+func (a *App) DeleteLoyaltyTier(tierId string) *model.AppError {
+	if result := <-a.Srv.Store.LoyaltyTier().Delete(tierId, model.GetMillis()); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// GetUserTier returns the tier record of the customer, with the tier itself when it still exists.
+func (a *App) GetUserTier(userId string) (*model.UserTier, *model.AppError) {
+	result := <-a.Srv.Store.UserTier().Get(userId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	userTier := result.Data.(*model.UserTier)
+	if len(userTier.TierId) > 0 {
+		userTier.Tier, _ = a.GetLoyaltyTier(userTier.TierId)
+	}
+
+	return userTier, nil
+}
+
+// applicationLoyaltySettings returns the loyalty settings of the active configuration of the
+// application, the ones of its columns when the configuration cannot be loaded.
+func (a *App) applicationLoyaltySettings(application *model.Application) model.ApplicationLoyaltySettings {
+	config, err := a.GetApplicationConfig(application.Id)
+	if err != nil {
+		mlog.Warn("Failed to get the configuration of the application", mlog.String("app_id", application.Id), mlog.Err(err))
+		config = model.ApplicationConfigFromApplication(application)
+	}
+
+	return config.LoyaltySettings
+}
+
+// getCustomerTier returns the tier the customer holds in the application, nil when the
+// application has no tiers or the customer has not reached any.
+func (a *App) getCustomerTier(userId string, appId string, settings model.ApplicationLoyaltySettings) *model.LoyaltyTier {
+	if len(*settings.TierMetric) == 0 {
+		return nil
+	}
+
+	userTier, err := a.GetUserTier(userId)
+	if err != nil || userTier.Tier == nil || userTier.Tier.AppId != appId {
+		return nil
+	}
+
+	return userTier.Tier
+}
+
+// GetCustomerRates returns the cashback and the max discount percents that apply to the
+// customer: the ones of the tier when the customer holds one, of the application otherwise.
+func (a *App) GetCustomerRates(userId string, application *model.Application) (cashback float64, maxDiscount float64) {
+	settings := a.applicationLoyaltySettings(application)
+	if tier := a.getCustomerTier(userId, application.Id, settings); tier != nil {
+		return tier.Cashback, tier.MaxDiscount
+	}
+
+	return *settings.Cashback, float64(*settings.MaxDiscount)
+}
+
+// initLoyaltyTierConfigListener recalculates the tiers of an application as soon as the metric
+// or the period of its tiers changes, instead of waiting for the loyalty tiers job.
+func (s *Server) initLoyaltyTierConfigListener() {
+	s.AddApplicationConfigListener(func(appId string, oldConfig, newConfig *model.ApplicationConfig) {
+		if *oldConfig.LoyaltySettings.TierMetric == *newConfig.LoyaltySettings.TierMetric &&
+			*oldConfig.LoyaltySettings.TierPeriodDays == *newConfig.LoyaltySettings.TierPeriodDays {
+			return
+		}
+
+		a := s.FakeApp()
+		if len(*newConfig.LoyaltySettings.TierMetric) == 0 || !a.IsLeader() {
+			return
+		}
+
+		s.Go(func() {
+			if err := a.RecalculateAppLoyaltyTiers(appId); err != nil {
+				mlog.Error("Failed to recalculate loyalty tiers", mlog.String("app_id", appId), mlog.Err(err))
+			}
+		})
+	})
+}
+
+func (a *App) HasLoyaltyTierApps() (bool, *model.AppError) {
+	result := <-a.Srv.Store.LoyaltyTier().GetAppIds()
+	if result.Err != nil {
+		return false, result.Err
+	}
+
+	return len(result.Data.([]string)) > 0, nil
+}
+
+// RecalculateLoyaltyTiers moves the customers of every application with tiers to the tier
+// their rolling figures give. It is run by the loyalty tiers job.
+func (a *App) RecalculateLoyaltyTiers() *model.AppError {
+	result := <-a.Srv.Store.LoyaltyTier().GetAppIds()
+	if result.Err != nil {
+		return result.Err
+	}
+
+	for _, appId := range result.Data.([]string) {
+		if err := a.RecalculateAppLoyaltyTiers(appId); err != nil {
+			mlog.Error("Failed to recalculate loyalty tiers", mlog.String("app_id", appId), mlog.Err(err))
+		}
+	}
+
+	return nil
+}
+
+func (a *App) RecalculateAppLoyaltyTiers(appId string) *model.AppError {
+	application, err := a.GetApplication(appId)
+	if err != nil {
+		return err
+	}
+
+	settings := a.applicationLoyaltySettings(application)
+	if len(*settings.TierMetric) == 0 {
+		return nil
+	}
+
+	tiers, err := a.GetLoyaltyTiersForApp(appId)
+	if err != nil {
+		return err
+	}
+
+	tiersById := make(map[string]*model.LoyaltyTier, len(tiers))
+	for _, tier := range tiers {
+		tiersById[tier.Id] = tier
+	}
+
+	period := *settings.TierPeriodDays
+	if period == 0 {
+		period = model.LOYALTY_TIER_DEFAULT_PERIOD
+	}
+	since := model.GetMillis() - int64(period)*int64(24*time.Hour/time.Millisecond)
+
+	afterId := ""
+	for {
+		result := <-a.Srv.Store.UserTier().GetCustomerSpend(appId, since, afterId, LOYALTY_TIER_RECALCULATION_BATCH_SIZE)
+		if result.Err != nil {
+			return result.Err
+		}
+
+		spends := result.Data.([]*model.CustomerSpend)
+		if len(spends) == 0 {
+			break
+		}
+
+		for _, spend := range spends {
+			value := spend.Spend
+			if *settings.TierMetric == model.LOYALTY_TIER_METRIC_ORDERS {
+				value = float64(spend.OrderCount)
+			}
+
+			tierId := ""
+			tier := model.LoyaltyTierForValue(tiers, value)
+			if tier != nil {
+				tierId = tier.Id
+			}
+
+			if tierId == spend.TierId && spend.Spend == spend.PrevSpend && spend.OrderCount == spend.PrevOrderCount {
+				continue
+			}
+
+			userTier := &model.UserTier{
+				UserId:     spend.UserId,
+				AppId:      appId,
+				TierId:     tierId,
+				Spend:      spend.Spend,
+				OrderCount: spend.OrderCount,
+			}
+			if result := <-a.Srv.Store.UserTier().Save(userTier); result.Err != nil {
+				return result.Err
+			}
+
+			if tier != nil && tierId != spend.TierId {
+				previous, ok := tiersById[spend.TierId]
+				if !ok || previous.Threshold < tier.Threshold {
+					a.sendLoyaltyTierUpgradePush(spend.UserId, tier)
+				}
+			}
+		}
+
+		afterId = spends[len(spends)-1].UserId
+
+		if len(spends) < LOYALTY_TIER_RECALCULATION_BATCH_SIZE {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (a *App) sendLoyaltyTierUpgradePush(userId string, tier *model.LoyaltyTier) {
+	user, err := a.GetUser(userId)
+	if err != nil {
+		mlog.Error("Failed to get user for loyalty tier push", mlog.String("user_id", userId), mlog.Err(err))
+		return
+	}
+
+	if user.NotifyProps[model.PUSH_NOTIFY_PROP] != model.USER_NOTIFY_ALL {
+		return
+	}
+
+	channel, err := a.getCustomerChannel(user.Id)
+	if err != nil {
+		mlog.Error("Failed to get channel for loyalty tier push", mlog.String("user_id", userId), mlog.Err(err))
+		return
+	}
+
+	a.SendCustomNotifications(user, channel, fmt.Sprintf("Поздравляем! Ваш новый статус: %s", tier.Name), NotificationPayload{
+		Type: model.LOYALTY_TIER_PUSH_TYPE,
+	})
+}
@@ -126,3 +126,13 @@ func (a *App) GetMetricsForBonuses(appId string) ([]*model.MetricsForBonuses, *m
 
 	return metrics, nil
 }
+
+// customerAppId returns the application of the customer, the business metrics are labelled with it.
+func (a *App) customerAppId(userId string) string {
+	user, err := a.GetUser(userId)
+	if err != nil {
+		return ""
+	}
+
+	return user.AppId
+}
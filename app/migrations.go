@@ -11,6 +11,7 @@ import (
 
 const ADVANCED_PERMISSIONS_MIGRATION_KEY = "AdvancedPermissionsMigrationComplete"
 const EMOJIS_PERMISSIONS_MIGRATION_KEY = "EmojisPermissionsMigrationComplete"
+const APPLICATION_STAFF_ROLES_MIGRATION_KEY = "ApplicationStaffRolesMigrationComplete"
 
 // This function migrates the default built in roles from code/config to the database.
 func (a *App) DoAdvancedPermissionsMigration() {
@@ -153,3 +154,37 @@ func (a *App) DoEmojisPermissionsMigration() {
 		mlog.Critical(fmt.Sprint(result.Err))
 	}
 }
+
+// This function adds the application staff roles to the database for the servers
+// which already went through the advanced permissions migration.
+func (a *App) DoApplicationStaffRolesMigration() {
+	// If the migration is already marked as completed, don't do it again.
+	if result := <-a.Srv.Store.System().GetByName(APPLICATION_STAFF_ROLES_MIGRATION_KEY); result.Err == nil {
+		return
+	}
+
+	mlog.Info("Migrating application staff roles to database.")
+	roles := model.MakeDefaultRoles()
+
+	for _, roleName := range model.APPLICATION_STAFF_ROLES {
+		if result := <-a.Srv.Store.Role().GetByName(roleName); result.Err == nil {
+			continue
+		}
+
+		if result := <-a.Srv.Store.Role().Save(roles[roleName]); result.Err != nil {
+			mlog.Critical("Failed to migrate application staff role to database.")
+			mlog.Critical(fmt.Sprint(result.Err))
+			return
+		}
+	}
+
+	system := model.System{
+		Name:  APPLICATION_STAFF_ROLES_MIGRATION_KEY,
+		Value: "true",
+	}
+
+	if result := <-a.Srv.Store.System().Save(&system); result.Err != nil {
+		mlog.Critical("Failed to mark application staff roles migration as completed.")
+		mlog.Critical(fmt.Sprint(result.Err))
+	}
+}
@@ -704,8 +704,9 @@ type postNotification struct {
 }
 
 type NotificationPayload struct {
-	Type    string
-	PromoId string
+	Type       string
+	PromoId    string
+	CampaignId string
 }
 
 // Returns the name of the channel for this notification. For direct messages, this is the sender's name
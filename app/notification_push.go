@@ -74,6 +74,7 @@ func (a *App) sendPushNotificationCustomSync(post *model.Post, user *model.User,
 	msg.RootId = post.RootId
 	msg.SenderId = post.UserId
 	msg.PromoId = payload.PromoId
+	msg.CampaignId = payload.CampaignId
 
 	contentsConfig := *cfg.EmailSettings.PushNotificationContents
 	if contentsConfig != model.GENERIC_NO_CHANNEL_NOTIFICATION || channel.Type == model.CHANNEL_DIRECT {
@@ -386,12 +387,18 @@ func (a *App) sendToPushProxy(msg model.PushNotification, session *model.Session
 	if err != nil {
 		mlog.Error(fmt.Sprintf("Error sending to push proxy: UserId=%v SessionId=%v message=%v",
 			session.UserId, session.Id, err.Error()), mlog.String("user_id", session.UserId))
+		if a.Metrics != nil {
+			a.Metrics.IncrementPushSent(true)
+		}
 		return
 	}
 
 	resp, err := a.HTTPService.MakeClient(true).Do(request)
 	if err != nil {
 		mlog.Error(fmt.Sprintf("Device push reported as error for UserId=%v SessionId=%v message=%v", session.UserId, session.Id, err.Error()), mlog.String("user_id", session.UserId))
+		if a.Metrics != nil {
+			a.Metrics.IncrementPushSent(true)
+		}
 		return
 	}
 
@@ -408,6 +415,10 @@ func (a *App) sendToPushProxy(msg model.PushNotification, session *model.Session
 	if pushResponse[model.PUSH_STATUS] == model.PUSH_STATUS_FAIL {
 		mlog.Error(fmt.Sprintf("Device push reported as error for UserId=%v SessionId=%v message=%v", session.UserId, session.Id, pushResponse[model.PUSH_STATUS_ERROR_MSG]), mlog.String("user_id", session.UserId))
 	}
+
+	if a.Metrics != nil {
+		a.Metrics.IncrementPushSent(pushResponse[model.PUSH_STATUS] == model.PUSH_STATUS_FAIL)
+	}
 }
 
 func (a *App) getMobileAppSessions(userId string) ([]*model.Session, *model.AppError) {
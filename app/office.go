@@ -76,6 +76,12 @@ func (a *App) UpdateOffice(office *model.Office, safeUpdate bool) (*model.Office
 
 	newOffice.Preview = office.Preview
 	newOffice.Description = office.Description
+	newOffice.PrintFormat = office.PrintFormat
+	newOffice.PrintPaperWidth = office.PrintPaperWidth
+
+	if err := newOffice.IsValid(); err != nil {
+		return nil, err
+	}
 
 	result = <-a.Srv.Store.Office().Update(newOffice)
 	if result.Err != nil {
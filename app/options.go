@@ -99,6 +99,7 @@ func ServerConnector(s *Server) AppOption {
 
 		a.Cluster = s.Cluster
 		a.Elasticsearch = s.Elasticsearch
+		a.Metrics = s.Metrics
 
 		a.HTTPService = s.HTTPService
 		a.ImageProxy = s.ImageProxy
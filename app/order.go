@@ -2,6 +2,7 @@ package app
 
 import (
 	"fmt"
+	"im/mlog"
 	"im/model"
 	"im/services/payment/sberbank/schema"
 	"math"
@@ -52,7 +53,7 @@ func (a *App) RecalculateOrder(order *model.Order) (*model.Order, *model.AppErro
 	var price float64 = 0
 	if order.Positions != nil {
 		order.NormalizePositions()
-		order.Positions = a.PrepareBasketListForClient(order.Positions, true)
+		order.Positions = a.PrepareBasketListForClient(order.Positions, order.UserId, true)
 
 		for _, position := range order.Positions {
 			price += position.Price * float64(position.Quantity)
@@ -63,8 +64,19 @@ func (a *App) RecalculateOrder(order *model.Order) (*model.Order, *model.AppErro
 	return order, nil
 }
 
+// CreateOrderInvoice saves the order and posts the invoice to the customer. With the legal
+// entity of the buyer the invoice is a PDF document attached to the post, the plain invoice
+// is posted when the document fails.
 func (a *App) CreateOrderInvoice(order *model.Order, user *model.User) (*model.Order, *model.AppError) {
-	result := <-a.Srv.Store.Order().Save(order)
+	buyer := order.Buyer
+	if buyer != nil {
+		buyer.Trim()
+		if err := buyer.IsValid(); err != nil {
+			return nil, err
+		}
+	}
+
+	result := <-a.Srv.Store.Order().SaveWithBasket(order)
 	if result.Err != nil {
 		return nil, result.Err
 	}
@@ -80,7 +92,15 @@ func (a *App) CreateOrderInvoice(order *model.Order, user *model.User) (*model.O
 		Type:     model.POST_WITH_INVOICE,
 	}
 
-	a.CreatePostWithOrder(post, newOrder, false)
+	if buyer == nil {
+		a.CreatePostWithOrder(post, newOrder, false)
+		return newOrder, nil
+	}
+
+	if _, err := a.createOrderDocument(newOrder, model.ORDER_DOCUMENT_TYPE_INVOICE, buyer, "", post.Clone()); err != nil {
+		mlog.Error("Failed to create the invoice document of the order", mlog.String("order_id", newOrder.Id), mlog.Err(err))
+		a.CreatePostWithOrder(post, newOrder, false)
+	}
 
 	return newOrder, nil
 }
@@ -93,12 +113,16 @@ func (a *App) CreateOrder(order *model.Order) (*model.Order, *model.AppError) {
 			productIds = append(productIds, position.ProductId)
 		}
 	}
-	if discountLimit, err := a.GetDiscountLimits(productIds); err != nil {
+	if discountLimit, err := a.GetDiscountLimits(order.UserId, productIds); err != nil {
 		return nil, err
 	} else if int64(order.DiscountValue) > discountLimit.Total {
 		return nil, model.NewAppError("CreateOrder", "api.order.create_order.discount_limit.app_error", nil, "id="+order.Id, http.StatusBadRequest)
 	}
 
+	if err := a.prepareOrderAddress(order); err != nil {
+		return nil, err
+	}
+
 	var err *model.AppError
 	order, err = a.RecalculateOrder(order)
 	if err != nil {
@@ -116,6 +140,11 @@ func (a *App) CreateOrder(order *model.Order) (*model.Order, *model.AppError) {
 	}
 
 	newOrder := result.Data.(*model.Order)
+
+	if a.Metrics != nil {
+		a.Metrics.IncrementOrderCreated(a.customerAppId(newOrder.UserId))
+	}
+
 	var msg string
 	msg += fmt.Sprintf("Заказ № %s \n", newOrder.FormatOrderNumber())
 
@@ -135,10 +164,22 @@ func (a *App) CreateOrder(order *model.Order) (*model.Order, *model.AppError) {
 		}
 
 		a.DeductionTransaction(transaction)
+
+		if _, err := a.AddOrderPayment(newOrder, &model.OrderPayment{
+			Tender: model.ORDER_PAYMENT_TENDER_BONUS,
+			Amount: newOrder.DiscountValue,
+		}); err != nil {
+			mlog.Warn("Failed to record the bonus payment of the order", mlog.String("order_id", newOrder.Id), mlog.Err(err))
+		}
 	}
 
 	a.CreatePostWithOrder(post, newOrder, false)
 
+	a.triggerOrderWebhooks(newOrder, model.WEBHOOK_EVENT_ORDER_CREATED)
+	a.publishOrderEvent(newOrder, model.WEBSOCKET_EVENT_ORDER_CREATED)
+	a.publishOrderTracking(newOrder, model.ORDER_TRACKING_TYPE_STATUS)
+	a.exportOrderToPos(newOrder)
+
 	return newOrder, nil
 }
 
@@ -166,6 +207,10 @@ func (a *App) UpdateOrder(id string, patch *model.OrderPatch, safeUpdate bool) (
 	*newOrder = *oldOrder
 	newOrder.Patch(patch)
 
+	if !oldOrder.Payed && newOrder.Payed {
+		newOrder.PayedAt = model.GetMillis()
+	}
+
 	if oldOrder.Status != newOrder.Status {
 		switch newOrder.Status {
 		case model.ORDER_STATUS_AWAITING_FULFILLMENT:
@@ -193,6 +238,7 @@ func (a *App) UpdateOrder(id string, patch *model.OrderPatch, safeUpdate bool) (
 				return nil, result.Err
 			}
 			rorder := result.Data.(*model.Order)
+			a.triggerOrderWebhooks(rorder, model.WEBHOOK_EVENT_ORDER_UPDATED)
 			rorder = a.PrepareOrderForClient(rorder, false)
 			a.UpdatePostWithOrder(rorder, false)
 			return rorder, nil
@@ -206,6 +252,34 @@ func (a *App) UpdateOrder(id string, patch *model.OrderPatch, safeUpdate bool) (
 		return nil, result.Err
 	}
 	rorder := result.Data.(*model.Order)
+
+	if oldOrder.Status != rorder.Status && rorder.IsOutForDelivery() {
+		a.sendCourierLocationToCustomer(rorder)
+	}
+
+	if oldOrder.Status != rorder.Status && rorder.Status == model.ORDER_STATUS_REFUNDED {
+		if a.Metrics != nil {
+			a.Metrics.IncrementOrderRefunded(a.customerAppId(rorder.UserId))
+		}
+
+		a.triggerOrderWebhooks(rorder, model.WEBHOOK_EVENT_ORDER_REFUNDED)
+	} else {
+		a.triggerOrderWebhooks(rorder, model.WEBHOOK_EVENT_ORDER_UPDATED)
+	}
+
+	if oldOrder.Status != rorder.Status && rorder.Status == model.ORDER_STATUS_AWAITING_FULFILLMENT {
+		a.queueOrderTickets(rorder, model.PRINT_TICKET_REASON_ACCEPTED)
+	}
+
+	if !oldOrder.Payed && rorder.Payed {
+		a.publishOrderEvent(rorder, model.WEBSOCKET_EVENT_ORDER_PAID)
+		a.publishOrderTracking(rorder, model.ORDER_TRACKING_TYPE_PAYMENT)
+		a.queueOrderTickets(rorder, model.PRINT_TICKET_REASON_PAID)
+	} else {
+		a.publishOrderEvent(rorder, model.WEBSOCKET_EVENT_ORDER_UPDATED)
+		a.publishOrderTracking(rorder, model.ORDER_TRACKING_TYPE_STATUS)
+	}
+
 	rorder = a.PrepareOrderForClient(rorder, false)
 
 	a.UpdatePostWithOrder(rorder, false)
@@ -214,19 +288,7 @@ func (a *App) UpdateOrder(id string, patch *model.OrderPatch, safeUpdate bool) (
 }
 
 func (a *App) PrepareOrderForClient(originalOrder *model.Order, isNewOrder bool) *model.Order {
-	order := originalOrder.Clone()
-
-	basketList := a.GetBasketForOrder(order)
-	order.Positions = a.PrepareBasketListForClient(basketList, isNewOrder)
-	if post, err := a.FindPostWithOrder(order.Id); err == nil {
-		order.Post = post
-	}
-	if users, err := a.GetUsersByIds([]string{order.UserId}, true); err == nil {
-		a.SanitizeProfile(users[len(users)-1], false)
-		order.User = users[len(users)-1]
-	}
-
-	return order
+	return a.prepareOrdersForClient([]*model.Order{originalOrder}, isNewOrder, a.NewBatchLoader())[0]
 }
 
 func (a *App) PrepareOrderListForClient(originalList *model.OrderList) *model.OrderList {
@@ -234,17 +296,109 @@ func (a *App) PrepareOrderListForClient(originalList *model.OrderList) *model.Or
 		Orders: make(map[string]*model.Order, len(originalList.Orders)),
 		Order:  originalList.Order, // Note that this uses the original Order array, so it isn't a deep copy
 		Total:  originalList.Total,
+
+		NextCursor: originalList.NextCursor,
 	}
 
-	for id, originalOrder := range originalList.Orders {
-		order := a.PrepareOrderForClient(originalOrder, false)
+	orders := make([]*model.Order, 0, len(originalList.Orders))
+	for _, originalOrder := range originalList.Orders {
+		orders = append(orders, originalOrder)
+	}
 
-		list.Orders[id] = order
+	for _, order := range a.prepareOrdersForClient(orders, false, a.NewBatchLoader()) {
+		list.Orders[order.Id] = order
 	}
 
 	return list
 }
 
+// prepareOrdersForClient fills in the positions, the payments, the posts and the customers of
+// the orders, each loaded for all the orders at once.
+func (a *App) prepareOrdersForClient(originalOrders []*model.Order, isNewOrder bool, loader *BatchLoader) []*model.Order {
+	orders := make([]*model.Order, 0, len(originalOrders))
+	if len(originalOrders) == 0 {
+		return orders
+	}
+
+	orderIds := make([]string, 0, len(originalOrders))
+	userIds := make([]string, 0, len(originalOrders))
+	for _, order := range originalOrders {
+		orderIds = append(orderIds, order.Id)
+		userIds = append(userIds, order.UserId)
+	}
+
+	basketsChan := a.Srv.Store.Basket().GetByOrderIds(orderIds)
+	paymentsChan := a.Srv.Store.OrderPayment().GetForOrders(orderIds)
+	postsChan := a.Srv.Store.Post().FindPostsWithOrders(orderIds)
+
+	baskets := make(map[string][]*model.Basket, len(orderIds))
+	if result := <-basketsChan; result.Err != nil {
+		mlog.Warn("Failed to get the baskets of the orders", mlog.Err(result.Err))
+	} else {
+		for _, basket := range result.Data.([]*model.Basket) {
+			baskets[basket.OrderId] = append(baskets[basket.OrderId], basket)
+		}
+	}
+
+	var payments map[string][]*model.OrderPayment
+	if result := <-paymentsChan; result.Err != nil {
+		mlog.Warn("Failed to get the payments of the orders", mlog.Err(result.Err))
+	} else {
+		payments = make(map[string][]*model.OrderPayment, len(orderIds))
+		for _, payment := range result.Data.([]*model.OrderPayment) {
+			payments[payment.OrderId] = append(payments[payment.OrderId], payment)
+		}
+	}
+
+	posts := make(map[string]*model.Post, len(orderIds))
+	if result := <-postsChan; result.Err != nil {
+		mlog.Warn("Failed to get the posts of the orders", mlog.Err(result.Err))
+	} else {
+		for _, post := range result.Data.([]*model.Post) {
+			if orderId, ok := post.Props["order_id"].(string); ok {
+				posts[orderId] = post
+			}
+		}
+	}
+
+	users := make(map[string]*model.User, len(userIds))
+	if rusers, err := a.GetUsersByIds(userIds, true); err != nil {
+		mlog.Warn("Failed to get the customers of the orders", mlog.Err(err))
+	} else {
+		for _, user := range rusers {
+			a.SanitizeProfile(user, false)
+			users[user.Id] = user
+		}
+	}
+
+	productIds := []string{}
+	for _, orderBaskets := range baskets {
+		for _, basket := range orderBaskets {
+			productIds = append(productIds, basket.ProductId)
+		}
+	}
+	loader.Products(productIds)
+
+	for _, originalOrder := range originalOrders {
+		order := originalOrder.Clone()
+
+		order.Positions = a.prepareBasketListForClient(baskets[order.Id], order.UserId, isNewOrder, loader)
+		if payments != nil {
+			order.Payments = payments[order.Id]
+		}
+		if post, ok := posts[order.Id]; ok {
+			order.Post = post
+		}
+		if user, ok := users[order.UserId]; ok {
+			order.User = user
+		}
+
+		orders = append(orders, order)
+	}
+
+	return orders
+}
+
 func (a *App) DeleteOrder(orderId, deleteByID string) (*model.Order, *model.AppError) {
 	result := <-a.Srv.Store.Order().Get(orderId)
 	if result.Err != nil {
@@ -308,6 +462,16 @@ func (a *App) GetUserOrders(options *model.OrderGetOptions) (*model.OrderList, *
 	}
 }
 
+// GetOrdersByCursor returns the cursor page of the orders the options select.
+func (a *App) GetOrdersByCursor(options *model.OrderCursorOptions) (*model.OrderList, *model.AppError) {
+	result := <-a.Srv.Store.Order().GetPage(options)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return a.PrepareOrderListForClient(result.Data.(*model.OrderList)), nil
+}
+
 func (a *App) CalculateBonusForOrder(order *model.Order) *model.AppError {
 	var application *model.Application
 	order = a.PrepareOrderForClient(order, false)
@@ -345,27 +509,7 @@ func (a *App) CalculateBonusForOrder(order *model.Order) *model.AppError {
 	})
 
 	a.Srv.Go(func() {
-		if levels, err := a.GetAllLevelsPage(0, 60, &application.Id); err == nil {
-			levels.SortByLvl()
-			if user, _ := a.GetUser(order.User.InvitedBy); user != nil {
-				for _, id := range levels.Order {
-					value := math.Floor(price * (levels.Levels[id].Value / 100))
-					transaction := &model.Transaction{
-						UserId:      user.Id,
-						OrderId:     order.Id,
-						Description: fmt.Sprintf("Начисление по заказу друга \n"),
-						Value:       value,
-						Type:        model.TRANSACTION_TYPE_BONUS,
-					}
-					if transaction.Value > 0 {
-						a.AccrualTransaction(transaction)
-					}
-					if user, _ = a.GetUser(user.InvitedBy); user == nil {
-						break
-					}
-				}
-			}
-		}
+		a.payReferralRewards(order, application, price)
 	})
 
 	return nil
@@ -397,6 +541,9 @@ func (a *App) SetOrderShipped(orderId string) *model.AppError {
 		return result.Err
 	}
 	a.UpdatePostWithOrder(order, false)
+	a.publishOrderEvent(order, model.WEBSOCKET_EVENT_ORDER_UPDATED)
+	a.publishOrderTracking(order, model.ORDER_TRACKING_TYPE_STATUS)
+	a.createOrderAct(order)
 
 	return nil
 }
@@ -417,9 +564,23 @@ func (a *App) SetOrderPayed(orderId string, response *schema.OrderStatusResponse
 	if result := <-a.Srv.Store.Order().SetOrderPayed(order.Id); result.Err != nil {
 		return result.Err
 	} else {
+		if err := a.SetOrderCardMask(order.Id, response.CardAuthInfo.MaskedPan); err != nil {
+			mlog.Warn(err.Error())
+		}
+
+		if a.Metrics != nil && !order.Payed {
+			a.Metrics.IncrementOrderPaid(a.customerAppId(order.UserId))
+		}
 
 		a.UpdatePostWithOrder(order, false)
 
+		if result := <-a.Srv.Store.Order().Get(order.Id); result.Err == nil {
+			a.triggerOrderWebhooks(result.Data.(*model.Order), model.WEBHOOK_EVENT_ORDER_PAYED)
+			a.publishOrderEvent(result.Data.(*model.Order), model.WEBSOCKET_EVENT_ORDER_PAID)
+			a.publishOrderTracking(result.Data.(*model.Order), model.ORDER_TRACKING_TYPE_PAYMENT)
+			a.queueOrderTickets(result.Data.(*model.Order), model.PRINT_TICKET_REASON_PAID)
+		}
+
 		/*a.AccrualTransaction(&model.Transaction{
 			OrderId: order.Id,
 			UserId:  order.UserId,
@@ -456,13 +617,16 @@ func (a *App) SetOrderCancel(orderId string) *model.AppError {
 	if result := <-a.Srv.Store.Order().SetOrderCancel(order.Id); result.Err != nil {
 		return result.Err
 	} else {
+		if a.Metrics != nil {
+			a.Metrics.IncrementOrderCanceled(a.customerAppId(order.UserId))
+		}
 
-		if order.DiscountValue > 0 {
+		if bonuses := a.refundOrderBonuses(order); bonuses > 0 {
 			transaction := &model.Transaction{
 				UserId:      order.UserId,
 				OrderId:     order.Id,
 				Description: fmt.Sprintf("Возврат по заказу № %s \n", order.FormatOrderNumber()),
-				Value:       math.Floor(order.DiscountValue),
+				Value:       math.Floor(bonuses),
 			}
 
 			_, err := a.AccrualTransaction(transaction)
@@ -501,6 +665,12 @@ func (a *App) SetOrderCancel(orderId string) *model.AppError {
 
 		a.UpdatePostWithOrder(order, false)
 
+		if result := <-a.Srv.Store.Order().Get(order.Id); result.Err == nil {
+			a.triggerOrderWebhooks(result.Data.(*model.Order), model.WEBHOOK_EVENT_ORDER_CANCELED)
+			a.publishOrderEvent(result.Data.(*model.Order), model.WEBSOCKET_EVENT_ORDER_CANCELLED)
+			a.publishOrderTracking(result.Data.(*model.Order), model.ORDER_TRACKING_TYPE_STATUS)
+		}
+
 		return nil
 	}
 }
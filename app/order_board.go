@@ -0,0 +1,114 @@
+package app
+
+import (
+	"im/mlog"
+	"im/model"
+)
+
+// GetOrderBoard returns the open orders of the application grouped by status, oldest first.
+// With an office only the orders with products of that office are on the board.
+func (a *App) GetOrderBoard(appId string, officeId string) (*model.OrderBoard, *model.AppError) {
+	result := <-a.Srv.Store.Order().GetPage(&model.OrderCursorOptions{
+		AppId:    appId,
+		Statuses: model.ORDER_BOARD_STATUSES,
+		OfficeId: officeId,
+		Sort:     &model.CursorSort{Name: "create_at", Column: "CreateAt"},
+		Limit:    model.ORDER_BOARD_MAX_ORDERS,
+	})
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	items, err := a.newOrderBoardItems(result.Data.(*model.OrderList).ToSlice())
+	if err != nil {
+		return nil, err
+	}
+
+	board := model.NewOrderBoard()
+	for _, item := range items {
+		board.Add(item)
+	}
+
+	return board, nil
+}
+
+// newOrderBoardItems loads the positions and the offices of the orders at once.
+func (a *App) newOrderBoardItems(orders []*model.Order) ([]*model.OrderBoardItem, *model.AppError) {
+	orderIds := make([]string, 0, len(orders))
+	for _, order := range orders {
+		orderIds = append(orderIds, order.Id)
+	}
+
+	result := <-a.Srv.Store.Basket().GetByOrderIds(orderIds)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	baskets := make(map[string][]*model.Basket, len(orders))
+	var productIds []string
+	for _, basket := range result.Data.([]*model.Basket) {
+		baskets[basket.OrderId] = append(baskets[basket.OrderId], basket)
+		productIds = append(productIds, basket.ProductId)
+	}
+
+	var productOffices map[string][]*model.Office
+	if len(productIds) > 0 {
+		result = <-a.Srv.Store.ProductOffice().GetOfficesForProducts(productIds)
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		productOffices = result.Data.(map[string][]*model.Office)
+	}
+
+	items := make([]*model.OrderBoardItem, 0, len(orders))
+	for _, order := range orders {
+		var officeIds []string
+		seen := make(map[string]bool)
+		for _, basket := range baskets[order.Id] {
+			for _, office := range productOffices[basket.ProductId] {
+				if !seen[office.Id] {
+					seen[office.Id] = true
+					officeIds = append(officeIds, office.Id)
+				}
+			}
+		}
+
+		items = append(items, model.NewOrderBoardItem(order, baskets[order.Id], officeIds))
+	}
+
+	return items, nil
+}
+
+// publishOrderEvent tells the staff of the application who may view its orders, and work at
+// one of the offices of the order, that the order changed.
+func (a *App) publishOrderEvent(order *model.Order, event string) {
+	order = order.Clone()
+
+	a.Srv.Go(func() {
+		customer, err := a.GetUser(order.UserId)
+		if err != nil {
+			mlog.Warn("Failed to get the customer of the order for the order event", mlog.String("order_id", order.Id), mlog.Err(err))
+			return
+		}
+
+		team, err := a.GetApplicationTeam(customer.AppId)
+		if err != nil {
+			mlog.Warn("Failed to get the team of the application for the order event", mlog.String("order_id", order.Id), mlog.Err(err))
+			return
+		}
+
+		items, err := a.newOrderBoardItems([]*model.Order{order})
+		if err != nil {
+			mlog.Warn("Failed to prepare the order for the order event", mlog.String("order_id", order.Id), mlog.Err(err))
+			return
+		}
+		item := items[0]
+
+		message := model.NewWebSocketEvent(event, team.Id, "", "", nil)
+		message.Broadcast.Permission = model.PERMISSION_VIEW_ORDERS.Id
+		message.Broadcast.OfficeIds = item.OfficeIds
+		message.Add("order", item.ToJson())
+
+		a.Publish(message)
+	})
+}
@@ -0,0 +1,168 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"im/mlog"
+	"im/model"
+	"im/services/invoice"
+	"im/utils/fileutils"
+)
+
+const (
+	ORDER_DOCUMENT_FONT = "nunito-bold.ttf"
+)
+
+// buildOrderDocument lays out the lines of the document from the positions of the order, an
+// order without positions is invoiced as one line for the amount due.
+func (a *App) buildOrderDocument(order *model.Order, application *model.Application, docType string, buyer *model.LegalEntity, date time.Time) *invoice.Document {
+	doc := &invoice.Document{
+		Type:           docType,
+		Number:         order.FormatOrderNumber(),
+		Date:           date,
+		Seller:         model.ApplicationSeller(application),
+		SellerContacts: application.ContactDetails,
+		Buyer:          buyer,
+	}
+
+	if doc.Seller == nil {
+		doc.SellerDetails = application.PaymentDetails
+	}
+
+	for _, item := range orderReceiptItems(order) {
+		doc.Lines = append(doc.Lines, &invoice.Line{
+			Name:     item.Name,
+			Measure:  item.Measure,
+			Quantity: item.Quantity,
+			Price:    item.Price,
+			Amount:   item.Amount,
+			VatRate:  item.VatRate,
+		})
+	}
+
+	if len(doc.Lines) == 0 {
+		amount := int64(math.Round(order.AmountDue() * 100))
+		doc.Lines = append(doc.Lines, &invoice.Line{
+			Name:     "Оплата по заказу № " + doc.Number,
+			Measure:  model.RECEIPT_DEFAULT_MEASURE,
+			Quantity: 1,
+			Price:    amount,
+			Amount:   amount,
+			VatRate:  model.VAT_RATE_NONE,
+		})
+	}
+
+	return doc
+}
+
+// createOrderDocument renders the document of the order as a PDF, keeps it with the file
+// backend and posts it to the channel of the customer with the post.
+func (a *App) createOrderDocument(order *model.Order, docType string, buyer *model.LegalEntity, basis string, post *model.Post) (*model.OrderDocument, *model.AppError) {
+	customer, err := a.GetUser(order.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	application, err := a.GetApplication(customer.AppId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	doc := a.buildOrderDocument(a.PrepareOrderForClient(order, false), application, docType, buyer, now)
+	doc.Basis = basis
+
+	fontsDir, _ := fileutils.FindDir("fonts")
+	content, renderErr := invoice.RenderPdf(doc, filepath.Join(fontsDir, ORDER_DOCUMENT_FONT))
+	if renderErr != nil {
+		return nil, model.NewAppError("createOrderDocument", "app.order_document.render.app_error", nil, "order_id="+order.Id+", "+renderErr.Error(), http.StatusInternalServerError)
+	}
+
+	filename := fmt.Sprintf("%s_%s.pdf", docType, doc.Number)
+	info, err := a.DoUploadFile(now, "", post.UserId, filename, content)
+	if err != nil {
+		return nil, err
+	}
+
+	post.FileIds = model.StringArray{info.Id}
+	rpost, err := a.CreatePostWithOrder(post, order, false)
+	if err != nil {
+		return nil, err
+	}
+
+	document := &model.OrderDocument{
+		AppId:     application.Id,
+		OrderId:   order.Id,
+		Type:      docType,
+		Number:    doc.Number,
+		Buyer:     buyer,
+		Total:     float64(doc.Total()) / 100,
+		VatTotal:  float64(doc.VatTotal()) / 100,
+		FileId:    info.Id,
+		PostId:    rpost.Id,
+		CreatorId: post.UserId,
+	}
+
+	result := <-a.Srv.Store.OrderDocument().Save(document)
+	if result.Err != nil {
+		if _, err := a.DeletePost(rpost.Id, post.UserId); err != nil {
+			mlog.Warn("Failed to delete the post of the unsaved document", mlog.String("post_id", rpost.Id), mlog.Err(err))
+		}
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.OrderDocument), nil
+}
+
+// createOrderAct makes the act of an invoiced order once it shipped, for the buyer of the
+// invoice. The orders without an invoice or with an act already are left alone.
+func (a *App) createOrderAct(order *model.Order) {
+	order = order.Clone()
+
+	a.Srv.Go(func() {
+		documents, err := a.GetOrderDocuments(order.Id)
+		if err != nil {
+			mlog.Warn("Failed to get the documents of the order", mlog.String("order_id", order.Id), mlog.Err(err))
+			return
+		}
+
+		var invoiceDocument *model.OrderDocument
+		for _, document := range documents {
+			if document.Type == model.ORDER_DOCUMENT_TYPE_ACT {
+				return
+			}
+			if document.Type == model.ORDER_DOCUMENT_TYPE_INVOICE {
+				invoiceDocument = document
+			}
+		}
+
+		if invoiceDocument == nil {
+			return
+		}
+
+		basis := fmt.Sprintf("Счет на оплату № %s от %s", invoiceDocument.Number, invoice.FormatDate(time.Unix(0, invoiceDocument.CreateAt*int64(time.Millisecond))))
+		post := &model.Post{
+			UserId:   invoiceDocument.CreatorId,
+			Message:  fmt.Sprintf("Акт № %s \n", invoiceDocument.Number),
+			CreateAt: model.GetMillis() + 1,
+			Type:     model.POST_WITH_INVOICE,
+		}
+
+		if _, err := a.createOrderDocument(order, model.ORDER_DOCUMENT_TYPE_ACT, invoiceDocument.Buyer, basis, post); err != nil {
+			mlog.Error("Failed to create the act of the order", mlog.String("order_id", order.Id), mlog.Err(err))
+		}
+	})
+}
+
+func (a *App) GetOrderDocuments(orderId string) ([]*model.OrderDocument, *model.AppError) {
+	result := <-a.Srv.Store.OrderDocument().GetForOrder(orderId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.([]*model.OrderDocument), nil
+}
@@ -0,0 +1,261 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+
+	"im/mlog"
+	"im/model"
+	"im/services/payment"
+)
+
+func (a *App) GetOrderPayments(orderId string) ([]*model.OrderPayment, *model.AppError) {
+	result := <-a.Srv.Store.OrderPayment().GetForOrder(orderId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.([]*model.OrderPayment), nil
+}
+
+// AddOrderPayment records the tender the order was paid with and marks the order as paid once
+// the tenders cover it. A card payment the acquirer reported before is recorded only once.
+func (a *App) AddOrderPayment(order *model.Order, orderPayment *model.OrderPayment) (*model.OrderPayment, *model.AppError) {
+	if order.Canceled {
+		return nil, model.NewAppError("AddOrderPayment", "app.order_payment.add.canceled.app_error", nil, "order_id="+order.Id, http.StatusBadRequest)
+	}
+
+	payments, err := a.GetOrderPayments(order.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(orderPayment.Reference) > 0 {
+		for _, p := range payments {
+			if p.Tender == orderPayment.Tender && p.Reference == orderPayment.Reference {
+				return p, nil
+			}
+		}
+	}
+
+	orderPayment.OrderId = order.Id
+
+	result := <-a.Srv.Store.OrderPayment().Save(orderPayment)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	orderPayment = result.Data.(*model.OrderPayment)
+
+	if orderPayment.Tender != model.ORDER_PAYMENT_TENDER_BONUS {
+		a.setOrderPayedIfCovered(order, append(payments, orderPayment))
+	}
+
+	return orderPayment, nil
+}
+
+// AddInPersonOrderPayment records the cash or the card terminal payment the cashier took. The
+// change is given by the cashier, so the payment may not exceed what is left to pay.
+func (a *App) AddInPersonOrderPayment(order *model.Order, request *model.OrderPaymentRequest, creatorId string) (*model.OrderPayment, *model.AppError) {
+	if !model.IsInPersonOrderPaymentTender(request.Tender) {
+		return nil, model.NewAppError("AddInPersonOrderPayment", "app.order_payment.add.tender.app_error", nil, "tender="+request.Tender, http.StatusBadRequest)
+	}
+
+	payments, err := a.GetOrderPayments(order.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	paid := order.Clone()
+	paid.Payments = payments
+
+	if outstanding := math.Round((paid.AmountDue()-paid.AmountPaid())*100) / 100; request.Amount > outstanding {
+		return nil, model.NewAppError("AddInPersonOrderPayment", "app.order_payment.add.amount.app_error", nil, fmt.Sprintf("order_id=%s, outstanding=%.2f", order.Id, outstanding), http.StatusBadRequest)
+	}
+
+	return a.AddOrderPayment(order, &model.OrderPayment{
+		Tender:    request.Tender,
+		Amount:    request.Amount,
+		Reference: request.Reference,
+		CreatorId: creatorId,
+	})
+}
+
+func (a *App) setOrderPayedIfCovered(order *model.Order, payments []*model.OrderPayment) {
+	covered := order.Clone()
+	covered.Payments = payments
+
+	if order.Payed || !covered.IsCovered() {
+		return
+	}
+
+	if result := <-a.Srv.Store.Order().SetOrderPayed(order.Id); result.Err != nil {
+		mlog.Error("Failed to mark the order as paid", mlog.String("order_id", order.Id), mlog.Err(result.Err))
+		return
+	}
+
+	if a.Metrics != nil {
+		a.Metrics.IncrementOrderPaid(a.customerAppId(order.UserId))
+	}
+
+	if result := <-a.Srv.Store.Order().Get(order.Id); result.Err == nil {
+		rorder := result.Data.(*model.Order)
+		a.triggerOrderWebhooks(rorder, model.WEBHOOK_EVENT_ORDER_PAYED)
+		a.publishOrderEvent(rorder, model.WEBSOCKET_EVENT_ORDER_PAID)
+		a.publishOrderTracking(rorder, model.ORDER_TRACKING_TYPE_PAYMENT)
+		a.queueOrderTickets(rorder, model.PRINT_TICKET_REASON_PAID)
+		a.UpdatePostWithOrder(a.PrepareOrderForClient(rorder, false), false)
+	}
+}
+
+// RefundOrder returns the amount to the tenders the order was paid with, the latest money
+// tenders first and the bonuses last. The order is refunded once nothing is left on its tenders.
+func (a *App) RefundOrder(order *model.Order, amount float64) ([]*model.OrderPayment, *model.AppError) {
+	payments, err := a.GetOrderPayments(order.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	var refundable float64
+	for _, p := range payments {
+		refundable += p.Remaining()
+	}
+
+	amount = math.Round(amount*100) / 100
+	if amount <= 0 || amount > math.Round(refundable*100)/100 {
+		return nil, model.NewAppError("RefundOrder", "app.order_payment.refund.amount.app_error", nil, fmt.Sprintf("order_id=%s, refundable=%.2f", order.Id, refundable), http.StatusBadRequest)
+	}
+
+	allocation := make([]*model.OrderPayment, 0, len(payments))
+	for i := len(payments) - 1; i >= 0; i-- {
+		if payments[i].Tender != model.ORDER_PAYMENT_TENDER_BONUS {
+			allocation = append(allocation, payments[i])
+		}
+	}
+	for _, p := range payments {
+		if p.Tender == model.ORDER_PAYMENT_TENDER_BONUS {
+			allocation = append(allocation, p)
+		}
+	}
+
+	left := amount
+	for _, p := range allocation {
+		if left <= 0 {
+			break
+		}
+
+		share := math.Min(left, p.Remaining())
+		if share <= 0 {
+			continue
+		}
+
+		refunded, err := a.refundOrderPayment(order, p, share)
+		if err != nil {
+			return payments, err
+		}
+
+		left = math.Round((left-refunded)*100) / 100
+	}
+
+	var remaining float64
+	for _, p := range payments {
+		remaining += p.Remaining()
+	}
+
+	if remaining <= 0 {
+		if _, err := a.UpdateOrder(order.Id, &model.OrderPatch{Status: model.NewString(model.ORDER_STATUS_REFUNDED)}, false); err != nil {
+			return payments, err
+		}
+	}
+
+	return payments, nil
+}
+
+// refundOrderPayment returns the share to the tender: the card payments through the acquirer,
+// the bonuses to the account of the customer. Cash and terminal refunds are handed out by the
+// cashier and only recorded. It returns what was refunded, the bonuses are credited in whole
+// bonuses only.
+func (a *App) refundOrderPayment(order *model.Order, orderPayment *model.OrderPayment, share float64) (float64, *model.AppError) {
+	if orderPayment.Tender == model.ORDER_PAYMENT_TENDER_BONUS {
+		share = math.Floor(share)
+		if share <= 0 {
+			return 0, nil
+		}
+	}
+
+	switch orderPayment.Tender {
+	case model.ORDER_PAYMENT_TENDER_CARD:
+		user, err := a.GetUser(order.UserId)
+		if err != nil {
+			return 0, err
+		}
+
+		application, err := a.GetApplication(user.AppId)
+		if err != nil {
+			return 0, err
+		}
+
+		switch orderPayment.PaySystemId {
+		case model.SBERBANK_AQUIRING_TYPE:
+			sber := payment.SberBankBackend{Metrics: a.Metrics}
+			err = sber.RefundPayment(orderPayment.Reference, share, a.sberbankClientConfig(application))
+		case model.ALFABANK_AQUIRING_TYPE:
+			alfa := payment.AlfaBankBackend{Metrics: a.Metrics}
+			err = alfa.RefundPayment(orderPayment.Reference, share, a.alfabankClientConfig(application))
+		default:
+			err = model.NewAppError("refundOrderPayment", "app.order_payment.refund.pay_system.app_error", nil, "id="+orderPayment.Id+", pay_system_id="+orderPayment.PaySystemId, http.StatusBadRequest)
+		}
+
+		if err != nil {
+			return 0, err
+		}
+	case model.ORDER_PAYMENT_TENDER_BONUS:
+		if _, err := a.AccrualTransaction(&model.Transaction{
+			UserId:      order.UserId,
+			OrderId:     order.Id,
+			Description: fmt.Sprintf("Возврат по заказу № %s \n", order.FormatOrderNumber()),
+			Value:       share,
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	orderPayment.RefundedAmount = math.Round((orderPayment.RefundedAmount+share)*100) / 100
+	if orderPayment.Remaining() <= 0 {
+		orderPayment.Status = model.ORDER_PAYMENT_STATUS_REFUNDED
+	}
+
+	if result := <-a.Srv.Store.OrderPayment().Update(orderPayment); result.Err != nil {
+		return 0, result.Err
+	}
+
+	return share, nil
+}
+
+// refundOrderBonuses returns the bonuses of the canceled order that were not refunded yet,
+// orders placed before the tenders were recorded give back their whole discount.
+func (a *App) refundOrderBonuses(order *model.Order) float64 {
+	payments, err := a.GetOrderPayments(order.Id)
+	if err != nil {
+		mlog.Warn("Failed to get the payments of the order", mlog.String("order_id", order.Id), mlog.Err(err))
+		return order.DiscountValue
+	}
+
+	for _, p := range payments {
+		if p.Tender != model.ORDER_PAYMENT_TENDER_BONUS {
+			continue
+		}
+
+		bonuses := p.Remaining()
+
+		p.RefundedAmount = p.Amount
+		p.Status = model.ORDER_PAYMENT_STATUS_REFUNDED
+		if result := <-a.Srv.Store.OrderPayment().Update(p); result.Err != nil {
+			mlog.Warn("Failed to refund the bonus payment of the order", mlog.String("order_id", order.Id), mlog.Err(result.Err))
+		}
+
+		return bonuses
+	}
+
+	return order.DiscountValue
+}
@@ -0,0 +1,166 @@
+package app
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"im/mlog"
+	"im/model"
+)
+
+// OrderTrackingWaiters wakes the long polls waiting for the tracking events of the orders. The
+// events reach it from Publish on this node and through the cluster from the others.
+type OrderTrackingWaiters struct {
+	mutex   sync.Mutex
+	waiters map[string]map[chan *model.OrderTracking]bool
+}
+
+func NewOrderTrackingWaiters() *OrderTrackingWaiters {
+	return &OrderTrackingWaiters{
+		waiters: make(map[string]map[chan *model.OrderTracking]bool),
+	}
+}
+
+func (w *OrderTrackingWaiters) add(orderIds []string, ch chan *model.OrderTracking) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for _, orderId := range orderIds {
+		if w.waiters[orderId] == nil {
+			w.waiters[orderId] = make(map[chan *model.OrderTracking]bool)
+		}
+		w.waiters[orderId][ch] = true
+	}
+}
+
+func (w *OrderTrackingWaiters) remove(orderIds []string, ch chan *model.OrderTracking) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for _, orderId := range orderIds {
+		delete(w.waiters[orderId], ch)
+		if len(w.waiters[orderId]) == 0 {
+			delete(w.waiters, orderId)
+		}
+	}
+}
+
+func (w *OrderTrackingWaiters) notify(tracking *model.OrderTracking) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for ch := range w.waiters[tracking.OrderId] {
+		select {
+		case ch <- tracking:
+		default:
+		}
+	}
+}
+
+// GetOrderTracking describes the order for its customer.
+func (a *App) GetOrderTracking(order *model.Order, trackingType string) *model.OrderTracking {
+	var courier *model.User
+	var location *model.CourierLocation
+
+	if len(order.CourierId) > 0 {
+		var err *model.AppError
+		if courier, err = a.GetUser(order.CourierId); err != nil {
+			mlog.Warn("Failed to get the courier of the order", mlog.String("order_id", order.Id), mlog.Err(err))
+			courier = nil
+		}
+
+		if order.IsOutForDelivery() {
+			if location, err = a.GetCourierLocation(order.CourierId); err != nil {
+				location = nil
+			}
+		}
+	}
+
+	return model.NewOrderTracking(order, courier, location, trackingType)
+}
+
+// GetUserOrderTrackings returns the tracking of the orders, which must all be orders of the user.
+func (a *App) GetUserOrderTrackings(userId string, orderIds []string) ([]*model.OrderTracking, *model.AppError) {
+	if len(orderIds) == 0 || len(orderIds) > model.ORDER_TRACKING_MAX_ORDERS {
+		return nil, model.NewAppError("GetUserOrderTrackings", "app.order_tracking.order_ids.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	trackings := make([]*model.OrderTracking, 0, len(orderIds))
+	for _, orderId := range orderIds {
+		order, err := a.GetOrder(orderId)
+		if err != nil {
+			return nil, err
+		}
+
+		if order.UserId != userId {
+			return nil, model.NewAppError("GetUserOrderTrackings", "app.order_tracking.other_user.app_error", nil, "order_id="+orderId, http.StatusForbidden)
+		}
+
+		trackings = append(trackings, a.GetOrderTracking(order, model.ORDER_TRACKING_TYPE_STATUS))
+	}
+
+	return trackings, nil
+}
+
+// WaitForOrderTrackings is the long poll for the clients without a websocket. It returns the
+// orders that changed after since at once, otherwise the first tracking event of the orders,
+// or nothing when the timeout passes.
+func (a *App) WaitForOrderTrackings(userId string, orderIds []string, since int64, timeout time.Duration) ([]*model.OrderTracking, *model.AppError) {
+	// Listen before reading the orders, so an event in between is not lost.
+	ch := make(chan *model.OrderTracking, len(orderIds))
+	a.Srv.orderTrackingWaiters.add(orderIds, ch)
+	defer a.Srv.orderTrackingWaiters.remove(orderIds, ch)
+
+	trackings, err := a.GetUserOrderTrackings(userId, orderIds)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := []*model.OrderTracking{}
+	for _, tracking := range trackings {
+		if tracking.UpdateAt > since {
+			changed = append(changed, tracking)
+		}
+	}
+
+	if len(changed) > 0 {
+		return changed, nil
+	}
+
+	select {
+	case tracking := <-ch:
+		return []*model.OrderTracking{tracking}, nil
+	case <-time.After(timeout):
+		return changed, nil
+	}
+}
+
+// publishOrderTracking sends the tracking event to the connections of the customer following
+// the order and wakes the long polls.
+func (a *App) publishOrderTracking(order *model.Order, trackingType string) {
+	order = order.Clone()
+
+	a.Srv.Go(func() {
+		tracking := a.GetOrderTracking(order, trackingType)
+
+		message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_ORDER_TRACKING, "", "", order.UserId, nil)
+		message.Broadcast.OrderId = order.Id
+		message.Add("tracking", tracking.ToJson())
+
+		a.Publish(message)
+	})
+}
+
+// notifyOrderTrackingWaiters hands the tracking event published on any node to the long polls.
+func (a *App) notifyOrderTrackingWaiters(message *model.WebSocketEvent) {
+	data, ok := message.Data["tracking"].(string)
+	if !ok {
+		return
+	}
+
+	if tracking := model.OrderTrackingFromJson(strings.NewReader(data)); tracking != nil {
+		a.Srv.orderTrackingWaiters.notify(tracking)
+	}
+}
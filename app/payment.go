@@ -0,0 +1,60 @@
+package app
+
+import (
+	"strings"
+
+	"im/model"
+	"im/services/payment"
+	"im/services/payment/alfabank"
+	"im/services/payment/sberbank"
+	"im/services/payment/sberbank/currency"
+)
+
+func (a *App) sberbankClientConfig(application *model.Application) sberbank.ClientConfig {
+	return sberbank.ClientConfig{
+		UserName:           application.AqUsername,
+		Password:           application.AqPassword,
+		Currency:           currency.RUB,
+		Language:           "ru",
+		SessionTimeoutSecs: 1200,
+		SandboxMode:        *a.Config().ServiceSettings.EnableDeveloper,
+		SandboxEndpoint:    a.PaymentSandboxEndpoint(),
+		SiteURL:            *a.Config().ServiceSettings.SiteURL,
+	}
+}
+
+func (a *App) alfabankClientConfig(application *model.Application) alfabank.ClientConfig {
+	return alfabank.ClientConfig{
+		UserName:           application.AqUsername,
+		Password:           application.AqPassword,
+		Currency:           currency.RUB,
+		Language:           "ru",
+		SessionTimeoutSecs: 1200,
+		SandboxMode:        *a.Config().ServiceSettings.EnableDeveloper,
+		SandboxEndpoint:    a.PaymentSandboxEndpoint(),
+		SiteURL:            *a.Config().ServiceSettings.SiteURL,
+	}
+}
+
+// PaymentSandboxEndpoint is where the acquirer clients go in the sandbox mode instead of the test
+// hosts of the banks, the mock acquirer of the server when it is on.
+func (a *App) PaymentSandboxEndpoint() string {
+	if !a.IsMockAcquirerEnabled() {
+		return ""
+	}
+
+	siteURL := strings.TrimRight(*a.Config().ServiceSettings.SiteURL, "/")
+	if len(siteURL) == 0 {
+		siteURL = "http://localhost" + *a.Config().ServiceSettings.ListenAddress
+	}
+
+	return siteURL + model.API_URL_SUFFIX + "/mock/acquirer"
+}
+
+func (a *App) IsMockAcquirerEnabled() bool {
+	return *a.Config().ServiceSettings.EnableDeveloper && *a.Config().ServiceSettings.EnableMockAcquirer
+}
+
+func (a *App) MockAcquirer() *payment.MockAcquirer {
+	return a.Srv.mockAcquirer
+}
@@ -19,6 +19,7 @@ const (
 	MIGRATION_KEY_ADD_BOT_PERMISSIONS                         = "add_bot_permissions"
 	MIGRATION_KEY_APPLY_CHANNEL_MANAGE_DELETE_TO_CHANNEL_USER = "apply_channel_manage_delete_to_channel_user"
 	MIGRATION_KEY_REMOVE_CHANNEL_MANAGE_DELETE_FROM_TEAM_USER = "remove_channel_manage_delete_from_team_user"
+	MIGRATION_KEY_ADD_APPLICATION_STAFF_PERMISSIONS           = "add_application_staff_permissions"
 
 	PERMISSION_MANAGE_SYSTEM                     = "manage_system"
 	PERMISSION_MANAGE_EMOJIS                     = "manage_emojis"
@@ -255,6 +256,28 @@ func removeChannelManageDeleteFromTeamUser() permissionsMap {
 	}
 }
 
+func getAddApplicationStaffPermissionsMigration() permissionsMap {
+	staffPermissions := []string{
+		model.PERMISSION_MANAGE_STAFF.Id,
+		model.PERMISSION_VIEW_ORDERS.Id,
+		model.PERMISSION_VIEW_ORDER_CUSTOMER.Id,
+		model.PERMISSION_MANAGE_ORDERS.Id,
+		model.PERMISSION_EDIT_ORDER_PAYMENT.Id,
+	}
+
+	return permissionsMap{
+		permissionTransformation{
+			On: permissionOr(
+				isRole(model.TEAM_ADMIN_ROLE_ID),
+				isRole(model.SYSTEM_ADMIN_ROLE_ID),
+				isRole(model.SYSTEM_MODERATOR_ROLE_ID),
+				isRole(model.SYSTEM_DIRECTOR_ROLE_ID),
+			),
+			Add: staffPermissions,
+		},
+	}
+}
+
 // DoPermissionsMigrations execute all the permissions migrations need by the current version.
 func (a *App) DoPermissionsMigrations() *model.AppError {
 	PermissionsMigrations := []struct {
@@ -268,6 +291,7 @@ func (a *App) DoPermissionsMigrations() *model.AppError {
 		{Key: MIGRATION_KEY_ADD_BOT_PERMISSIONS, Migration: getAddBotPermissionsMigration},
 		{Key: MIGRATION_KEY_APPLY_CHANNEL_MANAGE_DELETE_TO_CHANNEL_USER, Migration: applyChannelManageDeleteToChannelUser},
 		{Key: MIGRATION_KEY_REMOVE_CHANNEL_MANAGE_DELETE_FROM_TEAM_USER, Migration: removeChannelManageDeleteFromTeamUser},
+		{Key: MIGRATION_KEY_ADD_APPLICATION_STAFF_PERMISSIONS, Migration: getAddApplicationStaffPermissionsMigration},
 	}
 
 	for _, migration := range PermissionsMigrations {
@@ -0,0 +1,254 @@
+package app
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"im/mlog"
+	"im/model"
+	"im/services/pos"
+	"im/store"
+)
+
+const (
+	POS_API_KEY_LENGTH = 32
+)
+
+func (a *App) GetPosProductMappings(appId string) ([]*model.PosProductMapping, *model.AppError) {
+	result := <-a.Srv.Store.PosProductMapping().GetForApp(appId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.([]*model.PosProductMapping), nil
+}
+
+func (a *App) SavePosProductMapping(mapping *model.PosProductMapping) (*model.PosProductMapping, *model.AppError) {
+	product, err := a.GetProduct(mapping.ProductId)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.AppId != mapping.AppId {
+		return nil, model.NewAppError("SavePosProductMapping", "app.pos.save_mapping.other_app.app_error", nil, "product_id="+mapping.ProductId, http.StatusBadRequest)
+	}
+
+	result := <-a.Srv.Store.PosProductMapping().Save(mapping)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.PosProductMapping), nil
+}
+
+func (a *App) DeletePosProductMapping(appId, productId string) *model.AppError {
+	if result := <-a.Srv.Store.PosProductMapping().Delete(appId, productId); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func (a *App) GetPosOrderSync(orderId string) (*model.PosOrderSync, *model.AppError) {
+	result := <-a.Srv.Store.PosOrderSync().Get(orderId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.PosOrderSync), nil
+}
+
+func (a *App) GetPosOrderSyncs(appId, status string, page, perPage int) ([]*model.PosOrderSync, *model.AppError) {
+	result := <-a.Srv.Store.PosOrderSync().GetForApp(appId, status, page*perPage, perPage)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.([]*model.PosOrderSync), nil
+}
+
+// BuildPosOrder converts the order to the POS format. Every position has to be mapped
+// to a product of the POS menu, otherwise the kitchen would not know what to cook.
+func (a *App) BuildPosOrder(order *model.Order, customer *model.User) (*model.PosOrder, *model.AppError) {
+	positions := a.GetBasketForOrder(order)
+
+	productIds := make([]string, 0, len(positions))
+	for _, position := range positions {
+		productIds = append(productIds, position.ProductId)
+	}
+
+	result := <-a.Srv.Store.PosProductMapping().GetForProducts(customer.AppId, productIds)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	externalIds := make(map[string]string)
+	for _, mapping := range result.Data.([]*model.PosProductMapping) {
+		externalIds[mapping.ProductId] = mapping.ExternalId
+	}
+
+	posOrder := &model.PosOrder{
+		Id:            order.Id,
+		Number:        order.FormatOrderNumber(),
+		Phone:         order.Phone,
+		CustomerName:  customer.GetFullName(),
+		Address:       order.Address,
+		Latitude:      order.Latitude,
+		Longitude:     order.Longitude,
+		Comment:       order.Comment,
+		PaymentType:   order.PaySystemId,
+		Payed:         order.Payed,
+		Price:         order.Price,
+		PriceDelivery: order.PriceDelivery,
+		DiscountValue: order.DiscountValue,
+		Currency:      order.Currency,
+		DeliveryAt:    order.DeliveryAt,
+		CreateAt:      order.CreateAt,
+	}
+
+	for _, position := range positions {
+		externalId, ok := externalIds[position.ProductId]
+		if !ok {
+			return nil, model.NewAppError("BuildPosOrder", "app.pos.build_order.not_mapped.app_error", map[string]interface{}{"Name": position.Name}, "product_id="+position.ProductId, http.StatusBadRequest)
+		}
+
+		posOrder.Items = append(posOrder.Items, &model.PosOrderItem{
+			ExternalId: externalId,
+			ProductId:  position.ProductId,
+			Name:       position.Name,
+			Quantity:   position.Quantity,
+			Price:      position.Price,
+			Amount:     position.Price * float64(position.Quantity),
+		})
+	}
+
+	return posOrder, nil
+}
+
+// ExportOrderToPos sends the order to the POS of the application and stores the result
+// of the attempt. Nothing is done when the application has no POS.
+func (a *App) ExportOrderToPos(order *model.Order) (*model.PosOrderSync, *model.AppError) {
+	customer, err := a.GetUser(order.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	application, err := a.GetApplication(customer.AppId)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, perr := pos.NewProvider(application, a.HTTPService)
+	if perr == pos.ErrNotConfigured {
+		return nil, nil
+	} else if perr != nil {
+		return nil, model.NewAppError("ExportOrderToPos", "app.pos.export_order.provider.app_error", nil, perr.Error(), http.StatusInternalServerError)
+	}
+
+	sync, err := a.GetPosOrderSync(order.Id)
+	if err != nil {
+		sync = &model.PosOrderSync{
+			OrderId: order.Id,
+			AppId:   application.Id,
+		}
+	}
+	sync.Provider = application.PosType
+
+	if posOrder, err := a.BuildPosOrder(order, customer); err != nil {
+		sync.SetFailed(err.Error())
+	} else if exported, err := provider.ExportOrder(posOrder); err != nil {
+		sync.SetFailed(err.Error())
+	} else {
+		sync.SetSynced(exported.ExternalId, exported.Status)
+	}
+
+	result := <-a.Srv.Store.PosOrderSync().Save(sync)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	if sync.Status == model.POS_SYNC_STATUS_FAILED {
+		mlog.Warn("Failed to export order to POS", mlog.String("order_id", order.Id), mlog.String("error", sync.LastError))
+	}
+
+	return result.Data.(*model.PosOrderSync), nil
+}
+
+func (a *App) exportOrderToPos(order *model.Order) {
+	a.Srv.Go(func() {
+		if _, err := a.ExportOrderToPos(order); err != nil {
+			mlog.Error("Failed to export order to POS", mlog.String("order_id", order.Id), mlog.Err(err))
+		}
+	})
+}
+
+// IsValidPosApiKey checks the key the POS sends with the status feedback.
+func (a *App) IsValidPosApiKey(application *model.Application, apiKey string) bool {
+	if len(application.PosType) == 0 || len(application.PosApiKey) == 0 {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(application.PosApiKey), []byte(apiKey)) == 1
+}
+
+// RegeneratePosApiKey gives the application a new key for its POS, the old one stops working.
+func (a *App) RegeneratePosApiKey(appId string) (string, *model.AppError) {
+	result := <-a.Srv.Store.Application().Get(appId)
+	if result.Err != nil {
+		return "", result.Err
+	}
+	application := result.Data.(*model.Application).Clone()
+
+	application.PosApiKey = model.NewRandomString(POS_API_KEY_LENGTH)
+
+	if result := <-a.Srv.Store.Application().Update(application); result.Err != nil {
+		return "", result.Err
+	}
+
+	return application.PosApiKey, nil
+}
+
+// UpdateOrderFromPos applies the status feedback of the POS to the order through UpdateOrder.
+func (a *App) UpdateOrderFromPos(appId string, status *model.PosOrderStatus) (*model.Order, *model.AppError) {
+	var result store.StoreResult
+	if len(status.ExternalId) > 0 {
+		result = <-a.Srv.Store.PosOrderSync().GetByExternalId(appId, status.ExternalId)
+	} else {
+		result = <-a.Srv.Store.PosOrderSync().Get(status.OrderId)
+	}
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	sync := result.Data.(*model.PosOrderSync)
+
+	if sync.AppId != appId {
+		return nil, model.NewAppError("UpdateOrderFromPos", "app.pos.update_order.other_app.app_error", nil, "order_id="+sync.OrderId, http.StatusNotFound)
+	}
+
+	orderStatus, ok := model.PosStatusToOrderStatus(status.Status)
+	if !ok {
+		return nil, model.NewAppError("UpdateOrderFromPos", "app.pos.update_order.status.app_error", nil, "status="+status.Status, http.StatusBadRequest)
+	}
+
+	sync.ExternalStatus = status.Status
+	if result := <-a.Srv.Store.PosOrderSync().Save(sync); result.Err != nil {
+		return nil, result.Err
+	}
+
+	order, err := a.GetOrder(sync.OrderId)
+	if err != nil {
+		return nil, err
+	}
+
+	// the order is already closed on our side, a late status from the POS does not reopen it
+	switch order.Status {
+	case model.ORDER_STATUS_SHIPPED, model.ORDER_STATUS_DECLINED, model.ORDER_STATUS_REFUNDED:
+		return order, nil
+	}
+
+	if order.Status == orderStatus {
+		return order, nil
+	}
+
+	return a.UpdateOrder(order.Id, &model.OrderPatch{Status: &orderStatus}, false)
+}
@@ -0,0 +1,288 @@
+package app
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"im/mlog"
+	"im/model"
+	"im/services/ticketprinter"
+	"im/utils/fileutils"
+)
+
+const (
+	PRINT_AGENT_KEY_LENGTH = 32
+	PRINT_TICKET_FONT      = "nunito-bold.ttf"
+	PRINT_TICKET_ERROR_MAX = 500
+)
+
+// orderPositionsByOffice splits the positions of the order between the offices that make
+// their products.
+func (a *App) orderPositionsByOffice(orderId string) (map[string][]*model.Basket, *model.AppError) {
+	result := <-a.Srv.Store.Basket().GetByOrderIds([]string{orderId})
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	baskets := result.Data.([]*model.Basket)
+
+	productIds := make([]string, 0, len(baskets))
+	for _, basket := range baskets {
+		productIds = append(productIds, basket.ProductId)
+	}
+
+	positions := make(map[string][]*model.Basket)
+	if len(productIds) == 0 {
+		return positions, nil
+	}
+
+	result = <-a.Srv.Store.ProductOffice().GetOfficesForProducts(productIds)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	productOffices := result.Data.(map[string][]*model.Office)
+
+	for _, basket := range baskets {
+		for _, office := range productOffices[basket.ProductId] {
+			positions[office.Id] = append(positions[office.Id], basket)
+		}
+	}
+
+	return positions, nil
+}
+
+// QueueOrderTickets puts a kitchen ticket of the order in the print queue of every office
+// that makes its products and has a print agent. Apart from reprints an office gets one
+// ticket per order, whether the order was paid or accepted first. The ticket is kept unique
+// by the store, so a ticket queued concurrently for the same order is not queued twice.
+func (a *App) QueueOrderTickets(order *model.Order, reason string, officeId string, requestedBy string) ([]*model.PrintTicket, *model.AppError) {
+	positions, err := a.orderPositionsByOffice(order.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := <-a.Srv.Store.PrintTicket().GetForOrder(order.Id)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	printed := make(map[string]bool)
+	copies := make(map[string]int)
+	for _, ticket := range result.Data.([]*model.PrintTicket) {
+		printed[ticket.OfficeId] = true
+		if ticket.Copy > copies[ticket.OfficeId] {
+			copies[ticket.OfficeId] = ticket.Copy
+		}
+	}
+
+	tickets := []*model.PrintTicket{}
+	for id := range positions {
+		if (printed[id] && reason != model.PRINT_TICKET_REASON_REPRINT) || (len(officeId) > 0 && id != officeId) {
+			continue
+		}
+
+		result := <-a.Srv.Store.Office().Get(id)
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		office := result.Data.(*model.Office)
+
+		if len(office.PrintAgentKey) == 0 {
+			continue
+		}
+
+		ticket := &model.PrintTicket{
+			AppId:       office.AppId,
+			OfficeId:    office.Id,
+			OrderId:     order.Id,
+			Format:      office.PrintFormat,
+			PaperWidth:  office.PrintPaperWidth,
+			Reason:      reason,
+			RequestedBy: requestedBy,
+		}
+		if reason == model.PRINT_TICKET_REASON_REPRINT {
+			ticket.Copy = copies[id] + 1
+		}
+		if len(ticket.Format) == 0 {
+			ticket.Format = model.PRINT_FORMAT_ESCPOS
+		}
+		if ticket.PaperWidth == 0 {
+			ticket.PaperWidth = model.PRINT_PAPER_WIDTH_80
+		}
+
+		result = <-a.Srv.Store.PrintTicket().Save(ticket)
+		if result.Err != nil {
+			if result.Err.StatusCode == http.StatusConflict && reason != model.PRINT_TICKET_REASON_REPRINT {
+				// the order was paid and accepted at once, the office already has its ticket
+				continue
+			}
+			return nil, result.Err
+		}
+		tickets = append(tickets, result.Data.(*model.PrintTicket))
+	}
+
+	return tickets, nil
+}
+
+// queueOrderTickets queues the tickets of an order that was paid or accepted in the background.
+func (a *App) queueOrderTickets(order *model.Order, reason string) {
+	order = order.Clone()
+
+	a.Srv.Go(func() {
+		if _, err := a.QueueOrderTickets(order, reason, "", ""); err != nil {
+			mlog.Warn("Failed to queue the kitchen tickets of the order", mlog.String("order_id", order.Id), mlog.Err(err))
+		}
+	})
+}
+
+// TakePrintQueue hands the print agent of the office the tickets to print with their content.
+// A ticket that is not acknowledged in time is handed out again.
+func (a *App) TakePrintQueue(office *model.Office) ([]*model.PrintTicket, *model.AppError) {
+	now := model.GetMillis()
+
+	result := <-a.Srv.Store.PrintTicket().GetQueue(office.Id, now-model.PRINT_TICKET_RESEND_MILLIS, model.PRINT_QUEUE_MAX_TICKETS)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	tickets := []*model.PrintTicket{}
+	for _, ticket := range result.Data.([]*model.PrintTicket) {
+		content, err := a.RenderPrintTicket(ticket, ticket.Format)
+		if err != nil {
+			mlog.Warn("Failed to render the kitchen ticket", mlog.String("ticket_id", ticket.Id), mlog.Err(err))
+
+			ticket.Status = model.PRINT_TICKET_STATUS_FAILED
+			ticket.Error = err.Error()
+			if result := <-a.Srv.Store.PrintTicket().Update(ticket); result.Err != nil {
+				return nil, result.Err
+			}
+			continue
+		}
+
+		ticket.Status = model.PRINT_TICKET_STATUS_SENT
+		ticket.SentAt = now
+		ticket.Attempts++
+		if result := <-a.Srv.Store.PrintTicket().Update(ticket); result.Err != nil {
+			return nil, result.Err
+		}
+
+		ticket.Content = content
+		tickets = append(tickets, ticket)
+	}
+
+	return tickets, nil
+}
+
+// RenderPrintTicket renders the ticket in the format, ESC/POS bytes or a PDF document.
+func (a *App) RenderPrintTicket(ticket *model.PrintTicket, format string) ([]byte, *model.AppError) {
+	result := <-a.Srv.Store.Order().Get(ticket.OrderId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	order := result.Data.(*model.Order)
+
+	result = <-a.Srv.Store.Office().Get(ticket.OfficeId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	office := result.Data.(*model.Office)
+
+	positions, err := a.orderPositionsByOffice(order.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	templatesDir, _ := fileutils.FindDir("templates")
+	lines, renderErr := ticketprinter.NewTicket(order, positions[office.Id], office, ticket.Reason == model.PRINT_TICKET_REASON_REPRINT, time.Local).Lines(templatesDir)
+	if renderErr != nil {
+		return nil, model.NewAppError("RenderPrintTicket", "app.print_ticket.template.app_error", nil, renderErr.Error(), http.StatusInternalServerError)
+	}
+
+	var content []byte
+	if format == model.PRINT_FORMAT_PDF {
+		fontsDir, _ := fileutils.FindDir("fonts")
+		content, renderErr = ticketprinter.RenderPdf(lines, ticket.PaperWidth, filepath.Join(fontsDir, PRINT_TICKET_FONT))
+	} else {
+		content, renderErr = ticketprinter.RenderEscPos(lines, ticket.PaperWidth)
+	}
+
+	if renderErr != nil {
+		return nil, model.NewAppError("RenderPrintTicket", "app.print_ticket.render.app_error", nil, "format="+format+", "+renderErr.Error(), http.StatusInternalServerError)
+	}
+
+	return content, nil
+}
+
+// AckPrintTicket records whether the print agent printed the ticket.
+func (a *App) AckPrintTicket(officeId string, ticketId string, ack *model.PrintTicketAck) (*model.PrintTicket, *model.AppError) {
+	result := <-a.Srv.Store.PrintTicket().Get(ticketId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	ticket := result.Data.(*model.PrintTicket)
+
+	if ticket.OfficeId != officeId {
+		return nil, model.NewAppError("AckPrintTicket", "app.print_ticket.ack.other_office.app_error", nil, "ticket_id="+ticketId, http.StatusNotFound)
+	}
+
+	ticket.Status = ack.Status
+	ticket.Error = ack.Error
+	if len(ticket.Error) > PRINT_TICKET_ERROR_MAX {
+		ticket.Error = ticket.Error[:PRINT_TICKET_ERROR_MAX]
+	}
+	if ack.Status == model.PRINT_TICKET_STATUS_PRINTED {
+		ticket.PrintedAt = model.GetMillis()
+	}
+
+	result = <-a.Srv.Store.PrintTicket().Update(ticket)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.PrintTicket), nil
+}
+
+func (a *App) GetPrintTicket(ticketId string) (*model.PrintTicket, *model.AppError) {
+	result := <-a.Srv.Store.PrintTicket().Get(ticketId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.PrintTicket), nil
+}
+
+func (a *App) GetOrderPrintTickets(orderId string) ([]*model.PrintTicket, *model.AppError) {
+	result := <-a.Srv.Store.PrintTicket().GetForOrder(orderId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.([]*model.PrintTicket), nil
+}
+
+// RegeneratePrintAgentKey gives the office a new key for its print agent, the old one stops
+// working. Printing is on for the offices with a key.
+func (a *App) RegeneratePrintAgentKey(officeId string) (string, *model.AppError) {
+	result := <-a.Srv.Store.Office().Get(officeId)
+	if result.Err != nil {
+		return "", result.Err
+	}
+	office := result.Data.(*model.Office).Clone()
+
+	office.PrintAgentKey = model.NewRandomString(PRINT_AGENT_KEY_LENGTH)
+
+	if result := <-a.Srv.Store.Office().Update(office); result.Err != nil {
+		return "", result.Err
+	}
+
+	return office.PrintAgentKey, nil
+}
+
+func (a *App) IsValidPrintAgentKey(office *model.Office, key string) bool {
+	if len(office.PrintAgentKey) == 0 {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(office.PrintAgentKey), []byte(key)) == 1
+}
@@ -408,43 +408,97 @@ func (a *App) DeleteProduct(productId, deleteByID string) (*model.Product, *mode
 	return product, nil
 }
 
-func (a *App) SearchProducts(terms string, categoryId string, timeZoneOffset int, page, perPage int) (*model.ProductList, *model.AppError) {
-	result := <-a.Srv.Store.Product().Search(categoryId, terms, page, perPage)
-	if result.Err != nil {
-		return nil, result.Err
+// SearchProducts looks the products up in the product index of the application, the facets are
+// only counted there. Without Elasticsearch the full text search of the database is used.
+func (a *App) SearchProducts(appId string, params *model.ProductSearchParams) (*model.ProductSearchResults, *model.AppError) {
+	esInterface := a.Elasticsearch
+	if esInterface == nil || !*a.Config().ElasticsearchSettings.EnableSearching || len(appId) == 0 {
+		return a.searchProductsInDatabase(appId, params)
 	}
-	rlist := result.Data.(*model.ProductList)
-	return a.PrepareProductListForClient(rlist), nil
 
-	paramsList := model.ParseSearchParams(terms, timeZoneOffset)
-	esInterface := a.Elasticsearch
-	resultList := model.NewProductList()
-	if esInterface != nil && *a.Config().ElasticsearchSettings.EnableSearching {
-		if len(paramsList) == 0 {
-			return model.NewProductList(), nil
+	if len(params.CategoryId) > 0 {
+		categories, err := a.GetCategoryPath(params.CategoryId)
+		if err != nil {
+			return nil, err
 		}
 
-		if products, err := a.Elasticsearch.SearchProductsHint(paramsList, page, perPage); err != nil {
-			return nil, err
-		} else if len(products) > 0 {
-			for _, p := range products {
-				if (len(categoryId) > 0 && p.CategoryId == categoryId) || len(categoryId) == 0 {
-					if p.DeleteAt == 0 {
-						resultList.AddOrder(p.Id)
-						resultList.AddProduct(p)
-					}
-				}
+		// the products of the subcategories are found as well, the same as in the database
+		subtree := *params
+		subtree.CategoryIds = make([]string, 0, len(categories))
+		for _, category := range categories {
+			subtree.CategoryIds = append(subtree.CategoryIds, category.Id)
+		}
+		params = &subtree
+	}
+
+	productIds, total, facets, err := esInterface.SearchProducts(appId, params)
+	if err != nil {
+		return nil, err
+	}
+
+	list := model.NewProductList()
+	if len(productIds) > 0 {
+		result := <-a.Srv.Store.Product().GetProductsByIds(productIds, true)
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		products := make(map[string]*model.Product)
+		for _, p := range result.Data.([]*model.Product) {
+			products[p.Id] = p
+		}
+
+		// the index may lag behind the database, so the filters are checked once again
+		for _, id := range productIds {
+			if p, ok := products[id]; ok && p.AppId == appId && params.Matches(p) {
+				list.AddProduct(p)
+				list.AddOrder(p.Id)
 			}
 		}
-	} else {
-		result := <-a.Srv.Store.Product().Search(categoryId, terms, page, perPage)
-		resultList = result.Data.(*model.ProductList)
-		return a.PrepareProductListForClient(resultList), nil
 	}
-	return resultList, nil
+
+	return &model.ProductSearchResults{
+		ProductList: a.PrepareProductListForClient(list),
+		Total:       total,
+		Facets:      facets,
+	}, nil
+}
+
+func (a *App) searchProductsInDatabase(appId string, params *model.ProductSearchParams) (*model.ProductSearchResults, *model.AppError) {
+	result := <-a.Srv.Store.Product().Search(appId, params)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	found := result.Data.(*model.ProductList)
+
+	// the store takes care of the application, the category tree, the prices, the office and
+	// the tags
+	rest := *params
+	rest.CategoryId = ""
+	rest.CategoryIds = nil
+	rest.PriceFrom = nil
+	rest.PriceTo = nil
+	rest.Tags = nil
+
+	list := model.NewProductList()
+	for _, id := range found.Order {
+		p := found.Products[id]
+		if p == nil || !rest.Matches(p) {
+			continue
+		}
+
+		list.AddProduct(p)
+		list.AddOrder(p.Id)
+	}
+
+	return &model.ProductSearchResults{
+		ProductList: a.PrepareProductListForClient(list),
+		Total:       int64(len(list.Order)),
+	}, nil
 }
 
-func (a *App) GetDiscountLimits(productIds []string) (*model.ProductsDiscount, *model.AppError) {
+func (a *App) GetDiscountLimits(userId string, productIds []string) (*model.ProductsDiscount, *model.AppError) {
 	result := <-a.Srv.Store.Product().GetProductsByIds(productIds, true)
 	if result.Err != nil {
 		return nil, result.Err
@@ -464,6 +518,7 @@ func (a *App) GetDiscountLimits(productIds []string) (*model.ProductsDiscount, *
 	var totalPrice float64 = 0
 
 	rproducts := result.Data.([]*model.Product)
+	loader := a.NewBatchLoader()
 
 	for _, product := range rproducts {
 		value = 0
@@ -471,8 +526,9 @@ func (a *App) GetDiscountLimits(productIds []string) (*model.ProductsDiscount, *
 		Quantity := keys[product.Id]
 		if product.PrivateRule {
 			value = int64(product.Price*(product.DiscountLimit/100)) * int64(Quantity)
-		} else if application := (<-a.Srv.Store.Application().Get(product.AppId)).Data.(*model.Application); application != nil {
-			value = int64(product.Price*(float64(application.MaxDiscount)/100)) * int64(Quantity)
+		} else if application, err := loader.Application(product.AppId); err == nil {
+			_, maxDiscount := loader.CustomerRates(userId, application)
+			value = int64(product.Price*(maxDiscount/100)) * int64(Quantity)
 		}
 
 		discount.Limits = append(discount.Limits, struct {
@@ -520,5 +576,16 @@ func (a *App) UpdateProductStatus(productId string, status *model.ProductStatus)
 		return nil, result.Err
 	}
 
-	return result.Data.(*model.Product), nil
+	rproduct := result.Data.(*model.Product)
+
+	esInterface := a.Elasticsearch
+	if esInterface != nil && *a.Config().ElasticsearchSettings.EnableIndexing {
+		a.Srv.Go(func() {
+			if err := esInterface.IndexProduct(rproduct, rproduct.AppId); err != nil {
+				mlog.Error("Encountered error indexing product", mlog.String("product_id", rproduct.Id), mlog.Err(err))
+			}
+		})
+	}
+
+	return rproduct, nil
 }
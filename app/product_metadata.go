@@ -1,7 +1,9 @@
 package app
 
 import (
+	"im/mlog"
 	"im/model"
+	"im/store"
 	"im/utils"
 )
 
@@ -25,15 +27,92 @@ func (a *App) PrepareProductListForClient(originalList *model.ProductList) *mode
 		Order:    originalList.Order, // Note that this uses the original Order array, so it isn't a deep copy
 	}
 
-	for id, originalProduct := range originalList.Products {
-		product := a.PrepareProductForClient(originalProduct, false)
+	products := make([]*model.Product, 0, len(originalList.Products))
+	for _, originalProduct := range originalList.Products {
+		products = append(products, originalProduct)
+	}
 
-		list.Products[id] = product
+	for _, product := range a.prepareProductsForClient(products) {
+		list.Products[product.Id] = product
 	}
 
 	return list
 }
 
+// prepareProductsForClient is PrepareProductForClient for a list, the media, the offices, the
+// extras and the categories of all the products are loaded at once.
+func (a *App) prepareProductsForClient(originalProducts []*model.Product) []*model.Product {
+	products := make([]*model.Product, 0, len(originalProducts))
+	if len(originalProducts) == 0 {
+		return products
+	}
+
+	productIds := make([]string, 0, len(originalProducts))
+	categoryIds := make([]string, 0, len(originalProducts))
+	for _, product := range originalProducts {
+		productIds = append(productIds, product.Id)
+		if len(product.CategoryId) > 0 {
+			categoryIds = append(categoryIds, product.CategoryId)
+		}
+	}
+
+	mediaChan := a.Srv.Store.FileInfo().GetForMetadataIds(productIds)
+	officesChan := a.Srv.Store.ProductOffice().GetOfficesForProducts(productIds)
+	extrasChan := a.Srv.Store.Extra().GetExtraProductsForProducts(productIds)
+
+	var categoriesChan store.StoreChannel
+	if len(categoryIds) > 0 {
+		categoriesChan = a.Srv.Store.Category().GetCategoriesByIds(categoryIds)
+	}
+
+	media := make(map[string][]*model.FileInfo, len(productIds))
+	if result := <-mediaChan; result.Err != nil {
+		mlog.Warn("Failed to get files for the products", mlog.Err(result.Err))
+	} else {
+		for _, info := range result.Data.([]*model.FileInfo) {
+			media[info.MetadataId] = append(media[info.MetadataId], info)
+		}
+	}
+
+	var offices map[string][]*model.Office
+	if result := <-officesChan; result.Err != nil {
+		mlog.Warn("Failed to get offices for the products", mlog.Err(result.Err))
+	} else {
+		offices = result.Data.(map[string][]*model.Office)
+	}
+
+	var extras map[string][]*model.Product
+	if result := <-extrasChan; result.Err != nil {
+		mlog.Warn("Failed to get extra lists for the products", mlog.Err(result.Err))
+	} else {
+		extras = result.Data.(map[string][]*model.Product)
+	}
+
+	categories := make(map[string]*model.Category, len(categoryIds))
+	if categoriesChan != nil {
+		if result := <-categoriesChan; result.Err != nil {
+			mlog.Warn("Failed to get categories for the products", mlog.Err(result.Err))
+		} else {
+			for _, category := range result.Data.([]*model.Category) {
+				categories[category.Id] = category
+			}
+		}
+	}
+
+	for _, originalProduct := range originalProducts {
+		product := originalProduct.Clone()
+
+		product.Media = media[product.Id]
+		product.Offices = offices[product.Id]
+		product.ExtraProductList = extras[product.Id]
+		product.Category = categories[product.CategoryId]
+
+		products = append(products, product)
+	}
+
+	return products
+}
+
 func (a *App) PrepareProductForClient(originalProduct *model.Product, isNewProduct bool) *model.Product {
 	product := originalProduct.Clone()
 
@@ -0,0 +1,280 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"im/mlog"
+	"im/model"
+	"im/services/payment"
+)
+
+const (
+	RECEIPT_STATUS_CHECK_INTERVAL   = time.Minute
+	RECEIPT_STATUS_CHECK_BATCH_SIZE = 100
+
+	// RECEIPT_STATUS_TIMEOUT is how long the acquirer has to issue the receipt before the order
+	// is marked as failed and someone has to issue it by hand.
+	RECEIPT_STATUS_TIMEOUT = 24 * time.Hour
+
+	RECEIPT_DELIVERY_ITEM_NAME = "Доставка"
+)
+
+// BuildOrderReceipt makes the cart of the fiscal receipt from the positions of the order, nil when
+// the application does not issue receipts.
+func (a *App) BuildOrderReceipt(order *model.Order, application *model.Application) *model.Receipt {
+	if !application.FiscalReceipts || len(order.Positions) == 0 {
+		return nil
+	}
+
+	items := orderReceiptItems(order)
+	if len(items) == 0 {
+		return nil
+	}
+
+	receipt := &model.Receipt{
+		TaxSystem: application.TaxSystem,
+		Phone:     order.Phone,
+		Items:     items,
+	}
+
+	if order.User != nil {
+		receipt.Email = order.User.Email
+		if len(receipt.Phone) == 0 {
+			receipt.Phone = order.User.Phone
+		}
+	}
+
+	return receipt
+}
+
+// orderReceiptItems makes the items of the positions and the delivery of the order, nil when
+// nothing is to be paid for. The bonuses the customer paid with are spread over the positions in
+// proportion to their amounts so the items add up to the amount due.
+func orderReceiptItems(order *model.Order) []*model.ReceiptItem {
+	var items []*model.ReceiptItem
+	var subtotal int64
+	for _, position := range order.Positions {
+		if position.Quantity <= 0 {
+			continue
+		}
+
+		item := &model.ReceiptItem{
+			Code:           position.ProductId,
+			Name:           position.Name,
+			Measure:        model.RECEIPT_DEFAULT_MEASURE,
+			Quantity:       position.Quantity,
+			Amount:         int64(math.Round(position.Price*100)) * int64(position.Quantity),
+			VatRate:        model.VAT_RATE_NONE,
+			PaymentSubject: model.PAYMENT_SUBJECT_COMMODITY,
+			PaymentMethod:  model.PAYMENT_METHOD_FULL_PREPAYMENT,
+		}
+
+		if product := position.Product; product != nil {
+			if len(product.Measure) > 0 {
+				item.Measure = product.Measure
+			}
+			if len(product.VatRate) > 0 {
+				item.VatRate = product.VatRate
+			}
+			if len(product.PaymentSubject) > 0 {
+				item.PaymentSubject = product.PaymentSubject
+			}
+			if len(product.PaymentMethod) > 0 {
+				item.PaymentMethod = product.PaymentMethod
+			}
+		}
+
+		subtotal += item.Amount
+		items = append(items, item)
+	}
+
+	if subtotal == 0 {
+		return deliveryReceiptItems(order)
+	}
+
+	discount := subtotal - int64(math.Round(order.Price*100))
+	if discount > subtotal {
+		discount = subtotal
+	}
+
+	if discount > 0 {
+		remaining := discount
+		for _, item := range items {
+			share := discount * item.Amount / subtotal
+			item.Amount -= share
+			remaining -= share
+		}
+
+		// what the rounding leaves is taken from the first positions that still have an amount
+		for _, item := range items {
+			if remaining == 0 {
+				break
+			}
+
+			share := remaining
+			if share > item.Amount {
+				share = item.Amount
+			}
+			item.Amount -= share
+			remaining -= share
+		}
+	}
+
+	return append(splitReceiptItems(items), deliveryReceiptItems(order)...)
+}
+
+// deliveryReceiptItems makes the item of the delivery, it is paid along with the positions so
+// the items add up to the amount due.
+func deliveryReceiptItems(order *model.Order) []*model.ReceiptItem {
+	amount := int64(math.Round(order.PriceDelivery * 100))
+	if amount <= 0 {
+		return nil
+	}
+
+	return []*model.ReceiptItem{
+		{
+			Name:           RECEIPT_DELIVERY_ITEM_NAME,
+			Measure:        model.RECEIPT_DEFAULT_MEASURE,
+			Quantity:       1,
+			Price:          amount,
+			Amount:         amount,
+			VatRate:        model.VAT_RATE_NONE,
+			PaymentSubject: model.PAYMENT_SUBJECT_SERVICE,
+			PaymentMethod:  model.PAYMENT_METHOD_FULL_PREPAYMENT,
+		},
+	}
+}
+
+// splitReceiptItems sets the unit prices of the items. The price times the quantity has to give
+// the amount, so an item whose amount does not divide by its quantity after the discount is
+// split into the units at the lower price and one unit that takes the rest.
+func splitReceiptItems(items []*model.ReceiptItem) []*model.ReceiptItem {
+	var result []*model.ReceiptItem
+	for _, item := range items {
+		quantity := int64(item.Quantity)
+		item.Price = item.Amount / quantity
+		remainder := item.Amount - item.Price*quantity
+
+		if remainder == 0 {
+			result = append(result, item)
+			continue
+		}
+
+		last := *item
+		last.Quantity = 1
+		last.Price = item.Price + remainder
+		last.Amount = last.Price
+
+		item.Quantity--
+		item.Amount = item.Price * int64(item.Quantity)
+
+		result = append(result, item, &last)
+	}
+
+	return result
+}
+
+// RequestOrderReceipt marks the receipt of the paid order as pending, the receipt status job
+// then asks the acquirer for it until it is issued.
+func (a *App) RequestOrderReceipt(order *model.Order, application *model.Application) *model.AppError {
+	if !application.FiscalReceipts || len(order.ReceiptStatus) > 0 {
+		return nil
+	}
+
+	if result := <-a.Srv.Store.Order().SetReceipt(order.Id, &model.OrderReceipt{Status: model.RECEIPT_STATUS_PENDING}, model.GetMillis()); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func runReceiptStatusJob(s *Server) {
+	model.CreateRecurringTask("Receipt Status", func() {
+		if a := s.FakeApp(); a.IsLeader() {
+			a.CheckPendingReceipts()
+		}
+	}, RECEIPT_STATUS_CHECK_INTERVAL)
+}
+
+func (a *App) CheckPendingReceipts() {
+	result := <-a.Srv.Store.Order().GetPendingReceipts(RECEIPT_STATUS_CHECK_BATCH_SIZE)
+	if result.Err != nil {
+		mlog.Error("Failed to get the orders with pending receipts", mlog.Err(result.Err))
+		return
+	}
+
+	for _, order := range result.Data.([]*model.Order) {
+		if err := a.checkOrderReceipt(order); err != nil {
+			mlog.Warn("Failed to check the receipt of the order", mlog.String("order_id", order.Id), mlog.Err(err))
+		}
+	}
+}
+
+func (a *App) checkOrderReceipt(order *model.Order) *model.AppError {
+	receipt, err := a.getOrderReceiptStatus(order)
+	if err != nil {
+		receipt = &model.OrderReceipt{Status: model.RECEIPT_STATUS_PENDING}
+	}
+
+	if receipt.Status == model.RECEIPT_STATUS_PENDING {
+		if model.GetMillis()-order.ReceiptAt < int64(RECEIPT_STATUS_TIMEOUT/time.Millisecond) {
+			return err
+		}
+
+		mlog.Error("The acquirer has not issued the receipt of the order in time", mlog.String("order_id", order.Id))
+		receipt = &model.OrderReceipt{Status: model.RECEIPT_STATUS_FAILED}
+	}
+
+	if result := <-a.Srv.Store.Order().SetReceipt(order.Id, receipt, model.GetMillis()); result.Err != nil {
+		return result.Err
+	}
+
+	if receipt.Status == model.RECEIPT_STATUS_ISSUED {
+		a.postOrderReceipt(order, receipt)
+	}
+
+	return nil
+}
+
+func (a *App) getOrderReceiptStatus(order *model.Order) (*model.OrderReceipt, *model.AppError) {
+	user, err := a.GetUser(order.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	application, err := a.GetApplication(user.AppId)
+	if err != nil {
+		return nil, err
+	}
+
+	switch order.PaySystemId {
+	case model.SBERBANK_AQUIRING_TYPE:
+		sber := payment.SberBankBackend{Metrics: a.Metrics}
+		return sber.GetReceiptStatus(order, a.sberbankClientConfig(application))
+	case model.ALFABANK_AQUIRING_TYPE:
+		alfa := payment.AlfaBankBackend{Metrics: a.Metrics}
+		return alfa.GetReceiptStatus(order, a.alfabankClientConfig(application))
+	}
+
+	return nil, model.NewAppError("getOrderReceiptStatus", "app.receipt.get_status.pay_system.app_error", nil, "order_id="+order.Id+", pay_system_id="+order.PaySystemId, http.StatusBadRequest)
+}
+
+func (a *App) postOrderReceipt(order *model.Order, receipt *model.OrderReceipt) {
+	msg := fmt.Sprintf("Кассовый чек по заказу № %s сформирован.", order.FormatOrderNumber())
+	if len(receipt.Url) > 0 {
+		msg += "\nПроверить чек: " + receipt.Url
+	}
+
+	post := &model.Post{
+		UserId:   order.UserId,
+		Message:  msg,
+		CreateAt: model.GetMillis() + 1,
+		Type:     model.POST_WITH_TRANSACTION,
+	}
+	post.AddProp("receipt_qr", receipt.Qr)
+
+	a.CreatePostWithTransaction(post, false)
+}
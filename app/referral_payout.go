@@ -0,0 +1,212 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"im/mlog"
+	"im/model"
+	"im/store"
+)
+
+func (a *App) GetReferralPayout(payoutId string) (*model.ReferralPayout, *model.AppError) {
+	result := <-a.Srv.Store.ReferralPayout().Get(payoutId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.ReferralPayout), nil
+}
+
+func (a *App) GetReferralPayoutsForApp(appId string, status string, page, perPage int) ([]*model.ReferralPayout, *model.AppError) {
+	result := <-a.Srv.Store.ReferralPayout().GetForApp(appId, status, page*perPage, perPage)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.([]*model.ReferralPayout), nil
+}
+
+// ApproveReferralPayout credits a payout held for review. The monthly cap of the inviter is
+// checked again, as other payouts may have been credited since the payout was held.
+func (a *App) ApproveReferralPayout(payoutId string, reviewerId string) (*model.ReferralPayout, *model.AppError) {
+	payout, err := a.GetReferralPayout(payoutId)
+	if err != nil {
+		return nil, err
+	}
+
+	if payout.Status != model.REFERRAL_PAYOUT_STATUS_REVIEW {
+		return nil, model.NewAppError("ApproveReferralPayout", "app.referral_payout.approve.status.app_error", nil, "id="+payout.Id+", status="+payout.Status, http.StatusBadRequest)
+	}
+
+	application, err := a.GetApplication(payout.AppId)
+	if err != nil {
+		return nil, err
+	}
+
+	payout.ReviewerId = reviewerId
+	if err := a.creditReferralPayout(payout, application); err != nil {
+		return nil, err
+	}
+
+	return payout, nil
+}
+
+func (a *App) RejectReferralPayout(payoutId string, reviewerId string) (*model.ReferralPayout, *model.AppError) {
+	payout, err := a.GetReferralPayout(payoutId)
+	if err != nil {
+		return nil, err
+	}
+
+	if payout.Status != model.REFERRAL_PAYOUT_STATUS_REVIEW {
+		return nil, model.NewAppError("RejectReferralPayout", "app.referral_payout.reject.status.app_error", nil, "id="+payout.Id+", status="+payout.Status, http.StatusBadRequest)
+	}
+
+	payout.Status = model.REFERRAL_PAYOUT_STATUS_REJECTED
+	payout.ReviewerId = reviewerId
+
+	result := <-a.Srv.Store.ReferralPayout().Update(payout)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.ReferralPayout), nil
+}
+
+// RecordUserDevice remembers the device the user signed in from for the referral checks.
+func (a *App) RecordUserDevice(userId string, deviceId string) *model.AppError {
+	if result := <-a.Srv.Store.UserDevice().Save(&model.UserDevice{UserId: userId, DeviceId: deviceId}); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func (a *App) SetOrderCardMask(orderId string, cardMask string) *model.AppError {
+	if len(cardMask) == 0 {
+		return nil
+	}
+
+	if result := <-a.Srv.Store.Order().SetCardMask(orderId, cardMask); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// payReferralRewards pays the inviters up the chain of the customer, one per referral level.
+// The chain is walked once: it stops on a customer already met, so an invitation cycle can not
+// pay anybody twice.
+func (a *App) payReferralRewards(order *model.Order, application *model.Application, price float64) {
+	levels, err := a.GetAllLevelsPage(0, 60, &application.Id)
+	if err != nil {
+		return
+	}
+	levels.SortByLvl()
+
+	visited := map[string]bool{order.User.Id: true}
+	inviterId := order.User.InvitedBy
+
+	for i, id := range levels.Order {
+		if len(inviterId) == 0 || visited[inviterId] {
+			break
+		}
+		visited[inviterId] = true
+
+		inviter, err := a.GetUser(inviterId)
+		if err != nil {
+			break
+		}
+
+		value := math.Floor(price * (levels.Levels[id].Value / 100))
+		if value > 0 {
+			payout := &model.ReferralPayout{
+				AppId:     application.Id,
+				OrderId:   order.Id,
+				InviterId: inviter.Id,
+				InviteeId: order.User.Id,
+				Level:     i + 1,
+				Value:     value,
+			}
+
+			if err := a.processReferralPayout(payout, application); err != nil {
+				mlog.Error("Failed to pay referral reward", mlog.String("order_id", order.Id), mlog.String("inviter_id", inviter.Id), mlog.Err(err))
+			}
+		}
+
+		inviterId = inviter.InvitedBy
+	}
+}
+
+// processReferralPayout holds the payout for review when the customer and the inviter share a
+// device, an address or a card, and credits it otherwise.
+func (a *App) processReferralPayout(payout *model.ReferralPayout, application *model.Application) *model.AppError {
+	result := <-a.Srv.Store.ReferralPayout().GetFraudFlags(payout.InviteeId, payout.InviterId)
+	if result.Err != nil {
+		mlog.Error("Failed to check referral payout", mlog.String("order_id", payout.OrderId), mlog.Err(result.Err))
+		payout.Status = model.REFERRAL_PAYOUT_STATUS_REVIEW
+	} else if flags := result.Data.([]string); len(flags) > 0 {
+		payout.SetFlags(flags)
+		payout.Status = model.REFERRAL_PAYOUT_STATUS_REVIEW
+	}
+
+	if payout.Status == model.REFERRAL_PAYOUT_STATUS_REVIEW {
+		if result := <-a.Srv.Store.ReferralPayout().Save(payout); result.Err != nil {
+			return result.Err
+		}
+		return nil
+	}
+
+	return a.creditReferralPayout(payout, application)
+}
+
+// creditReferralPayout accrues the payout to the inviter, cut down to what is left of the
+// monthly cap of the application. A payout over the cap is kept as capped.
+func (a *App) creditReferralPayout(payout *model.ReferralPayout, application *model.Application) *model.AppError {
+	payout.Status = model.REFERRAL_PAYOUT_STATUS_CREDITED
+
+	if application.ReferralMonthlyCap > 0 {
+		now := time.Now().UTC()
+		monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+		result := <-a.Srv.Store.ReferralPayout().GetCreditedTotal(payout.InviterId, model.GetMillisForTime(monthStart))
+		if result.Err != nil {
+			return result.Err
+		}
+
+		remaining := math.Floor(application.ReferralMonthlyCap - result.Data.(float64))
+		if remaining <= 0 {
+			payout.Status = model.REFERRAL_PAYOUT_STATUS_CAPPED
+		} else if payout.Value > remaining {
+			payout.Value = remaining
+		}
+	}
+
+	if payout.Status == model.REFERRAL_PAYOUT_STATUS_CREDITED {
+		transaction, err := a.AccrualTransaction(&model.Transaction{
+			AppId:       application.Id,
+			UserId:      payout.InviterId,
+			OrderId:     payout.OrderId,
+			Description: fmt.Sprintf("Начисление по заказу друга \n"),
+			Value:       payout.Value,
+			Type:        model.TRANSACTION_TYPE_BONUS,
+		})
+		if err != nil {
+			return err
+		}
+
+		payout.TransactionId = transaction.Id
+		payout.CreditedAt = model.GetMillis()
+	}
+
+	var result store.StoreResult
+	if len(payout.Id) == 0 {
+		result = <-a.Srv.Store.ReferralPayout().Save(payout)
+	} else {
+		result = <-a.Srv.Store.ReferralPayout().Update(payout)
+	}
+
+	return result.Err
+}
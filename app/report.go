@@ -0,0 +1,206 @@
+package app
+
+import (
+	"math"
+	"sort"
+
+	"im/model"
+)
+
+// GetCohortRetention groups the customers by the week of their first order within the period
+// and returns the share of every cohort that ordered again in each of the following weeks.
+func (a *App) GetCohortRetention(options model.ReportOptions, weeks int) ([]*model.CohortRetention, *model.AppError) {
+	firstCohort := model.ReportWeek(options.BeginAt, options.Offset)
+	lastCohort := model.ReportWeek(options.ExpireAt, options.Offset)
+
+	result := <-a.Srv.Store.Order().GetCohortCounts(options, firstCohort, lastCohort)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	cohorts := make(map[int64]*model.CohortRetention)
+	list := []*model.CohortRetention{}
+	for week := firstCohort; week <= lastCohort; week++ {
+		cohort := &model.CohortRetention{
+			WeekStart: model.ReportWeekStart(week, options.Offset),
+			Weeks:     make([]*model.CohortWeek, weeks+1),
+		}
+		for i := range cohort.Weeks {
+			cohort.Weeks[i] = &model.CohortWeek{Week: i}
+		}
+
+		cohorts[week] = cohort
+		list = append(list, cohort)
+	}
+
+	for _, count := range result.Data.([]*model.CohortCount) {
+		cohort, ok := cohorts[count.Cohort]
+		if !ok || count.WeekOffset < 0 || count.WeekOffset > int64(weeks) {
+			continue
+		}
+
+		cohort.Weeks[count.WeekOffset].Customers = count.Customers
+		if count.WeekOffset == 0 {
+			cohort.Customers = count.Customers
+		}
+	}
+
+	for _, cohort := range list {
+		if cohort.Customers == 0 {
+			continue
+		}
+		for _, week := range cohort.Weeks {
+			week.Rate = math.Round(float64(week.Customers)/float64(cohort.Customers)*10000) / 100
+		}
+	}
+
+	return list, nil
+}
+
+// GetRfmReport scores the customers who ordered in the period from 1 to 5 by the recency, the
+// frequency and the monetary value of their orders, quintiles of the customers of the period,
+// and puts them into segments.
+func (a *App) GetRfmReport(options model.ReportOptions) (*model.RfmReport, *model.AppError) {
+	result := <-a.Srv.Store.Order().GetCustomerRfm(options)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	customers := result.Data.([]*model.CustomerRfm)
+
+	recency := rfmScores(len(customers), func(i int) float64 { return float64(customers[i].LastOrderAt) })
+	frequency := rfmScores(len(customers), func(i int) float64 { return float64(customers[i].Frequency) })
+	monetary := rfmScores(len(customers), func(i int) float64 { return customers[i].Monetary })
+
+	segments := make(map[string]*model.RfmSegment)
+	report := &model.RfmReport{
+		Segments:  []*model.RfmSegment{},
+		Customers: customers,
+	}
+	for _, name := range []string{
+		model.RFM_SEGMENT_CHAMPIONS,
+		model.RFM_SEGMENT_LOYAL,
+		model.RFM_SEGMENT_PROMISING,
+		model.RFM_SEGMENT_AT_RISK,
+		model.RFM_SEGMENT_HIBERNATING,
+		model.RFM_SEGMENT_OTHERS,
+	} {
+		segments[name] = &model.RfmSegment{Name: name}
+		report.Segments = append(report.Segments, segments[name])
+	}
+
+	for i, customer := range customers {
+		customer.RecencyScore = recency[i]
+		customer.FrequencyScore = frequency[i]
+		customer.MonetaryScore = monetary[i]
+		customer.Segment = model.RfmSegmentFor(customer.RecencyScore, customer.FrequencyScore)
+
+		segment := segments[customer.Segment]
+		segment.Customers++
+		segment.Monetary += customer.Monetary
+	}
+
+	return report, nil
+}
+
+// rfmScores ranks the values and gives the bottom fifth 1 and the top fifth 5. Equal values
+// get the same score.
+func rfmScores(n int, value func(i int) float64) []int {
+	indexes := make([]int, n)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return value(indexes[i]) < value(indexes[j])
+	})
+
+	scores := make([]int, n)
+	rank := 0
+	for i, index := range indexes {
+		if i == 0 || value(index) != value(indexes[i-1]) {
+			rank = i
+		}
+		scores[index] = 1 + rank*5/n
+	}
+
+	return scores
+}
+
+func (a *App) GetProductSalesReport(options model.ReportOptions) ([]*model.SalesReportRow, *model.AppError) {
+	result := <-a.Srv.Store.Order().GetProductSales(options)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.([]*model.SalesReportRow), nil
+}
+
+func (a *App) GetCategorySalesReport(options model.ReportOptions) ([]*model.SalesReportRow, *model.AppError) {
+	result := <-a.Srv.Store.Order().GetCategorySales(options)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.([]*model.SalesReportRow), nil
+}
+
+// GetBonusLiabilityReport returns, for every day of the period, the bonuses accrued and deducted
+// and the balance the customers hold at the end of the day.
+func (a *App) GetBonusLiabilityReport(options model.ReportOptions) ([]*model.BonusLiability, *model.AppError) {
+	balanceResult := <-a.Srv.Store.Transaction().GetBalanceBefore(options.AppId, options.BeginAt)
+	if balanceResult.Err != nil {
+		return nil, balanceResult.Err
+	}
+
+	result := <-a.Srv.Store.Transaction().GetBalanceMovements(options)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	movements := make(map[int64]*model.BalanceMovement)
+	for _, movement := range result.Data.([]*model.BalanceMovement) {
+		movements[movement.Day] = movement
+	}
+
+	outstanding := balanceResult.Data.(float64)
+	list := []*model.BonusLiability{}
+	for day := model.ReportDay(options.BeginAt, options.Offset); day <= model.ReportDay(options.ExpireAt, options.Offset); day++ {
+		liability := &model.BonusLiability{Date: model.ReportDayDate(day)}
+		if movement, ok := movements[day]; ok {
+			liability.Accrued = movement.Accrued
+			liability.Deducted = movement.Deducted
+			outstanding += movement.Accrued - movement.Deducted
+		}
+		liability.Outstanding = outstanding
+
+		list = append(list, liability)
+	}
+
+	return list, nil
+}
+
+// GetAverageCheckReport returns the orders, the revenue and the average check for every hour of the day.
+func (a *App) GetAverageCheckReport(options model.ReportOptions) ([]*model.HourlyCheck, *model.AppError) {
+	result := <-a.Srv.Store.Order().GetChecksByHour(options)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	list := make([]*model.HourlyCheck, 24)
+	for hour := range list {
+		list[hour] = &model.HourlyCheck{Hour: hour}
+	}
+
+	for _, check := range result.Data.([]*model.HourlyCheck) {
+		if check.Hour < 0 || check.Hour >= len(list) {
+			continue
+		}
+
+		list[check.Hour] = check
+		if check.Orders > 0 {
+			check.AvgCheck = math.Round(check.Revenue/float64(check.Orders)*100) / 100
+		}
+	}
+
+	return list, nil
+}
@@ -25,8 +25,10 @@ import (
 	"im/mlog"
 	"im/model"
 
+	"im/services/geocoder"
 	"im/services/httpservice"
 	"im/services/imageproxy"
+	"im/services/payment"
 	"im/services/timezones"
 	"im/store"
 	"im/utils"
@@ -98,6 +100,14 @@ type Server struct {
 
 	ImageProxy *imageproxy.ImageProxy
 
+	Geocoder *geocoder.Geocoder
+
+	orderTrackingWaiters *OrderTrackingWaiters
+
+	applicationConfigs *ApplicationConfigs
+
+	mockAcquirer *payment.MockAcquirer
+
 	Log *mlog.Logger
 
 	joinCluster        bool
@@ -106,6 +116,7 @@ type Server struct {
 
 	Cluster       einterfaces.ClusterInterface
 	Elasticsearch einterfaces.ElasticsearchInterface
+	Metrics       einterfaces.MetricsInterface
 }
 
 func NewServer(options ...Option) (*Server, error) {
@@ -118,6 +129,9 @@ func NewServer(options ...Option) (*Server, error) {
 		sessionCache:            utils.NewLru(model.SESSION_CACHE_SIZE),
 		seenPendingPostIdsCache: utils.NewLru(PENDING_POST_IDS_CACHE_SIZE),
 		clientConfig:            make(map[string]string),
+		orderTrackingWaiters:    NewOrderTrackingWaiters(),
+		applicationConfigs:      NewApplicationConfigs(),
+		mockAcquirer:            payment.NewMockAcquirer(),
 	}
 	for _, option := range options {
 		if err := option(s); err != nil {
@@ -152,6 +166,8 @@ func NewServer(options ...Option) (*Server, error) {
 
 	s.ImageProxy = imageproxy.MakeImageProxy(s, s.HTTPService)
 
+	s.Geocoder = geocoder.MakeGeocoder(s, s.HTTPService)
+
 	if err := utils.TranslationsPreInit(); err != nil {
 		return nil, errors.Wrapf(err, "unable to load translation files")
 	}
@@ -171,6 +187,8 @@ func NewServer(options ...Option) (*Server, error) {
 		s.InitEmailBatching()
 	})
 
+	s.initLoyaltyTierConfigListener()
+
 	mlog.Info(fmt.Sprintf("Current version is %v (%v/%v/%v/%v)", model.CurrentVersion, model.BuildNumber, model.BuildDate, model.BuildHash, model.BuildHashEnterprise))
 	mlog.Info(fmt.Sprintf("Enterprise Enabled: %v", model.BuildEnterpriseReady))
 	pwd, _ := os.Getwd()
@@ -208,6 +226,10 @@ func NewServer(options ...Option) (*Server, error) {
 		s.StartElasticsearch()
 	}
 
+	if s.startMetrics && s.Metrics != nil && *s.Config().MetricsSettings.Enable {
+		s.Metrics.StartServer()
+	}
+
 	s.initJobs()
 
 	if s.runjobs {
@@ -223,6 +245,15 @@ func NewServer(options ...Option) (*Server, error) {
 		s.Go(func() {
 			runTokenCleanupJob(s)
 		})
+		s.Go(func() {
+			runWebhookDeliveryJob(s)
+		})
+		s.Go(func() {
+			runWebhookDeliveryCleanupJob(s)
+		})
+		s.Go(func() {
+			runReceiptStatusJob(s)
+		})
 
 		if *s.Config().JobSettings.RunJobs && s.Jobs != nil {
 			s.Jobs.StartWorkers()
@@ -291,6 +322,10 @@ func (s *Server) Shutdown() error {
 		s.Cluster.StopInterNodeCommunication()
 	}
 
+	if s.Metrics != nil {
+		s.Metrics.StopServer()
+	}
+
 	if s.Jobs != nil && s.runjobs {
 		s.Jobs.StopWorkers()
 		s.Jobs.StopSchedulers()
@@ -48,7 +48,7 @@ func (s *Server) RunOldAppInitalization() error {
 
 	if s.FakeApp().Srv.newStore == nil {
 		s.FakeApp().Srv.newStore = func() store.Store {
-			return store.NewLayeredStore(sqlstore.NewSqlSupplier(s.FakeApp().Config().SqlSettings), s.Cluster)
+			return store.NewLayeredStore(sqlstore.NewSqlSupplier(s.FakeApp().Config().SqlSettings), s.Cluster, s.Metrics, s.FakeApp().Config().CacheSettings)
 		}
 	}
 
@@ -119,6 +119,7 @@ func (s *Server) RunOldAppInitalization() error {
 
 	s.FakeApp().DoAdvancedPermissionsMigration()
 	s.FakeApp().DoEmojisPermissionsMigration()
+	s.FakeApp().DoApplicationStaffRolesMigration()
 	s.FakeApp().DoPermissionsMigrations()
 
 	s.FakeApp().InitPostMetadata()
@@ -44,6 +44,10 @@ func (a *App) sendToSmsProxy(login, apiKey string, msg model.SmsNotification) {
 		mlog.Error(err.Error())
 	}
 
+	if a.Metrics != nil {
+		a.Metrics.IncrementSmsSent(err != nil)
+	}
+
 	fmt.Printf("%#v\n", resp)
 
 	return
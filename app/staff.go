@@ -0,0 +1,417 @@
+package app
+
+import (
+	"net/http"
+	"strings"
+
+	"im/model"
+)
+
+const (
+	STAFF_MEMBERS_PAGE_SIZE = 200
+)
+
+func (a *App) GetApplicationTeam(appId string) (*model.Team, *model.AppError) {
+	team, err := a.GetTeamByName(appId)
+	if err != nil {
+		return nil, err
+	}
+
+	if team.DeleteAt != 0 {
+		return nil, model.NewAppError("GetApplicationTeam", "app.staff.get_application_team.deleted.app_error", nil, "app_id="+appId, http.StatusBadRequest)
+	}
+
+	return team, nil
+}
+
+func (a *App) GetStaff(appId string, page, perPage int) ([]*model.Staff, *model.AppError) {
+	team, err := a.GetApplicationTeam(appId)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := a.GetTeamMembers(team.Id, page*perPage, perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.prepareStaff(members)
+}
+
+func (a *App) GetStaffMember(appId, userId string) (*model.Staff, *model.AppError) {
+	team, err := a.GetApplicationTeam(appId)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := a.GetTeamMember(team.Id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := a.prepareStaff([]*model.TeamMember{member})
+	if err != nil {
+		return nil, err
+	}
+
+	return list[0], nil
+}
+
+func (a *App) GetStaffForOffice(office *model.Office) ([]*model.Staff, *model.AppError) {
+	result := <-a.Srv.Store.StaffOffice().GetForOffice(office.Id)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	var userIds []string
+	for _, so := range result.Data.([]*model.StaffOffice) {
+		userIds = append(userIds, so.UserId)
+	}
+
+	if len(userIds) == 0 {
+		return []*model.Staff{}, nil
+	}
+
+	team, err := a.GetApplicationTeam(office.AppId)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := a.GetTeamMembersByIds(team.Id, userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.prepareStaff(members)
+}
+
+func (a *App) prepareStaff(members []*model.TeamMember) ([]*model.Staff, *model.AppError) {
+	var userIds []string
+	for _, member := range members {
+		userIds = append(userIds, member.UserId)
+	}
+
+	list := make([]*model.Staff, 0, len(members))
+	if len(userIds) == 0 {
+		return list, nil
+	}
+
+	users, err := a.GetUsersByIds(userIds, true)
+	if err != nil {
+		return nil, err
+	}
+
+	usersById := make(map[string]*model.User, len(users))
+	for _, user := range users {
+		usersById[user.Id] = user
+	}
+
+	result := <-a.Srv.Store.StaffOffice().GetForUsers(userIds)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	officesByUser := make(map[string][]string)
+	for _, so := range result.Data.([]*model.StaffOffice) {
+		officesByUser[so.UserId] = append(officesByUser[so.UserId], so.OfficeId)
+	}
+
+	for _, member := range members {
+		officeIds := officesByUser[member.UserId]
+		if officeIds == nil {
+			officeIds = []string{}
+		}
+
+		list = append(list, &model.Staff{
+			UserId:    member.UserId,
+			Roles:     member.Roles,
+			OfficeIds: officeIds,
+			User:      usersById[member.UserId],
+		})
+	}
+
+	return list, nil
+}
+
+// InviteStaff creates an account for a new employee of the application (or reuses the
+// existing one with the same email) and adds it to the application team with the given staff roles.
+func (a *App) InviteStaff(appId string, invite *model.StaffInvite) (*model.Staff, *model.AppError) {
+	if err := invite.IsValid(); err != nil {
+		return nil, err
+	}
+
+	team, err := a.GetApplicationTeam(appId)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := a.GetUserByEmail(invite.Email)
+	if err != nil {
+		user, err = a.CreateUser(&model.User{
+			Username:      invite.Email,
+			Email:         invite.Email,
+			EmailVerified: true,
+			Password:      invite.Password,
+			Phone:         invite.Phone,
+			FirstName:     invite.FirstName,
+			LastName:      invite.LastName,
+			Nickname:      invite.Email,
+			Locale:        "ru",
+			AppId:         appId,
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else if user.AppId != appId {
+		return nil, model.NewAppError("InviteStaff", "app.staff.invite.other_application.app_error", nil, "user_id="+user.Id, http.StatusBadRequest)
+	}
+
+	if _, err = a.AddTeamMember(team.Id, user.Id); err != nil {
+		return nil, err
+	}
+
+	if _, err = a.UpdateStaffRoles(appId, user.Id, invite.Roles); err != nil {
+		return nil, err
+	}
+
+	for _, officeId := range invite.OfficeIds {
+		if err = a.AssignStaffToOffice(appId, user.Id, officeId); err != nil {
+			return nil, err
+		}
+	}
+
+	return a.GetStaffMember(appId, user.Id)
+}
+
+// UpdateStaffRoles replaces the staff roles of the member, keeping the rest of the team roles as they are.
+// IsStaffTeamAdmin tells whether the staff member administers the team of the application, such
+// a member is managed only by the ones allowed to manage the team.
+func (a *App) IsStaffTeamAdmin(appId, userId string) (bool, *model.AppError) {
+	team, err := a.GetApplicationTeam(appId)
+	if err != nil {
+		return false, err
+	}
+
+	member, err := a.GetTeamMember(team.Id, userId)
+	if err != nil {
+		return false, err
+	}
+
+	if member.SchemeAdmin {
+		return true, nil
+	}
+
+	for _, role := range member.GetRoles() {
+		if role == model.TEAM_ADMIN_ROLE_ID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (a *App) UpdateStaffRoles(appId, userId string, roles string) (*model.Staff, *model.AppError) {
+	if !model.IsValidStaffRoles(roles) {
+		return nil, model.NewAppError("UpdateStaffRoles", "app.staff.update_roles.invalid.app_error", nil, "roles="+roles, http.StatusBadRequest)
+	}
+
+	team, err := a.GetApplicationTeam(appId)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := a.GetTeamMember(team.Id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var newRoles []string
+	for _, role := range strings.Fields(member.Roles) {
+		if !model.IsApplicationStaffRole(role) {
+			newRoles = append(newRoles, role)
+		}
+	}
+	newRoles = append(newRoles, strings.Fields(roles)...)
+
+	if _, err = a.UpdateTeamMemberRoles(team.Id, userId, strings.Join(newRoles, " ")); err != nil {
+		return nil, err
+	}
+
+	return a.GetStaffMember(appId, userId)
+}
+
+func (a *App) RemoveStaff(appId, userId, requestorId string) *model.AppError {
+	team, err := a.GetApplicationTeam(appId)
+	if err != nil {
+		return err
+	}
+
+	if _, err = a.GetTeamMember(team.Id, userId); err != nil {
+		return err
+	}
+
+	if result := <-a.Srv.Store.StaffOffice().DeleteForUser(appId, userId); result.Err != nil {
+		return result.Err
+	}
+
+	return a.RemoveUserFromTeam(team.Id, userId, requestorId)
+}
+
+func (a *App) AssignStaffToOffice(appId, userId, officeId string) *model.AppError {
+	office, err := a.GetOffice(officeId)
+	if err != nil {
+		return err
+	}
+
+	if office.AppId != appId {
+		return model.NewAppError("AssignStaffToOffice", "app.staff.assign_office.other_application.app_error", nil, "office_id="+officeId, http.StatusBadRequest)
+	}
+
+	result := <-a.Srv.Store.StaffOffice().GetForUser(userId)
+	if result.Err != nil {
+		return result.Err
+	}
+
+	for _, so := range result.Data.([]*model.StaffOffice) {
+		if so.OfficeId == officeId {
+			return nil
+		}
+	}
+
+	if result := <-a.Srv.Store.StaffOffice().Save(model.NewStaffOffice(appId, officeId, userId)); result.Err != nil {
+		return result.Err
+	}
+
+	a.InvalidateCacheForUser(userId)
+
+	return nil
+}
+
+func (a *App) UnassignStaffFromOffice(appId, userId, officeId string) *model.AppError {
+	office, err := a.GetOffice(officeId)
+	if err != nil {
+		return err
+	}
+
+	if office.AppId != appId {
+		return model.NewAppError("UnassignStaffFromOffice", "app.staff.unassign_office.other_application.app_error", nil, "office_id="+officeId, http.StatusBadRequest)
+	}
+
+	if _, err = a.GetStaffMember(appId, userId); err != nil {
+		return err
+	}
+
+	if result := <-a.Srv.Store.StaffOffice().Delete(userId, officeId); result.Err != nil {
+		return result.Err
+	}
+
+	a.InvalidateCacheForUser(userId)
+
+	return nil
+}
+
+// GetStaffOfficeIds returns the ids of the offices the user works in.
+func (a *App) GetStaffOfficeIds(userId string) ([]string, *model.AppError) {
+	result := <-a.Srv.Store.StaffOffice().GetForUser(userId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	officeIds := []string{}
+	for _, so := range result.Data.([]*model.StaffOffice) {
+		officeIds = append(officeIds, so.OfficeId)
+	}
+
+	return officeIds, nil
+}
+
+// getCustomerChannelMemberIds returns the members of the application team who
+// should be added to customer channels. Staff members only get there when they
+// can see every order together with the customer data, so couriers and kitchen
+// are left out.
+func (a *App) getCustomerChannelMemberIds(teamId string) ([]string, *model.AppError) {
+	var members []*model.TeamMember
+	for offset := 0; ; offset += STAFF_MEMBERS_PAGE_SIZE {
+		page, err := a.GetTeamMembers(teamId, offset, STAFF_MEMBERS_PAGE_SIZE)
+		if err != nil {
+			return nil, err
+		}
+
+		members = append(members, page...)
+		if len(page) < STAFF_MEMBERS_PAGE_SIZE {
+			break
+		}
+	}
+
+	var userIds []string
+	for _, member := range members {
+		roles := member.GetRoles()
+
+		isStaff := false
+		for _, role := range roles {
+			if model.IsApplicationStaffRole(role) {
+				isStaff = true
+				break
+			}
+		}
+
+		if isStaff && !(a.RolesGrantPermission(roles, model.PERMISSION_VIEW_ORDERS.Id) &&
+			a.RolesGrantPermission(roles, model.PERMISSION_VIEW_ORDER_CUSTOMER.Id)) {
+			continue
+		}
+
+		userIds = append(userIds, member.UserId)
+	}
+
+	return userIds, nil
+}
+
+// SessionCanViewOrder reports whether the session is allowed to read the order and
+// whether the customer data of the order may be shown to it.
+func (a *App) SessionCanViewOrder(session model.Session, order *model.Order) (bool, bool) {
+	if len(session.UserId) > 0 && order.UserId == session.UserId {
+		return true, true
+	}
+
+	customer, err := a.GetUser(order.UserId)
+	if err != nil {
+		return false, false
+	}
+
+	canView := a.SessionHasPermissionToApplication(session, customer.AppId, model.PERMISSION_VIEW_ORDERS) ||
+		(len(order.CourierId) > 0 && order.CourierId == session.UserId &&
+			a.SessionHasPermissionToApplication(session, customer.AppId, model.PERMISSION_VIEW_ASSIGNED_ORDERS))
+
+	if !canView {
+		return false, false
+	}
+
+	return true, a.SessionHasPermissionToApplication(session, customer.AppId, model.PERMISSION_VIEW_ORDER_CUSTOMER)
+}
+
+// MissingPermissionToPatchOrder checks the patch against the staff permissions of the
+// session and returns the permission the session lacks, or nil. Customers may still
+// change the payment system of their own orders, but marking an order as paid is left
+// to the cashiers.
+func (a *App) MissingPermissionToPatchOrder(session model.Session, order *model.Order, patch *model.OrderPatch) *model.Permission {
+	customer, err := a.GetUser(order.UserId)
+	if err != nil {
+		return model.PERMISSION_MANAGE_ORDERS
+	}
+
+	isOwner := len(session.UserId) > 0 && order.UserId == session.UserId
+
+	if patch.Payed != nil || (!isOwner && patch.HasPaymentChanges()) {
+		if !a.SessionHasPermissionToApplication(session, customer.AppId, model.PERMISSION_EDIT_ORDER_PAYMENT) {
+			return model.PERMISSION_EDIT_ORDER_PAYMENT
+		}
+	}
+
+	if !isOwner && patch.HasFulfillmentChanges() {
+		if !a.SessionHasPermissionToApplication(session, customer.AppId, model.PERMISSION_MANAGE_ORDERS) {
+			return model.PERMISSION_MANAGE_ORDERS
+		}
+	}
+
+	return nil
+}
@@ -98,6 +98,7 @@ func (a *App) PrepareTransactionListForClient(originalList *model.TransactionLis
 	list := &model.TransactionList{
 		Transactions: make(map[string]*model.Transaction, len(originalList.Transactions)),
 		Order:        originalList.Order, // Note that this uses the original Order array, so it isn't a deep copy
+		NextCursor:   originalList.NextCursor,
 	}
 
 	for id, originalTransaction := range originalList.Transactions {
@@ -183,8 +184,14 @@ func (a *App) AccrualTransaction(transaction *model.Transaction) (*model.Transac
 
 	a.AccrualBalance(transaction.UserId, transaction.Value)
 
+	if a.Metrics != nil {
+		a.Metrics.AddBonusAccrued(a.customerAppId(transaction.UserId), transaction.Value)
+	}
+
 	rtransaction := result.Data.(*model.Transaction)
 
+	a.triggerTransactionWebhooks(rtransaction, model.WEBHOOK_EVENT_BALANCE_ACCRUED)
+
 	return rtransaction, nil
 }
 
@@ -197,8 +204,14 @@ func (a *App) DeductionTransaction(transaction *model.Transaction) (*model.Trans
 
 	a.DeductionBalance(transaction.UserId, transaction.Value)
 
+	if a.Metrics != nil {
+		a.Metrics.AddBonusDeducted(a.customerAppId(transaction.UserId), math.Abs(transaction.Value))
+	}
+
 	rtransaction := result.Data.(*model.Transaction)
 
+	a.triggerTransactionWebhooks(rtransaction, model.WEBHOOK_EVENT_BALANCE_DEDUCTED)
+
 	return rtransaction, nil
 }
 
@@ -212,6 +225,16 @@ func (a *App) GetUserTransactions(options *model.TransactionGetOptions) (*model.
 	}
 }
 
+// GetTransactionsByCursor returns the cursor page of the transactions the options select.
+func (a *App) GetTransactionsByCursor(options *model.TransactionCursorOptions) (*model.TransactionList, *model.AppError) {
+	result := <-a.Srv.Store.Transaction().GetPage(options)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return a.PrepareTransactionListForClient(result.Data.(*model.TransactionList)), nil
+}
+
 func (a *App) GetBonusTransactionsForUser(orderUserId string, userId string) (*model.TransactionList, *model.AppError) {
 
 	if result := <-a.Srv.Store.Transaction().GetBonusTransactionsForUser(orderUserId, userId); result.Err != nil {
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"im/model"
+	"im/services/payment"
+)
+
+// walletMerchant is the merchant login the acquirer expects with the wallet tokens.
+func walletMerchant(application *model.Application) string {
+	if len(application.AqMerchant) > 0 {
+		return application.AqMerchant
+	}
+
+	return strings.TrimSuffix(application.AqUsername, "-api")
+}
+
+// PayOrderWithWallet forwards the Apple Pay or Google Pay token of the order to the acquirer and
+// remembers the order of the acquirer, its status is then checked as for the card payments.
+func (a *App) PayOrderWithWallet(order *model.Order, application *model.Application, wallet string, paymentToken string, ip string) (*model.Order, *model.AppError) {
+	if order.Payed || order.Canceled {
+		return nil, model.NewAppError("PayOrderWithWallet", "app.wallet_payment.pay.order_status.app_error", nil, "order_id="+order.Id, http.StatusBadRequest)
+	}
+
+	var mdOrder string
+	var err *model.AppError
+
+	switch application.AqType {
+	case model.SBERBANK_AQUIRING_TYPE:
+		sber := payment.SberBankBackend{Metrics: a.Metrics}
+		mdOrder, err = sber.PayWithWallet(order, wallet, paymentToken, walletMerchant(application), ip, a.sberbankClientConfig(application))
+	case model.ALFABANK_AQUIRING_TYPE:
+		alfa := payment.AlfaBankBackend{Metrics: a.Metrics}
+		mdOrder, err = alfa.PayWithWallet(order, wallet, paymentToken, walletMerchant(application), ip, a.alfabankClientConfig(application))
+	default:
+		return nil, model.NewAppError("PayOrderWithWallet", "app.wallet_payment.pay.pay_system.app_error", nil, "aq_type="+application.AqType, http.StatusBadRequest)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return a.UpdateOrder(order.Id, &model.OrderPatch{
+		PaySystemId:       model.NewString(application.AqType),
+		PaySystemCode:     model.NewString(mdOrder),
+		PaySystemOrderNum: model.NewString(strconv.FormatInt(model.GetMillis(), 10)),
+	}, false)
+}
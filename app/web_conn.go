@@ -36,6 +36,10 @@ type WebConn struct {
 	Locale                    string
 	AllChannelMembers         map[string]string
 	LastAllChannelMembersTime int64
+	StaffOfficeIds            map[string]bool
+	LastStaffOfficesTime      int64
+	orderSubscriptions        map[string]bool
+	orderSubscriptionsMutex   sync.RWMutex
 	Sequence                  int64
 	closeOnce                 sync.Once
 	endWritePump              chan struct{}
@@ -243,6 +247,8 @@ func (c *WebConn) writePump() {
 func (webCon *WebConn) InvalidateCache() {
 	webCon.AllChannelMembers = nil
 	webCon.LastAllChannelMembersTime = 0
+	webCon.StaffOfficeIds = nil
+	webCon.LastStaffOfficesTime = 0
 	webCon.SetSession(nil)
 	webCon.SetSessionExpiresAt(0)
 }
@@ -304,6 +310,11 @@ func (webCon *WebConn) ShouldSendEvent(msg *model.WebSocketEvent) bool {
 		}
 	}
 
+	// If the event is about an order, only send it to the connections that follow the order
+	if len(msg.Broadcast.OrderId) > 0 && !webCon.IsSubscribedToOrder(msg.Broadcast.OrderId) {
+		return false
+	}
+
 	// If the event is destined to a specific user
 	if len(msg.Broadcast.UserId) > 0 {
 		if webCon.UserId == msg.Broadcast.UserId {
@@ -344,12 +355,103 @@ func (webCon *WebConn) ShouldSendEvent(msg *model.WebSocketEvent) bool {
 
 	// Only report events to users who are in the team for the event
 	if len(msg.Broadcast.TeamId) > 0 {
-		return webCon.IsMemberOfTeam(msg.Broadcast.TeamId)
+		if !webCon.IsMemberOfTeam(msg.Broadcast.TeamId) {
+			return false
+		}
+
+		if len(msg.Broadcast.Permission) > 0 && !webCon.HasPermissionInTeam(msg.Broadcast.TeamId, msg.Broadcast.Permission) {
+			return false
+		}
+
+		if len(msg.Broadcast.OfficeIds) > 0 {
+			return webCon.WorksAtAnyOffice(msg.Broadcast.OfficeIds)
+		}
+
+		return true
 	}
 
 	return true
 }
 
+// SubscribeToOrders makes the connection receive the tracking events of the orders. The caller
+// checks that the orders are of the user of the connection.
+func (webCon *WebConn) SubscribeToOrders(orderIds []string) {
+	webCon.orderSubscriptionsMutex.Lock()
+	defer webCon.orderSubscriptionsMutex.Unlock()
+
+	if webCon.orderSubscriptions == nil {
+		webCon.orderSubscriptions = make(map[string]bool)
+	}
+
+	for _, orderId := range orderIds {
+		webCon.orderSubscriptions[orderId] = true
+	}
+}
+
+func (webCon *WebConn) UnsubscribeFromOrders(orderIds []string) {
+	webCon.orderSubscriptionsMutex.Lock()
+	defer webCon.orderSubscriptionsMutex.Unlock()
+
+	for _, orderId := range orderIds {
+		delete(webCon.orderSubscriptions, orderId)
+	}
+}
+
+func (webCon *WebConn) IsSubscribedToOrder(orderId string) bool {
+	webCon.orderSubscriptionsMutex.RLock()
+	defer webCon.orderSubscriptionsMutex.RUnlock()
+
+	return webCon.orderSubscriptions[orderId]
+}
+
+func (webCon *WebConn) HasPermissionInTeam(teamId string, permissionId string) bool {
+	session := webCon.GetSession()
+	if session == nil {
+		return false
+	}
+
+	if member := session.GetTeamByTeamId(teamId); member != nil && webCon.App.RolesGrantPermission(member.GetRoles(), permissionId) {
+		return true
+	}
+
+	return webCon.App.RolesGrantPermission(session.GetUserRoles(), permissionId)
+}
+
+// WorksAtAnyOffice tells whether the user is staff of one of the offices. Staff not assigned to
+// any office work for the whole application.
+func (webCon *WebConn) WorksAtAnyOffice(officeIds []string) bool {
+	if model.GetMillis()-webCon.LastStaffOfficesTime > WEBCONN_MEMBER_CACHE_TIME {
+		webCon.StaffOfficeIds = nil
+		webCon.LastStaffOfficesTime = 0
+	}
+
+	if webCon.StaffOfficeIds == nil {
+		result := <-webCon.App.Srv.Store.StaffOffice().GetForUser(webCon.UserId)
+		if result.Err != nil {
+			mlog.Error("webhub.worksAtAnyOffice: " + result.Err.Error())
+			return false
+		}
+
+		webCon.StaffOfficeIds = make(map[string]bool)
+		for _, so := range result.Data.([]*model.StaffOffice) {
+			webCon.StaffOfficeIds[so.OfficeId] = true
+		}
+		webCon.LastStaffOfficesTime = model.GetMillis()
+	}
+
+	if len(webCon.StaffOfficeIds) == 0 {
+		return true
+	}
+
+	for _, officeId := range officeIds {
+		if webCon.StaffOfficeIds[officeId] {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (webCon *WebConn) IsMemberOfTeam(teamId string) bool {
 	currentSession := webCon.GetSession()
 
@@ -168,6 +168,10 @@ func (a *App) Publish(message *model.WebSocketEvent) {
 			message.Event == model.WEBSOCKET_EVENT_POST_EDITED ||
 			message.Event == model.WEBSOCKET_EVENT_DIRECT_ADDED ||
 			message.Event == model.WEBSOCKET_EVENT_DEFERRED_ADDED ||
+			message.Event == model.WEBSOCKET_EVENT_ORDER_CREATED ||
+			message.Event == model.WEBSOCKET_EVENT_ORDER_UPDATED ||
+			message.Event == model.WEBSOCKET_EVENT_ORDER_PAID ||
+			message.Event == model.WEBSOCKET_EVENT_ORDER_CANCELLED ||
 			message.Event == model.WEBSOCKET_EVENT_GROUP_ADDED ||
 			message.Event == model.WEBSOCKET_EVENT_ADDED_TO_TEAM {
 			cm.SendType = model.CLUSTER_SEND_RELIABLE
@@ -178,6 +182,10 @@ func (a *App) Publish(message *model.WebSocketEvent) {
 }
 
 func (a *App) PublishSkipClusterSend(message *model.WebSocketEvent) {
+	if message.Event == model.WEBSOCKET_EVENT_ORDER_TRACKING {
+		a.notifyOrderTrackingWaiters(message)
+	}
+
 	if message.Broadcast.UserId != "" {
 		hub := a.GetHubForUserId(message.Broadcast.UserId)
 		if hub != nil {
@@ -395,9 +403,15 @@ func (h *Hub) Start() {
 			case webCon := <-h.register:
 				connections.Add(webCon)
 				atomic.StoreInt64(&h.connectionCount, int64(len(connections.All())))
+				if h.app.Metrics != nil {
+					h.app.Metrics.IncrementWebSocketConnections()
+				}
 			case webCon := <-h.unregister:
 				connections.Remove(webCon)
 				atomic.StoreInt64(&h.connectionCount, int64(len(connections.All())))
+				if h.app.Metrics != nil {
+					h.app.Metrics.DecrementWebSocketConnections()
+				}
 
 				if len(webCon.UserId) == 0 {
 					continue
@@ -0,0 +1,298 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"im/mlog"
+	"im/model"
+)
+
+const (
+	WEBHOOK_DELIVERY_BATCH_SIZE         = 100
+	WEBHOOK_DELIVERY_RETRY_INTERVAL     = 30 * time.Second
+	WEBHOOK_DELIVERY_RETENTION          = 30 * 24 * time.Hour
+	WEBHOOK_DELIVERY_RESPONSE_READ_SIZE = 1024
+
+	// the retry job leaves a delivery alone for this long while its first attempt is in flight
+	WEBHOOK_DELIVERY_ATTEMPT_LEASE = 2 * time.Minute
+)
+
+func (a *App) GetWebhookSubscription(subscriptionId string) (*model.WebhookSubscription, *model.AppError) {
+	result := <-a.Srv.Store.WebhookSubscription().Get(subscriptionId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.WebhookSubscription), nil
+}
+
+func (a *App) GetWebhookSubscriptionsForApp(appId string) ([]*model.WebhookSubscription, *model.AppError) {
+	result := <-a.Srv.Store.WebhookSubscription().GetByAppId(appId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.([]*model.WebhookSubscription), nil
+}
+
+func (a *App) CreateWebhookSubscription(subscription *model.WebhookSubscription) (*model.WebhookSubscription, *model.AppError) {
+	subscription.Secret = ""
+
+	result := <-a.Srv.Store.WebhookSubscription().Save(subscription)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.WebhookSubscription), nil
+}
+
+func (a *App) PatchWebhookSubscription(subscription *model.WebhookSubscription, patch *model.WebhookSubscriptionPatch) (*model.WebhookSubscription, *model.AppError) {
+	subscription = subscription.Clone()
+	subscription.Patch(patch)
+
+	result := <-a.Srv.Store.WebhookSubscription().Update(subscription)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.WebhookSubscription), nil
+}
+
+// RegenerateWebhookSubscriptionSecret replaces the signing secret, the old one stops working at once.
+func (a *App) RegenerateWebhookSubscriptionSecret(subscription *model.WebhookSubscription) (*model.WebhookSubscription, *model.AppError) {
+	subscription = subscription.Clone()
+	subscription.Secret = model.NewRandomString(model.WEBHOOK_SUBSCRIPTION_SECRET_LENGTH)
+
+	result := <-a.Srv.Store.WebhookSubscription().Update(subscription)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.WebhookSubscription), nil
+}
+
+func (a *App) DeleteWebhookSubscription(subscription *model.WebhookSubscription) *model.AppError {
+	if result := <-a.Srv.Store.WebhookSubscription().Delete(subscription.Id, model.GetMillis()); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func (a *App) GetWebhookDelivery(deliveryId string) (*model.WebhookDelivery, *model.AppError) {
+	result := <-a.Srv.Store.WebhookDelivery().Get(deliveryId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.WebhookDelivery), nil
+}
+
+func (a *App) GetWebhookDeliveries(subscriptionId string, status string, page, perPage int) ([]*model.WebhookDelivery, *model.AppError) {
+	result := <-a.Srv.Store.WebhookDelivery().GetBySubscriptionId(subscriptionId, status, page*perPage, perPage)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.([]*model.WebhookDelivery), nil
+}
+
+// ReplayWebhookDelivery sends the stored payload once more, e.g. from the dead-letter log
+// after the integrator has fixed the receiving side.
+func (a *App) ReplayWebhookDelivery(delivery *model.WebhookDelivery) (*model.WebhookDelivery, *model.AppError) {
+	subscription, err := a.GetWebhookSubscription(delivery.SubscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.ResetForReplay()
+	delivery.NextAttemptAt += int64(WEBHOOK_DELIVERY_ATTEMPT_LEASE / time.Millisecond)
+
+	result := <-a.Srv.Store.WebhookDelivery().Update(delivery)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	attempt := *delivery
+	a.Srv.Go(func() {
+		a.attemptWebhookDelivery(subscription, &attempt)
+	})
+
+	return delivery, nil
+}
+
+// TriggerWebhooks queues the event for every active subscription of the application
+// interested in it and makes the first delivery attempt in the background.
+func (a *App) TriggerWebhooks(appId string, event string, data interface{}) {
+	if len(appId) == 0 {
+		return
+	}
+
+	a.Srv.Go(func() {
+		subscriptions, err := a.GetWebhookSubscriptionsForApp(appId)
+		if err != nil {
+			mlog.Error("Failed to get webhook subscriptions", mlog.String("app_id", appId), mlog.Err(err))
+			return
+		}
+
+		for _, subscription := range subscriptions {
+			if !subscription.Active || !subscription.IsSubscribedTo(event) {
+				continue
+			}
+
+			delivery := &model.WebhookDelivery{
+				Id:             model.NewId(),
+				SubscriptionId: subscription.Id,
+				AppId:          appId,
+				Event:          event,
+				NextAttemptAt:  model.GetMillis() + int64(WEBHOOK_DELIVERY_ATTEMPT_LEASE/time.Millisecond),
+			}
+
+			payload := &model.WebhookPayload{
+				Id:       delivery.Id,
+				Event:    event,
+				AppId:    appId,
+				CreateAt: model.GetMillis(),
+				Data:     data,
+			}
+			delivery.Payload = payload.ToJson()
+
+			result := <-a.Srv.Store.WebhookDelivery().Save(delivery)
+			if result.Err != nil {
+				mlog.Error("Failed to save webhook delivery", mlog.String("subscription_id", subscription.Id), mlog.Err(result.Err))
+				continue
+			}
+
+			a.attemptWebhookDelivery(subscription, result.Data.(*model.WebhookDelivery))
+		}
+	})
+}
+
+func (a *App) triggerOrderWebhooks(order *model.Order, event string) {
+	customer, err := a.GetUser(order.UserId)
+	if err != nil {
+		mlog.Error("Failed to get order customer for webhooks", mlog.String("order_id", order.Id), mlog.Err(err))
+		return
+	}
+
+	order = order.Clone()
+	order.Post = nil
+	order.User = nil
+
+	a.TriggerWebhooks(customer.AppId, event, order)
+}
+
+func (a *App) triggerTransactionWebhooks(transaction *model.Transaction, event string) {
+	customer, err := a.GetUser(transaction.UserId)
+	if err != nil {
+		mlog.Error("Failed to get transaction user for webhooks", mlog.String("transaction_id", transaction.Id), mlog.Err(err))
+		return
+	}
+
+	a.TriggerWebhooks(customer.AppId, event, map[string]interface{}{
+		"transaction": transaction,
+		"balance":     customer.Balance,
+	})
+}
+
+func (a *App) attemptWebhookDelivery(subscription *model.WebhookSubscription, delivery *model.WebhookDelivery) {
+	if !subscription.Active || subscription.DeleteAt != 0 {
+		delivery.SetFailed(0, "subscription is inactive")
+	} else if statusCode, err := a.sendWebhookDelivery(subscription, delivery); err != nil {
+		delivery.SetFailed(statusCode, err.Error())
+	} else {
+		delivery.SetSucceeded(statusCode)
+	}
+
+	if result := <-a.Srv.Store.WebhookDelivery().Update(delivery); result.Err != nil {
+		mlog.Error("Failed to update webhook delivery", mlog.String("delivery_id", delivery.Id), mlog.Err(result.Err))
+	}
+
+	if delivery.Status == model.WEBHOOK_DELIVERY_STATUS_DEAD {
+		mlog.Warn("Webhook delivery moved to the dead-letter log",
+			mlog.String("delivery_id", delivery.Id),
+			mlog.String("subscription_id", subscription.Id),
+			mlog.String("error", delivery.LastError))
+	}
+}
+
+// sendWebhookDelivery posts the payload to the subscription url. The request goes through
+// the untrusted http client, so the rules for internal hosts from the config apply.
+func (a *App) sendWebhookDelivery(subscription *model.WebhookSubscription, delivery *model.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := model.GetMillis()
+
+	req, err := http.NewRequest("POST", subscription.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(model.WEBHOOK_HEADER_EVENT, delivery.Event)
+	req.Header.Set(model.WEBHOOK_HEADER_DELIVERY, delivery.Id)
+	req.Header.Set(model.WEBHOOK_HEADER_TIMESTAMP, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(model.WEBHOOK_HEADER_SIGNATURE, subscription.Sign(timestamp, body))
+
+	resp, err := a.HTTPService.MakeClient(false).Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := ioutil.ReadAll(io.LimitReader(resp.Body, WEBHOOK_DELIVERY_RESPONSE_READ_SIZE))
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return resp.StatusCode, nil
+}
+
+// ProcessDueWebhookDeliveries retries the deliveries whose backoff has expired.
+func (a *App) ProcessDueWebhookDeliveries() {
+	result := <-a.Srv.Store.WebhookDelivery().GetDue(model.GetMillis(), WEBHOOK_DELIVERY_BATCH_SIZE)
+	if result.Err != nil {
+		mlog.Error("Failed to get due webhook deliveries", mlog.Err(result.Err))
+		return
+	}
+
+	subscriptions := make(map[string]*model.WebhookSubscription)
+	for _, delivery := range result.Data.([]*model.WebhookDelivery) {
+		subscription, ok := subscriptions[delivery.SubscriptionId]
+		if !ok {
+			r := <-a.Srv.Store.WebhookSubscription().Get(delivery.SubscriptionId)
+			if r.Err != nil {
+				// the subscription was deleted, there is nobody to deliver to
+				delivery.SetDead("subscription not found")
+				<-a.Srv.Store.WebhookDelivery().Update(delivery)
+				continue
+			}
+			subscription = r.Data.(*model.WebhookSubscription)
+			subscriptions[delivery.SubscriptionId] = subscription
+		}
+
+		a.attemptWebhookDelivery(subscription, delivery)
+	}
+}
+
+func runWebhookDeliveryJob(s *Server) {
+	model.CreateRecurringTask("Webhook Delivery", func() {
+		if a := s.FakeApp(); a.IsLeader() {
+			a.ProcessDueWebhookDeliveries()
+		}
+	}, WEBHOOK_DELIVERY_RETRY_INTERVAL)
+}
+
+func runWebhookDeliveryCleanupJob(s *Server) {
+	model.CreateRecurringTask("Webhook Delivery Cleanup", func() {
+		s.Store.WebhookDelivery().PermanentDeleteBefore(model.GetMillis() - int64(WEBHOOK_DELIVERY_RETENTION/time.Millisecond))
+	}, time.Hour*24)
+}
@@ -0,0 +1,16 @@
+package campaigns
+
+import (
+	"im/app"
+	ejobs "im/einterfaces/jobs"
+)
+
+type CampaignJobInterfaceImpl struct {
+	App *app.App
+}
+
+func init() {
+	app.RegisterJobsCampaignInterface(func(a *app.App) ejobs.CampaignJobInterface {
+		return &CampaignJobInterfaceImpl{a}
+	})
+}
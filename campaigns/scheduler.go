@@ -0,0 +1,57 @@
+package campaigns
+
+import (
+	"time"
+
+	"im/app"
+	"im/model"
+)
+
+const (
+	SCHEDULER_NAME           = "CampaignScheduler"
+	SCHEDULER_CHECK_INTERVAL = time.Minute
+)
+
+type Scheduler struct {
+	App *app.App
+}
+
+func (c *CampaignJobInterfaceImpl) MakeScheduler() model.Scheduler {
+	return &Scheduler{c.App}
+}
+
+func (scheduler *Scheduler) Name() string {
+	return SCHEDULER_NAME
+}
+
+func (scheduler *Scheduler) JobType() string {
+	return model.JOB_TYPE_CAMPAIGN
+}
+
+func (scheduler *Scheduler) Enabled(cfg *model.Config) bool {
+	return true
+}
+
+func (scheduler *Scheduler) NextScheduleTime(cfg *model.Config, now time.Time, pendingJobs bool, lastSuccessfulJob *model.Job) *time.Time {
+	nextTime := now.Add(SCHEDULER_CHECK_INTERVAL)
+	return &nextTime
+}
+
+// ScheduleJob creates a job only when a campaign is due, so that an idle installation does
+// not fill the jobs table with a record every minute.
+func (scheduler *Scheduler) ScheduleJob(cfg *model.Config, pendingJobs bool, lastSuccessfulJob *model.Job) (*model.Job, *model.AppError) {
+	if pendingJobs {
+		return nil, nil
+	}
+
+	due, err := scheduler.App.HasDueCampaigns()
+	if err != nil {
+		return nil, err
+	}
+
+	if !due {
+		return nil, nil
+	}
+
+	return scheduler.App.Srv.Jobs.CreateJob(model.JOB_TYPE_CAMPAIGN, nil)
+}
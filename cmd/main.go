@@ -14,7 +14,12 @@ import (
 	"im/web"
 	"im/wsapi"
 
+	_ "im/campaigns"
 	_ "im/impl"
+	_ "im/indexer"
+	_ "im/lifecycle"
+	_ "im/metrics"
+	_ "im/tiers"
 )
 
 const CONFIG_FILE_DNS = "default.json"
@@ -14,9 +14,12 @@ type ElasticsearchInterface interface {
 
 	SearchPostsHint(searchParams []*model.SearchParams, page, perPage int) ([]*model.Post, *model.AppError)
 
+	CreateProductIndex(appId string) *model.AppError
+	DeleteProductIndex(appId string) *model.AppError
 	IndexProduct(product *model.Product, appId string) *model.AppError
-	SearchProductsHint(searchParams []*model.SearchParams, page, perPage int) ([]*model.Product, *model.AppError)
-	DeleteProduct(productId *model.Product) *model.AppError
+	BulkIndexProducts(products []*model.Product, appId string) *model.AppError
+	SearchProducts(appId string, params *model.ProductSearchParams) ([]string, int64, *model.ProductSearchFacets, *model.AppError)
+	DeleteProduct(product *model.Product) *model.AppError
 
 	DeletePost(post *model.Post) *model.AppError
 	IndexChannel(channel *model.Channel) *model.AppError
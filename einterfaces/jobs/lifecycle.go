@@ -0,0 +1,10 @@
+package jobs
+
+import (
+	"im/model"
+)
+
+type LifecycleTriggersJobInterface interface {
+	MakeWorker() model.Worker
+	MakeScheduler() model.Scheduler
+}
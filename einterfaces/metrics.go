@@ -0,0 +1,27 @@
+package einterfaces
+
+type MetricsInterface interface {
+	StartServer()
+	StopServer()
+
+	IncrementOrderCreated(appId string)
+	IncrementOrderPaid(appId string)
+	IncrementOrderCanceled(appId string)
+	IncrementOrderRefunded(appId string)
+
+	ObservePaymentRequest(provider, method string, elapsed float64, failed bool)
+
+	AddBonusAccrued(appId string, value float64)
+	AddBonusDeducted(appId string, value float64)
+
+	IncrementSmsSent(failed bool)
+	IncrementPushSent(failed bool)
+
+	IncrementWebSocketConnections()
+	DecrementWebSocketConnections()
+
+	ObserveJobDuration(jobType string, elapsed float64, failed bool)
+
+	IncrementCacheHit(cacheName string)
+	IncrementCacheMiss(cacheName string)
+}
@@ -11,6 +11,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -188,13 +189,15 @@ func ElasticUsersResponseFromJson(data io.Reader) *ElasticUsersResponse {
 type ElasticsearcInterfaceImpl struct {
 	App        *app.App
 	HttpClient *http.Client
+
+	productIndexes sync.Map
 }
 
 func init() {
 
 	app.RegisterElasticsearchInterface(func(a *app.App) e.ElasticsearchInterface {
 
-		return &ElasticsearcInterfaceImpl{a, nil}
+		return &ElasticsearcInterfaceImpl{App: a}
 	})
 
 }
@@ -232,28 +235,6 @@ func (m *ElasticsearcInterfaceImpl) IndexPost(post *model.Post, teamId string) *
 	return nil
 }
 
-func (m *ElasticsearcInterfaceImpl) IndexProduct(product *model.Product, appId string) *model.AppError {
-
-	st := product.ToJson()
-
-	request, _ := http.NewRequest("PUT", *m.App.Config().ElasticsearchSettings.ConnectionUrl+"/"+*m.App.Config().ElasticsearchSettings.IndexPrefix+"_products"+"/products/"+product.Id, strings.NewReader(st))
-	request.Header.Set("Content-Type", "application/json")
-
-	resp, err := m.App.HTTPService.MakeClient(true).Do(request)
-	if err != nil {
-		return model.NewAppError("SearchElasticsearch", "ent.elasticsearch.search", nil, "", http.StatusBadRequest)
-
-	}
-
-	result, _ := ioutil.ReadAll(resp.Body)
-	if resp.Body != nil {
-		m.App.HTTPService.ConsumeAndClose(resp)
-	}
-
-	fmt.Println(string(result[:]))
-	return nil
-}
-
 func (m *ElasticsearcInterfaceImpl) IndexUser(user *model.User, teamsIds, channelsIds []string) *model.AppError {
 	st := user.ToJson()
 
@@ -429,88 +410,6 @@ func (m *ElasticsearcInterfaceImpl) SearchPostsHint(searchParams []*model.Search
 	return posts, nil
 }
 
-func (m *ElasticsearcInterfaceImpl) SearchProductsHint(searchParams []*model.SearchParams, page, perPage int) ([]*model.Product, *model.AppError) {
-
-	//st := ""//searchParams.ToJson()
-
-	var terms []string
-	for _, param := range searchParams {
-		terms = append(terms, strings.ToLower(param.Terms))
-	}
-
-	term := strings.Join(terms, " ")
-	dsl := Q{
-		Query: Query{
-			Bool: BoolMain{
-				Must: []Must{
-					Must{
-						Bool: Bool{Should: []Should{
-							Should{
-								MultiMatchFuzziness: MultiMatchFuzziness{
-									Query:     term,
-									Fields:    []string{"name", "preview", "description"},
-									Type:      "best_fields",
-									Operator:  "or",
-									Fuzziness: 1,
-								},
-							},
-							Should{
-								MultiMatchFuzziness: MultiMatchFuzziness{
-									Query:    term,
-									Fields:   []string{"name", "preview", "description"},
-									Type:     "phrase_prefix",
-									Operator: "or",
-								},
-							},
-						}, MinimumShouldMatch: "1"},
-					},
-				},
-			},
-		},
-		Highlight: Highlight{PreTags: []string{"<mark>"}, PostTags: []string{"</mark>"}, Fields: Fields{Message: Message{}}},
-		Size:      50,
-		From:      0,
-	}
-
-	query, _ := json.Marshal(dsl)
-	fmt.Println(string(query[:]))
-	request, _ := http.NewRequest("GET", *m.App.Config().ElasticsearchSettings.ConnectionUrl+"/"+*m.App.Config().ElasticsearchSettings.IndexPrefix+"_products"+"/products/_search", strings.NewReader(string(query[:])))
-	request.Header.Set("Content-Type", "application/json")
-
-	resp, err := m.App.HTTPService.MakeClient(true).Do(request)
-	if err != nil {
-		return nil, model.NewAppError("SearchElasticsearch", "ent.elasticsearch.search", nil, "", http.StatusBadRequest)
-
-	}
-
-	var products []*model.Product
-	if resp.Body != nil {
-
-		//htmlData, _ := ioutil.ReadAll(resp.Body) //<--- here!
-
-		//fmt.Println(string(htmlData[:]))
-
-		parsed := ElasticProductsResponseFromJson(resp.Body)
-
-		if parsed != nil {
-
-			if len(parsed.Hits.Hits) > 0 {
-				for _, product := range parsed.Hits.Hits {
-					i := product.Source
-					/*h := post.Highlight
-					i.Message = h.Message[0]*/
-					products = append(products, &i)
-				}
-
-			}
-		}
-
-		m.App.HTTPService.ConsumeAndClose(resp)
-	}
-
-	return products, nil
-}
-
 func (m *ElasticsearcInterfaceImpl) SearchUsersInApp(appId, term string, options *model.UserSearchOptions) ([]string, *model.AppError) {
 
 	/*var term []string
@@ -659,6 +558,11 @@ func PurgeUsersIndexes(m *ElasticsearcInterfaceImpl) *model.AppError {
 }
 
 func PurgeProductsIndexes(m *ElasticsearcInterfaceImpl) *model.AppError {
+	m.productIndexes.Range(func(appId, _ interface{}) bool {
+		m.productIndexes.Delete(appId)
+		return true
+	})
+
 	request, _ := http.NewRequest("DELETE", *m.App.Config().ElasticsearchSettings.ConnectionUrl+"/"+*m.App.Config().ElasticsearchSettings.IndexPrefix+"_products_*", strings.NewReader(""))
 	request.Header.Set("Content-Type", "application/json")
 
@@ -711,19 +615,3 @@ func (m *ElasticsearcInterfaceImpl) PurgeIndexes() *model.AppError {
 func (m *ElasticsearcInterfaceImpl) DataRetentionDeleteIndexes(cutoff time.Time) *model.AppError {
 	return nil
 }
-func (m *ElasticsearcInterfaceImpl) DeleteProduct(product *model.Product) *model.AppError {
-	request, _ := http.NewRequest("DELETE", *m.App.Config().ElasticsearchSettings.ConnectionUrl+"/"+*m.App.Config().ElasticsearchSettings.IndexPrefix+"_products"+"/products/"+product.Id, strings.NewReader(""))
-	request.Header.Set("Content-Type", "application/json")
-
-	resp, err := m.App.HTTPService.MakeClient(true).Do(request)
-	if err != nil {
-		return model.NewAppError("DeleteProductElasticsearch", "ent.elasticsearch.delete.product", nil, "", http.StatusBadRequest)
-
-	}
-	if resp.Body != nil {
-		parsed := ElasticProductsResponseFromJson(resp.Body)
-		fmt.Println(parsed)
-		m.App.HTTPService.ConsumeAndClose(resp)
-	}
-	return nil
-}
@@ -0,0 +1,465 @@
+package impl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"im/mlog"
+	"im/model"
+)
+
+const (
+	PRODUCT_INDEX_TYPE   = "products"
+	PRODUCT_FACETS_LIMIT = 50
+)
+
+// productDocument is what the product index keeps, the offices are denormalized so the
+// availability can be filtered and counted.
+type productDocument struct {
+	Id          string   `json:"id"`
+	AppId       string   `json:"app_id"`
+	Name        string   `json:"name"`
+	Preview     string   `json:"preview"`
+	Description string   `json:"description"`
+	CategoryId  string   `json:"category_id"`
+	Price       float64  `json:"price"`
+	Status      string   `json:"status"`
+	Active      bool     `json:"active"`
+	DeleteAt    int64    `json:"delete_at"`
+	UpdateAt    int64    `json:"update_at"`
+	OfficeIds   []string `json:"office_ids"`
+	Tags        []string `json:"tags"`
+}
+
+type productFacetBucket struct {
+	Key      interface{} `json:"key"`
+	From     *float64    `json:"from"`
+	To       *float64    `json:"to"`
+	DocCount int64       `json:"doc_count"`
+}
+
+type ElasticProductSearchResponse struct {
+	Hits struct {
+		Total int64 `json:"total"`
+		Hits  []struct {
+			ID string `json:"_id"`
+		} `json:"hits"`
+	} `json:"hits"`
+	Aggregations map[string]struct {
+		Values struct {
+			Buckets []productFacetBucket `json:"buckets"`
+		} `json:"values"`
+	} `json:"aggregations"`
+}
+
+type ElasticBulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		ID     string      `json:"_id"`
+		Status int         `json:"status"`
+		Error  interface{} `json:"error"`
+	} `json:"items"`
+}
+
+func (m *ElasticsearcInterfaceImpl) productIndexName(appId string) string {
+	return *m.App.Config().ElasticsearchSettings.IndexPrefix + "_products_" + appId
+}
+
+// doProductRequest sends the request to the cluster and returns the body of the response, the
+// statuses of the cluster errors are returned along with it.
+func (m *ElasticsearcInterfaceImpl) doProductRequest(method, path string, body []byte, contentType string) ([]byte, int, *model.AppError) {
+	request, _ := http.NewRequest(method, *m.App.Config().ElasticsearchSettings.ConnectionUrl+"/"+path, bytes.NewReader(body))
+	request.Header.Set("Content-Type", contentType)
+
+	resp, err := m.App.HTTPService.MakeClient(true).Do(request)
+	if err != nil {
+		return nil, 0, model.NewAppError("ProductElasticsearch", "ent.elasticsearch.request.product", nil, err.Error(), http.StatusInternalServerError)
+	}
+	defer m.App.HTTPService.ConsumeAndClose(resp)
+
+	result, _ := ioutil.ReadAll(resp.Body)
+	return result, resp.StatusCode, nil
+}
+
+// CreateProductIndex creates the product index of the application with the russian analyzer.
+// The synonyms from the config are applied to the search terms only, so they can be changed
+// without reindexing the catalog.
+func (m *ElasticsearcInterfaceImpl) CreateProductIndex(appId string) *model.AppError {
+	settings := m.App.Config().ElasticsearchSettings
+
+	searchFilters := []string{"lowercase", "russian_stop", "russian_stemmer"}
+	filters := map[string]interface{}{
+		"russian_stop":    map[string]interface{}{"type": "stop", "stopwords": "_russian_"},
+		"russian_stemmer": map[string]interface{}{"type": "stemmer", "language": "russian"},
+	}
+
+	if len(settings.ProductSynonyms) > 0 {
+		filters["product_synonyms"] = map[string]interface{}{"type": "synonym_graph", "synonyms": settings.ProductSynonyms}
+		searchFilters = []string{"lowercase", "product_synonyms", "russian_stop", "russian_stemmer"}
+	}
+
+	text := map[string]interface{}{"type": "text", "analyzer": "product_index", "search_analyzer": "product_search"}
+	keyword := map[string]interface{}{"type": "keyword"}
+
+	index := map[string]interface{}{
+		"settings": map[string]interface{}{
+			"number_of_shards":   *settings.ProductIndexShards,
+			"number_of_replicas": *settings.ProductIndexReplicas,
+			"analysis": map[string]interface{}{
+				"filter": filters,
+				"analyzer": map[string]interface{}{
+					"product_index": map[string]interface{}{
+						"tokenizer": "standard",
+						"filter":    []string{"lowercase", "russian_stop", "russian_stemmer"},
+					},
+					"product_search": map[string]interface{}{
+						"tokenizer": "standard",
+						"filter":    searchFilters,
+					},
+				},
+			},
+		},
+		"mappings": map[string]interface{}{
+			PRODUCT_INDEX_TYPE: map[string]interface{}{
+				"properties": map[string]interface{}{
+					"id":          keyword,
+					"app_id":      keyword,
+					"name":        text,
+					"preview":     text,
+					"description": text,
+					"category_id": keyword,
+					"price":       map[string]interface{}{"type": "double"},
+					"status":      keyword,
+					"active":      map[string]interface{}{"type": "boolean"},
+					"delete_at":   map[string]interface{}{"type": "long"},
+					"update_at":   map[string]interface{}{"type": "long"},
+					"office_ids":  keyword,
+					"tags":        map[string]interface{}{"type": "keyword", "fields": map[string]interface{}{"text": text}},
+				},
+			},
+		},
+	}
+
+	body, _ := json.Marshal(index)
+	result, status, err := m.doProductRequest("PUT", m.productIndexName(appId), body, "application/json")
+	if err != nil {
+		return err
+	}
+
+	if status >= 300 && !strings.Contains(string(result), "resource_already_exists_exception") {
+		return model.NewAppError("CreateProductIndex", "ent.elasticsearch.create_index.product", nil, "app_id="+appId+", "+string(result), http.StatusInternalServerError)
+	}
+
+	m.productIndexes.Store(appId, true)
+	return nil
+}
+
+func (m *ElasticsearcInterfaceImpl) DeleteProductIndex(appId string) *model.AppError {
+	result, status, err := m.doProductRequest("DELETE", m.productIndexName(appId), nil, "application/json")
+	if err != nil {
+		return err
+	}
+
+	m.productIndexes.Delete(appId)
+
+	if status >= 300 && status != http.StatusNotFound {
+		return model.NewAppError("DeleteProductIndex", "ent.elasticsearch.delete_index.product", nil, "app_id="+appId+", "+string(result), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// ensureProductIndex creates the index before the first product goes there, otherwise the
+// cluster would make one up without the analyzer.
+func (m *ElasticsearcInterfaceImpl) ensureProductIndex(appId string) *model.AppError {
+	if _, ok := m.productIndexes.Load(appId); ok {
+		return nil
+	}
+
+	return m.CreateProductIndex(appId)
+}
+
+func (m *ElasticsearcInterfaceImpl) productDocument(product *model.Product) *productDocument {
+	offices := product.Offices
+	if offices == nil {
+		var err *model.AppError
+		if offices, err = m.App.GetOfficesForProduct(product.Id); err != nil {
+			mlog.Warn("Failed to get the offices of the product for indexing", mlog.String("product_id", product.Id), mlog.Err(err))
+		}
+	}
+
+	officeIds := make([]string, 0, len(offices))
+	for _, office := range offices {
+		officeIds = append(officeIds, office.Id)
+	}
+
+	tags := []string(product.Tags)
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return &productDocument{
+		Id:          product.Id,
+		AppId:       product.AppId,
+		Name:        product.Name,
+		Preview:     product.Preview,
+		Description: product.Description,
+		CategoryId:  product.CategoryId,
+		Price:       product.Price,
+		Status:      product.Status,
+		Active:      product.Active,
+		DeleteAt:    product.DeleteAt,
+		UpdateAt:    product.UpdateAt,
+		OfficeIds:   officeIds,
+		Tags:        tags,
+	}
+}
+
+func (m *ElasticsearcInterfaceImpl) IndexProduct(product *model.Product, appId string) *model.AppError {
+	if err := m.ensureProductIndex(appId); err != nil {
+		return err
+	}
+
+	body, _ := json.Marshal(m.productDocument(product))
+	result, status, err := m.doProductRequest("PUT", m.productIndexName(appId)+"/"+PRODUCT_INDEX_TYPE+"/"+product.Id, body, "application/json")
+	if err != nil {
+		return err
+	}
+
+	if status >= 300 {
+		return model.NewAppError("IndexProduct", "ent.elasticsearch.index.product", nil, "product_id="+product.Id+", "+string(result), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// BulkIndexProducts sends the products to the index of the application in one request.
+func (m *ElasticsearcInterfaceImpl) BulkIndexProducts(products []*model.Product, appId string) *model.AppError {
+	if len(products) == 0 {
+		return nil
+	}
+
+	if err := m.ensureProductIndex(appId); err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	for _, product := range products {
+		action, _ := json.Marshal(map[string]interface{}{
+			"index": map[string]string{"_index": m.productIndexName(appId), "_type": PRODUCT_INDEX_TYPE, "_id": product.Id},
+		})
+		document, _ := json.Marshal(m.productDocument(product))
+
+		body.Write(action)
+		body.WriteByte('\n')
+		body.Write(document)
+		body.WriteByte('\n')
+	}
+
+	result, status, err := m.doProductRequest("POST", "_bulk", body.Bytes(), "application/x-ndjson")
+	if err != nil {
+		return err
+	}
+
+	if status >= 300 {
+		return model.NewAppError("BulkIndexProducts", "ent.elasticsearch.bulk_index.product", nil, "app_id="+appId+", "+string(result), http.StatusInternalServerError)
+	}
+
+	var response ElasticBulkResponse
+	if jsonErr := json.Unmarshal(result, &response); jsonErr != nil {
+		return model.NewAppError("BulkIndexProducts", "ent.elasticsearch.bulk_index.product", nil, "app_id="+appId+", "+jsonErr.Error(), http.StatusInternalServerError)
+	}
+
+	if response.Errors {
+		failed := 0
+		for _, item := range response.Items {
+			for _, action := range item {
+				if action.Error != nil {
+					failed++
+				}
+			}
+		}
+		return model.NewAppError("BulkIndexProducts", "ent.elasticsearch.bulk_index.product", nil, fmt.Sprintf("app_id=%s, failed=%d", appId, failed), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// productSearchFilters returns the filters of the search by the facet they narrow, so each facet
+// can be counted without its own filter and the customer still sees the other options.
+func productSearchFilters(params *model.ProductSearchParams) map[string][]interface{} {
+	filters := map[string][]interface{}{}
+
+	if len(params.CategoryIds) > 0 {
+		filters["categories"] = append(filters["categories"], map[string]interface{}{"terms": map[string]interface{}{"category_id": params.CategoryIds}})
+	} else if len(params.CategoryId) > 0 {
+		filters["categories"] = append(filters["categories"], map[string]interface{}{"term": map[string]interface{}{"category_id": params.CategoryId}})
+	}
+
+	if params.PriceFrom != nil || params.PriceTo != nil {
+		price := map[string]interface{}{}
+		if params.PriceFrom != nil {
+			price["gte"] = *params.PriceFrom
+		}
+		if params.PriceTo != nil {
+			price["lte"] = *params.PriceTo
+		}
+		filters["prices"] = append(filters["prices"], map[string]interface{}{"range": map[string]interface{}{"price": price}})
+	}
+
+	if len(params.OfficeId) > 0 {
+		filters["offices"] = append(filters["offices"], map[string]interface{}{"term": map[string]interface{}{"office_ids": params.OfficeId}})
+	}
+
+	for _, tag := range params.Tags {
+		filters["tags"] = append(filters["tags"], map[string]interface{}{"term": map[string]interface{}{"tags": tag}})
+	}
+
+	return filters
+}
+
+func productSearchFiltersExcept(filters map[string][]interface{}, facet string) []interface{} {
+	rest := []interface{}{}
+	for name, f := range filters {
+		if name != facet {
+			rest = append(rest, f...)
+		}
+	}
+	return rest
+}
+
+func productPriceRanges() []map[string]interface{} {
+	bounds := model.PRODUCT_SEARCH_PRICE_RANGES
+	ranges := make([]map[string]interface{}, 0, len(bounds))
+	for i, from := range bounds {
+		r := map[string]interface{}{"from": from}
+		if i+1 < len(bounds) {
+			r["to"] = bounds[i+1]
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// SearchProducts returns the ids of the found products in the order of relevance, the total
+// number of them and the facets.
+func (m *ElasticsearcInterfaceImpl) SearchProducts(appId string, params *model.ProductSearchParams) ([]string, int64, *model.ProductSearchFacets, *model.AppError) {
+	must := []interface{}{}
+	if terms := strings.TrimSpace(params.Terms); len(terms) > 0 {
+		must = append(must, map[string]interface{}{
+			"bool": map[string]interface{}{
+				"should": []interface{}{
+					map[string]interface{}{"multi_match": map[string]interface{}{
+						"query":     terms,
+						"fields":    []string{"name^3", "tags.text^2", "preview", "description"},
+						"type":      "best_fields",
+						"operator":  "and",
+						"fuzziness": "AUTO",
+					}},
+					map[string]interface{}{"multi_match": map[string]interface{}{
+						"query":  terms,
+						"fields": []string{"name^3", "preview"},
+						"type":   "phrase_prefix",
+					}},
+				},
+				"minimum_should_match": 1,
+			},
+		})
+	}
+
+	filters := productSearchFilters(params)
+
+	facets := map[string]interface{}{
+		"categories": map[string]interface{}{"terms": map[string]interface{}{"field": "category_id", "size": PRODUCT_FACETS_LIMIT}},
+		"prices":     map[string]interface{}{"range": map[string]interface{}{"field": "price", "ranges": productPriceRanges()}},
+		"offices":    map[string]interface{}{"terms": map[string]interface{}{"field": "office_ids", "size": PRODUCT_FACETS_LIMIT}},
+		"tags":       map[string]interface{}{"terms": map[string]interface{}{"field": "tags", "size": PRODUCT_FACETS_LIMIT}},
+	}
+
+	aggs := map[string]interface{}{}
+	for name, agg := range facets {
+		aggs[name] = map[string]interface{}{
+			"filter": map[string]interface{}{"bool": map[string]interface{}{"filter": productSearchFiltersExcept(filters, name)}},
+			"aggs":   map[string]interface{}{"values": agg},
+		}
+	}
+
+	dsl := map[string]interface{}{
+		"from":    params.Page * params.PerPage,
+		"size":    params.PerPage,
+		"_source": false,
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must": must,
+				"filter": []interface{}{
+					map[string]interface{}{"term": map[string]interface{}{"active": true}},
+					map[string]interface{}{"term": map[string]interface{}{"status": model.PRODUCT_STATUS_ACCEPTED}},
+					map[string]interface{}{"term": map[string]interface{}{"delete_at": 0}},
+				},
+			},
+		},
+		"post_filter": map[string]interface{}{"bool": map[string]interface{}{"filter": productSearchFiltersExcept(filters, "")}},
+		"aggs":        aggs,
+	}
+
+	body, _ := json.Marshal(dsl)
+	result, status, err := m.doProductRequest("POST", m.productIndexName(appId)+"/"+PRODUCT_INDEX_TYPE+"/_search", body, "application/json")
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	if status == http.StatusNotFound {
+		return []string{}, 0, model.NewProductSearchFacets(), nil
+	}
+
+	if status >= 300 {
+		return nil, 0, nil, model.NewAppError("SearchProducts", "ent.elasticsearch.search.product", nil, "app_id="+appId+", "+string(result), http.StatusInternalServerError)
+	}
+
+	var response ElasticProductSearchResponse
+	if jsonErr := json.Unmarshal(result, &response); jsonErr != nil {
+		return nil, 0, nil, model.NewAppError("SearchProducts", "ent.elasticsearch.search.product", nil, "app_id="+appId+", "+jsonErr.Error(), http.StatusInternalServerError)
+	}
+
+	ids := make([]string, 0, len(response.Hits.Hits))
+	for _, hit := range response.Hits.Hits {
+		ids = append(ids, hit.ID)
+	}
+
+	bucketsToFacets := func(name string) []*model.ProductSearchFacet {
+		list := []*model.ProductSearchFacet{}
+		for _, bucket := range response.Aggregations[name].Values.Buckets {
+			list = append(list, &model.ProductSearchFacet{
+				Key:   fmt.Sprint(bucket.Key),
+				From:  bucket.From,
+				To:    bucket.To,
+				Count: bucket.DocCount,
+			})
+		}
+		return list
+	}
+
+	return ids, response.Hits.Total, &model.ProductSearchFacets{
+		Categories: bucketsToFacets("categories"),
+		Prices:     bucketsToFacets("prices"),
+		Offices:    bucketsToFacets("offices"),
+		Tags:       bucketsToFacets("tags"),
+	}, nil
+}
+
+func (m *ElasticsearcInterfaceImpl) DeleteProduct(product *model.Product) *model.AppError {
+	result, status, err := m.doProductRequest("DELETE", m.productIndexName(product.AppId)+"/"+PRODUCT_INDEX_TYPE+"/"+product.Id, nil, "application/json")
+	if err != nil {
+		return err
+	}
+
+	if status >= 300 && status != http.StatusNotFound {
+		return model.NewAppError("DeleteProduct", "ent.elasticsearch.delete.product", nil, "product_id="+product.Id+", "+string(result), http.StatusInternalServerError)
+	}
+
+	return nil
+}
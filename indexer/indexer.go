@@ -0,0 +1,16 @@
+package indexer
+
+import (
+	"im/app"
+	ejobs "im/einterfaces/jobs"
+)
+
+type ElasticsearchIndexerInterfaceImpl struct {
+	App *app.App
+}
+
+func init() {
+	app.RegisterJobsElasticsearchIndexerInterface(func(a *app.App) ejobs.ElasticsearchIndexerInterface {
+		return &ElasticsearchIndexerInterfaceImpl{a}
+	})
+}
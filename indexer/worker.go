@@ -0,0 +1,137 @@
+package indexer
+
+import (
+	"net/http"
+	"strconv"
+
+	"im/app"
+	"im/jobs"
+	"im/mlog"
+	"im/model"
+)
+
+const (
+	WORKER_NAME = "ElasticsearchIndexer"
+)
+
+type Worker struct {
+	name      string
+	stop      chan bool
+	stopped   chan bool
+	jobs      chan model.Job
+	jobServer *jobs.JobServer
+	app       *app.App
+}
+
+func (c *ElasticsearchIndexerInterfaceImpl) MakeWorker() model.Worker {
+	return &Worker{
+		name:      WORKER_NAME,
+		stop:      make(chan bool, 1),
+		stopped:   make(chan bool, 1),
+		jobs:      make(chan model.Job),
+		jobServer: c.App.Srv.Jobs,
+		app:       c.App,
+	}
+}
+
+func (worker *Worker) Run() {
+	mlog.Debug("Worker started", mlog.String("worker", worker.name))
+
+	defer func() {
+		mlog.Debug("Worker finished", mlog.String("worker", worker.name))
+		worker.stopped <- true
+	}()
+
+	for {
+		select {
+		case <-worker.stop:
+			mlog.Debug("Worker received stop signal", mlog.String("worker", worker.name))
+			return
+		case job := <-worker.jobs:
+			mlog.Debug("Worker received a new candidate job.", mlog.String("worker", worker.name))
+			worker.DoJob(&job)
+		}
+	}
+}
+
+func (worker *Worker) Stop() {
+	mlog.Debug("Worker stopping", mlog.String("worker", worker.name))
+	worker.stop <- true
+	<-worker.stopped
+}
+
+func (worker *Worker) JobChannel() chan<- model.Job {
+	return worker.jobs
+}
+
+func (worker *Worker) DoJob(job *model.Job) {
+	if claimed, err := worker.jobServer.ClaimJob(job); err != nil {
+		mlog.Info("Worker experienced an error while trying to claim job",
+			mlog.String("worker", worker.name),
+			mlog.String("job_id", job.Id),
+			mlog.String("error", err.Error()))
+		return
+	} else if !claimed {
+		return
+	}
+
+	switch job.Type {
+	case model.JOB_TYPE_ELASTICSEARCH_PRODUCT_INDEXING:
+		if err := worker.indexProducts(job); err != nil {
+			mlog.Error("Worker: Failed to index products", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+			worker.setJobError(job, err)
+			return
+		}
+	default:
+		worker.setJobError(job, model.NewAppError("DoJob", "indexer.worker.do_job.type.app_error", nil, "type="+job.Type, http.StatusNotImplemented))
+		return
+	}
+
+	mlog.Info("Worker: Job is complete", mlog.String("worker", worker.name), mlog.String("job_id", job.Id))
+	worker.setJobSuccess(job)
+}
+
+// indexProducts rebuilds the product indexes of the application from the job data, or of every
+// application with products. The progress is the share of the applications done.
+func (worker *Worker) indexProducts(job *model.Job) *model.AppError {
+	appIds := []string{job.Data["app_id"]}
+	if len(appIds[0]) == 0 {
+		var err *model.AppError
+		if appIds, err = worker.app.GetProductAppIds(); err != nil {
+			return err
+		}
+	}
+
+	indexed := 0
+	for i, appId := range appIds {
+		count, err := worker.app.ReindexAppProducts(appId)
+		if err != nil {
+			return err
+		}
+		indexed += count
+
+		if job.Data == nil {
+			job.Data = make(map[string]string)
+		}
+		job.Data["indexed_products"] = strconv.Itoa(indexed)
+
+		if err := worker.jobServer.SetJobProgress(job, int64((i+1)*100/len(appIds))); err != nil {
+			mlog.Warn("Worker: Failed to set progress for job", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+		}
+	}
+
+	return nil
+}
+
+func (worker *Worker) setJobSuccess(job *model.Job) {
+	if err := worker.jobServer.SetJobSuccess(job); err != nil {
+		mlog.Error("Worker: Failed to set success for job", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+		worker.setJobError(job, err)
+	}
+}
+
+func (worker *Worker) setJobError(job *model.Job, appError *model.AppError) {
+	if err := worker.jobServer.SetJobError(job, appError); err != nil {
+		mlog.Error("Worker: Failed to set job error", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+	}
+}
@@ -48,6 +48,9 @@ func (srv *JobServer) ClaimJob(job *model.Job) (bool, *model.AppError) {
 		return false, result.Err
 	} else {
 		success := result.Data.(bool)
+		if success {
+			job.StartAt = model.GetMillis()
+		}
 		return success, nil
 	}
 }
@@ -64,11 +67,15 @@ func (srv *JobServer) SetJobProgress(job *model.Job, progress int64) *model.AppE
 }
 
 func (srv *JobServer) SetJobSuccess(job *model.Job) *model.AppError {
+	srv.observeJobDuration(job, false)
+
 	result := <-srv.Store.Job().UpdateStatus(job.Id, model.JOB_STATUS_SUCCESS)
 	return result.Err
 }
 
 func (srv *JobServer) SetJobError(job *model.Job, jobError *model.AppError) *model.AppError {
+	srv.observeJobDuration(job, true)
+
 	if jobError == nil {
 		result := <-srv.Store.Job().UpdateStatus(job.Id, model.JOB_STATUS_ERROR)
 		return result.Err
@@ -170,3 +177,18 @@ func (srv *JobServer) GetLastSuccessfulJobByType(jobType string) (*model.Job, *m
 		return result.Data.(*model.Job), nil
 	}
 }
+
+// observeJobDuration reports how long the job ran, from the moment it was claimed or, for the
+// jobs that were not claimed by this server, from its creation.
+func (srv *JobServer) observeJobDuration(job *model.Job, failed bool) {
+	if srv.Metrics == nil {
+		return
+	}
+
+	startAt := job.StartAt
+	if startAt == 0 {
+		startAt = job.CreateAt
+	}
+
+	srv.Metrics.ObserveJobDuration(job.Type, float64(model.GetMillis()-startAt)/1000, failed)
+}
@@ -90,6 +90,13 @@ func (watcher *Watcher) PollAndNotify() {
 					default:
 					}
 				}
+			} else if job.Type == model.JOB_TYPE_ELASTICSEARCH_PRODUCT_INDEXING {
+				if watcher.workers.ElasticsearchIndexing != nil {
+					select {
+					case watcher.workers.ElasticsearchIndexing.JobChannel() <- *job:
+					default:
+					}
+				}
 			} else if job.Type == model.JOB_TYPE_ELASTICSEARCH_POST_AGGREGATION {
 				if watcher.workers.ElasticsearchAggregation != nil {
 					select {
@@ -118,6 +125,27 @@ func (watcher *Watcher) PollAndNotify() {
 					default:
 					}
 				}
+			} else if job.Type == model.JOB_TYPE_CAMPAIGN {
+				if watcher.workers.Campaign != nil {
+					select {
+					case watcher.workers.Campaign.JobChannel() <- *job:
+					default:
+					}
+				}
+			} else if job.Type == model.JOB_TYPE_LIFECYCLE_TRIGGERS {
+				if watcher.workers.LifecycleTriggers != nil {
+					select {
+					case watcher.workers.LifecycleTriggers.JobChannel() <- *job:
+					default:
+					}
+				}
+			} else if job.Type == model.JOB_TYPE_LOYALTY_TIERS {
+				if watcher.workers.LoyaltyTiers != nil {
+					select {
+					case watcher.workers.LoyaltyTiers.JobChannel() <- *job:
+					default:
+					}
+				}
 			}
 		}
 	}
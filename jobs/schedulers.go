@@ -37,6 +37,18 @@ func (srv *JobServer) InitSchedulers() *Schedulers {
 		schedulers.schedulers = append(schedulers.schedulers, elasticsearchAggregatorInterface.MakeScheduler())
 	}
 
+	if campaignInterface := srv.Campaign; campaignInterface != nil {
+		schedulers.schedulers = append(schedulers.schedulers, campaignInterface.MakeScheduler())
+	}
+
+	if lifecycleTriggersInterface := srv.LifecycleTriggers; lifecycleTriggersInterface != nil {
+		schedulers.schedulers = append(schedulers.schedulers, lifecycleTriggersInterface.MakeScheduler())
+	}
+
+	if loyaltyTiersInterface := srv.LoyaltyTiers; loyaltyTiersInterface != nil {
+		schedulers.schedulers = append(schedulers.schedulers, loyaltyTiersInterface.MakeScheduler())
+	}
+
 	schedulers.nextRunTimes = make([]*time.Time, len(schedulers.schedulers))
 	return schedulers
 }
@@ -1,6 +1,7 @@
 package jobs
 
 import (
+	"im/einterfaces"
 	ejobs "im/einterfaces/jobs"
 	"im/model"
 	"im/services/configservice"
@@ -15,6 +16,11 @@ type JobServer struct {
 
 	ElasticsearchAggregator ejobs.ElasticsearchAggregatorInterface
 	ElasticsearchIndexer    ejobs.ElasticsearchIndexerInterface
+	Campaign                ejobs.CampaignJobInterface
+	LifecycleTriggers       ejobs.LifecycleTriggersJobInterface
+	LoyaltyTiers            ejobs.LoyaltyTiersJobInterface
+
+	Metrics einterfaces.MetricsInterface
 }
 
 func NewJobServer(configService configservice.ConfigService, store store.Store) *JobServer {
@@ -20,6 +20,9 @@ type Workers struct {
 	LdapSync                 model.Worker
 	Migrations               model.Worker
 	Plugins                  model.Worker
+	Campaign                 model.Worker
+	LifecycleTriggers        model.Worker
+	LoyaltyTiers             model.Worker
 
 	listenerId string
 }
@@ -38,6 +41,18 @@ func (srv *JobServer) InitWorkers() *Workers {
 		workers.ElasticsearchAggregation = elasticsearchAggregatorInterface.MakeWorker()
 	}
 
+	if campaignInterface := srv.Campaign; campaignInterface != nil {
+		workers.Campaign = campaignInterface.MakeWorker()
+	}
+
+	if lifecycleTriggersInterface := srv.LifecycleTriggers; lifecycleTriggersInterface != nil {
+		workers.LifecycleTriggers = lifecycleTriggersInterface.MakeWorker()
+	}
+
+	if loyaltyTiersInterface := srv.LoyaltyTiers; loyaltyTiersInterface != nil {
+		workers.LoyaltyTiers = loyaltyTiersInterface.MakeWorker()
+	}
+
 	return workers
 }
 
@@ -65,6 +80,18 @@ func (workers *Workers) Start() *Workers {
 			go workers.Plugins.Run()
 		}
 
+		if workers.Campaign != nil {
+			go workers.Campaign.Run()
+		}
+
+		if workers.LifecycleTriggers != nil {
+			go workers.LifecycleTriggers.Run()
+		}
+
+		if workers.LoyaltyTiers != nil {
+			go workers.LoyaltyTiers.Run()
+		}
+
 		go workers.Watcher.Start()
 	})
 
@@ -127,6 +154,18 @@ func (workers *Workers) Stop() *Workers {
 		workers.Plugins.Stop()
 	}
 
+	if workers.Campaign != nil {
+		workers.Campaign.Stop()
+	}
+
+	if workers.LifecycleTriggers != nil {
+		workers.LifecycleTriggers.Stop()
+	}
+
+	if workers.LoyaltyTiers != nil {
+		workers.LoyaltyTiers.Stop()
+	}
+
 	mlog.Info("Stopped workers")
 
 	return workers
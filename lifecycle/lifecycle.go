@@ -0,0 +1,16 @@
+package lifecycle
+
+import (
+	"im/app"
+	ejobs "im/einterfaces/jobs"
+)
+
+type LifecycleTriggersJobInterfaceImpl struct {
+	App *app.App
+}
+
+func init() {
+	app.RegisterJobsLifecycleTriggersInterface(func(a *app.App) ejobs.LifecycleTriggersJobInterface {
+		return &LifecycleTriggersJobInterfaceImpl{a}
+	})
+}
@@ -0,0 +1,56 @@
+package lifecycle
+
+import (
+	"time"
+
+	"im/app"
+	"im/model"
+)
+
+const (
+	SCHEDULER_NAME           = "LifecycleTriggersScheduler"
+	SCHEDULER_CHECK_INTERVAL = 5 * time.Minute
+)
+
+type Scheduler struct {
+	App *app.App
+}
+
+func (c *LifecycleTriggersJobInterfaceImpl) MakeScheduler() model.Scheduler {
+	return &Scheduler{c.App}
+}
+
+func (scheduler *Scheduler) Name() string {
+	return SCHEDULER_NAME
+}
+
+func (scheduler *Scheduler) JobType() string {
+	return model.JOB_TYPE_LIFECYCLE_TRIGGERS
+}
+
+func (scheduler *Scheduler) Enabled(cfg *model.Config) bool {
+	return true
+}
+
+func (scheduler *Scheduler) NextScheduleTime(cfg *model.Config, now time.Time, pendingJobs bool, lastSuccessfulJob *model.Job) *time.Time {
+	nextTime := now.Add(SCHEDULER_CHECK_INTERVAL)
+	return &nextTime
+}
+
+// ScheduleJob creates a job only when some application has an enabled trigger.
+func (scheduler *Scheduler) ScheduleJob(cfg *model.Config, pendingJobs bool, lastSuccessfulJob *model.Job) (*model.Job, *model.AppError) {
+	if pendingJobs {
+		return nil, nil
+	}
+
+	enabled, err := scheduler.App.HasEnabledLifecycleTriggers()
+	if err != nil {
+		return nil, err
+	}
+
+	if !enabled {
+		return nil, nil
+	}
+
+	return scheduler.App.Srv.Jobs.CreateJob(model.JOB_TYPE_LIFECYCLE_TRIGGERS, nil)
+}
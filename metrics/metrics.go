@@ -0,0 +1,290 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"im/app"
+	"im/einterfaces"
+	"im/mlog"
+)
+
+const (
+	METRICS_NAMESPACE = "im"
+
+	METRICS_SUBSYSTEM_ORDERS    = "orders"
+	METRICS_SUBSYSTEM_PAYMENTS  = "payments"
+	METRICS_SUBSYSTEM_BONUSES   = "bonuses"
+	METRICS_SUBSYSTEM_SMS       = "sms"
+	METRICS_SUBSYSTEM_PUSH      = "push"
+	METRICS_SUBSYSTEM_WEBSOCKET = "websocket"
+	METRICS_SUBSYSTEM_JOBS      = "jobs"
+	METRICS_SUBSYSTEM_CACHE     = "cache"
+
+	METRICS_RESULT_SUCCESS = "success"
+	METRICS_RESULT_FAILURE = "failure"
+
+	METRICS_SERVER_SHUTDOWN_TIMEOUT = 5 * time.Second
+)
+
+type MetricsInterfaceImpl struct {
+	App *app.App
+
+	Registry *prometheus.Registry
+	server   *http.Server
+
+	OrdersCreated  *prometheus.CounterVec
+	OrdersPaid     *prometheus.CounterVec
+	OrdersCanceled *prometheus.CounterVec
+	OrdersRefunded *prometheus.CounterVec
+
+	PaymentRequests        *prometheus.CounterVec
+	PaymentRequestDuration *prometheus.HistogramVec
+
+	BonusesAccrued  *prometheus.CounterVec
+	BonusesDeducted *prometheus.CounterVec
+
+	SmsSent  *prometheus.CounterVec
+	PushSent *prometheus.CounterVec
+
+	WebSocketConnections prometheus.Gauge
+
+	JobDuration *prometheus.HistogramVec
+
+	CacheHits   *prometheus.CounterVec
+	CacheMisses *prometheus.CounterVec
+}
+
+func init() {
+	app.RegisterMetricsInterface(func(a *app.App) einterfaces.MetricsInterface {
+		return NewMetricsInterface(a)
+	})
+}
+
+func NewMetricsInterface(a *app.App) *MetricsInterfaceImpl {
+	m := &MetricsInterfaceImpl{
+		App:      a,
+		Registry: prometheus.NewRegistry(),
+	}
+
+	m.Registry.MustRegister(prometheus.NewGoCollector())
+	m.Registry.MustRegister(prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
+
+	m.OrdersCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: METRICS_NAMESPACE,
+		Subsystem: METRICS_SUBSYSTEM_ORDERS,
+		Name:      "created_total",
+		Help:      "The number of orders created.",
+	}, []string{"app_id"})
+	m.Registry.MustRegister(m.OrdersCreated)
+
+	m.OrdersPaid = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: METRICS_NAMESPACE,
+		Subsystem: METRICS_SUBSYSTEM_ORDERS,
+		Name:      "paid_total",
+		Help:      "The number of orders paid by card.",
+	}, []string{"app_id"})
+	m.Registry.MustRegister(m.OrdersPaid)
+
+	m.OrdersCanceled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: METRICS_NAMESPACE,
+		Subsystem: METRICS_SUBSYSTEM_ORDERS,
+		Name:      "canceled_total",
+		Help:      "The number of orders canceled.",
+	}, []string{"app_id"})
+	m.Registry.MustRegister(m.OrdersCanceled)
+
+	m.OrdersRefunded = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: METRICS_NAMESPACE,
+		Subsystem: METRICS_SUBSYSTEM_ORDERS,
+		Name:      "refunded_total",
+		Help:      "The number of orders refunded.",
+	}, []string{"app_id"})
+	m.Registry.MustRegister(m.OrdersRefunded)
+
+	m.PaymentRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: METRICS_NAMESPACE,
+		Subsystem: METRICS_SUBSYSTEM_PAYMENTS,
+		Name:      "requests_total",
+		Help:      "The number of requests made to the payment providers.",
+	}, []string{"provider", "method", "result"})
+	m.Registry.MustRegister(m.PaymentRequests)
+
+	m.PaymentRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: METRICS_NAMESPACE,
+		Subsystem: METRICS_SUBSYSTEM_PAYMENTS,
+		Name:      "request_duration_seconds",
+		Help:      "The time the payment providers took to answer.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 5, 10, 30},
+	}, []string{"provider", "method"})
+	m.Registry.MustRegister(m.PaymentRequestDuration)
+
+	m.BonusesAccrued = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: METRICS_NAMESPACE,
+		Subsystem: METRICS_SUBSYSTEM_BONUSES,
+		Name:      "accrued_total",
+		Help:      "The bonus points accrued to the customers.",
+	}, []string{"app_id"})
+	m.Registry.MustRegister(m.BonusesAccrued)
+
+	m.BonusesDeducted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: METRICS_NAMESPACE,
+		Subsystem: METRICS_SUBSYSTEM_BONUSES,
+		Name:      "deducted_total",
+		Help:      "The bonus points deducted from the customers.",
+	}, []string{"app_id"})
+	m.Registry.MustRegister(m.BonusesDeducted)
+
+	m.SmsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: METRICS_NAMESPACE,
+		Subsystem: METRICS_SUBSYSTEM_SMS,
+		Name:      "sent_total",
+		Help:      "The number of sms sent to the sms gateway.",
+	}, []string{"result"})
+	m.Registry.MustRegister(m.SmsSent)
+
+	m.PushSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: METRICS_NAMESPACE,
+		Subsystem: METRICS_SUBSYSTEM_PUSH,
+		Name:      "sent_total",
+		Help:      "The number of push notifications sent to the push proxy.",
+	}, []string{"result"})
+	m.Registry.MustRegister(m.PushSent)
+
+	m.WebSocketConnections = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: METRICS_NAMESPACE,
+		Subsystem: METRICS_SUBSYSTEM_WEBSOCKET,
+		Name:      "connections",
+		Help:      "The number of open websocket connections.",
+	})
+	m.Registry.MustRegister(m.WebSocketConnections)
+
+	m.JobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: METRICS_NAMESPACE,
+		Subsystem: METRICS_SUBSYSTEM_JOBS,
+		Name:      "duration_seconds",
+		Help:      "The time the jobs took to run.",
+		Buckets:   []float64{1, 5, 15, 60, 300, 900, 3600},
+	}, []string{"type", "result"})
+	m.Registry.MustRegister(m.JobDuration)
+
+	m.CacheHits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: METRICS_NAMESPACE,
+		Subsystem: METRICS_SUBSYSTEM_CACHE,
+		Name:      "hits_total",
+		Help:      "The number of reads served by the store cache.",
+	}, []string{"name"})
+	m.Registry.MustRegister(m.CacheHits)
+
+	m.CacheMisses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: METRICS_NAMESPACE,
+		Subsystem: METRICS_SUBSYSTEM_CACHE,
+		Name:      "misses_total",
+		Help:      "The number of reads the store cache passed to the database.",
+	}, []string{"name"})
+	m.Registry.MustRegister(m.CacheMisses)
+
+	return m
+}
+
+func (m *MetricsInterfaceImpl) StartServer() {
+	handler := http.NewServeMux()
+	handler.Handle("/metrics", promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{}))
+
+	m.server = &http.Server{
+		Addr:    *m.App.Config().MetricsSettings.ListenAddress,
+		Handler: handler,
+	}
+
+	go func() {
+		if err := m.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			mlog.Error("Metrics server failed", mlog.Err(err))
+		}
+	}()
+
+	mlog.Info("Metrics server is listening", mlog.String("address", m.server.Addr))
+}
+
+func (m *MetricsInterfaceImpl) StopServer() {
+	if m.server == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), METRICS_SERVER_SHUTDOWN_TIMEOUT)
+	defer cancel()
+
+	if err := m.server.Shutdown(ctx); err != nil {
+		mlog.Warn("Failed to stop the metrics server", mlog.Err(err))
+	}
+
+	m.server = nil
+}
+
+func (m *MetricsInterfaceImpl) IncrementOrderCreated(appId string) {
+	m.OrdersCreated.WithLabelValues(appId).Inc()
+}
+
+func (m *MetricsInterfaceImpl) IncrementOrderPaid(appId string) {
+	m.OrdersPaid.WithLabelValues(appId).Inc()
+}
+
+func (m *MetricsInterfaceImpl) IncrementOrderCanceled(appId string) {
+	m.OrdersCanceled.WithLabelValues(appId).Inc()
+}
+
+func (m *MetricsInterfaceImpl) IncrementOrderRefunded(appId string) {
+	m.OrdersRefunded.WithLabelValues(appId).Inc()
+}
+
+func (m *MetricsInterfaceImpl) ObservePaymentRequest(provider, method string, elapsed float64, failed bool) {
+	m.PaymentRequests.WithLabelValues(provider, method, resultLabel(failed)).Inc()
+	m.PaymentRequestDuration.WithLabelValues(provider, method).Observe(elapsed)
+}
+
+func (m *MetricsInterfaceImpl) AddBonusAccrued(appId string, value float64) {
+	m.BonusesAccrued.WithLabelValues(appId).Add(value)
+}
+
+func (m *MetricsInterfaceImpl) AddBonusDeducted(appId string, value float64) {
+	m.BonusesDeducted.WithLabelValues(appId).Add(value)
+}
+
+func (m *MetricsInterfaceImpl) IncrementSmsSent(failed bool) {
+	m.SmsSent.WithLabelValues(resultLabel(failed)).Inc()
+}
+
+func (m *MetricsInterfaceImpl) IncrementPushSent(failed bool) {
+	m.PushSent.WithLabelValues(resultLabel(failed)).Inc()
+}
+
+func (m *MetricsInterfaceImpl) IncrementWebSocketConnections() {
+	m.WebSocketConnections.Inc()
+}
+
+func (m *MetricsInterfaceImpl) DecrementWebSocketConnections() {
+	m.WebSocketConnections.Dec()
+}
+
+func (m *MetricsInterfaceImpl) ObserveJobDuration(jobType string, elapsed float64, failed bool) {
+	m.JobDuration.WithLabelValues(jobType, resultLabel(failed)).Observe(elapsed)
+}
+
+func (m *MetricsInterfaceImpl) IncrementCacheHit(cacheName string) {
+	m.CacheHits.WithLabelValues(cacheName).Inc()
+}
+
+func (m *MetricsInterfaceImpl) IncrementCacheMiss(cacheName string) {
+	m.CacheMisses.WithLabelValues(cacheName).Inc()
+}
+
+func resultLabel(failed bool) string {
+	if failed {
+		return METRICS_RESULT_FAILURE
+	}
+
+	return METRICS_RESULT_SUCCESS
+}
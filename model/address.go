@@ -0,0 +1,229 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	ADDRESS_LABEL_MAX_RUNES   = 64
+	ADDRESS_FIELD_MAX_RUNES   = 255
+	ADDRESS_COMMENT_MAX_RUNES = 1024
+)
+
+type Address struct {
+	Id        string  `json:"id"`
+	UserId    string  `json:"user_id"`
+	Label     string  `json:"label"`
+	City      string  `json:"city"`
+	Street    string  `json:"street"`
+	House     string  `json:"house"`
+	Apartment string  `json:"apartment"`
+	Entrance  string  `json:"entrance"`
+	Floor     string  `json:"floor"`
+	Intercom  string  `json:"intercom"`
+	Comment   string  `json:"comment"`
+	Phone     string  `json:"phone"`
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"long"`
+	CreateAt  int64   `json:"create_at"`
+	UpdateAt  int64   `json:"update_at"`
+	DeleteAt  int64   `json:"delete_at"`
+}
+
+type AddressPatch struct {
+	Label     *string  `json:"label"`
+	City      *string  `json:"city"`
+	Street    *string  `json:"street"`
+	House     *string  `json:"house"`
+	Apartment *string  `json:"apartment"`
+	Entrance  *string  `json:"entrance"`
+	Floor     *string  `json:"floor"`
+	Intercom  *string  `json:"intercom"`
+	Comment   *string  `json:"comment"`
+	Phone     *string  `json:"phone"`
+	Latitude  *float64 `json:"lat"`
+	Longitude *float64 `json:"long"`
+}
+
+func (a *Address) Patch(patch *AddressPatch) {
+	if patch.Label != nil {
+		a.Label = *patch.Label
+	}
+	if patch.City != nil {
+		a.City = *patch.City
+	}
+	if patch.Street != nil {
+		a.Street = *patch.Street
+	}
+	if patch.House != nil {
+		a.House = *patch.House
+	}
+	if patch.Apartment != nil {
+		a.Apartment = *patch.Apartment
+	}
+	if patch.Entrance != nil {
+		a.Entrance = *patch.Entrance
+	}
+	if patch.Floor != nil {
+		a.Floor = *patch.Floor
+	}
+	if patch.Intercom != nil {
+		a.Intercom = *patch.Intercom
+	}
+	if patch.Comment != nil {
+		a.Comment = *patch.Comment
+	}
+	if patch.Phone != nil {
+		a.Phone = *patch.Phone
+	}
+	if patch.Latitude != nil {
+		a.Latitude = *patch.Latitude
+	}
+	if patch.Longitude != nil {
+		a.Longitude = *patch.Longitude
+	}
+}
+
+// HasLocationChanges reports whether the patch moves the address, so the coordinates have to be found again.
+func (patch *AddressPatch) HasLocationChanges() bool {
+	return patch.City != nil || patch.Street != nil || patch.House != nil
+}
+
+func (a *Address) ToJson() string {
+	b, _ := json.Marshal(a)
+	return string(b)
+}
+
+func AddressFromJson(data io.Reader) *Address {
+	var a *Address
+	json.NewDecoder(data).Decode(&a)
+	return a
+}
+
+func AddressPatchFromJson(data io.Reader) *AddressPatch {
+	var patch *AddressPatch
+	json.NewDecoder(data).Decode(&patch)
+	return patch
+}
+
+func AddressesToJson(addresses []*Address) string {
+	b, _ := json.Marshal(addresses)
+	return string(b)
+}
+
+func (a *Address) Clone() *Address {
+	copy := *a
+	return &copy
+}
+
+func (a *Address) PreSave() {
+	if a.Id == "" {
+		a.Id = NewId()
+	}
+
+	if a.CreateAt == 0 {
+		a.CreateAt = GetMillis()
+	}
+
+	a.UpdateAt = a.CreateAt
+	a.trim()
+}
+
+func (a *Address) PreUpdate() {
+	a.UpdateAt = GetMillis()
+	a.trim()
+}
+
+func (a *Address) trim() {
+	a.Label = strings.TrimSpace(a.Label)
+	a.City = strings.TrimSpace(a.City)
+	a.Street = strings.TrimSpace(a.Street)
+	a.House = strings.TrimSpace(a.House)
+	a.Apartment = strings.TrimSpace(a.Apartment)
+	a.Entrance = strings.TrimSpace(a.Entrance)
+	a.Floor = strings.TrimSpace(a.Floor)
+	a.Intercom = strings.TrimSpace(a.Intercom)
+	a.Comment = strings.TrimSpace(a.Comment)
+	a.Phone = strings.TrimSpace(a.Phone)
+}
+
+func (a *Address) IsValid() *AppError {
+	if len(a.Id) != 26 {
+		return NewAppError("Address.IsValid", "model.address.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(a.UserId) != 26 {
+		return NewAppError("Address.IsValid", "model.address.is_valid.user_id.app_error", nil, "id="+a.Id, http.StatusBadRequest)
+	}
+
+	if a.CreateAt == 0 {
+		return NewAppError("Address.IsValid", "model.address.is_valid.create_at.app_error", nil, "id="+a.Id, http.StatusBadRequest)
+	}
+
+	if a.UpdateAt == 0 {
+		return NewAppError("Address.IsValid", "model.address.is_valid.update_at.app_error", nil, "id="+a.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(a.Label) > ADDRESS_LABEL_MAX_RUNES {
+		return NewAppError("Address.IsValid", "model.address.is_valid.label.app_error", nil, "id="+a.Id, http.StatusBadRequest)
+	}
+
+	if len(a.Street) == 0 || len(a.House) == 0 {
+		return NewAppError("Address.IsValid", "model.address.is_valid.street.app_error", nil, "id="+a.Id, http.StatusBadRequest)
+	}
+
+	for _, field := range []string{a.City, a.Street, a.House, a.Apartment, a.Entrance, a.Floor, a.Intercom, a.Phone} {
+		if utf8.RuneCountInString(field) > ADDRESS_FIELD_MAX_RUNES {
+			return NewAppError("Address.IsValid", "model.address.is_valid.field.app_error", nil, "id="+a.Id, http.StatusBadRequest)
+		}
+	}
+
+	if utf8.RuneCountInString(a.Comment) > ADDRESS_COMMENT_MAX_RUNES {
+		return NewAppError("Address.IsValid", "model.address.is_valid.comment.app_error", nil, "id="+a.Id, http.StatusBadRequest)
+	}
+
+	if !IsValidLocation(a.Latitude, a.Longitude) {
+		return NewAppError("Address.IsValid", "model.address.is_valid.location.app_error", nil, "id="+a.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (a *Address) HasLocation() bool {
+	return a.Latitude != 0 || a.Longitude != 0
+}
+
+// GeocodeQuery returns the part of the address that a geocoder can resolve.
+func (a *Address) GeocodeQuery() string {
+	var parts []string
+	for _, part := range []string{a.City, a.Street, a.House} {
+		if len(part) > 0 {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Format returns the address as a single line, the way it is shown in the order.
+func (a *Address) Format() string {
+	parts := []string{a.GeocodeQuery()}
+
+	if len(a.Apartment) > 0 {
+		parts = append(parts, "кв. "+a.Apartment)
+	}
+	if len(a.Entrance) > 0 {
+		parts = append(parts, "подъезд "+a.Entrance)
+	}
+	if len(a.Floor) > 0 {
+		parts = append(parts, "этаж "+a.Floor)
+	}
+	if len(a.Intercom) > 0 {
+		parts = append(parts, "домофон "+a.Intercom)
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -35,6 +35,9 @@ type Application struct {
 	AqType     string `json:"aq_type"`
 	AqUsername string `json:"aq_username"`
 	AqPassword string `json:"aq_password"`
+	// AqMerchant is the merchant login the wallet payments are made for, the api login without
+	// the "-api" suffix when empty.
+	AqMerchant string `json:"aq_merchant"`
 
 	Cash     bool    `json:"cash"`
 	Cashback float64 `json:"cashback"`
@@ -50,6 +53,22 @@ type Application struct {
 
 	SmsLogin  string `json:"sms_login"`
 	SmsApiKey string `json:"sms_api_key"`
+
+	PosType string `json:"pos_type"`
+	PosUrl  string `json:"pos_url"`
+	// PosApiKey authenticates the requests to the POS and the status feedback from it, it is
+	// only shown once by RegeneratePosApiKey.
+	PosApiKey string `json:"-"`
+
+	TierMetric     string `json:"tier_metric"`
+	TierPeriodDays int    `json:"tier_period_days"`
+
+	// ReferralMonthlyCap limits what one inviter may earn from referrals in a calendar month, 0 is no limit.
+	ReferralMonthlyCap float64 `json:"referral_monthly_cap"`
+
+	// FiscalReceipts sends the cart of card payments to the acquirer, which issues the 54-FZ receipts.
+	FiscalReceipts bool `json:"fiscal_receipts"`
+	TaxSystem      int  `json:"tax_system"`
 }
 
 type ApplicationPatch struct {
@@ -65,6 +84,7 @@ type ApplicationPatch struct {
 	AqType         *string  `json:"aq_type"`
 	AqUsername     *string  `json:"aq_username"`
 	AqPassword     *string  `json:"aq_password"`
+	AqMerchant     *string  `json:"aq_merchant"`
 	Cash           *bool    `json:"cash"`
 	Cashback       *float64 `json:"cashback"`
 	HasModeration  *bool    `json:"has_moderation"`
@@ -74,6 +94,15 @@ type ApplicationPatch struct {
 	Password       *string  `json:"password"`
 	SmsLogin       *string  `json:"sms_login"`
 	SmsApiKey      *string  `json:"sms_api_key"`
+	PosType        *string  `json:"pos_type"`
+	PosUrl         *string  `json:"pos_url"`
+	TierMetric     *string  `json:"tier_metric"`
+	TierPeriodDays *int     `json:"tier_period_days"`
+
+	ReferralMonthlyCap *float64 `json:"referral_monthly_cap"`
+
+	FiscalReceipts *bool `json:"fiscal_receipts"`
+	TaxSystem      *int  `json:"tax_system"`
 }
 
 func (p *Application) Patch(patch *ApplicationPatch) {
@@ -114,6 +143,9 @@ func (p *Application) Patch(patch *ApplicationPatch) {
 	if patch.AqPassword != nil {
 		p.AqPassword = *patch.AqPassword
 	}
+	if patch.AqMerchant != nil {
+		p.AqMerchant = *patch.AqMerchant
+	}
 	if patch.Cash != nil {
 		p.Cash = *patch.Cash
 	}
@@ -141,6 +173,27 @@ func (p *Application) Patch(patch *ApplicationPatch) {
 	if patch.SmsApiKey != nil {
 		p.SmsApiKey = *patch.SmsApiKey
 	}
+	if patch.PosType != nil {
+		p.PosType = *patch.PosType
+	}
+	if patch.PosUrl != nil {
+		p.PosUrl = *patch.PosUrl
+	}
+	if patch.TierMetric != nil {
+		p.TierMetric = *patch.TierMetric
+	}
+	if patch.TierPeriodDays != nil {
+		p.TierPeriodDays = *patch.TierPeriodDays
+	}
+	if patch.ReferralMonthlyCap != nil {
+		p.ReferralMonthlyCap = *patch.ReferralMonthlyCap
+	}
+	if patch.FiscalReceipts != nil {
+		p.FiscalReceipts = *patch.FiscalReceipts
+	}
+	if patch.TaxSystem != nil {
+		p.TaxSystem = *patch.TaxSystem
+	}
 }
 
 func (application *Application) ToJson() string {
@@ -201,5 +254,35 @@ func (o *Application) IsValid() *AppError {
 		return NewAppError("Application.IsValid", "model.application.is_valid.update_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
 	}
 
+	if len(o.PosType) > 0 {
+		if o.PosType != POS_PROVIDER_TYPE_HTTP {
+			return NewAppError("Application.IsValid", "model.application.is_valid.pos_type.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+		}
+
+		if !IsValidHttpUrl(o.PosUrl) {
+			return NewAppError("Application.IsValid", "model.application.is_valid.pos_url.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+		}
+	}
+
+	switch o.TierMetric {
+	case "":
+	case LOYALTY_TIER_METRIC_SPEND:
+	case LOYALTY_TIER_METRIC_ORDERS:
+	default:
+		return NewAppError("Application.IsValid", "model.application.is_valid.tier_metric.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.TierPeriodDays < 0 || o.TierPeriodDays > LOYALTY_TIER_MAX_PERIOD_DAYS {
+		return NewAppError("Application.IsValid", "model.application.is_valid.tier_period_days.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.ReferralMonthlyCap < 0 {
+		return NewAppError("Application.IsValid", "model.application.is_valid.referral_monthly_cap.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.TaxSystem < TAX_SYSTEM_COMMON || o.TaxSystem > TAX_SYSTEM_PATENT {
+		return NewAppError("Application.IsValid", "model.application.is_valid.tax_system.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
 	return nil
 }
@@ -0,0 +1,346 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+)
+
+const (
+	APPLICATION_CONFIG_MAX_PERCENT = 100
+	APPLICATION_CONFIG_HISTORY_MAX = 200
+)
+
+// ApplicationConfig is the typed configuration of an application. It is kept as JSON, so a new
+// setting of the applications is a new field here with its default, not a new column and a new
+// field of the patch.
+type ApplicationConfig struct {
+	LoyaltySettings    ApplicationLoyaltySettings    `json:"loyalty_settings"`
+	PaymentSettings    ApplicationPaymentSettings    `json:"payment_settings"`
+	ModerationSettings ApplicationModerationSettings `json:"moderation_settings"`
+	ClientSettings     ApplicationClientSettings     `json:"client_settings"`
+}
+
+type ApplicationLoyaltySettings struct {
+	// MaxDiscount is the share of the price in percents the customers may pay with bonuses.
+	MaxDiscount *int `json:"max_discount"`
+	// Cashback is the share of the price in percents the customers get back as bonuses.
+	Cashback *float64 `json:"cashback"`
+	// RegBonus is what a customer gets for signing up.
+	RegBonus *int `json:"reg_bonus"`
+
+	TierMetric     *string `json:"tier_metric"`
+	TierPeriodDays *int    `json:"tier_period_days"`
+
+	// ReferralMonthlyCap limits what one inviter may earn from referrals in a calendar month, 0 is no limit.
+	ReferralMonthlyCap *float64 `json:"referral_monthly_cap"`
+}
+
+type ApplicationPaymentSettings struct {
+	AcquiringType  *string `json:"acquiring_type"`
+	Cash           *bool   `json:"cash"`
+	FiscalReceipts *bool   `json:"fiscal_receipts"`
+	TaxSystem      *int    `json:"tax_system"`
+}
+
+type ApplicationModerationSettings struct {
+	// Enable holds the products and the promos of the application for a moderation.
+	Enable *bool `json:"enable"`
+}
+
+type ApplicationClientSettings struct {
+	// Settings is the free-form settings string the mobile clients read.
+	Settings *string `json:"settings"`
+}
+
+// ApplicationConfigVersion is a saved version of the configuration of an application. The
+// latest version is the active one.
+type ApplicationConfigVersion struct {
+	Id        string             `json:"id"`
+	AppId     string             `json:"app_id"`
+	Version   int64              `json:"version"`
+	Config    *ApplicationConfig `json:"config"`
+	CreatorId string             `json:"creator_id"`
+	CreateAt  int64              `json:"create_at"`
+}
+
+// ApplicationConfigChange is a setting that differs between two configurations, the path is
+// the dotted path of its JSON keys.
+type ApplicationConfigChange struct {
+	Path     string      `json:"path"`
+	OldValue interface{} `json:"old_value"`
+	NewValue interface{} `json:"new_value"`
+}
+
+func (s *ApplicationLoyaltySettings) SetDefaults() {
+	if s.MaxDiscount == nil {
+		s.MaxDiscount = NewInt(0)
+	}
+
+	if s.Cashback == nil {
+		s.Cashback = NewFloat64(0)
+	}
+
+	if s.RegBonus == nil {
+		s.RegBonus = NewInt(0)
+	}
+
+	if s.TierMetric == nil {
+		s.TierMetric = NewString("")
+	}
+
+	if s.TierPeriodDays == nil {
+		s.TierPeriodDays = NewInt(0)
+	}
+
+	if s.ReferralMonthlyCap == nil {
+		s.ReferralMonthlyCap = NewFloat64(0)
+	}
+}
+
+func (s *ApplicationPaymentSettings) SetDefaults() {
+	if s.AcquiringType == nil {
+		s.AcquiringType = NewString("")
+	}
+
+	if s.Cash == nil {
+		s.Cash = NewBool(false)
+	}
+
+	if s.FiscalReceipts == nil {
+		s.FiscalReceipts = NewBool(false)
+	}
+
+	if s.TaxSystem == nil {
+		s.TaxSystem = NewInt(TAX_SYSTEM_COMMON)
+	}
+}
+
+func (s *ApplicationModerationSettings) SetDefaults() {
+	if s.Enable == nil {
+		s.Enable = NewBool(false)
+	}
+}
+
+func (s *ApplicationClientSettings) SetDefaults() {
+	if s.Settings == nil {
+		s.Settings = NewString("")
+	}
+}
+
+func (c *ApplicationConfig) SetDefaults() {
+	c.LoyaltySettings.SetDefaults()
+	c.PaymentSettings.SetDefaults()
+	c.ModerationSettings.SetDefaults()
+	c.ClientSettings.SetDefaults()
+}
+
+func (c *ApplicationConfig) IsValid() *AppError {
+	loyalty := c.LoyaltySettings
+	if *loyalty.MaxDiscount < 0 || *loyalty.MaxDiscount > APPLICATION_CONFIG_MAX_PERCENT {
+		return NewAppError("ApplicationConfig.IsValid", "model.application_config.is_valid.max_discount.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *loyalty.Cashback < 0 || *loyalty.Cashback > APPLICATION_CONFIG_MAX_PERCENT {
+		return NewAppError("ApplicationConfig.IsValid", "model.application_config.is_valid.cashback.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *loyalty.RegBonus < 0 {
+		return NewAppError("ApplicationConfig.IsValid", "model.application_config.is_valid.reg_bonus.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	switch *loyalty.TierMetric {
+	case "", LOYALTY_TIER_METRIC_SPEND, LOYALTY_TIER_METRIC_ORDERS:
+	default:
+		return NewAppError("ApplicationConfig.IsValid", "model.application_config.is_valid.tier_metric.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *loyalty.TierPeriodDays < 0 || *loyalty.TierPeriodDays > LOYALTY_TIER_MAX_PERIOD_DAYS {
+		return NewAppError("ApplicationConfig.IsValid", "model.application_config.is_valid.tier_period_days.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *loyalty.ReferralMonthlyCap < 0 {
+		return NewAppError("ApplicationConfig.IsValid", "model.application_config.is_valid.referral_monthly_cap.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	payment := c.PaymentSettings
+	switch *payment.AcquiringType {
+	case "", SBERBANK_AQUIRING_TYPE, ALFABANK_AQUIRING_TYPE:
+	default:
+		return NewAppError("ApplicationConfig.IsValid", "model.application_config.is_valid.acquiring_type.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *payment.TaxSystem < TAX_SYSTEM_COMMON || *payment.TaxSystem > TAX_SYSTEM_PATENT {
+		return NewAppError("ApplicationConfig.IsValid", "model.application_config.is_valid.tax_system.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// ApplicationConfigFromApplication makes the configuration out of the columns of an application
+// that has none saved yet.
+func ApplicationConfigFromApplication(application *Application) *ApplicationConfig {
+	c := &ApplicationConfig{}
+	c.LoadFromApplication(application)
+	c.SetDefaults()
+
+	return c
+}
+
+// LoadFromApplication takes the settings the application still keeps in its own columns, the
+// settings that live in the configuration only are left as they are.
+func (c *ApplicationConfig) LoadFromApplication(application *Application) {
+	c.LoyaltySettings.MaxDiscount = NewInt(application.MaxDiscount)
+	c.LoyaltySettings.Cashback = NewFloat64(application.Cashback)
+	c.LoyaltySettings.RegBonus = NewInt(application.RegBonus)
+	c.LoyaltySettings.TierMetric = NewString(application.TierMetric)
+	c.LoyaltySettings.TierPeriodDays = NewInt(application.TierPeriodDays)
+	c.LoyaltySettings.ReferralMonthlyCap = NewFloat64(application.ReferralMonthlyCap)
+	c.PaymentSettings.AcquiringType = NewString(application.AqType)
+	c.PaymentSettings.Cash = NewBool(application.Cash)
+	c.PaymentSettings.FiscalReceipts = NewBool(application.FiscalReceipts)
+	c.PaymentSettings.TaxSystem = NewInt(application.TaxSystem)
+	c.ModerationSettings.Enable = NewBool(application.HasModeration)
+	c.ClientSettings.Settings = NewString(application.Settings)
+}
+
+// ApplyToApplication copies the settings the application still keeps in its own columns, so
+// the code reading them sees the active configuration.
+func (c *ApplicationConfig) ApplyToApplication(application *Application) {
+	application.MaxDiscount = *c.LoyaltySettings.MaxDiscount
+	application.Cashback = *c.LoyaltySettings.Cashback
+	application.RegBonus = *c.LoyaltySettings.RegBonus
+	application.TierMetric = *c.LoyaltySettings.TierMetric
+	application.TierPeriodDays = *c.LoyaltySettings.TierPeriodDays
+	application.ReferralMonthlyCap = *c.LoyaltySettings.ReferralMonthlyCap
+	application.AqType = *c.PaymentSettings.AcquiringType
+	application.Cash = *c.PaymentSettings.Cash
+	application.FiscalReceipts = *c.PaymentSettings.FiscalReceipts
+	application.TaxSystem = *c.PaymentSettings.TaxSystem
+	application.HasModeration = *c.ModerationSettings.Enable
+	application.Settings = *c.ClientSettings.Settings
+}
+
+func (c *ApplicationConfig) Clone() *ApplicationConfig {
+	var ret ApplicationConfig
+	if err := json.Unmarshal([]byte(c.ToJson()), &ret); err != nil {
+		panic(err)
+	}
+	return &ret
+}
+
+// DiffApplicationConfigs lists the settings that differ between the configurations, sorted by
+// their paths. It walks the JSON of the configurations, so the settings added later are
+// compared too.
+func DiffApplicationConfigs(oldConfig *ApplicationConfig, newConfig *ApplicationConfig) []*ApplicationConfigChange {
+	oldValues := flattenConfigJson(oldConfig.ToJson())
+	newValues := flattenConfigJson(newConfig.ToJson())
+
+	paths := make(map[string]bool, len(oldValues)+len(newValues))
+	for path := range oldValues {
+		paths[path] = true
+	}
+	for path := range newValues {
+		paths[path] = true
+	}
+
+	changes := []*ApplicationConfigChange{}
+	for path := range paths {
+		if !reflect.DeepEqual(oldValues[path], newValues[path]) {
+			changes = append(changes, &ApplicationConfigChange{
+				Path:     path,
+				OldValue: oldValues[path],
+				NewValue: newValues[path],
+			})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+
+	return changes
+}
+
+// flattenConfigJson maps the dotted paths of the values of a JSON object to the values.
+func flattenConfigJson(data string) map[string]interface{} {
+	var object map[string]interface{}
+	json.Unmarshal([]byte(data), &object)
+
+	values := make(map[string]interface{})
+	var flatten func(prefix string, value interface{})
+	flatten = func(prefix string, value interface{}) {
+		if fields, ok := value.(map[string]interface{}); ok {
+			for key, child := range fields {
+				if prefix == "" {
+					flatten(key, child)
+				} else {
+					flatten(fmt.Sprintf("%s.%s", prefix, key), child)
+				}
+			}
+			return
+		}
+		values[prefix] = value
+	}
+	flatten("", object)
+
+	return values
+}
+
+func (c *ApplicationConfig) ToJson() string {
+	b, _ := json.Marshal(c)
+	return string(b)
+}
+
+func ApplicationConfigFromJson(data io.Reader) *ApplicationConfig {
+	var c *ApplicationConfig
+	json.NewDecoder(data).Decode(&c)
+	return c
+}
+
+func (v *ApplicationConfigVersion) PreSave() {
+	if v.Id == "" {
+		v.Id = NewId()
+	}
+
+	v.CreateAt = GetMillis()
+}
+
+func (v *ApplicationConfigVersion) IsValid() *AppError {
+	if len(v.Id) != 26 {
+		return NewAppError("ApplicationConfigVersion.IsValid", "model.application_config_version.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(v.AppId) != 26 {
+		return NewAppError("ApplicationConfigVersion.IsValid", "model.application_config_version.is_valid.app_id.app_error", nil, "id="+v.Id, http.StatusBadRequest)
+	}
+
+	if len(v.CreatorId) > 0 && len(v.CreatorId) != 26 {
+		return NewAppError("ApplicationConfigVersion.IsValid", "model.application_config_version.is_valid.creator_id.app_error", nil, "id="+v.Id, http.StatusBadRequest)
+	}
+
+	if v.Config == nil {
+		return NewAppError("ApplicationConfigVersion.IsValid", "model.application_config_version.is_valid.config.app_error", nil, "id="+v.Id, http.StatusBadRequest)
+	}
+
+	if v.CreateAt == 0 {
+		return NewAppError("ApplicationConfigVersion.IsValid", "model.application_config_version.is_valid.create_at.app_error", nil, "id="+v.Id, http.StatusBadRequest)
+	}
+
+	return v.Config.IsValid()
+}
+
+func (v *ApplicationConfigVersion) ToJson() string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func ApplicationConfigVersionListToJson(list []*ApplicationConfigVersion) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func ApplicationConfigChangeListToJson(list []*ApplicationConfigChange) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
@@ -6,6 +6,11 @@ import (
 	"net/http"
 )
 
+const (
+	BASKET_COMMENT_MAX_LENGTH  = 500
+	BASKET_MODIFIERS_MAX_COUNT = 20
+)
+
 type Basket struct {
 	Id            string  `json:"id"`
 	OrderId       string  `json:"order_id"`
@@ -27,6 +32,10 @@ type Basket struct {
 	DeleteAt int64 `json:"delete_at"`
 
 	Cashback float64 `json:"cashback"`
+
+	// Modifiers are the free text options of the position, like "no onion", the kitchen sees them on the ticket.
+	Modifiers StringArray `json:"modifiers,omitempty"`
+	Comment   string      `json:"comment,omitempty"`
 }
 
 type BasketPatch struct {
@@ -82,7 +91,9 @@ func (o *Basket) Fil(order *Order) {
 }
 
 func (o *Basket) MakeNonNil() {
-
+	if o.Modifiers == nil {
+		o.Modifiers = StringArray{}
+	}
 }
 
 func (o *Basket) IsValid() *AppError {
@@ -99,5 +110,9 @@ func (o *Basket) IsValid() *AppError {
 		return NewAppError("Basket.IsValid", "model.basket.is_valid.update_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
 	}
 
+	if len(o.Comment) > BASKET_COMMENT_MAX_LENGTH || len(o.Modifiers) > BASKET_MODIFIERS_MAX_COUNT {
+		return NewAppError("Basket.IsValid", "model.basket.is_valid.comment.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
 	return nil
 }
@@ -1,6 +1,7 @@
 package model
 
-func NewBool(b bool) *bool       { return &b }
-func NewInt(n int) *int          { return &n }
-func NewInt64(n int64) *int64    { return &n }
-func NewString(s string) *string { return &s }
+func NewBool(b bool) *bool          { return &b }
+func NewInt(n int) *int             { return &n }
+func NewInt64(n int64) *int64       { return &n }
+func NewFloat64(f float64) *float64 { return &f }
+func NewString(s string) *string    { return &s }
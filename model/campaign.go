@@ -0,0 +1,310 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	CAMPAIGN_STATUS_DRAFT     = "draft"
+	CAMPAIGN_STATUS_SCHEDULED = "scheduled"
+	CAMPAIGN_STATUS_SENDING   = "sending"
+	CAMPAIGN_STATUS_COMPLETED = "completed"
+	CAMPAIGN_STATUS_CANCELED  = "canceled"
+
+	CAMPAIGN_RECIPIENT_STATUS_PENDING = "pending"
+	CAMPAIGN_RECIPIENT_STATUS_SENT    = "sent"
+	CAMPAIGN_RECIPIENT_STATUS_FAILED  = "failed"
+	CAMPAIGN_RECIPIENT_STATUS_SKIPPED = "skipped"
+
+	CAMPAIGN_NAME_MAX_RUNES    = 128
+	CAMPAIGN_MESSAGE_MAX_RUNES = 1024
+	CAMPAIGN_ERROR_MAX_SIZE    = 255
+
+	CAMPAIGN_DEFAULT_BATCH_SIZE     = 100
+	CAMPAIGN_MAX_BATCH_SIZE         = 1000
+	CAMPAIGN_DEFAULT_BATCH_INTERVAL = 1000 // milliseconds between two batches
+	CAMPAIGN_MAX_BATCH_INTERVAL     = 60000
+
+	CAMPAIGN_PUSH_TYPE = "campaign"
+)
+
+// CampaignSegment selects the customers of the application a campaign is sent to.
+// Empty fields do not restrict the audience.
+type CampaignSegment struct {
+	LastOrderAfter  int64    `json:"last_order_after,omitempty"`
+	LastOrderBefore int64    `json:"last_order_before,omitempty"`
+	MinOrderCount   *int     `json:"min_order_count,omitempty"`
+	MaxOrderCount   *int     `json:"max_order_count,omitempty"`
+	MinOrderSum     *float64 `json:"min_order_sum,omitempty"`
+	MaxOrderSum     *float64 `json:"max_order_sum,omitempty"`
+	MinBalance      *float64 `json:"min_balance,omitempty"`
+	MaxBalance      *float64 `json:"max_balance,omitempty"`
+	OfficeIds       []string `json:"office_ids,omitempty"`
+	// Referred keeps only the customers who came (true) or did not come (false) through a referral link.
+	Referred *bool `json:"referred,omitempty"`
+	// MinReferrals keeps only the customers who have invited at least that many others.
+	MinReferrals *int `json:"min_referrals,omitempty"`
+	// AppInstalled keeps only the customers with (true) or without (false) a mobile device session.
+	AppInstalled *bool `json:"app_installed,omitempty"`
+}
+
+func (s *CampaignSegment) ToJson() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func CampaignSegmentFromJson(data io.Reader) *CampaignSegment {
+	var s *CampaignSegment
+	json.NewDecoder(data).Decode(&s)
+	return s
+}
+
+func (s *CampaignSegment) IsValid() *AppError {
+	if s.LastOrderAfter < 0 || s.LastOrderBefore < 0 || (s.LastOrderBefore > 0 && s.LastOrderAfter > s.LastOrderBefore) {
+		return NewAppError("CampaignSegment.IsValid", "model.campaign_segment.is_valid.last_order.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if s.MinOrderCount != nil && s.MaxOrderCount != nil && *s.MinOrderCount > *s.MaxOrderCount {
+		return NewAppError("CampaignSegment.IsValid", "model.campaign_segment.is_valid.order_count.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if s.MinOrderSum != nil && s.MaxOrderSum != nil && *s.MinOrderSum > *s.MaxOrderSum {
+		return NewAppError("CampaignSegment.IsValid", "model.campaign_segment.is_valid.order_sum.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if s.MinBalance != nil && s.MaxBalance != nil && *s.MinBalance > *s.MaxBalance {
+		return NewAppError("CampaignSegment.IsValid", "model.campaign_segment.is_valid.balance.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	for _, officeId := range s.OfficeIds {
+		if len(officeId) != 26 {
+			return NewAppError("CampaignSegment.IsValid", "model.campaign_segment.is_valid.office_id.app_error", nil, "office_id="+officeId, http.StatusBadRequest)
+		}
+	}
+
+	return nil
+}
+
+type Campaign struct {
+	Id            string           `json:"id"`
+	AppId         string           `json:"app_id"`
+	CreatorId     string           `json:"creator_id"`
+	Name          string           `json:"name"`
+	Message       string           `json:"message"`
+	PromoId       string           `json:"promo_id"`
+	Segment       *CampaignSegment `json:"segment"`
+	Status        string           `json:"status"`
+	SendAt        int64            `json:"send_at"`
+	BatchSize     int              `json:"batch_size"`
+	BatchInterval int64            `json:"batch_interval"`
+	Total         int64            `json:"total"`
+	StartedAt     int64            `json:"started_at"`
+	CompletedAt   int64            `json:"completed_at"`
+	CreateAt      int64            `json:"create_at"`
+	UpdateAt      int64            `json:"update_at"`
+	DeleteAt      int64            `json:"delete_at"`
+
+	Stats *CampaignStats `db:"-" json:"stats,omitempty"`
+}
+
+type CampaignPatch struct {
+	Name          *string          `json:"name"`
+	Message       *string          `json:"message"`
+	PromoId       *string          `json:"promo_id"`
+	Segment       *CampaignSegment `json:"segment"`
+	SendAt        *int64           `json:"send_at"`
+	BatchSize     *int             `json:"batch_size"`
+	BatchInterval *int64           `json:"batch_interval"`
+}
+
+func (c *Campaign) Patch(patch *CampaignPatch) {
+	if patch.Name != nil {
+		c.Name = *patch.Name
+	}
+	if patch.Message != nil {
+		c.Message = *patch.Message
+	}
+	if patch.PromoId != nil {
+		c.PromoId = *patch.PromoId
+	}
+	if patch.Segment != nil {
+		c.Segment = patch.Segment
+	}
+	if patch.SendAt != nil {
+		c.SendAt = *patch.SendAt
+	}
+	if patch.BatchSize != nil {
+		c.BatchSize = *patch.BatchSize
+	}
+	if patch.BatchInterval != nil {
+		c.BatchInterval = *patch.BatchInterval
+	}
+}
+
+func (c *Campaign) ToJson() string {
+	b, _ := json.Marshal(c)
+	return string(b)
+}
+
+func CampaignFromJson(data io.Reader) *Campaign {
+	var c *Campaign
+	json.NewDecoder(data).Decode(&c)
+	return c
+}
+
+func CampaignPatchFromJson(data io.Reader) *CampaignPatch {
+	var patch *CampaignPatch
+	json.NewDecoder(data).Decode(&patch)
+	return patch
+}
+
+func CampaignListToJson(list []*Campaign) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func (c *Campaign) Clone() *Campaign {
+	copy := *c
+	return &copy
+}
+
+func (c *Campaign) PreSave() {
+	if c.Id == "" {
+		c.Id = NewId()
+	}
+
+	if c.Status == "" {
+		c.Status = CAMPAIGN_STATUS_DRAFT
+	}
+
+	if c.Segment == nil {
+		c.Segment = &CampaignSegment{}
+	}
+
+	if c.BatchSize == 0 {
+		c.BatchSize = CAMPAIGN_DEFAULT_BATCH_SIZE
+	}
+
+	if c.BatchInterval == 0 {
+		c.BatchInterval = CAMPAIGN_DEFAULT_BATCH_INTERVAL
+	}
+
+	c.CreateAt = GetMillis()
+	c.UpdateAt = c.CreateAt
+}
+
+func (c *Campaign) PreUpdate() {
+	c.UpdateAt = GetMillis()
+}
+
+// IsEditable reports whether the content and the audience of the campaign may still be changed.
+func (c *Campaign) IsEditable() bool {
+	return c.Status == CAMPAIGN_STATUS_DRAFT || c.Status == CAMPAIGN_STATUS_SCHEDULED
+}
+
+func (c *Campaign) IsValid() *AppError {
+	if len(c.Id) != 26 {
+		return NewAppError("Campaign.IsValid", "model.campaign.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(c.AppId) != 26 {
+		return NewAppError("Campaign.IsValid", "model.campaign.is_valid.app_id.app_error", nil, "id="+c.Id, http.StatusBadRequest)
+	}
+
+	if len(c.CreatorId) != 26 {
+		return NewAppError("Campaign.IsValid", "model.campaign.is_valid.creator_id.app_error", nil, "id="+c.Id, http.StatusBadRequest)
+	}
+
+	if len(c.Name) == 0 || utf8.RuneCountInString(c.Name) > CAMPAIGN_NAME_MAX_RUNES {
+		return NewAppError("Campaign.IsValid", "model.campaign.is_valid.name.app_error", nil, "id="+c.Id, http.StatusBadRequest)
+	}
+
+	if len(c.Message) == 0 || utf8.RuneCountInString(c.Message) > CAMPAIGN_MESSAGE_MAX_RUNES {
+		return NewAppError("Campaign.IsValid", "model.campaign.is_valid.message.app_error", nil, "id="+c.Id, http.StatusBadRequest)
+	}
+
+	if len(c.PromoId) > 0 && len(c.PromoId) != 26 {
+		return NewAppError("Campaign.IsValid", "model.campaign.is_valid.promo_id.app_error", nil, "id="+c.Id, http.StatusBadRequest)
+	}
+
+	if c.Segment != nil {
+		if err := c.Segment.IsValid(); err != nil {
+			return err
+		}
+	}
+
+	switch c.Status {
+	case CAMPAIGN_STATUS_DRAFT:
+	case CAMPAIGN_STATUS_SCHEDULED:
+	case CAMPAIGN_STATUS_SENDING:
+	case CAMPAIGN_STATUS_COMPLETED:
+	case CAMPAIGN_STATUS_CANCELED:
+	default:
+		return NewAppError("Campaign.IsValid", "model.campaign.is_valid.status.app_error", nil, "id="+c.Id, http.StatusBadRequest)
+	}
+
+	if c.BatchSize < 1 || c.BatchSize > CAMPAIGN_MAX_BATCH_SIZE {
+		return NewAppError("Campaign.IsValid", "model.campaign.is_valid.batch_size.app_error", nil, "id="+c.Id, http.StatusBadRequest)
+	}
+
+	if c.BatchInterval < 0 || c.BatchInterval > CAMPAIGN_MAX_BATCH_INTERVAL {
+		return NewAppError("Campaign.IsValid", "model.campaign.is_valid.batch_interval.app_error", nil, "id="+c.Id, http.StatusBadRequest)
+	}
+
+	if c.CreateAt == 0 {
+		return NewAppError("Campaign.IsValid", "model.campaign.is_valid.create_at.app_error", nil, "id="+c.Id, http.StatusBadRequest)
+	}
+
+	if c.UpdateAt == 0 {
+		return NewAppError("Campaign.IsValid", "model.campaign.is_valid.update_at.app_error", nil, "id="+c.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// CampaignRecipient is the delivery result of the campaign for one customer.
+type CampaignRecipient struct {
+	CampaignId string `json:"campaign_id"`
+	UserId     string `json:"user_id"`
+	Status     string `json:"status"`
+	Error      string `json:"error"`
+	SentAt     int64  `json:"sent_at"`
+	OpenedAt   int64  `json:"opened_at"`
+	CreateAt   int64  `json:"create_at"`
+}
+
+func CampaignRecipientListToJson(list []*CampaignRecipient) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func (r *CampaignRecipient) SetSent() {
+	r.Status = CAMPAIGN_RECIPIENT_STATUS_SENT
+	r.Error = ""
+	r.SentAt = GetMillis()
+}
+
+func (r *CampaignRecipient) SetFailed(status string, errMsg string) {
+	if len(errMsg) > CAMPAIGN_ERROR_MAX_SIZE {
+		errMsg = errMsg[:CAMPAIGN_ERROR_MAX_SIZE]
+	}
+	r.Status = status
+	r.Error = errMsg
+}
+
+type CampaignStats struct {
+	Total   int64 `json:"total"`
+	Pending int64 `json:"pending"`
+	Sent    int64 `json:"sent"`
+	Failed  int64 `json:"failed"`
+	Skipped int64 `json:"skipped"`
+	Opened  int64 `json:"opened"`
+}
+
+func (s *CampaignStats) ToJson() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+)
+
+// CardBinding is a card the customer saved with the acquirer, later orders are paid with it
+// without the hosted payment page. BindingId is the id of the acquirer and never leaves the server.
+type CardBinding struct {
+	Id          string `json:"id"`
+	UserId      string `json:"user_id"`
+	PaySystemId string `json:"pay_system_id"`
+	BindingId   string `json:"-"`
+	MaskedPan   string `json:"masked_pan"`
+	// ExpiryDate is the year and the month the card expires in, as YYYYMM.
+	ExpiryDate string `json:"expiry_date"`
+	CreateAt   int64  `json:"create_at"`
+	UpdateAt   int64  `json:"update_at"`
+	VerifiedAt int64  `json:"verified_at"`
+	DeleteAt   int64  `json:"delete_at"`
+}
+
+// CardBindingPayment is what is left to do to pay an order with a bound card. It is empty when
+// the payment went through, otherwise the client follows the 3-D Secure page of the card issuer.
+type CardBindingPayment struct {
+	Order   *Order `json:"order,omitempty"`
+	AcsUrl  string `json:"acs_url,omitempty"`
+	PaReq   string `json:"pa_req,omitempty"`
+	TermUrl string `json:"term_url,omitempty"`
+}
+
+type CardBindingPaymentRequest struct {
+	BindingId string `json:"binding_id"`
+	Cvc       string `json:"cvc"`
+}
+
+func (b *CardBinding) ToJson() string {
+	b2, _ := json.Marshal(b)
+	return string(b2)
+}
+
+func CardBindingListToJson(list []*CardBinding) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func (p *CardBindingPayment) ToJson() string {
+	b, _ := json.Marshal(p)
+	return string(b)
+}
+
+func CardBindingPaymentRequestFromJson(data io.Reader) *CardBindingPaymentRequest {
+	var o *CardBindingPaymentRequest
+	json.NewDecoder(data).Decode(&o)
+	return o
+}
+
+func (b *CardBinding) PreSave() {
+	if b.Id == "" {
+		b.Id = NewId()
+	}
+
+	b.CreateAt = GetMillis()
+	b.UpdateAt = b.CreateAt
+}
+
+// IsExpired tells whether the card can no longer be charged, cards are valid through the last
+// day of their expiry month.
+func (b *CardBinding) IsExpired(now time.Time) bool {
+	expiry, err := time.Parse("200601", b.ExpiryDate)
+	if err != nil {
+		return false
+	}
+
+	return !now.Before(expiry.AddDate(0, 1, 0))
+}
+
+func (b *CardBinding) IsValid() *AppError {
+	if len(b.Id) != 26 {
+		return NewAppError("CardBinding.IsValid", "model.card_binding.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(b.UserId) != 26 {
+		return NewAppError("CardBinding.IsValid", "model.card_binding.is_valid.user_id.app_error", nil, "id="+b.Id, http.StatusBadRequest)
+	}
+
+	if b.PaySystemId != SBERBANK_AQUIRING_TYPE && b.PaySystemId != ALFABANK_AQUIRING_TYPE {
+		return NewAppError("CardBinding.IsValid", "model.card_binding.is_valid.pay_system_id.app_error", nil, "id="+b.Id, http.StatusBadRequest)
+	}
+
+	if len(b.BindingId) == 0 || len(b.BindingId) > 64 {
+		return NewAppError("CardBinding.IsValid", "model.card_binding.is_valid.binding_id.app_error", nil, "id="+b.Id, http.StatusBadRequest)
+	}
+
+	if len(b.MaskedPan) > 32 {
+		return NewAppError("CardBinding.IsValid", "model.card_binding.is_valid.masked_pan.app_error", nil, "id="+b.Id, http.StatusBadRequest)
+	}
+
+	if b.CreateAt == 0 {
+		return NewAppError("CardBinding.IsValid", "model.card_binding.is_valid.create_at.app_error", nil, "id="+b.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
@@ -21,6 +21,12 @@ const (
 	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_ROLES                        = "inv_roles"
 	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_SCHEMES                      = "inv_schemes"
 	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_GROUPS                       = "inv_groups"
+	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_PRODUCTS                     = "inv_products"
+	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_CATEGORIES                   = "inv_categories"
+	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_OFFICES                      = "inv_offices"
+	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_LEVELS                       = "inv_levels"
+	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_APPLICATIONS                 = "inv_applications"
+	CLUSTER_EVENT_UPDATE_APPLICATION_CONFIG                         = "update_app_config"
 
 	CLUSTER_SEND_BEST_EFFORT = "best_effort"
 	CLUSTER_SEND_RELIABLE    = "reliable"
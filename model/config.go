@@ -154,6 +154,8 @@ const (
 	ELASTICSEARCH_SETTINGS_DEFAULT_CHANNEL_INDEX_SHARDS              = 1
 	ELASTICSEARCH_SETTINGS_DEFAULT_USER_INDEX_REPLICAS               = 1
 	ELASTICSEARCH_SETTINGS_DEFAULT_USER_INDEX_SHARDS                 = 1
+	ELASTICSEARCH_SETTINGS_DEFAULT_PRODUCT_INDEX_REPLICAS            = 1
+	ELASTICSEARCH_SETTINGS_DEFAULT_PRODUCT_INDEX_SHARDS              = 1
 	ELASTICSEARCH_SETTINGS_DEFAULT_AGGREGATE_POSTS_AFTER_DAYS        = 365
 	ELASTICSEARCH_SETTINGS_DEFAULT_POSTS_AGGREGATOR_JOB_START_TIME   = "03:00"
 	ELASTICSEARCH_SETTINGS_DEFAULT_INDEX_PREFIX                      = ""
@@ -180,6 +182,18 @@ const (
 	IMAGE_PROXY_TYPE_LOCAL      = "local"
 	IMAGE_PROXY_TYPE_ATMOS_CAMO = "atmos/camo"
 
+	GEOCODER_TYPE_LOCAL  = "local"
+	GEOCODER_TYPE_YANDEX = "yandex"
+
+	CACHE_TYPE_LRU   = "lru"
+	CACHE_TYPE_REDIS = "redis"
+
+	CACHE_SETTINGS_DEFAULT_REDIS_ADDRESS = "localhost:6379"
+	CACHE_SETTINGS_DEFAULT_CACHE_SIZE    = 20000
+	CACHE_SETTINGS_DEFAULT_CACHE_SECONDS = 30 * 60
+
+	GEOCODER_SETTINGS_DEFAULT_YANDEX_API_URL = "https://geocode-maps.yandex.ru/1.x/"
+
 	PAYMENT_PROXY_TYPE_ALFABANK = "alfa"
 	PAYMENT_PROXY_TYPE_SBERBANK = "sber"
 )
@@ -239,6 +253,7 @@ type ServiceSettings struct {
 	EnableLinkPreviews                                *bool
 	EnableTesting                                     *bool   `restricted:"true"`
 	EnableDeveloper                                   *bool   `restricted:"true"`
+	EnableMockAcquirer                                *bool   `restricted:"true"`
 	EnableSecurityFixAlert                            *bool   `restricted:"true"`
 	EnableInsecureOutgoingConnections                 *bool   `restricted:"true"`
 	AllowedUntrustedInternalConnections               *string `restricted:"true"`
@@ -331,6 +346,10 @@ func (s *ServiceSettings) SetDefaults() {
 		s.EnableDeveloper = NewBool(false)
 	}
 
+	if s.EnableMockAcquirer == nil {
+		s.EnableMockAcquirer = NewBool(false)
+	}
+
 	if s.EnableSecurityFixAlert == nil {
 		s.EnableSecurityFixAlert = NewBool(true)
 	}
@@ -1511,6 +1530,9 @@ type ElasticsearchSettings struct {
 	ChannelIndexShards            *int    `restricted:"true"`
 	UserIndexReplicas             *int    `restricted:"true"`
 	UserIndexShards               *int    `restricted:"true"`
+	ProductIndexReplicas          *int    `restricted:"true"`
+	ProductIndexShards            *int    `restricted:"true"`
+	ProductSynonyms               []string
 	AggregatePostsAfterDays       *int    `restricted:"true"`
 	PostsAggregatorJobStartTime   *string `restricted:"true"`
 	IndexPrefix                   *string `restricted:"true"`
@@ -1572,6 +1594,18 @@ func (s *ElasticsearchSettings) SetDefaults() {
 		s.UserIndexShards = NewInt(ELASTICSEARCH_SETTINGS_DEFAULT_USER_INDEX_SHARDS)
 	}
 
+	if s.ProductIndexReplicas == nil {
+		s.ProductIndexReplicas = NewInt(ELASTICSEARCH_SETTINGS_DEFAULT_PRODUCT_INDEX_REPLICAS)
+	}
+
+	if s.ProductIndexShards == nil {
+		s.ProductIndexShards = NewInt(ELASTICSEARCH_SETTINGS_DEFAULT_PRODUCT_INDEX_SHARDS)
+	}
+
+	if s.ProductSynonyms == nil {
+		s.ProductSynonyms = []string{}
+	}
+
 	if s.AggregatePostsAfterDays == nil {
 		s.AggregatePostsAfterDays = NewInt(ELASTICSEARCH_SETTINGS_DEFAULT_AGGREGATE_POSTS_AFTER_DAYS)
 	}
@@ -1699,6 +1733,105 @@ func (ips *ImageProxySettings) SetDefaults(ss ServiceSettings) {
 	}
 }
 
+type GeocoderSettings struct {
+	Enable       *bool
+	GeocoderType *string
+	ApiURL       *string
+	ApiKey       *string `restricted:"true"`
+}
+
+func (gs *GeocoderSettings) SetDefaults() {
+	if gs.Enable == nil {
+		gs.Enable = NewBool(false)
+	}
+
+	if gs.GeocoderType == nil {
+		gs.GeocoderType = NewString(GEOCODER_TYPE_LOCAL)
+	}
+
+	if gs.ApiURL == nil {
+		gs.ApiURL = NewString(GEOCODER_SETTINGS_DEFAULT_YANDEX_API_URL)
+	}
+
+	if gs.ApiKey == nil {
+		gs.ApiKey = NewString("")
+	}
+}
+
+type MetricsSettings struct {
+	Enable        *bool
+	ListenAddress *string
+}
+
+func (s *MetricsSettings) SetDefaults() {
+	if s.ListenAddress == nil {
+		s.ListenAddress = NewString(":8067")
+	}
+
+	if s.Enable == nil {
+		s.Enable = NewBool(false)
+	}
+}
+
+// CacheSettings configure the cache of the products, the categories, the offices, the levels
+// and the applications. An empty CacheType turns the cache off.
+type CacheSettings struct {
+	CacheType     *string
+	RedisAddress  *string
+	RedisPassword *string `restricted:"true"`
+	RedisDB       *int
+	CacheSize     *int
+	CacheSeconds  *int
+}
+
+func (s *CacheSettings) SetDefaults() {
+	if s.CacheType == nil {
+		s.CacheType = NewString(CACHE_TYPE_LRU)
+	}
+
+	if s.RedisAddress == nil {
+		s.RedisAddress = NewString(CACHE_SETTINGS_DEFAULT_REDIS_ADDRESS)
+	}
+
+	if s.RedisPassword == nil {
+		s.RedisPassword = NewString("")
+	}
+
+	if s.RedisDB == nil {
+		s.RedisDB = NewInt(0)
+	}
+
+	if s.CacheSize == nil {
+		s.CacheSize = NewInt(CACHE_SETTINGS_DEFAULT_CACHE_SIZE)
+	}
+
+	if s.CacheSeconds == nil {
+		s.CacheSeconds = NewInt(CACHE_SETTINGS_DEFAULT_CACHE_SECONDS)
+	}
+}
+
+func (s *CacheSettings) isValid() *AppError {
+	switch *s.CacheType {
+	case "", CACHE_TYPE_LRU:
+	case CACHE_TYPE_REDIS:
+		if *s.RedisAddress == "" {
+			return NewAppError("Config.IsValid", "model.config.is_valid.cache_redis_address.app_error", nil, "", http.StatusBadRequest)
+		}
+	default:
+		return NewAppError("Config.IsValid", "model.config.is_valid.cache_type.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *s.CacheSize <= 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.cache_size.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *s.CacheSeconds <= 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.cache_seconds.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
 type ConfigFunc func() *Config
 
 type Config struct {
@@ -1721,6 +1854,8 @@ type Config struct {
 	LocalizationSettings LocalizationSettings
 
 	ClusterSettings ClusterSettings
+	MetricsSettings MetricsSettings
+	CacheSettings   CacheSettings
 
 	ExperimentalSettings  ExperimentalSettings
 	AnalyticsSettings     AnalyticsSettings
@@ -1731,6 +1866,7 @@ type Config struct {
 
 	DisplaySettings    DisplaySettings
 	ImageProxySettings ImageProxySettings
+	GeocoderSettings   GeocoderSettings
 }
 
 func (o *Config) Clone() *Config {
@@ -1795,6 +1931,9 @@ func (o *Config) SetDefaults() {
 	o.JobSettings.SetDefaults()
 	o.DisplaySettings.SetDefaults()
 	o.ImageProxySettings.SetDefaults(o.ServiceSettings)
+	o.GeocoderSettings.SetDefaults()
+	o.MetricsSettings.SetDefaults()
+	o.CacheSettings.SetDefaults()
 }
 
 func (o *Config) IsValid() *AppError {
@@ -1858,6 +1997,14 @@ func (o *Config) IsValid() *AppError {
 		return err
 	}
 
+	if err := o.GeocoderSettings.isValid(); err != nil {
+		return err
+	}
+
+	if err := o.CacheSettings.isValid(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -2162,6 +2309,27 @@ func (ips *ImageProxySettings) isValid() *AppError {
 	return nil
 }
 
+func (gs *GeocoderSettings) isValid() *AppError {
+	if *gs.Enable {
+		switch *gs.GeocoderType {
+		case GEOCODER_TYPE_LOCAL:
+			// No other settings to validate
+		case GEOCODER_TYPE_YANDEX:
+			if *gs.ApiURL == "" {
+				return NewAppError("Config.IsValid", "model.config.is_valid.geocoder_api_url.app_error", nil, "", http.StatusBadRequest)
+			}
+
+			if *gs.ApiKey == "" {
+				return NewAppError("Config.IsValid", "model.config.is_valid.geocoder_api_key.app_error", nil, "", http.StatusBadRequest)
+			}
+		default:
+			return NewAppError("Config.IsValid", "model.config.is_valid.geocoder_type.app_error", nil, "", http.StatusBadRequest)
+		}
+	}
+
+	return nil
+}
+
 func (o *Config) GetSanitizeOptions() map[string]bool {
 	options := map[string]bool{}
 	options["fullname"] = *o.PrivacySettings.ShowFullName
@@ -2193,4 +2361,12 @@ func (o *Config) Sanitize() {
 	}
 
 	*o.ElasticsearchSettings.Password = FAKE_SETTING
+
+	if len(*o.GeocoderSettings.ApiKey) > 0 {
+		*o.GeocoderSettings.ApiKey = FAKE_SETTING
+	}
+
+	if len(*o.CacheSettings.RedisPassword) > 0 {
+		*o.CacheSettings.RedisPassword = FAKE_SETTING
+	}
 }
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+)
+
+const (
+	EARTH_RADIUS_METERS = 6371000
+)
+
+// CourierLocation is the last known position of a courier reported by the mobile client.
+type CourierLocation struct {
+	UserId    string  `json:"user_id"`
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"long"`
+	Accuracy  float64 `json:"accuracy"`
+	Speed     float64 `json:"speed"`
+	Heading   float64 `json:"heading"`
+	CreateAt  int64   `json:"create_at"`
+	UpdateAt  int64   `json:"update_at"`
+}
+
+func (cl *CourierLocation) ToJson() string {
+	b, _ := json.Marshal(cl)
+	return string(b)
+}
+
+func CourierLocationFromJson(data io.Reader) *CourierLocation {
+	var cl *CourierLocation
+	json.NewDecoder(data).Decode(&cl)
+	return cl
+}
+
+func (cl *CourierLocation) PreSave() {
+	cl.UpdateAt = GetMillis()
+
+	if cl.CreateAt == 0 {
+		cl.CreateAt = cl.UpdateAt
+	}
+}
+
+func (cl *CourierLocation) IsValid() *AppError {
+	if len(cl.UserId) != 26 {
+		return NewAppError("CourierLocation.IsValid", "model.courier_location.is_valid.user_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if !IsValidLocation(cl.Latitude, cl.Longitude) {
+		return NewAppError("CourierLocation.IsValid", "model.courier_location.is_valid.location.app_error", nil, "user_id="+cl.UserId, http.StatusBadRequest)
+	}
+
+	if cl.Accuracy < 0 || cl.Speed < 0 {
+		return NewAppError("CourierLocation.IsValid", "model.courier_location.is_valid.accuracy.app_error", nil, "user_id="+cl.UserId, http.StatusBadRequest)
+	}
+
+	if cl.UpdateAt == 0 {
+		return NewAppError("CourierLocation.IsValid", "model.courier_location.is_valid.update_at.app_error", nil, "user_id="+cl.UserId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func IsValidLocation(latitude, longitude float64) bool {
+	return latitude >= -90 && latitude <= 90 && longitude >= -180 && longitude <= 180
+}
+
+// GeoDistance returns the great-circle distance between two points in meters.
+func GeoDistance(lat1, long1, lat2, long2 float64) float64 {
+	toRad := func(deg float64) float64 {
+		return deg * math.Pi / 180
+	}
+
+	dLat := toRad(lat2 - lat1)
+	dLong := toRad(long2 - long1)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLong/2)*math.Sin(dLong/2)
+
+	return 2 * EARTH_RADIUS_METERS * math.Asin(math.Sqrt(h))
+}
@@ -12,9 +12,13 @@ const (
 	JOB_TYPE_MESSAGE_EXPORT                 = "message_export"
 	JOB_TYPE_ELASTICSEARCH_POST_INDEXING    = "elasticsearch_post_indexing"
 	JOB_TYPE_ELASTICSEARCH_POST_AGGREGATION = "elasticsearch_post_aggregation"
+	JOB_TYPE_ELASTICSEARCH_PRODUCT_INDEXING = "elasticsearch_product_indexing"
 	JOB_TYPE_LDAP_SYNC                      = "ldap_sync"
 	JOB_TYPE_MIGRATIONS                     = "migrations"
 	JOB_TYPE_PLUGINS                        = "plugins"
+	JOB_TYPE_CAMPAIGN                       = "campaign"
+	JOB_TYPE_LIFECYCLE_TRIGGERS             = "lifecycle_triggers"
+	JOB_TYPE_LOYALTY_TIERS                  = "loyalty_tiers"
 
 	JOB_STATUS_PENDING          = "pending"
 	JOB_STATUS_IN_PROGRESS      = "in_progress"
@@ -49,10 +53,14 @@ func (j *Job) IsValid() *AppError {
 	case JOB_TYPE_DATA_RETENTION:
 	case JOB_TYPE_ELASTICSEARCH_POST_INDEXING:
 	case JOB_TYPE_ELASTICSEARCH_POST_AGGREGATION:
+	case JOB_TYPE_ELASTICSEARCH_PRODUCT_INDEXING:
 	case JOB_TYPE_LDAP_SYNC:
 	case JOB_TYPE_MESSAGE_EXPORT:
 	case JOB_TYPE_MIGRATIONS:
 	case JOB_TYPE_PLUGINS:
+	case JOB_TYPE_CAMPAIGN:
+	case JOB_TYPE_LIFECYCLE_TRIGGERS:
+	case JOB_TYPE_LOYALTY_TIERS:
 	default:
 		return NewAppError("Job.IsValid", "model.job.is_valid.type.app_error", nil, "id="+j.Id, http.StatusBadRequest)
 	}
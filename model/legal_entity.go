@@ -0,0 +1,116 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	LEGAL_ENTITY_NAME_MAX_RUNES    = 500
+	LEGAL_ENTITY_ADDRESS_MAX_RUNES = 1000
+)
+
+// LegalEntity holds the requisites of an organization or an individual entrepreneur the way
+// they are printed on the invoices and the acts. The bank requisites are needed only to be paid.
+type LegalEntity struct {
+	Name        string `json:"name"`
+	Inn         string `json:"inn"`
+	Kpp         string `json:"kpp,omitempty"`
+	Ogrn        string `json:"ogrn,omitempty"`
+	Address     string `json:"address,omitempty"`
+	BankName    string `json:"bank_name,omitempty"`
+	Bik         string `json:"bik,omitempty"`
+	Account     string `json:"account,omitempty"`
+	CorrAccount string `json:"corr_account,omitempty"`
+}
+
+func (e *LegalEntity) Trim() {
+	e.Name = strings.TrimSpace(e.Name)
+	e.Inn = strings.TrimSpace(e.Inn)
+	e.Kpp = strings.TrimSpace(e.Kpp)
+	e.Ogrn = strings.TrimSpace(e.Ogrn)
+	e.Address = strings.TrimSpace(e.Address)
+	e.BankName = strings.TrimSpace(e.BankName)
+	e.Bik = strings.TrimSpace(e.Bik)
+	e.Account = strings.TrimSpace(e.Account)
+	e.CorrAccount = strings.TrimSpace(e.CorrAccount)
+}
+
+func (e *LegalEntity) IsValid() *AppError {
+	if len(e.Name) == 0 || utf8.RuneCountInString(e.Name) > LEGAL_ENTITY_NAME_MAX_RUNES {
+		return NewAppError("LegalEntity.IsValid", "model.legal_entity.is_valid.name.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	// 10 digits for organizations, 12 for individual entrepreneurs
+	if !isDigits(e.Inn, 10) && !isDigits(e.Inn, 12) {
+		return NewAppError("LegalEntity.IsValid", "model.legal_entity.is_valid.inn.app_error", nil, "inn="+e.Inn, http.StatusBadRequest)
+	}
+
+	if len(e.Kpp) > 0 && !isDigits(e.Kpp, 9) {
+		return NewAppError("LegalEntity.IsValid", "model.legal_entity.is_valid.kpp.app_error", nil, "inn="+e.Inn, http.StatusBadRequest)
+	}
+
+	if len(e.Ogrn) > 0 && !isDigits(e.Ogrn, 13) && !isDigits(e.Ogrn, 15) {
+		return NewAppError("LegalEntity.IsValid", "model.legal_entity.is_valid.ogrn.app_error", nil, "inn="+e.Inn, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(e.Address) > LEGAL_ENTITY_ADDRESS_MAX_RUNES || utf8.RuneCountInString(e.BankName) > LEGAL_ENTITY_NAME_MAX_RUNES {
+		return NewAppError("LegalEntity.IsValid", "model.legal_entity.is_valid.address.app_error", nil, "inn="+e.Inn, http.StatusBadRequest)
+	}
+
+	if (len(e.Bik) > 0 && !isDigits(e.Bik, 9)) ||
+		(len(e.Account) > 0 && !isDigits(e.Account, 20)) ||
+		(len(e.CorrAccount) > 0 && !isDigits(e.CorrAccount, 20)) {
+		return NewAppError("LegalEntity.IsValid", "model.legal_entity.is_valid.bank.app_error", nil, "inn="+e.Inn, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// HasBankAccount reports whether the entity can be paid by a bank transfer.
+func (e *LegalEntity) HasBankAccount() bool {
+	return len(e.BankName) > 0 && len(e.Bik) > 0 && len(e.Account) > 0
+}
+
+// ApplicationSeller returns the requisites of the seller kept in the payment details of the
+// application as JSON, nil when the payment details are free text.
+func ApplicationSeller(application *Application) *LegalEntity {
+	if !strings.HasPrefix(strings.TrimSpace(application.PaymentDetails), "{") {
+		return nil
+	}
+
+	seller := LegalEntityFromJson(strings.NewReader(application.PaymentDetails))
+	if seller == nil || seller.IsValid() != nil {
+		return nil
+	}
+
+	return seller
+}
+
+func isDigits(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (e *LegalEntity) ToJson() string {
+	b, _ := json.Marshal(e)
+	return string(b)
+}
+
+func LegalEntityFromJson(data io.Reader) *LegalEntity {
+	var e *LegalEntity
+	json.NewDecoder(data).Decode(&e)
+	return e
+}
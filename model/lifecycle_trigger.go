@@ -0,0 +1,243 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	LIFECYCLE_EVENT_ABANDONED_ORDER = "abandoned_order"
+	LIFECYCLE_EVENT_WIN_BACK        = "win_back"
+	LIFECYCLE_EVENT_BIRTHDAY        = "birthday"
+
+	LIFECYCLE_TRIGGER_NAME_MAX_RUNES    = 128
+	LIFECYCLE_TRIGGER_MESSAGE_MAX_RUNES = 1024
+	LIFECYCLE_TRIGGER_ERROR_MAX_SIZE    = 255
+
+	LIFECYCLE_DEFAULT_ABANDONED_ORDER_DELAY = 30 // minutes
+	LIFECYCLE_DEFAULT_WIN_BACK_DAYS         = 30
+	LIFECYCLE_MAX_WIN_BACK_DAYS             = 365
+	LIFECYCLE_MAX_COOLDOWN_HOURS            = 24 * 365
+
+	// orders older than the window are not reminded about, even if the trigger was created later
+	LIFECYCLE_ABANDONED_ORDER_WINDOW = 24 * 60 * 60 * 1000
+
+	LIFECYCLE_PUSH_TYPE = "lifecycle"
+)
+
+// LifecycleTrigger is an automation of the application: when the event happens to a customer
+// the trigger sends a push, posts into the support channel and/or accrues bonus points.
+type LifecycleTrigger struct {
+	Id        string `json:"id"`
+	AppId     string `json:"app_id"`
+	CreatorId string `json:"creator_id"`
+	Name      string `json:"name"`
+	Event     string `json:"event"`
+	Enabled   bool   `json:"enabled"`
+
+	// DelayMinutes is how long an order stays unpaid before the abandoned order trigger fires.
+	DelayMinutes int `json:"delay_minutes"`
+	// InactiveDays is how long a customer has not ordered before the win-back trigger fires.
+	InactiveDays int `json:"inactive_days"`
+
+	Message string  `json:"message"`
+	Push    bool    `json:"push"`
+	Post    bool    `json:"post"`
+	Bonus   float64 `json:"bonus"`
+
+	// CooldownHours is the minimal interval between two firings for the same customer.
+	CooldownHours int `json:"cooldown_hours"`
+	// MaxPerUser limits the number of firings for the same customer, zero is unlimited.
+	MaxPerUser int `json:"max_per_user"`
+
+	CreateAt int64 `json:"create_at"`
+	UpdateAt int64 `json:"update_at"`
+	DeleteAt int64 `json:"delete_at"`
+}
+
+type LifecycleTriggerPatch struct {
+	Name          *string  `json:"name"`
+	Enabled       *bool    `json:"enabled"`
+	DelayMinutes  *int     `json:"delay_minutes"`
+	InactiveDays  *int     `json:"inactive_days"`
+	Message       *string  `json:"message"`
+	Push          *bool    `json:"push"`
+	Post          *bool    `json:"post"`
+	Bonus         *float64 `json:"bonus"`
+	CooldownHours *int     `json:"cooldown_hours"`
+	MaxPerUser    *int     `json:"max_per_user"`
+}
+
+func (t *LifecycleTrigger) Patch(patch *LifecycleTriggerPatch) {
+	if patch.Name != nil {
+		t.Name = *patch.Name
+	}
+	if patch.Enabled != nil {
+		t.Enabled = *patch.Enabled
+	}
+	if patch.DelayMinutes != nil {
+		t.DelayMinutes = *patch.DelayMinutes
+	}
+	if patch.InactiveDays != nil {
+		t.InactiveDays = *patch.InactiveDays
+	}
+	if patch.Message != nil {
+		t.Message = *patch.Message
+	}
+	if patch.Push != nil {
+		t.Push = *patch.Push
+	}
+	if patch.Post != nil {
+		t.Post = *patch.Post
+	}
+	if patch.Bonus != nil {
+		t.Bonus = *patch.Bonus
+	}
+	if patch.CooldownHours != nil {
+		t.CooldownHours = *patch.CooldownHours
+	}
+	if patch.MaxPerUser != nil {
+		t.MaxPerUser = *patch.MaxPerUser
+	}
+}
+
+func (t *LifecycleTrigger) ToJson() string {
+	b, _ := json.Marshal(t)
+	return string(b)
+}
+
+func LifecycleTriggerFromJson(data io.Reader) *LifecycleTrigger {
+	var t *LifecycleTrigger
+	json.NewDecoder(data).Decode(&t)
+	return t
+}
+
+func LifecycleTriggerPatchFromJson(data io.Reader) *LifecycleTriggerPatch {
+	var patch *LifecycleTriggerPatch
+	json.NewDecoder(data).Decode(&patch)
+	return patch
+}
+
+func LifecycleTriggerListToJson(list []*LifecycleTrigger) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func (t *LifecycleTrigger) PreSave() {
+	if t.Id == "" {
+		t.Id = NewId()
+	}
+
+	if t.Event == LIFECYCLE_EVENT_ABANDONED_ORDER && t.DelayMinutes == 0 {
+		t.DelayMinutes = LIFECYCLE_DEFAULT_ABANDONED_ORDER_DELAY
+	}
+
+	if t.Event == LIFECYCLE_EVENT_WIN_BACK && t.InactiveDays == 0 {
+		t.InactiveDays = LIFECYCLE_DEFAULT_WIN_BACK_DAYS
+	}
+
+	t.CreateAt = GetMillis()
+	t.UpdateAt = t.CreateAt
+}
+
+func (t *LifecycleTrigger) PreUpdate() {
+	t.UpdateAt = GetMillis()
+}
+
+func (t *LifecycleTrigger) IsValid() *AppError {
+	if len(t.Id) != 26 {
+		return NewAppError("LifecycleTrigger.IsValid", "model.lifecycle_trigger.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(t.AppId) != 26 {
+		return NewAppError("LifecycleTrigger.IsValid", "model.lifecycle_trigger.is_valid.app_id.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	if len(t.CreatorId) != 26 {
+		return NewAppError("LifecycleTrigger.IsValid", "model.lifecycle_trigger.is_valid.creator_id.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	if len(t.Name) == 0 || utf8.RuneCountInString(t.Name) > LIFECYCLE_TRIGGER_NAME_MAX_RUNES {
+		return NewAppError("LifecycleTrigger.IsValid", "model.lifecycle_trigger.is_valid.name.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	switch t.Event {
+	case LIFECYCLE_EVENT_ABANDONED_ORDER:
+		if t.DelayMinutes < 1 || t.DelayMinutes*60*1000 >= LIFECYCLE_ABANDONED_ORDER_WINDOW {
+			return NewAppError("LifecycleTrigger.IsValid", "model.lifecycle_trigger.is_valid.delay_minutes.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+		}
+	case LIFECYCLE_EVENT_WIN_BACK:
+		if t.InactiveDays < 1 || t.InactiveDays > LIFECYCLE_MAX_WIN_BACK_DAYS {
+			return NewAppError("LifecycleTrigger.IsValid", "model.lifecycle_trigger.is_valid.inactive_days.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+		}
+	case LIFECYCLE_EVENT_BIRTHDAY:
+	default:
+		return NewAppError("LifecycleTrigger.IsValid", "model.lifecycle_trigger.is_valid.event.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	if !t.Push && !t.Post && t.Bonus == 0 {
+		return NewAppError("LifecycleTrigger.IsValid", "model.lifecycle_trigger.is_valid.action.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	if (t.Push || t.Post) && len(t.Message) == 0 {
+		return NewAppError("LifecycleTrigger.IsValid", "model.lifecycle_trigger.is_valid.message.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(t.Message) > LIFECYCLE_TRIGGER_MESSAGE_MAX_RUNES {
+		return NewAppError("LifecycleTrigger.IsValid", "model.lifecycle_trigger.is_valid.message.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	if t.Bonus < 0 {
+		return NewAppError("LifecycleTrigger.IsValid", "model.lifecycle_trigger.is_valid.bonus.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	if t.CooldownHours < 0 || t.CooldownHours > LIFECYCLE_MAX_COOLDOWN_HOURS {
+		return NewAppError("LifecycleTrigger.IsValid", "model.lifecycle_trigger.is_valid.cooldown_hours.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	if t.MaxPerUser < 0 {
+		return NewAppError("LifecycleTrigger.IsValid", "model.lifecycle_trigger.is_valid.max_per_user.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	if t.CreateAt == 0 {
+		return NewAppError("LifecycleTrigger.IsValid", "model.lifecycle_trigger.is_valid.create_at.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	if t.UpdateAt == 0 {
+		return NewAppError("LifecycleTrigger.IsValid", "model.lifecycle_trigger.is_valid.update_at.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// LifecycleTriggerCandidate is a customer the trigger is about to fire for. SubjectId identifies
+// the occurrence of the event: the order for an abandoned order, the year for a birthday and the
+// time of the last order for a win-back.
+type LifecycleTriggerCandidate struct {
+	UserId    string
+	SubjectId string
+}
+
+// LifecycleTriggerLog records that the trigger fired for the customer. The logs are what the
+// frequency caps are evaluated against.
+type LifecycleTriggerLog struct {
+	TriggerId string `json:"trigger_id"`
+	UserId    string `json:"user_id"`
+	SubjectId string `json:"subject_id"`
+	Error     string `json:"error"`
+	CreateAt  int64  `json:"create_at"`
+}
+
+func LifecycleTriggerLogListToJson(list []*LifecycleTriggerLog) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func (l *LifecycleTriggerLog) SetError(errMsg string) {
+	if len(errMsg) > LIFECYCLE_TRIGGER_ERROR_MAX_SIZE {
+		errMsg = errMsg[:LIFECYCLE_TRIGGER_ERROR_MAX_SIZE]
+	}
+	l.Error = errMsg
+}
@@ -0,0 +1,178 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"unicode/utf8"
+)
+
+const (
+	LOYALTY_TIER_METRIC_SPEND  = "spend"
+	LOYALTY_TIER_METRIC_ORDERS = "orders"
+
+	LOYALTY_TIER_NAME_MAX_RUNES    = 64
+	LOYALTY_TIER_DEFAULT_PERIOD    = 365 // days of orders the tier is computed from
+	LOYALTY_TIER_MAX_PERIOD_DAYS   = 3650
+	LOYALTY_TIER_MAX_TIERS_PER_APP = 20
+
+	LOYALTY_TIER_PUSH_TYPE = "tier"
+)
+
+// LoyaltyTier is a customer status of the application (Silver, Gold...). The customer reaches
+// the tier when the spend or the order count of the rolling period is at least Threshold.
+// While in the tier the customer gets its cashback and may pay a bigger part with bonuses.
+type LoyaltyTier struct {
+	Id          string  `json:"id"`
+	AppId       string  `json:"app_id"`
+	Name        string  `json:"name"`
+	Threshold   float64 `json:"threshold"`
+	Cashback    float64 `json:"cashback"`
+	MaxDiscount float64 `json:"max_discount"`
+	CreateAt    int64   `json:"create_at"`
+	UpdateAt    int64   `json:"update_at"`
+	DeleteAt    int64   `json:"delete_at"`
+}
+
+type LoyaltyTierPatch struct {
+	Name        *string  `json:"name"`
+	Threshold   *float64 `json:"threshold"`
+	Cashback    *float64 `json:"cashback"`
+	MaxDiscount *float64 `json:"max_discount"`
+}
+
+func (t *LoyaltyTier) Patch(patch *LoyaltyTierPatch) {
+	if patch.Name != nil {
+		t.Name = *patch.Name
+	}
+	if patch.Threshold != nil {
+		t.Threshold = *patch.Threshold
+	}
+	if patch.Cashback != nil {
+		t.Cashback = *patch.Cashback
+	}
+	if patch.MaxDiscount != nil {
+		t.MaxDiscount = *patch.MaxDiscount
+	}
+}
+
+func (t *LoyaltyTier) ToJson() string {
+	b, _ := json.Marshal(t)
+	return string(b)
+}
+
+func LoyaltyTierFromJson(data io.Reader) *LoyaltyTier {
+	var t *LoyaltyTier
+	json.NewDecoder(data).Decode(&t)
+	return t
+}
+
+func LoyaltyTierPatchFromJson(data io.Reader) *LoyaltyTierPatch {
+	var patch *LoyaltyTierPatch
+	json.NewDecoder(data).Decode(&patch)
+	return patch
+}
+
+func LoyaltyTierListToJson(list []*LoyaltyTier) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func (t *LoyaltyTier) PreSave() {
+	if t.Id == "" {
+		t.Id = NewId()
+	}
+
+	t.CreateAt = GetMillis()
+	t.UpdateAt = t.CreateAt
+}
+
+func (t *LoyaltyTier) PreUpdate() {
+	t.UpdateAt = GetMillis()
+}
+
+func (t *LoyaltyTier) IsValid() *AppError {
+	if len(t.Id) != 26 {
+		return NewAppError("LoyaltyTier.IsValid", "model.loyalty_tier.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(t.AppId) != 26 {
+		return NewAppError("LoyaltyTier.IsValid", "model.loyalty_tier.is_valid.app_id.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	if len(t.Name) == 0 || utf8.RuneCountInString(t.Name) > LOYALTY_TIER_NAME_MAX_RUNES {
+		return NewAppError("LoyaltyTier.IsValid", "model.loyalty_tier.is_valid.name.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	if t.Threshold < 0 {
+		return NewAppError("LoyaltyTier.IsValid", "model.loyalty_tier.is_valid.threshold.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	if t.Cashback < 0 || t.Cashback > 100 {
+		return NewAppError("LoyaltyTier.IsValid", "model.loyalty_tier.is_valid.cashback.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	if t.MaxDiscount < 0 || t.MaxDiscount > 100 {
+		return NewAppError("LoyaltyTier.IsValid", "model.loyalty_tier.is_valid.max_discount.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	if t.CreateAt == 0 {
+		return NewAppError("LoyaltyTier.IsValid", "model.loyalty_tier.is_valid.create_at.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	if t.UpdateAt == 0 {
+		return NewAppError("LoyaltyTier.IsValid", "model.loyalty_tier.is_valid.update_at.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// LoyaltyTierForValue picks the highest tier whose threshold the value reaches.
+func LoyaltyTierForValue(tiers []*LoyaltyTier, value float64) *LoyaltyTier {
+	sorted := make([]*LoyaltyTier, len(tiers))
+	copy(sorted, tiers)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Threshold > sorted[j].Threshold
+	})
+
+	for _, tier := range sorted {
+		if value >= tier.Threshold {
+			return tier
+		}
+	}
+
+	return nil
+}
+
+// UserTier is the tier the customer currently holds together with the figures it was computed from.
+type UserTier struct {
+	UserId     string  `json:"user_id"`
+	AppId      string  `json:"app_id"`
+	TierId     string  `json:"tier_id"`
+	Spend      float64 `json:"spend"`
+	OrderCount int64   `json:"order_count"`
+	UpdateAt   int64   `json:"update_at"`
+
+	Tier *LoyaltyTier `db:"-" json:"tier,omitempty"`
+}
+
+func (ut *UserTier) ToJson() string {
+	b, _ := json.Marshal(ut)
+	return string(b)
+}
+
+func (ut *UserTier) PreSave() {
+	ut.UpdateAt = GetMillis()
+}
+
+// CustomerSpend is the spend and the order count of a customer over the tier period, along with
+// the tier and the figures stored by the previous recalculation.
+type CustomerSpend struct {
+	UserId         string
+	Spend          float64
+	OrderCount     int64
+	TierId         string
+	PrevSpend      float64
+	PrevOrderCount int64
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 type Office struct {
@@ -18,6 +20,11 @@ type Office struct {
 	DeleteAt    int64  `json:"delete_at"`
 	Latitude    string `json:"lat"`
 	Longitude   string `json:"long"`
+
+	// The kitchen tickets of the office are printed in this format when it has a print agent key.
+	PrintFormat     string `json:"print_format"`
+	PrintPaperWidth int    `json:"print_paper_width"`
+	PrintAgentKey   string `json:"-"`
 }
 
 type OfficePatch struct {
@@ -27,6 +34,9 @@ type OfficePatch struct {
 	Active      *bool   `json:"active"`
 	Latitude    string  `json:"lat"`
 	Longitude   string  `json:"long"`
+
+	PrintFormat     *string `json:"print_format"`
+	PrintPaperWidth *int    `json:"print_paper_width"`
 }
 
 func (p *Office) Patch(patch *OfficePatch) {
@@ -43,6 +53,12 @@ func (p *Office) Patch(patch *OfficePatch) {
 	if patch.Active != nil {
 		p.Active = *patch.Active
 	}
+	if patch.PrintFormat != nil {
+		p.PrintFormat = *patch.PrintFormat
+	}
+	if patch.PrintPaperWidth != nil {
+		p.PrintPaperWidth = *patch.PrintPaperWidth
+	}
 }
 
 func (office *Office) ToJson() string {
@@ -94,5 +110,24 @@ func (o *Office) IsValid() *AppError {
 		return NewAppError("Office.IsValid", "model.office.is_valid.update_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
 	}
 
+	if (len(o.PrintFormat) > 0 && !IsValidPrintFormat(o.PrintFormat)) || (o.PrintPaperWidth != 0 && !IsValidPrintPaperWidth(o.PrintPaperWidth)) {
+		return NewAppError("Office.IsValid", "model.office.is_valid.print.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
 	return nil
 }
+
+// GetLocation parses the office coordinates, ok is false when they are not set or malformed.
+func (o *Office) GetLocation() (latitude float64, longitude float64, ok bool) {
+	var err error
+
+	if latitude, err = strconv.ParseFloat(strings.TrimSpace(o.Latitude), 64); err != nil {
+		return 0, 0, false
+	}
+
+	if longitude, err = strconv.ParseFloat(strings.TrimSpace(o.Longitude), 64); err != nil {
+		return 0, 0, false
+	}
+
+	return latitude, longitude, IsValidLocation(latitude, longitude)
+}
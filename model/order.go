@@ -1,8 +1,10 @@
 package model
 
 import (
+	"crypto/rand"
 	"encoding/json"
 	"io"
+	"math/big"
 	"net/http"
 	"strconv"
 )
@@ -39,43 +41,61 @@ const (
 	PAYMENT_SYSTEM_CASH     string = "cash"
 	PAYMENT_SYSTEM_ALFABANK string = "alfabank"
 	PAYMENT_SYSTEM_SBERBANK string = "sberbank"
+
+	ORDER_HANDOVER_CODE_LENGTH = 4
 )
 
 type Order struct {
-	Id                   string    `json:"id"`
-	Payed                bool      `json:"payed"`
-	PayedAt              int64     `json:"payed_at"`
-	Canceled             bool      `json:"canceled"`
-	CanceledAt           int64     `json:"canceled_at"`
-	ReasonCanceled       string    `json:"reason_canceled"`
-	Status               string    `json:"status"`
-	StatusAt             int64     `json:"status_at"`
-	PriceDelivery        float64   `json:"price_delivery"`
-	DeliveryAt           int64     `json:"delivery_at"`
-	Price                float64   `json:"price"`
-	Currency             string    `json:"currency"`
-	DiscountValue        float64   `json:"discount_value"`
-	UserId               string    `json:"user_id"`
-	PaySystemId          string    `json:"pay_system_id"`
-	DeliveryId           string    `json:"delivery_id"`
-	PaySystemStatus      string    `json:"pay_systems_status"`
-	PaySystemCode        string    `json:"pay_system_code"`
-	PaySystemDescription string    `json:"pay_system_description"`
-	PaySystemMessage     string    `json:"pay_system_message"`
-	PaySystemSum         float64   `json:"pay_system_sum"`
-	PaySystemCurrency    string    `json:"pay_system_currency"`
-	PaySystemResponseAt  int64     `json:"pay_system_response_at"`
-	PaySystemOrderNum    string    `json:"pay_system_order_num"`
-	CreateAt             int64     `json:"create_at"`
-	UpdateAt             int64     `json:"update_at"`
-	DeleteAt             int64     `json:"delete_at"`
-	Address              string    `json:"address"`
-	Comment              string    `json:"comment"`
-	Phone                string    `json:"phone"`
-	Processing           bool      `json:"processing"`
-	Positions            []*Basket `db:"-" json:"positions"`
-	Post                 *Post     `db:"-" json:"post,omitempty"`
-	User                 *User     `db:"-" json:"user,omitempty"`
+	Id                   string          `json:"id"`
+	Payed                bool            `json:"payed"`
+	PayedAt              int64           `json:"payed_at"`
+	Canceled             bool            `json:"canceled"`
+	CanceledAt           int64           `json:"canceled_at"`
+	ReasonCanceled       string          `json:"reason_canceled"`
+	Status               string          `json:"status"`
+	StatusAt             int64           `json:"status_at"`
+	PriceDelivery        float64         `json:"price_delivery"`
+	DeliveryAt           int64           `json:"delivery_at"`
+	Price                float64         `json:"price"`
+	Currency             string          `json:"currency"`
+	DiscountValue        float64         `json:"discount_value"`
+	UserId               string          `json:"user_id"`
+	PaySystemId          string          `json:"pay_system_id"`
+	DeliveryId           string          `json:"delivery_id"`
+	PaySystemStatus      string          `json:"pay_systems_status"`
+	PaySystemCode        string          `json:"pay_system_code"`
+	PaySystemDescription string          `json:"pay_system_description"`
+	PaySystemMessage     string          `json:"pay_system_message"`
+	PaySystemSum         float64         `json:"pay_system_sum"`
+	PaySystemCurrency    string          `json:"pay_system_currency"`
+	PaySystemResponseAt  int64           `json:"pay_system_response_at"`
+	PaySystemOrderNum    string          `json:"pay_system_order_num"`
+	CardMask             string          `json:"card_mask"`
+	ReceiptStatus        string          `json:"receipt_status"`
+	ReceiptQr            string          `json:"receipt_qr"`
+	ReceiptUrl           string          `json:"receipt_url"`
+	ReceiptAt            int64           `json:"receipt_at"`
+	CreateAt             int64           `json:"create_at"`
+	UpdateAt             int64           `json:"update_at"`
+	DeleteAt             int64           `json:"delete_at"`
+	Address              string          `json:"address"`
+	Comment              string          `json:"comment"`
+	Phone                string          `json:"phone"`
+	Processing           bool            `json:"processing"`
+	CourierId            string          `json:"courier_id"`
+	CourierAssignedAt    int64           `json:"courier_assigned_at"`
+	HandoverCode         string          `json:"-"`
+	HandoverAt           int64           `json:"handover_at"`
+	Latitude             float64         `json:"lat"`
+	Longitude            float64         `json:"long"`
+	AddressId            string          `json:"address_id"`
+	DeliveryAddress      *Address        `json:"delivery_address,omitempty"`
+	Positions            []*Basket       `db:"-" json:"positions"`
+	Payments             []*OrderPayment `db:"-" json:"payments,omitempty"`
+	Post                 *Post           `db:"-" json:"post,omitempty"`
+	User                 *User           `db:"-" json:"user,omitempty"`
+	// Buyer is the legal entity an invoice is made out to, sent along with the order of the invoice.
+	Buyer *LegalEntity `db:"-" json:"buyer,omitempty"`
 }
 
 type OrderPatch struct {
@@ -85,6 +105,7 @@ type OrderPatch struct {
 	Processing        *bool   `json:"processing"`
 	PaySystemId       *string `json:"pay_system_id"`
 	PaySystemOrderNum *string `json:"pay_system_order_num"`
+	Payed             *bool   `json:"payed"`
 }
 
 func (o *Order) Patch(patch *OrderPatch) {
@@ -106,6 +127,19 @@ func (o *Order) Patch(patch *OrderPatch) {
 	if patch.PaySystemOrderNum != nil {
 		o.PaySystemOrderNum = *patch.PaySystemOrderNum
 	}
+	if patch.Payed != nil {
+		o.Payed = *patch.Payed
+	}
+}
+
+// HasPaymentChanges reports whether the patch touches the payment state of the order.
+func (patch *OrderPatch) HasPaymentChanges() bool {
+	return patch.PaySystemCode != nil || patch.PaySystemId != nil || patch.PaySystemOrderNum != nil || patch.Payed != nil
+}
+
+// HasFulfillmentChanges reports whether the patch touches the fulfillment state of the order.
+func (patch *OrderPatch) HasFulfillmentChanges() bool {
+	return patch.Status != nil || patch.DeliveryAt != nil || patch.Processing != nil
 }
 
 func (order *Order) ToJson() string {
@@ -178,9 +212,56 @@ func (o *Order) IsValid() *AppError {
 		return NewAppError("Order.IsValid", "model.order.is_valid.price.app_error", nil, "id="+o.Id, http.StatusBadRequest)
 	}
 
+	if !IsValidLocation(o.Latitude, o.Longitude) {
+		return NewAppError("Order.IsValid", "model.order.is_valid.location.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
 	return nil
 }
 
+// SanitizeCustomer removes the customer personal data, leaving only the order contents.
+func (o *Order) SanitizeCustomer() {
+	o.Phone = ""
+	o.Address = ""
+	o.DeliveryAddress = nil
+	o.User = nil
+	o.Post = nil
+}
+
+// SetDeliveryAddress copies the saved address into the order, so later changes
+// in the address book do not affect the orders already placed.
+func (o *Order) SetDeliveryAddress(address *Address) {
+	o.AddressId = address.Id
+	o.DeliveryAddress = address.Clone()
+	o.Address = address.Format()
+	o.Latitude = address.Latitude
+	o.Longitude = address.Longitude
+
+	if len(o.Phone) == 0 {
+		o.Phone = address.Phone
+	}
+}
+
+// HasLocation reports whether the delivery coordinates of the order are known.
+func (o *Order) HasLocation() bool {
+	return o.Latitude != 0 || o.Longitude != 0
+}
+
+// IsOutForDelivery reports whether the courier is on the way to the customer.
+func (o *Order) IsOutForDelivery() bool {
+	return len(o.CourierId) > 0 && o.Status == ORDER_STATUS_AWAITING_SHIPMENT
+}
+
+// NewHandoverCode generates the code the customer tells the courier on delivery.
+func NewHandoverCode() string {
+	code := ""
+	for i := 0; i < ORDER_HANDOVER_CODE_LENGTH; i++ {
+		n, _ := rand.Int(rand.Reader, big.NewInt(10))
+		code += n.String()
+	}
+	return code
+}
+
 func (o *Order) NormalizePositions() {
 	positions := make(map[string]*Basket)
 	var list []*Basket
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// ORDER_BOARD_STATUSES are the columns of the order board, in the order the kitchen works them.
+var ORDER_BOARD_STATUSES = []string{
+	ORDER_STATUS_AWAITING_PAYMENT,
+	ORDER_STATUS_AWAITING_FULFILLMENT,
+	ORDER_STATUS_AWAITING_PICKUP,
+	ORDER_STATUS_AWAITING_SHIPMENT,
+}
+
+const ORDER_BOARD_MAX_ORDERS = 200
+
+// OrderBoardItem is the compact order the staff board and the order events carry.
+type OrderBoardItem struct {
+	Id         string                `json:"id"`
+	Number     string                `json:"number"`
+	Status     string                `json:"status"`
+	Payed      bool                  `json:"payed"`
+	Canceled   bool                  `json:"canceled"`
+	Price      float64               `json:"price"`
+	Currency   string                `json:"currency"`
+	DeliveryAt int64                 `json:"delivery_at"`
+	CourierId  string                `json:"courier_id"`
+	Comment    string                `json:"comment"`
+	OfficeIds  []string              `json:"office_ids"`
+	Positions  []*OrderBoardPosition `json:"positions"`
+	CreateAt   int64                 `json:"create_at"`
+	UpdateAt   int64                 `json:"update_at"`
+}
+
+type OrderBoardPosition struct {
+	ProductId string `json:"product_id"`
+	Name      string `json:"name"`
+	Quantity  int    `json:"quantity"`
+}
+
+// OrderBoard groups the open orders of an application by status.
+type OrderBoard struct {
+	Columns map[string][]*OrderBoardItem `json:"columns"`
+}
+
+func NewOrderBoardItem(order *Order, baskets []*Basket, officeIds []string) *OrderBoardItem {
+	if officeIds == nil {
+		officeIds = []string{}
+	}
+
+	positions := make([]*OrderBoardPosition, 0, len(baskets))
+	for _, basket := range baskets {
+		positions = append(positions, &OrderBoardPosition{
+			ProductId: basket.ProductId,
+			Name:      basket.Name,
+			Quantity:  basket.Quantity,
+		})
+	}
+
+	return &OrderBoardItem{
+		Id:         order.Id,
+		Number:     order.FormatOrderNumber(),
+		Status:     order.Status,
+		Payed:      order.Payed,
+		Canceled:   order.Canceled,
+		Price:      order.Price,
+		Currency:   order.Currency,
+		DeliveryAt: order.DeliveryAt,
+		CourierId:  order.CourierId,
+		Comment:    order.Comment,
+		OfficeIds:  officeIds,
+		Positions:  positions,
+		CreateAt:   order.CreateAt,
+		UpdateAt:   order.UpdateAt,
+	}
+}
+
+func NewOrderBoard() *OrderBoard {
+	board := &OrderBoard{Columns: make(map[string][]*OrderBoardItem, len(ORDER_BOARD_STATUSES))}
+	for _, status := range ORDER_BOARD_STATUSES {
+		board.Columns[status] = []*OrderBoardItem{}
+	}
+
+	return board
+}
+
+// Add puts the order in the column of its status. Orders in other statuses are left off the board.
+func (b *OrderBoard) Add(item *OrderBoardItem) {
+	if column, ok := b.Columns[item.Status]; ok {
+		b.Columns[item.Status] = append(column, item)
+	}
+}
+
+func (i *OrderBoardItem) ToJson() string {
+	b, _ := json.Marshal(i)
+	return string(b)
+}
+
+func (b *OrderBoard) ToJson() string {
+	data, _ := json.Marshal(b)
+	return string(data)
+}
+
+func OrderBoardFromJson(data io.Reader) *OrderBoard {
+	var b *OrderBoard
+	json.NewDecoder(data).Decode(&b)
+	return b
+}
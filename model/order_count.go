@@ -3,6 +3,8 @@ package model
 // Options for counting users
 type OrderCountOptions struct {
 	AppId string
+	// Only count orders assigned to the courier
+	CourierId string
 	// Should include deleted users (of any type)
 	IncludeDeleted bool
 }
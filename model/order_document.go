@@ -0,0 +1,88 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+const (
+	// ORDER_DOCUMENT_TYPE_INVOICE is the invoice (счет на оплату) a corporate customer pays by
+	// a bank transfer.
+	ORDER_DOCUMENT_TYPE_INVOICE = "invoice"
+	// ORDER_DOCUMENT_TYPE_ACT is the closing document (акт) of an invoiced order once it shipped.
+	ORDER_DOCUMENT_TYPE_ACT = "act"
+)
+
+// OrderDocument is a PDF document made for an order. The file is kept by the file backend
+// and attached to the post of the document.
+type OrderDocument struct {
+	Id        string       `json:"id"`
+	AppId     string       `json:"app_id"`
+	OrderId   string       `json:"order_id"`
+	Type      string       `json:"type"`
+	Number    string       `json:"number"`
+	Buyer     *LegalEntity `json:"buyer"`
+	Total     float64      `json:"total"`
+	VatTotal  float64      `json:"vat_total"`
+	FileId    string       `json:"file_id"`
+	PostId    string       `json:"post_id"`
+	CreatorId string       `json:"creator_id"`
+	CreateAt  int64        `json:"create_at"`
+}
+
+func (d *OrderDocument) PreSave() {
+	if d.Id == "" {
+		d.Id = NewId()
+	}
+
+	d.CreateAt = GetMillis()
+}
+
+func (d *OrderDocument) IsValid() *AppError {
+	if len(d.Id) != 26 {
+		return NewAppError("OrderDocument.IsValid", "model.order_document.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(d.AppId) != 26 || len(d.OrderId) != 26 || len(d.CreatorId) != 26 {
+		return NewAppError("OrderDocument.IsValid", "model.order_document.is_valid.ids.app_error", nil, "id="+d.Id, http.StatusBadRequest)
+	}
+
+	if d.Type != ORDER_DOCUMENT_TYPE_INVOICE && d.Type != ORDER_DOCUMENT_TYPE_ACT {
+		return NewAppError("OrderDocument.IsValid", "model.order_document.is_valid.type.app_error", nil, "id="+d.Id, http.StatusBadRequest)
+	}
+
+	if d.Buyer == nil {
+		return NewAppError("OrderDocument.IsValid", "model.order_document.is_valid.buyer.app_error", nil, "id="+d.Id, http.StatusBadRequest)
+	}
+
+	if err := d.Buyer.IsValid(); err != nil {
+		return err
+	}
+
+	if len(d.FileId) != 26 {
+		return NewAppError("OrderDocument.IsValid", "model.order_document.is_valid.file_id.app_error", nil, "id="+d.Id, http.StatusBadRequest)
+	}
+
+	if d.CreateAt == 0 {
+		return NewAppError("OrderDocument.IsValid", "model.order_document.is_valid.create_at.app_error", nil, "id="+d.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (d *OrderDocument) ToJson() string {
+	b, _ := json.Marshal(d)
+	return string(b)
+}
+
+func OrderDocumentFromJson(data io.Reader) *OrderDocument {
+	var d *OrderDocument
+	json.NewDecoder(data).Decode(&d)
+	return d
+}
+
+func OrderDocumentListToJson(list []*OrderDocument) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
@@ -15,4 +15,34 @@ type OrderGetOptions struct {
 	AppId string
 	// user id
 	UserId string
+	// courier id
+	CourierId string
+}
+
+// ORDER_CURSOR_SORT_COLUMNS are the columns an order cursor page may be sorted by.
+var ORDER_CURSOR_SORT_COLUMNS = map[string]string{
+	"create_at":   "CreateAt",
+	"delivery_at": "DeliveryAt",
+	"price":       "Price",
+}
+
+// OrderCursorOptions filter the orders of one application for a cursor page. Terms match the
+// phone of the order or its number.
+type OrderCursorOptions struct {
+	AppId     string
+	UserId    string
+	CourierId string
+
+	Statuses    []string
+	CreatedFrom int64
+	CreatedTo   int64
+	OfficeId    string
+	PaySystemId string
+	AmountFrom  *float64
+	AmountTo    *float64
+	Terms       string
+
+	Sort  *CursorSort
+	After *PageCursor
+	Limit int
 }
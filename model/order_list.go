@@ -10,6 +10,8 @@ type OrderList struct {
 	Order  []string          `json:"order"`
 	Orders map[string]*Order `json:"orders"`
 	Total  string            `json:"total"`
+	// NextCursor is the after cursor of the next page, empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 func NewOrderList() *OrderList {
@@ -98,6 +100,12 @@ func (o *OrderList) Extend(other *OrderList) {
 	}
 }
 
+func (o *OrderList) SanitizeCustomer() {
+	for _, order := range o.Orders {
+		order.SanitizeCustomer()
+	}
+}
+
 func (o *OrderList) SortBy(column string, descending bool) {
 	sort.Slice(o.Order, func(i, j int) bool {
 		if descending {
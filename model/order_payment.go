@@ -0,0 +1,176 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+)
+
+const (
+	// бонусы, списанные при оформлении заказа
+	ORDER_PAYMENT_TENDER_BONUS = "bonus"
+	// наличные, принятые курьером или кассиром
+	ORDER_PAYMENT_TENDER_CASH = "cash"
+	// оплата картой через эквайринг
+	ORDER_PAYMENT_TENDER_CARD = "card"
+	// оплата картой через терминал при получении
+	ORDER_PAYMENT_TENDER_TERMINAL = "terminal"
+
+	ORDER_PAYMENT_STATUS_PAID     = "paid"
+	ORDER_PAYMENT_STATUS_REFUNDED = "refunded"
+
+	ORDER_PAYMENT_REFERENCE_MAX_LENGTH = 64
+)
+
+// OrderPayment is one tender the order was paid with. Reference is the id of the payment at the
+// provider: the acquirer order for cards, the slip number of the terminal.
+type OrderPayment struct {
+	Id             string  `json:"id"`
+	OrderId        string  `json:"order_id"`
+	Tender         string  `json:"tender"`
+	Amount         float64 `json:"amount"`
+	RefundedAmount float64 `json:"refunded_amount"`
+	Status         string  `json:"status"`
+	PaySystemId    string  `json:"pay_system_id"`
+	Reference      string  `json:"reference"`
+	CreatorId      string  `json:"creator_id"`
+	CreateAt       int64   `json:"create_at"`
+	UpdateAt       int64   `json:"update_at"`
+}
+
+// OrderPaymentRequest is the payment the cashier took in person.
+type OrderPaymentRequest struct {
+	Tender    string  `json:"tender"`
+	Amount    float64 `json:"amount"`
+	Reference string  `json:"reference"`
+}
+
+type OrderRefundRequest struct {
+	Amount float64 `json:"amount"`
+}
+
+func (p *OrderPayment) ToJson() string {
+	b, _ := json.Marshal(p)
+	return string(b)
+}
+
+func OrderPaymentListToJson(list []*OrderPayment) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func OrderPaymentRequestFromJson(data io.Reader) *OrderPaymentRequest {
+	var o *OrderPaymentRequest
+	json.NewDecoder(data).Decode(&o)
+	return o
+}
+
+func OrderRefundRequestFromJson(data io.Reader) *OrderRefundRequest {
+	var o *OrderRefundRequest
+	json.NewDecoder(data).Decode(&o)
+	return o
+}
+
+func IsValidOrderPaymentTender(tender string) bool {
+	switch tender {
+	case ORDER_PAYMENT_TENDER_BONUS, ORDER_PAYMENT_TENDER_CASH, ORDER_PAYMENT_TENDER_CARD, ORDER_PAYMENT_TENDER_TERMINAL:
+		return true
+	}
+
+	return false
+}
+
+// IsInPersonOrderPaymentTender tells whether the cashier may record the tender by hand.
+func IsInPersonOrderPaymentTender(tender string) bool {
+	return tender == ORDER_PAYMENT_TENDER_CASH || tender == ORDER_PAYMENT_TENDER_TERMINAL
+}
+
+// Remaining is what can still be refunded to the tender, the bonuses are refunded in whole
+// bonuses only.
+func (p *OrderPayment) Remaining() float64 {
+	if p.Tender == ORDER_PAYMENT_TENDER_BONUS {
+		return math.Floor(p.Amount - p.RefundedAmount)
+	}
+
+	return p.Amount - p.RefundedAmount
+}
+
+func (p *OrderPayment) PreSave() {
+	if p.Id == "" {
+		p.Id = NewId()
+	}
+
+	if p.Status == "" {
+		p.Status = ORDER_PAYMENT_STATUS_PAID
+	}
+
+	p.Amount = math.Round(p.Amount*100) / 100
+
+	p.CreateAt = GetMillis()
+	p.UpdateAt = p.CreateAt
+}
+
+func (p *OrderPayment) PreUpdate() {
+	p.UpdateAt = GetMillis()
+}
+
+func (p *OrderPayment) IsValid() *AppError {
+	if len(p.Id) != 26 {
+		return NewAppError("OrderPayment.IsValid", "model.order_payment.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(p.OrderId) != 26 {
+		return NewAppError("OrderPayment.IsValid", "model.order_payment.is_valid.order_id.app_error", nil, "id="+p.Id, http.StatusBadRequest)
+	}
+
+	if !IsValidOrderPaymentTender(p.Tender) {
+		return NewAppError("OrderPayment.IsValid", "model.order_payment.is_valid.tender.app_error", nil, "id="+p.Id, http.StatusBadRequest)
+	}
+
+	if p.Amount <= 0 {
+		return NewAppError("OrderPayment.IsValid", "model.order_payment.is_valid.amount.app_error", nil, "id="+p.Id, http.StatusBadRequest)
+	}
+
+	if p.RefundedAmount < 0 || p.RefundedAmount > p.Amount {
+		return NewAppError("OrderPayment.IsValid", "model.order_payment.is_valid.refunded_amount.app_error", nil, "id="+p.Id, http.StatusBadRequest)
+	}
+
+	if p.Status != ORDER_PAYMENT_STATUS_PAID && p.Status != ORDER_PAYMENT_STATUS_REFUNDED {
+		return NewAppError("OrderPayment.IsValid", "model.order_payment.is_valid.status.app_error", nil, "id="+p.Id, http.StatusBadRequest)
+	}
+
+	if len(p.Reference) > ORDER_PAYMENT_REFERENCE_MAX_LENGTH {
+		return NewAppError("OrderPayment.IsValid", "model.order_payment.is_valid.reference.app_error", nil, "id="+p.Id, http.StatusBadRequest)
+	}
+
+	if p.CreateAt == 0 {
+		return NewAppError("OrderPayment.IsValid", "model.order_payment.is_valid.create_at.app_error", nil, "id="+p.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// AmountDue is what the money tenders have to cover, the bonuses are already taken off the price.
+func (o *Order) AmountDue() float64 {
+	return o.Price + o.PriceDelivery
+}
+
+// AmountPaid sums up the money tenders of the order less what was refunded.
+func (o *Order) AmountPaid() float64 {
+	var paid float64
+	for _, payment := range o.Payments {
+		if payment.Tender == ORDER_PAYMENT_TENDER_BONUS {
+			continue
+		}
+
+		paid += payment.Remaining()
+	}
+
+	return math.Round(paid*100) / 100
+}
+
+// IsCovered tells whether the tenders pay the order in full.
+func (o *Order) IsCovered() bool {
+	return o.AmountPaid() >= math.Round(o.AmountDue()*100)/100
+}
@@ -0,0 +1,129 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const (
+	ORDER_TRACKING_TYPE_STATUS   = "status"
+	ORDER_TRACKING_TYPE_PAYMENT  = "payment"
+	ORDER_TRACKING_TYPE_COURIER  = "courier"
+	ORDER_TRACKING_TYPE_LOCATION = "location"
+
+	ORDER_PAYMENT_STATE_PENDING  = "pending"
+	ORDER_PAYMENT_STATE_PAID     = "paid"
+	ORDER_PAYMENT_STATE_CANCELED = "canceled"
+	ORDER_PAYMENT_STATE_REFUNDED = "refunded"
+
+	ORDER_TRACKING_MAX_ORDERS           = 50
+	ORDER_TRACKING_POLL_TIMEOUT_DEFAULT = 25
+	ORDER_TRACKING_POLL_TIMEOUT_MAXIMUM = 60
+
+	// COURIER_DEFAULT_SPEED is the speed in meters per second the arrival of a courier is
+	// estimated with when the courier does not report one.
+	COURIER_DEFAULT_SPEED = 7.0
+)
+
+// OrderTracking is what the customer sees of the progress of an order.
+type OrderTracking struct {
+	OrderId      string                `json:"order_id"`
+	Number       string                `json:"number"`
+	Type         string                `json:"type"`
+	Status       string                `json:"status"`
+	StatusAt     int64                 `json:"status_at"`
+	PaymentState string                `json:"payment_state"`
+	PayedAt      int64                 `json:"payed_at"`
+	EtaAt        int64                 `json:"eta_at"`
+	Courier      *OrderTrackingCourier `json:"courier,omitempty"`
+	UpdateAt     int64                 `json:"update_at"`
+}
+
+type OrderTrackingCourier struct {
+	UserId     string  `json:"user_id"`
+	FirstName  string  `json:"first_name"`
+	Phone      string  `json:"phone"`
+	Latitude   float64 `json:"lat,omitempty"`
+	Longitude  float64 `json:"long,omitempty"`
+	LocationAt int64   `json:"location_at,omitempty"`
+}
+
+// NewOrderTracking describes the order for its customer. The courier and the courier location
+// may be nil. Out for delivery the arrival is estimated from the distance the courier has left,
+// otherwise it is the delivery time of the order.
+func NewOrderTracking(order *Order, courier *User, location *CourierLocation, trackingType string) *OrderTracking {
+	t := &OrderTracking{
+		OrderId:      order.Id,
+		Number:       order.FormatOrderNumber(),
+		Type:         trackingType,
+		Status:       order.Status,
+		StatusAt:     order.StatusAt,
+		PaymentState: OrderPaymentState(order),
+		PayedAt:      order.PayedAt,
+		EtaAt:        order.DeliveryAt,
+	}
+
+	if courier != nil {
+		t.Courier = &OrderTrackingCourier{
+			UserId:    courier.Id,
+			FirstName: courier.FirstName,
+			Phone:     courier.Phone,
+		}
+
+		if location != nil && order.IsOutForDelivery() {
+			t.Courier.Latitude = location.Latitude
+			t.Courier.Longitude = location.Longitude
+			t.Courier.LocationAt = location.UpdateAt
+
+			if order.Latitude != 0 || order.Longitude != 0 {
+				speed := location.Speed
+				if speed < 1 {
+					speed = COURIER_DEFAULT_SPEED
+				}
+
+				distance := GeoDistance(location.Latitude, location.Longitude, order.Latitude, order.Longitude)
+				t.EtaAt = location.UpdateAt + int64(distance/speed*1000)
+			}
+		}
+	}
+
+	for _, at := range []int64{order.UpdateAt, order.StatusAt, order.PayedAt, order.CanceledAt, order.CourierAssignedAt} {
+		if at > t.UpdateAt {
+			t.UpdateAt = at
+		}
+	}
+	if t.Courier != nil && t.Courier.LocationAt > t.UpdateAt {
+		t.UpdateAt = t.Courier.LocationAt
+	}
+
+	return t
+}
+
+func OrderPaymentState(order *Order) string {
+	switch {
+	case order.Status == ORDER_STATUS_REFUNDED:
+		return ORDER_PAYMENT_STATE_REFUNDED
+	case order.Canceled:
+		return ORDER_PAYMENT_STATE_CANCELED
+	case order.Payed:
+		return ORDER_PAYMENT_STATE_PAID
+	default:
+		return ORDER_PAYMENT_STATE_PENDING
+	}
+}
+
+func (t *OrderTracking) ToJson() string {
+	b, _ := json.Marshal(t)
+	return string(b)
+}
+
+func OrderTrackingFromJson(data io.Reader) *OrderTracking {
+	var t *OrderTracking
+	json.NewDecoder(data).Decode(&t)
+	return t
+}
+
+func OrderTrackingListToJson(list []*OrderTracking) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
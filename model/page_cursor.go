@@ -0,0 +1,69 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+// PageCursor points after the last item of a page: the value of the sort column and the id of
+// the item. The items are ordered by the column and then by the id, so the next page neither
+// repeats nor skips items when new ones are added.
+type PageCursor struct {
+	Sort  string  `json:"s"`
+	Value float64 `json:"v"`
+	Id    string  `json:"i"`
+}
+
+// CursorSort is the column a cursor page is ordered by.
+type CursorSort struct {
+	Name       string
+	Column     string
+	Descending bool
+}
+
+func (c *PageCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodePageCursor(s string) *PageCursor {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil
+	}
+
+	var c *PageCursor
+	if err := json.Unmarshal(b, &c); err != nil || c == nil || len(c.Id) != 26 {
+		return nil
+	}
+
+	return c
+}
+
+// ParseCursorSort reads a sort like "-create_at" against the columns the list may be sorted
+// by. The list is sorted by the default column, newest first, when the sort is empty.
+func ParseCursorSort(s string, columns map[string]string, defaultName string) (*CursorSort, bool) {
+	if len(s) == 0 {
+		return &CursorSort{Name: defaultName, Column: columns[defaultName], Descending: true}, true
+	}
+
+	descending := strings.HasPrefix(s, "-")
+	name := strings.TrimPrefix(s, "-")
+
+	column, ok := columns[name]
+	if !ok {
+		return nil, false
+	}
+
+	return &CursorSort{Name: name, Column: column, Descending: descending}, true
+}
+
+// Key is how the sort is kept in the cursor, so a cursor is not used with another sort.
+func (s *CursorSort) Key() string {
+	if s.Descending {
+		return "-" + s.Name
+	}
+
+	return s.Name
+}
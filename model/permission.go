@@ -82,6 +82,12 @@ var PERMISSION_READ_BOTS *Permission
 var PERMISSION_READ_OTHERS_BOTS *Permission
 var PERMISSION_MANAGE_BOTS *Permission
 var PERMISSION_MANAGE_OTHERS_BOTS *Permission
+var PERMISSION_MANAGE_STAFF *Permission
+var PERMISSION_VIEW_ORDERS *Permission
+var PERMISSION_VIEW_ASSIGNED_ORDERS *Permission
+var PERMISSION_VIEW_ORDER_CUSTOMER *Permission
+var PERMISSION_MANAGE_ORDERS *Permission
+var PERMISSION_EDIT_ORDER_PAYMENT *Permission
 
 // General permission that encompasses all system admin functions
 // in the future this could be broken up to allow access to some
@@ -516,6 +522,42 @@ func initializePermissions() {
 		"authentication.permisssions.manage_jobs.description",
 		PERMISSION_SCOPE_SYSTEM,
 	}
+	PERMISSION_MANAGE_STAFF = &Permission{
+		"manage_staff",
+		"authentication.permissions.manage_staff.name",
+		"authentication.permissions.manage_staff.description",
+		PERMISSION_SCOPE_TEAM,
+	}
+	PERMISSION_VIEW_ORDERS = &Permission{
+		"view_orders",
+		"authentication.permissions.view_orders.name",
+		"authentication.permissions.view_orders.description",
+		PERMISSION_SCOPE_TEAM,
+	}
+	PERMISSION_VIEW_ASSIGNED_ORDERS = &Permission{
+		"view_assigned_orders",
+		"authentication.permissions.view_assigned_orders.name",
+		"authentication.permissions.view_assigned_orders.description",
+		PERMISSION_SCOPE_TEAM,
+	}
+	PERMISSION_VIEW_ORDER_CUSTOMER = &Permission{
+		"view_order_customer",
+		"authentication.permissions.view_order_customer.name",
+		"authentication.permissions.view_order_customer.description",
+		PERMISSION_SCOPE_TEAM,
+	}
+	PERMISSION_MANAGE_ORDERS = &Permission{
+		"manage_orders",
+		"authentication.permissions.manage_orders.name",
+		"authentication.permissions.manage_orders.description",
+		PERMISSION_SCOPE_TEAM,
+	}
+	PERMISSION_EDIT_ORDER_PAYMENT = &Permission{
+		"edit_order_payment",
+		"authentication.permissions.edit_order_payment.name",
+		"authentication.permissions.edit_order_payment.description",
+		PERMISSION_SCOPE_TEAM,
+	}
 
 	ALL_PERMISSIONS = []*Permission{
 		PERMISSION_INVITE_USER,
@@ -587,6 +629,12 @@ func initializePermissions() {
 		PERMISSION_READ_OTHERS_BOTS,
 		PERMISSION_MANAGE_BOTS,
 		PERMISSION_MANAGE_OTHERS_BOTS,
+		PERMISSION_MANAGE_STAFF,
+		PERMISSION_VIEW_ORDERS,
+		PERMISSION_VIEW_ASSIGNED_ORDERS,
+		PERMISSION_VIEW_ORDER_CUSTOMER,
+		PERMISSION_MANAGE_ORDERS,
+		PERMISSION_EDIT_ORDER_PAYMENT,
 		PERMISSION_MANAGE_SYSTEM,
 	}
 }
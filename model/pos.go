@@ -0,0 +1,227 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+const (
+	POS_PROVIDER_TYPE_HTTP = "http"
+
+	POS_SYNC_STATUS_PENDING = "pending"
+	POS_SYNC_STATUS_SYNCED  = "synced"
+	POS_SYNC_STATUS_FAILED  = "failed"
+
+	POS_SYNC_ERROR_MAX_SIZE    = 1024
+	POS_EXTERNAL_ID_MAX_LENGTH = 128
+
+	// statuses the POS reports back for an exported order
+	POS_ORDER_STATUS_ACCEPTED = "accepted"
+	POS_ORDER_STATUS_COOKING  = "cooking"
+	POS_ORDER_STATUS_READY    = "ready"
+	POS_ORDER_STATUS_ON_WAY   = "on_way"
+	POS_ORDER_STATUS_CLOSED   = "closed"
+	POS_ORDER_STATUS_CANCELED = "canceled"
+)
+
+var posOrderStatuses = map[string]string{
+	POS_ORDER_STATUS_ACCEPTED: ORDER_STATUS_AWAITING_FULFILLMENT,
+	POS_ORDER_STATUS_COOKING:  ORDER_STATUS_AWAITING_FULFILLMENT,
+	POS_ORDER_STATUS_READY:    ORDER_STATUS_AWAITING_PICKUP,
+	POS_ORDER_STATUS_ON_WAY:   ORDER_STATUS_AWAITING_SHIPMENT,
+	POS_ORDER_STATUS_CLOSED:   ORDER_STATUS_SHIPPED,
+	POS_ORDER_STATUS_CANCELED: ORDER_STATUS_DECLINED,
+}
+
+// PosStatusToOrderStatus maps the status reported by the POS to the status of our order.
+func PosStatusToOrderStatus(status string) (string, bool) {
+	orderStatus, ok := posOrderStatuses[status]
+	return orderStatus, ok
+}
+
+// PosProductMapping links a product of the application to its id in the POS menu.
+type PosProductMapping struct {
+	AppId      string `json:"app_id"`
+	ProductId  string `json:"product_id"`
+	ExternalId string `json:"external_id"`
+	CreateAt   int64  `json:"create_at"`
+	UpdateAt   int64  `json:"update_at"`
+}
+
+func (m *PosProductMapping) ToJson() string {
+	b, _ := json.Marshal(m)
+	return string(b)
+}
+
+func PosProductMappingFromJson(data io.Reader) *PosProductMapping {
+	var m *PosProductMapping
+	json.NewDecoder(data).Decode(&m)
+	return m
+}
+
+func PosProductMappingListToJson(list []*PosProductMapping) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func (m *PosProductMapping) PreSave() {
+	if m.CreateAt == 0 {
+		m.CreateAt = GetMillis()
+	}
+	m.UpdateAt = GetMillis()
+}
+
+func (m *PosProductMapping) IsValid() *AppError {
+	if len(m.AppId) != 26 {
+		return NewAppError("PosProductMapping.IsValid", "model.pos_product_mapping.is_valid.app_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(m.ProductId) != 26 {
+		return NewAppError("PosProductMapping.IsValid", "model.pos_product_mapping.is_valid.product_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(m.ExternalId) == 0 || len(m.ExternalId) > POS_EXTERNAL_ID_MAX_LENGTH {
+		return NewAppError("PosProductMapping.IsValid", "model.pos_product_mapping.is_valid.external_id.app_error", nil, "product_id="+m.ProductId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// PosOrderSync keeps the state of the order export to the POS of the application.
+type PosOrderSync struct {
+	OrderId        string `json:"order_id"`
+	AppId          string `json:"app_id"`
+	Provider       string `json:"provider"`
+	ExternalId     string `json:"external_id"`
+	Status         string `json:"status"`
+	ExternalStatus string `json:"external_status"`
+	Attempts       int    `json:"attempts"`
+	LastError      string `json:"last_error"`
+	SyncedAt       int64  `json:"synced_at"`
+	CreateAt       int64  `json:"create_at"`
+	UpdateAt       int64  `json:"update_at"`
+}
+
+func (ps *PosOrderSync) ToJson() string {
+	b, _ := json.Marshal(ps)
+	return string(b)
+}
+
+func PosOrderSyncListToJson(list []*PosOrderSync) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func (ps *PosOrderSync) PreSave() {
+	if ps.CreateAt == 0 {
+		ps.CreateAt = GetMillis()
+	}
+	if ps.Status == "" {
+		ps.Status = POS_SYNC_STATUS_PENDING
+	}
+	ps.UpdateAt = GetMillis()
+}
+
+func (ps *PosOrderSync) IsValid() *AppError {
+	if len(ps.OrderId) != 26 {
+		return NewAppError("PosOrderSync.IsValid", "model.pos_order_sync.is_valid.order_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(ps.AppId) != 26 {
+		return NewAppError("PosOrderSync.IsValid", "model.pos_order_sync.is_valid.app_id.app_error", nil, "order_id="+ps.OrderId, http.StatusBadRequest)
+	}
+
+	if len(ps.ExternalId) > POS_EXTERNAL_ID_MAX_LENGTH {
+		return NewAppError("PosOrderSync.IsValid", "model.pos_order_sync.is_valid.external_id.app_error", nil, "order_id="+ps.OrderId, http.StatusBadRequest)
+	}
+
+	switch ps.Status {
+	case POS_SYNC_STATUS_PENDING:
+	case POS_SYNC_STATUS_SYNCED:
+	case POS_SYNC_STATUS_FAILED:
+	default:
+		return NewAppError("PosOrderSync.IsValid", "model.pos_order_sync.is_valid.status.app_error", nil, "order_id="+ps.OrderId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (ps *PosOrderSync) SetSynced(externalId, externalStatus string) {
+	ps.Attempts++
+	ps.Status = POS_SYNC_STATUS_SYNCED
+	ps.ExternalId = externalId
+	ps.LastError = ""
+	ps.SyncedAt = GetMillis()
+	if len(externalStatus) > 0 {
+		ps.ExternalStatus = externalStatus
+	}
+}
+
+func (ps *PosOrderSync) SetFailed(errMsg string) {
+	ps.Attempts++
+	ps.Status = POS_SYNC_STATUS_FAILED
+	if len(errMsg) > POS_SYNC_ERROR_MAX_SIZE {
+		errMsg = errMsg[:POS_SYNC_ERROR_MAX_SIZE]
+	}
+	ps.LastError = errMsg
+}
+
+// PosOrder is the order in the format sent to the POS.
+type PosOrder struct {
+	Id            string          `json:"id"`
+	Number        string          `json:"number"`
+	Phone         string          `json:"phone"`
+	CustomerName  string          `json:"customer_name"`
+	Address       string          `json:"address"`
+	Latitude      float64         `json:"lat,omitempty"`
+	Longitude     float64         `json:"long,omitempty"`
+	Comment       string          `json:"comment"`
+	PaymentType   string          `json:"payment_type"`
+	Payed         bool            `json:"payed"`
+	Price         float64         `json:"price"`
+	PriceDelivery float64         `json:"price_delivery"`
+	DiscountValue float64         `json:"discount_value"`
+	Currency      string          `json:"currency"`
+	DeliveryAt    int64           `json:"delivery_at"`
+	CreateAt      int64           `json:"create_at"`
+	Items         []*PosOrderItem `json:"items"`
+}
+
+type PosOrderItem struct {
+	ExternalId string  `json:"external_id"`
+	ProductId  string  `json:"product_id"`
+	Name       string  `json:"name"`
+	Quantity   int     `json:"quantity"`
+	Price      float64 `json:"price"`
+	Amount     float64 `json:"amount"`
+}
+
+func (o *PosOrder) ToJson() string {
+	b, _ := json.Marshal(o)
+	return string(b)
+}
+
+func PosOrderFromJson(data io.Reader) *PosOrder {
+	var o *PosOrder
+	json.NewDecoder(data).Decode(&o)
+	return o
+}
+
+// PosOrderStatus is the status feedback the POS sends for an exported order.
+type PosOrderStatus struct {
+	OrderId    string `json:"order_id"`
+	ExternalId string `json:"external_id"`
+	Status     string `json:"status"`
+}
+
+func (s *PosOrderStatus) ToJson() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func PosOrderStatusFromJson(data io.Reader) *PosOrderStatus {
+	var s *PosOrderStatus
+	json.NewDecoder(data).Decode(&s)
+	return s
+}
@@ -0,0 +1,138 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+const (
+	PRINT_FORMAT_ESCPOS = "escpos"
+	PRINT_FORMAT_PDF    = "pdf"
+
+	PRINT_PAPER_WIDTH_58 = 58
+	PRINT_PAPER_WIDTH_80 = 80
+
+	PRINT_TICKET_STATUS_PENDING = "pending"
+	PRINT_TICKET_STATUS_SENT    = "sent"
+	PRINT_TICKET_STATUS_PRINTED = "printed"
+	PRINT_TICKET_STATUS_FAILED  = "failed"
+
+	PRINT_TICKET_REASON_PAID     = "paid"
+	PRINT_TICKET_REASON_ACCEPTED = "accepted"
+	PRINT_TICKET_REASON_REPRINT  = "reprint"
+
+	PRINT_QUEUE_MAX_TICKETS = 20
+
+	// PRINT_TICKET_RESEND_MILLIS is how long a ticket handed to the print agent waits for the
+	// acknowledgement before it is handed out again.
+	PRINT_TICKET_RESEND_MILLIS = 2 * 60 * 1000
+)
+
+// PrintTicket is a kitchen ticket of an order waiting in the print queue of an office. The
+// content is rendered when the print agent takes the ticket. Copy is 0 for the ticket queued
+// when the order is paid or accepted and reprints are numbered from 1, an office gets one
+// ticket of each copy per order.
+type PrintTicket struct {
+	Id          string `json:"id"`
+	AppId       string `json:"app_id"`
+	OfficeId    string `json:"office_id"`
+	OrderId     string `json:"order_id"`
+	Format      string `json:"format"`
+	PaperWidth  int    `json:"paper_width"`
+	Reason      string `json:"reason"`
+	Copy        int    `json:"copy"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+	Attempts    int    `json:"attempts"`
+	RequestedBy string `json:"requested_by,omitempty"`
+	CreateAt    int64  `json:"create_at"`
+	UpdateAt    int64  `json:"update_at"`
+	SentAt      int64  `json:"sent_at"`
+	PrintedAt   int64  `json:"printed_at"`
+
+	Content []byte `db:"-" json:"content,omitempty"`
+}
+
+// PrintTicketAck is what the print agent reports once it tried to print a ticket.
+type PrintTicketAck struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+}
+
+func (t *PrintTicket) PreSave() {
+	if t.Id == "" {
+		t.Id = NewId()
+	}
+
+	if t.Status == "" {
+		t.Status = PRINT_TICKET_STATUS_PENDING
+	}
+
+	t.CreateAt = GetMillis()
+	t.UpdateAt = t.CreateAt
+}
+
+func (t *PrintTicket) PreUpdate() {
+	t.UpdateAt = GetMillis()
+}
+
+func (t *PrintTicket) IsValid() *AppError {
+	if len(t.Id) != 26 {
+		return NewAppError("PrintTicket.IsValid", "model.print_ticket.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(t.AppId) != 26 || len(t.OfficeId) != 26 || len(t.OrderId) != 26 {
+		return NewAppError("PrintTicket.IsValid", "model.print_ticket.is_valid.ids.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	if !IsValidPrintFormat(t.Format) || !IsValidPrintPaperWidth(t.PaperWidth) {
+		return NewAppError("PrintTicket.IsValid", "model.print_ticket.is_valid.format.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	switch t.Reason {
+	case PRINT_TICKET_REASON_PAID, PRINT_TICKET_REASON_ACCEPTED, PRINT_TICKET_REASON_REPRINT:
+	default:
+		return NewAppError("PrintTicket.IsValid", "model.print_ticket.is_valid.reason.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	if t.CreateAt == 0 {
+		return NewAppError("PrintTicket.IsValid", "model.print_ticket.is_valid.create_at.app_error", nil, "id="+t.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (a *PrintTicketAck) IsValid() bool {
+	return a.Status == PRINT_TICKET_STATUS_PRINTED || a.Status == PRINT_TICKET_STATUS_FAILED
+}
+
+func IsValidPrintFormat(format string) bool {
+	return format == PRINT_FORMAT_ESCPOS || format == PRINT_FORMAT_PDF
+}
+
+func IsValidPrintPaperWidth(width int) bool {
+	return width == PRINT_PAPER_WIDTH_58 || width == PRINT_PAPER_WIDTH_80
+}
+
+func (t *PrintTicket) ToJson() string {
+	b, _ := json.Marshal(t)
+	return string(b)
+}
+
+func PrintTicketFromJson(data io.Reader) *PrintTicket {
+	var t *PrintTicket
+	json.NewDecoder(data).Decode(&t)
+	return t
+}
+
+func PrintTicketListToJson(list []*PrintTicket) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func PrintTicketAckFromJson(data io.Reader) *PrintTicketAck {
+	var a *PrintTicketAck
+	json.NewDecoder(data).Decode(&a)
+	return a
+}
@@ -12,6 +12,8 @@ const (
 	PRODUCT_STATUS_MODERATION = "moderation"
 	PRODUCT_STATUS_ACCEPTED   = "accepted"
 	PRODUCT_STATUS_REJECTED   = "rejected"
+
+	PRODUCT_TAGS_MAX_RUNES = 500
 )
 
 type Product struct {
@@ -34,8 +36,14 @@ type Product struct {
 	Extra         bool    `json:"extra"`
 	PrivateRule   bool    `json:"private_rule"`
 	Type          string  `json:"type"`
+
+	VatRate        string `json:"vat_rate"`
+	PaymentSubject string `json:"payment_subject"`
+	PaymentMethod  string `json:"payment_method"`
+
 	//Category      *Category `json:"category"`
 	FileIds          StringArray `json:"file_ids,omitempty"`
+	Tags             StringArray `json:"tags,omitempty"`
 	Category         *Category   `json:"category,omitempty" db:"-"`
 	Media            []*FileInfo `db:"-" json:"media,omitempty"`
 	Offices          []*Office   `db:"-" json:"offices,omitempty"`
@@ -49,6 +57,7 @@ type ProductPatch struct {
 	Description      *string      `json:"description"`
 	CategoryId       *string      `json:"category_id"`
 	FileIds          *StringArray `json:"file_ids"`
+	Tags             *StringArray `json:"tags"`
 	Price            *float64     `json:"price,string"`
 	DiscountLimit    *float64     `json:"discount_limit,string"`
 	Cashback         *float64     `json:"cashback,string"`
@@ -61,6 +70,10 @@ type ProductPatch struct {
 	Offices          *[]*Office   `json:"offices"`
 	ExtraProductList *[]*Product  `json:"extra_product_list"`
 	Required         *bool        `json:"required"`
+
+	VatRate        *string `json:"vat_rate"`
+	PaymentSubject *string `json:"payment_subject"`
+	PaymentMethod  *string `json:"payment_method"`
 }
 
 func ProductPatchFromJson(data io.Reader) *ProductPatch {
@@ -94,6 +107,11 @@ type ProductSearch struct {
 	PerPage        *int    `json:"per_page"`
 	CategoryId     *string `json:"category_id"`
 	AppId          *string `json:"app_id"`
+
+	PriceFrom *float64 `json:"price_from"`
+	PriceTo   *float64 `json:"price_to"`
+	OfficeId  *string  `json:"office_id"`
+	Tags      []string `json:"tags"`
 }
 
 func ProductSearchFromJson(data io.Reader) *ProductSearch {
@@ -123,6 +141,9 @@ func (p *Product) Patch(patch *ProductPatch) {
 	if patch.FileIds != nil {
 		p.FileIds = *patch.FileIds
 	}
+	if patch.Tags != nil {
+		p.Tags = *patch.Tags
+	}
 	if patch.Price != nil {
 		p.Price = *patch.Price
 	}
@@ -159,6 +180,15 @@ func (p *Product) Patch(patch *ProductPatch) {
 	if patch.Required != nil {
 		p.Required = *patch.Required
 	}
+	if patch.VatRate != nil {
+		p.VatRate = *patch.VatRate
+	}
+	if patch.PaymentSubject != nil {
+		p.PaymentSubject = *patch.PaymentSubject
+	}
+	if patch.PaymentMethod != nil {
+		p.PaymentMethod = *patch.PaymentMethod
+	}
 }
 
 func (product *Product) ToJson() string {
@@ -195,6 +225,12 @@ func (o *Product) PreCommit() {
 	}
 
 	o.FileIds = RemoveDuplicateStrings(o.FileIds)
+
+	if o.Tags == nil {
+		o.Tags = []string{}
+	}
+
+	o.Tags = RemoveDuplicateStrings(o.Tags)
 }
 
 func (o *Product) MakeNonNil() {
@@ -223,5 +259,21 @@ func (o *Product) IsValid() *AppError {
 		return NewAppError("Product.IsValid", "model.product.is_valid.file_ids.app_error", nil, "id="+o.Id, http.StatusBadRequest)
 	}
 
+	if utf8.RuneCountInString(ArrayToJson(o.Tags)) > PRODUCT_TAGS_MAX_RUNES {
+		return NewAppError("Product.IsValid", "model.product.is_valid.tags.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.VatRate) > 0 && !IsValidVatRate(o.VatRate) {
+		return NewAppError("Product.IsValid", "model.product.is_valid.vat_rate.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.PaymentSubject) > 0 && !IsValidPaymentSubject(o.PaymentSubject) {
+		return NewAppError("Product.IsValid", "model.product.is_valid.payment_subject.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.PaymentMethod) > 0 && !IsValidPaymentMethod(o.PaymentMethod) {
+		return NewAppError("Product.IsValid", "model.product.is_valid.payment_method.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
 	return nil
 }
@@ -0,0 +1,128 @@
+package model
+
+import (
+	"encoding/json"
+)
+
+// PRODUCT_SEARCH_PRICE_RANGES are the bounds of the price facet, the last range has no upper bound.
+var PRODUCT_SEARCH_PRICE_RANGES = []float64{0, 300, 500, 1000, 2000, 5000}
+
+// ProductSearchParams is the search of the catalog of one application. Only the products that
+// are active and accepted by the moderation are found. CategoryIds are the category and its
+// subcategories, filled in by the app so the products of the whole subtree are found.
+type ProductSearchParams struct {
+	Terms       string
+	CategoryId  string
+	CategoryIds []string
+	PriceFrom   *float64
+	PriceTo     *float64
+	OfficeId    string
+	Tags        []string
+	Page        int
+	PerPage     int
+}
+
+// ProductSearchFacet is the number of the found products with the key. From and To bound the
+// price ranges.
+type ProductSearchFacet struct {
+	Key   string   `json:"key"`
+	From  *float64 `json:"from,omitempty"`
+	To    *float64 `json:"to,omitempty"`
+	Count int64    `json:"count"`
+}
+
+type ProductSearchFacets struct {
+	Categories []*ProductSearchFacet `json:"categories"`
+	Prices     []*ProductSearchFacet `json:"prices"`
+	Offices    []*ProductSearchFacet `json:"offices"`
+	Tags       []*ProductSearchFacet `json:"tags"`
+}
+
+type ProductSearchResults struct {
+	*ProductList
+	Total  int64                `json:"total"`
+	Facets *ProductSearchFacets `json:"facets,omitempty"`
+}
+
+func NewProductSearchFacets() *ProductSearchFacets {
+	return &ProductSearchFacets{
+		Categories: []*ProductSearchFacet{},
+		Prices:     []*ProductSearchFacet{},
+		Offices:    []*ProductSearchFacet{},
+		Tags:       []*ProductSearchFacet{},
+	}
+}
+
+func (o *ProductSearchResults) ToJson() string {
+	b, _ := json.Marshal(o)
+	return string(b)
+}
+
+func (p *ProductSearch) ToParams() *ProductSearchParams {
+	params := &ProductSearchParams{
+		PriceFrom: p.PriceFrom,
+		PriceTo:   p.PriceTo,
+		Tags:      p.Tags,
+		PerPage:   60,
+	}
+
+	if p.Terms != nil {
+		params.Terms = *p.Terms
+	}
+
+	if p.CategoryId != nil {
+		params.CategoryId = *p.CategoryId
+	}
+
+	if p.OfficeId != nil {
+		params.OfficeId = *p.OfficeId
+	}
+
+	if p.Page != nil {
+		params.Page = *p.Page
+	}
+
+	if p.PerPage != nil {
+		params.PerPage = *p.PerPage
+	}
+
+	return params
+}
+
+// Matches tells whether the product passes the filters of the search, the terms aside.
+func (p *ProductSearchParams) Matches(product *Product) bool {
+	if product.DeleteAt != 0 || !product.Active || product.Status != PRODUCT_STATUS_ACCEPTED {
+		return false
+	}
+
+	if len(p.CategoryIds) > 0 {
+		if !stringInSlice(product.CategoryId, p.CategoryIds) {
+			return false
+		}
+	} else if len(p.CategoryId) > 0 && product.CategoryId != p.CategoryId {
+		return false
+	}
+
+	if p.PriceFrom != nil && product.Price < *p.PriceFrom {
+		return false
+	}
+
+	if p.PriceTo != nil && product.Price > *p.PriceTo {
+		return false
+	}
+
+	for _, tag := range p.Tags {
+		found := false
+		for _, t := range product.Tags {
+			if t == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
@@ -43,6 +43,7 @@ type PushNotification struct {
 	FromWebhook      string `json:"from_webhook"`
 	Version          string `json:"version"`
 	PromoId          string `json:"promo_id"`
+	CampaignId       string `json:"campaign_id,omitempty"`
 }
 
 func (me *PushNotification) ToJson() string {
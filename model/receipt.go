@@ -0,0 +1,100 @@
+package model
+
+const (
+	VAT_RATE_NONE = "none"
+	VAT_RATE_0    = "vat0"
+	VAT_RATE_10   = "vat10"
+	VAT_RATE_20   = "vat20"
+	VAT_RATE_110  = "vat110"
+	VAT_RATE_120  = "vat120"
+
+	PAYMENT_SUBJECT_COMMODITY = "commodity"
+	PAYMENT_SUBJECT_EXCISE    = "excise"
+	PAYMENT_SUBJECT_JOB       = "job"
+	PAYMENT_SUBJECT_SERVICE   = "service"
+	PAYMENT_SUBJECT_ANOTHER   = "another"
+
+	PAYMENT_METHOD_FULL_PREPAYMENT = "full_prepayment"
+	PAYMENT_METHOD_PREPAYMENT      = "prepayment"
+	PAYMENT_METHOD_ADVANCE         = "advance"
+	PAYMENT_METHOD_FULL_PAYMENT    = "full_payment"
+
+	// Tax systems (СНО) of the seller, the numbers are the ones of the acquirers API.
+	TAX_SYSTEM_COMMON                = 0
+	TAX_SYSTEM_SIMPLIFIED_INCOME     = 1
+	TAX_SYSTEM_SIMPLIFIED_NET_INCOME = 2
+	TAX_SYSTEM_IMPUTED_INCOME        = 3
+	TAX_SYSTEM_AGRICULTURAL          = 4
+	TAX_SYSTEM_PATENT                = 5
+
+	RECEIPT_STATUS_PENDING = "pending"
+	RECEIPT_STATUS_ISSUED  = "issued"
+	RECEIPT_STATUS_FAILED  = "failed"
+
+	RECEIPT_DEFAULT_MEASURE = "шт"
+)
+
+// Receipt is the cart of a paid order the way it is printed on the fiscal receipt (54-FZ).
+// Prices and amounts are in kopecks.
+type Receipt struct {
+	TaxSystem int
+	Email     string
+	Phone     string
+	Items     []*ReceiptItem
+}
+
+type ReceiptItem struct {
+	Code           string
+	Name           string
+	Measure        string
+	Quantity       int
+	Price          int64
+	Amount         int64
+	VatRate        string
+	PaymentSubject string
+	PaymentMethod  string
+}
+
+// Total is what the customer pays for the receipt, it has to match the amount of the payment.
+func (r *Receipt) Total() int64 {
+	var total int64
+	for _, item := range r.Items {
+		total += item.Amount
+	}
+
+	return total
+}
+
+// OrderReceipt is the fiscal data of an issued receipt.
+type OrderReceipt struct {
+	Status string
+	Qr     string
+	Url    string
+}
+
+func IsValidVatRate(vatRate string) bool {
+	switch vatRate {
+	case VAT_RATE_NONE, VAT_RATE_0, VAT_RATE_10, VAT_RATE_20, VAT_RATE_110, VAT_RATE_120:
+		return true
+	}
+
+	return false
+}
+
+func IsValidPaymentSubject(subject string) bool {
+	switch subject {
+	case PAYMENT_SUBJECT_COMMODITY, PAYMENT_SUBJECT_EXCISE, PAYMENT_SUBJECT_JOB, PAYMENT_SUBJECT_SERVICE, PAYMENT_SUBJECT_ANOTHER:
+		return true
+	}
+
+	return false
+}
+
+func IsValidPaymentMethod(method string) bool {
+	switch method {
+	case PAYMENT_METHOD_FULL_PREPAYMENT, PAYMENT_METHOD_PREPAYMENT, PAYMENT_METHOD_ADVANCE, PAYMENT_METHOD_FULL_PAYMENT:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,126 @@
+package model
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+const (
+	REFERRAL_PAYOUT_STATUS_CREDITED = "credited"
+	REFERRAL_PAYOUT_STATUS_REVIEW   = "review"
+	REFERRAL_PAYOUT_STATUS_REJECTED = "rejected"
+	REFERRAL_PAYOUT_STATUS_CAPPED   = "capped"
+
+	REFERRAL_FLAG_SHARED_DEVICE  = "shared_device"
+	REFERRAL_FLAG_SHARED_ADDRESS = "shared_address"
+	REFERRAL_FLAG_SHARED_CARD    = "shared_card"
+)
+
+// ReferralPayout is the reward of an inviter for an order of a customer down its referral chain.
+// Payouts flagged as suspicious wait in review and are only credited once approved.
+type ReferralPayout struct {
+	Id            string  `json:"id"`
+	AppId         string  `json:"app_id"`
+	OrderId       string  `json:"order_id"`
+	InviterId     string  `json:"inviter_id"`
+	InviteeId     string  `json:"invitee_id"`
+	Level         int     `json:"level"`
+	Value         float64 `json:"value"`
+	Status        string  `json:"status"`
+	Flags         string  `json:"flags"`
+	TransactionId string  `json:"transaction_id"`
+	ReviewerId    string  `json:"reviewer_id"`
+	CreateAt      int64   `json:"create_at"`
+	UpdateAt      int64   `json:"update_at"`
+	CreditedAt    int64   `json:"credited_at"`
+}
+
+func (p *ReferralPayout) ToJson() string {
+	b, _ := json.Marshal(p)
+	return string(b)
+}
+
+func ReferralPayoutListToJson(list []*ReferralPayout) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func (p *ReferralPayout) PreSave() {
+	if p.Id == "" {
+		p.Id = NewId()
+	}
+
+	p.CreateAt = GetMillis()
+	p.UpdateAt = p.CreateAt
+}
+
+func (p *ReferralPayout) PreUpdate() {
+	p.UpdateAt = GetMillis()
+}
+
+func (p *ReferralPayout) SetFlags(flags []string) {
+	p.Flags = strings.Join(flags, ",")
+}
+
+func (p *ReferralPayout) GetFlags() []string {
+	if len(p.Flags) == 0 {
+		return []string{}
+	}
+
+	return strings.Split(p.Flags, ",")
+}
+
+func IsValidReferralPayoutStatus(status string) bool {
+	switch status {
+	case REFERRAL_PAYOUT_STATUS_CREDITED, REFERRAL_PAYOUT_STATUS_REVIEW, REFERRAL_PAYOUT_STATUS_REJECTED, REFERRAL_PAYOUT_STATUS_CAPPED:
+		return true
+	}
+
+	return false
+}
+
+func (p *ReferralPayout) IsValid() *AppError {
+	if len(p.Id) != 26 {
+		return NewAppError("ReferralPayout.IsValid", "model.referral_payout.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(p.AppId) != 26 {
+		return NewAppError("ReferralPayout.IsValid", "model.referral_payout.is_valid.app_id.app_error", nil, "id="+p.Id, http.StatusBadRequest)
+	}
+
+	if len(p.OrderId) != 26 {
+		return NewAppError("ReferralPayout.IsValid", "model.referral_payout.is_valid.order_id.app_error", nil, "id="+p.Id, http.StatusBadRequest)
+	}
+
+	if len(p.InviterId) != 26 || len(p.InviteeId) != 26 {
+		return NewAppError("ReferralPayout.IsValid", "model.referral_payout.is_valid.user_id.app_error", nil, "id="+p.Id, http.StatusBadRequest)
+	}
+
+	if p.Value < 0 {
+		return NewAppError("ReferralPayout.IsValid", "model.referral_payout.is_valid.value.app_error", nil, "id="+p.Id, http.StatusBadRequest)
+	}
+
+	if !IsValidReferralPayoutStatus(p.Status) {
+		return NewAppError("ReferralPayout.IsValid", "model.referral_payout.is_valid.status.app_error", nil, "id="+p.Id, http.StatusBadRequest)
+	}
+
+	if p.CreateAt == 0 {
+		return NewAppError("ReferralPayout.IsValid", "model.referral_payout.is_valid.create_at.app_error", nil, "id="+p.Id, http.StatusBadRequest)
+	}
+
+	if p.UpdateAt == 0 {
+		return NewAppError("ReferralPayout.IsValid", "model.referral_payout.is_valid.update_at.app_error", nil, "id="+p.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// UserDevice remembers a device the customer has signed in from, so accounts sharing a device
+// can be told apart from the sessions that may be long gone.
+type UserDevice struct {
+	UserId   string `json:"user_id"`
+	DeviceId string `json:"device_id"`
+	CreateAt int64  `json:"create_at"`
+	UpdateAt int64  `json:"update_at"`
+}
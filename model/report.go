@@ -0,0 +1,262 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	REPORT_HOUR_MILLIS = 60 * 60 * 1000
+	REPORT_DAY_MILLIS  = 24 * REPORT_HOUR_MILLIS
+	REPORT_WEEK_MILLIS = 7 * REPORT_DAY_MILLIS
+
+	// REPORT_MONDAY_MILLIS is the first monday after the epoch, weeks of the reports start on mondays.
+	REPORT_MONDAY_MILLIS = 4 * REPORT_DAY_MILLIS
+
+	REPORT_MAX_DAYS = 731
+
+	REPORT_DEFAULT_COHORT_WEEKS = 12
+	REPORT_MAX_COHORT_WEEKS     = 52
+
+	RFM_SEGMENT_CHAMPIONS   = "champions"
+	RFM_SEGMENT_LOYAL       = "loyal"
+	RFM_SEGMENT_PROMISING   = "promising"
+	RFM_SEGMENT_AT_RISK     = "at_risk"
+	RFM_SEGMENT_HIBERNATING = "hibernating"
+	RFM_SEGMENT_OTHERS      = "others"
+
+	REPORT_SORT_REVENUE  = "revenue"
+	REPORT_SORT_QUANTITY = "quantity"
+)
+
+// ReportOptions is the period and the scope of a report. Times are in milliseconds, Offset is
+// the time zone offset in seconds the days, weeks and hours are counted in.
+type ReportOptions struct {
+	AppId    string
+	OfficeId string
+	BeginAt  int64
+	ExpireAt int64
+	Offset   int
+	Sort     string
+	Limit    int
+}
+
+// CohortCount is the number of customers of the cohort that ordered in the week WeekOffset
+// weeks after their first order.
+type CohortCount struct {
+	Cohort     int64
+	WeekOffset int64
+	Customers  int64
+}
+
+type CohortWeek struct {
+	Week      int     `json:"week"`
+	Customers int64   `json:"customers"`
+	Rate      float64 `json:"rate"`
+}
+
+// CohortRetention is the customers whose first order falls in the week starting at WeekStart
+// and how many of them came back in each of the following weeks.
+type CohortRetention struct {
+	WeekStart int64         `json:"week_start"`
+	Customers int64         `json:"customers"`
+	Weeks     []*CohortWeek `json:"weeks"`
+}
+
+func CohortRetentionListToJson(list []*CohortRetention) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func CohortRetentionListToCsv(list []*CohortRetention, weeks int) [][]string {
+	header := []string{"week_start", "customers"}
+	for i := 0; i <= weeks; i++ {
+		header = append(header, "week_"+strconv.Itoa(i))
+	}
+
+	rows := [][]string{header}
+	for _, cohort := range list {
+		row := []string{formatReportDate(cohort.WeekStart), strconv.FormatInt(cohort.Customers, 10)}
+		for _, week := range cohort.Weeks {
+			row = append(row, formatReportFloat(week.Rate))
+		}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+type CustomerRfm struct {
+	UserId         string  `json:"user_id"`
+	FirstName      string  `json:"first_name"`
+	LastName       string  `json:"last_name"`
+	Phone          string  `json:"phone"`
+	LastOrderAt    int64   `json:"last_order_at"`
+	Frequency      int64   `json:"frequency"`
+	Monetary       float64 `json:"monetary"`
+	RecencyScore   int     `json:"recency_score" db:"-"`
+	FrequencyScore int     `json:"frequency_score" db:"-"`
+	MonetaryScore  int     `json:"monetary_score" db:"-"`
+	Segment        string  `json:"segment" db:"-"`
+}
+
+// RfmSegmentFor names the segment of the customer from its recency and frequency scores.
+func RfmSegmentFor(recency, frequency int) string {
+	switch {
+	case recency >= 4 && frequency >= 4:
+		return RFM_SEGMENT_CHAMPIONS
+	case frequency >= 4:
+		return RFM_SEGMENT_LOYAL
+	case recency >= 4 && frequency <= 2:
+		return RFM_SEGMENT_PROMISING
+	case recency <= 2 && frequency >= 3:
+		return RFM_SEGMENT_AT_RISK
+	case recency <= 2 && frequency <= 2:
+		return RFM_SEGMENT_HIBERNATING
+	}
+
+	return RFM_SEGMENT_OTHERS
+}
+
+type RfmSegment struct {
+	Name      string  `json:"name"`
+	Customers int64   `json:"customers"`
+	Monetary  float64 `json:"monetary"`
+}
+
+type RfmReport struct {
+	Segments  []*RfmSegment  `json:"segments"`
+	Customers []*CustomerRfm `json:"customers"`
+}
+
+func (r *RfmReport) ToJson() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func (r *RfmReport) ToCsv() [][]string {
+	rows := [][]string{{"user_id", "first_name", "last_name", "phone", "last_order_at", "frequency", "monetary", "r", "f", "m", "segment"}}
+	for _, c := range r.Customers {
+		rows = append(rows, []string{
+			c.UserId,
+			c.FirstName,
+			c.LastName,
+			c.Phone,
+			formatReportDate(c.LastOrderAt),
+			strconv.FormatInt(c.Frequency, 10),
+			formatReportFloat(c.Monetary),
+			strconv.Itoa(c.RecencyScore),
+			strconv.Itoa(c.FrequencyScore),
+			strconv.Itoa(c.MonetaryScore),
+			c.Segment,
+		})
+	}
+
+	return rows
+}
+
+// SalesReportRow is the quantity sold and the revenue of a product or a category.
+type SalesReportRow struct {
+	Id       string  `json:"id"`
+	Name     string  `json:"name"`
+	Quantity int64   `json:"quantity"`
+	Revenue  float64 `json:"revenue"`
+}
+
+func SalesReportToJson(list []*SalesReportRow) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func SalesReportToCsv(list []*SalesReportRow) [][]string {
+	rows := [][]string{{"id", "name", "quantity", "revenue"}}
+	for _, row := range list {
+		rows = append(rows, []string{row.Id, row.Name, strconv.FormatInt(row.Quantity, 10), formatReportFloat(row.Revenue)})
+	}
+
+	return rows
+}
+
+// BalanceMovement is what was accrued and deducted on the day Day days after the epoch.
+type BalanceMovement struct {
+	Day      int64
+	Accrued  float64
+	Deducted float64
+}
+
+// BonusLiability is the bonus balance the customers hold at the end of the day.
+type BonusLiability struct {
+	Date        string  `json:"date"`
+	Accrued     float64 `json:"accrued"`
+	Deducted    float64 `json:"deducted"`
+	Outstanding float64 `json:"outstanding"`
+}
+
+func BonusLiabilityListToJson(list []*BonusLiability) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func BonusLiabilityListToCsv(list []*BonusLiability) [][]string {
+	rows := [][]string{{"date", "accrued", "deducted", "outstanding"}}
+	for _, row := range list {
+		rows = append(rows, []string{row.Date, formatReportFloat(row.Accrued), formatReportFloat(row.Deducted), formatReportFloat(row.Outstanding)})
+	}
+
+	return rows
+}
+
+type HourlyCheck struct {
+	Hour     int     `json:"hour"`
+	Orders   int64   `json:"orders"`
+	Revenue  float64 `json:"revenue"`
+	AvgCheck float64 `json:"avg_check" db:"-"`
+}
+
+func HourlyCheckListToJson(list []*HourlyCheck) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func HourlyCheckListToCsv(list []*HourlyCheck) [][]string {
+	rows := [][]string{{"hour", "orders", "revenue", "avg_check"}}
+	for _, row := range list {
+		rows = append(rows, []string{strconv.Itoa(row.Hour), strconv.FormatInt(row.Orders, 10), formatReportFloat(row.Revenue), formatReportFloat(row.AvgCheck)})
+	}
+
+	return rows
+}
+
+// ReportWeek numbers the week of the time from the epoch the way the report queries do.
+func ReportWeek(millis int64, offset int) int64 {
+	return (millis + int64(offset)*1000 - REPORT_MONDAY_MILLIS) / REPORT_WEEK_MILLIS
+}
+
+// ReportWeekStart is the time the numbered week starts at.
+func ReportWeekStart(week int64, offset int) int64 {
+	return week*REPORT_WEEK_MILLIS + REPORT_MONDAY_MILLIS - int64(offset)*1000
+}
+
+// ReportDay numbers the day of the time from the epoch the way the report queries do.
+func ReportDay(millis int64, offset int) int64 {
+	return (millis + int64(offset)*1000) / REPORT_DAY_MILLIS
+}
+
+// ReportDayDate formats the numbered day as a date.
+func ReportDayDate(day int64) string {
+	return time.Unix(day*REPORT_DAY_MILLIS/1000, 0).UTC().Format("2006-01-02")
+}
+
+func formatReportDate(millis int64) string {
+	if millis == 0 {
+		return ""
+	}
+
+	return time.Unix(0, millis*int64(time.Millisecond)).Format("2006-01-02")
+}
+
+func formatReportFloat(value float64) string {
+	return fmt.Sprintf("%.2f", value)
+}
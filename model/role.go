@@ -24,11 +24,23 @@ const (
 	SYSTEM_MODERATOR_ROLE_ID = "system_moderator"
 	SYSTEM_DIRECTOR_ROLE_ID  = "system_director"
 
+	APPLICATION_MANAGER_ROLE_ID = "app_manager"
+	APPLICATION_CASHIER_ROLE_ID = "app_cashier"
+	APPLICATION_COURIER_ROLE_ID = "app_courier"
+	APPLICATION_KITCHEN_ROLE_ID = "app_kitchen"
+
 	ROLE_NAME_MAX_LENGTH         = 64
 	ROLE_DISPLAY_NAME_MAX_LENGTH = 128
 	ROLE_DESCRIPTION_MAX_LENGTH  = 1024
 )
 
+var APPLICATION_STAFF_ROLES = []string{
+	APPLICATION_MANAGER_ROLE_ID,
+	APPLICATION_CASHIER_ROLE_ID,
+	APPLICATION_COURIER_ROLE_ID,
+	APPLICATION_KITCHEN_ROLE_ID,
+}
+
 type Role struct {
 	Id            string   `json:"id"`
 	Name          string   `json:"name"`
@@ -170,6 +182,15 @@ func IsValidRoleName(roleName string) bool {
 	return true
 }
 
+func IsApplicationStaffRole(roleName string) bool {
+	for _, staffRole := range APPLICATION_STAFF_ROLES {
+		if staffRole == roleName {
+			return true
+		}
+	}
+	return false
+}
+
 func MakeDefaultRoles() map[string]*Role {
 	roles := make(map[string]*Role)
 
@@ -255,6 +276,11 @@ func MakeDefaultRoles() map[string]*Role {
 			PERMISSION_MANAGE_OTHERS_SLASH_COMMANDS.Id,
 			PERMISSION_MANAGE_INCOMING_WEBHOOKS.Id,
 			PERMISSION_MANAGE_OUTGOING_WEBHOOKS.Id,
+			PERMISSION_MANAGE_STAFF.Id,
+			PERMISSION_VIEW_ORDERS.Id,
+			PERMISSION_VIEW_ORDER_CUSTOMER.Id,
+			PERMISSION_MANAGE_ORDERS.Id,
+			PERMISSION_EDIT_ORDER_PAYMENT.Id,
 		},
 		SchemeManaged: false,
 		BuiltIn:       true,
@@ -371,6 +397,59 @@ func MakeDefaultRoles() map[string]*Role {
 		BuiltIn:       true,
 	}
 
+	roles[APPLICATION_MANAGER_ROLE_ID] = &Role{
+		Name:        "app_manager",
+		DisplayName: "authentication.roles.app_manager.name",
+		Description: "authentication.roles.app_manager.description",
+		Permissions: []string{
+			PERMISSION_MANAGE_STAFF.Id,
+			PERMISSION_VIEW_ORDERS.Id,
+			PERMISSION_VIEW_ASSIGNED_ORDERS.Id,
+			PERMISSION_VIEW_ORDER_CUSTOMER.Id,
+			PERMISSION_MANAGE_ORDERS.Id,
+			PERMISSION_EDIT_ORDER_PAYMENT.Id,
+		},
+		SchemeManaged: false,
+		BuiltIn:       true,
+	}
+
+	roles[APPLICATION_CASHIER_ROLE_ID] = &Role{
+		Name:        "app_cashier",
+		DisplayName: "authentication.roles.app_cashier.name",
+		Description: "authentication.roles.app_cashier.description",
+		Permissions: []string{
+			PERMISSION_VIEW_ORDERS.Id,
+			PERMISSION_VIEW_ORDER_CUSTOMER.Id,
+			PERMISSION_EDIT_ORDER_PAYMENT.Id,
+		},
+		SchemeManaged: false,
+		BuiltIn:       true,
+	}
+
+	roles[APPLICATION_COURIER_ROLE_ID] = &Role{
+		Name:        "app_courier",
+		DisplayName: "authentication.roles.app_courier.name",
+		Description: "authentication.roles.app_courier.description",
+		Permissions: []string{
+			PERMISSION_VIEW_ASSIGNED_ORDERS.Id,
+			PERMISSION_VIEW_ORDER_CUSTOMER.Id,
+		},
+		SchemeManaged: false,
+		BuiltIn:       true,
+	}
+
+	roles[APPLICATION_KITCHEN_ROLE_ID] = &Role{
+		Name:        "app_kitchen",
+		DisplayName: "authentication.roles.app_kitchen.name",
+		Description: "authentication.roles.app_kitchen.description",
+		Permissions: []string{
+			PERMISSION_VIEW_ORDERS.Id,
+			PERMISSION_MANAGE_ORDERS.Id,
+		},
+		SchemeManaged: false,
+		BuiltIn:       true,
+	}
+
 	roles[SYSTEM_MODERATOR_ROLE_ID] = &Role{
 		Name:          "system_moderator",
 		DisplayName:   "authentication.roles.system_moderator.name",
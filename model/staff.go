@@ -0,0 +1,96 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Staff is a member of the application team together with the staff roles
+// and the offices the member works in.
+type Staff struct {
+	UserId    string   `json:"user_id"`
+	Roles     string   `json:"roles"`
+	OfficeIds []string `json:"office_ids"`
+	User      *User    `json:"user,omitempty"`
+}
+
+type StaffInvite struct {
+	Email     string   `json:"email"`
+	Phone     string   `json:"phone"`
+	Password  string   `json:"password"`
+	FirstName string   `json:"first_name"`
+	LastName  string   `json:"last_name"`
+	Roles     string   `json:"roles"`
+	OfficeIds []string `json:"office_ids"`
+}
+
+func (s *Staff) ToJson() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func StaffListToJson(list []*Staff) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func (s *Staff) GetStaffRoles() []string {
+	var roles []string
+	for _, role := range strings.Fields(s.Roles) {
+		if IsApplicationStaffRole(role) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+func (i *StaffInvite) ToJson() string {
+	b, _ := json.Marshal(i)
+	return string(b)
+}
+
+func StaffInviteFromJson(data io.Reader) *StaffInvite {
+	var invite *StaffInvite
+	json.NewDecoder(data).Decode(&invite)
+	return invite
+}
+
+func (i *StaffInvite) IsValid() *AppError {
+	if len(i.Email) == 0 || !IsValidEmail(i.Email) {
+		return NewAppError("StaffInvite.IsValid", "model.staff_invite.is_valid.email.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(i.Password) == 0 {
+		return NewAppError("StaffInvite.IsValid", "model.staff_invite.is_valid.password.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if !IsValidStaffRoles(i.Roles) {
+		return NewAppError("StaffInvite.IsValid", "model.staff_invite.is_valid.roles.app_error", nil, "roles="+i.Roles, http.StatusBadRequest)
+	}
+
+	for _, officeId := range i.OfficeIds {
+		if len(officeId) != 26 {
+			return NewAppError("StaffInvite.IsValid", "model.staff_invite.is_valid.office_id.app_error", nil, "office_id="+officeId, http.StatusBadRequest)
+		}
+	}
+
+	return nil
+}
+
+// IsValidStaffRoles reports whether roles is a non-empty, space separated list of staff roles.
+func IsValidStaffRoles(roles string) bool {
+	fields := strings.Fields(roles)
+	if len(fields) == 0 {
+		return false
+	}
+
+	for _, role := range fields {
+		if !IsApplicationStaffRole(role) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+type StaffOffice struct {
+	Id string `json:"id"`
+
+	UserId   string `json:"user_id"`
+	OfficeId string `json:"office_id"`
+	AppId    string `json:"app_id"`
+
+	CreateAt int64 `json:"create_at"`
+	UpdateAt int64 `json:"update_at"`
+	DeleteAt int64 `json:"delete_at"`
+}
+
+func (so *StaffOffice) ToJson() string {
+	b, _ := json.Marshal(so)
+	return string(b)
+}
+
+func StaffOfficeFromJson(data io.Reader) *StaffOffice {
+	var so *StaffOffice
+	json.NewDecoder(data).Decode(&so)
+	return so
+}
+
+func StaffOfficesToJson(sos []*StaffOffice) string {
+	b, _ := json.Marshal(sos)
+	return string(b)
+}
+
+func (so *StaffOffice) PreSave() {
+	if so.Id == "" {
+		so.Id = NewId()
+	}
+
+	mills := GetMillis()
+
+	if so.CreateAt == 0 {
+		so.CreateAt = mills
+	}
+
+	so.UpdateAt = mills
+}
+
+func (so *StaffOffice) IsValid() *AppError {
+	if len(so.Id) != 26 {
+		return NewAppError("StaffOffice.IsValid", "model.staff_office.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(so.UserId) != 26 {
+		return NewAppError("StaffOffice.IsValid", "model.staff_office.is_valid.user_id.app_error", nil, "id="+so.Id, http.StatusBadRequest)
+	}
+
+	if len(so.OfficeId) != 26 {
+		return NewAppError("StaffOffice.IsValid", "model.staff_office.is_valid.office_id.app_error", nil, "id="+so.Id, http.StatusBadRequest)
+	}
+
+	if len(so.AppId) != 26 {
+		return NewAppError("StaffOffice.IsValid", "model.staff_office.is_valid.app_id.app_error", nil, "id="+so.Id, http.StatusBadRequest)
+	}
+
+	if so.CreateAt == 0 {
+		return NewAppError("StaffOffice.IsValid", "model.staff_office.is_valid.create_at.app_error", nil, "id="+so.Id, http.StatusBadRequest)
+	}
+
+	if so.UpdateAt == 0 {
+		return NewAppError("StaffOffice.IsValid", "model.staff_office.is_valid.update_at.app_error", nil, "id="+so.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func NewStaffOffice(appId, officeId, userId string) *StaffOffice {
+	so := &StaffOffice{
+		AppId:    appId,
+		OfficeId: officeId,
+		UserId:   userId,
+	}
+
+	return so
+}
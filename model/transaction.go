@@ -52,6 +52,39 @@ type TransactionGetOptions struct {
 	UserId string
 }
 
+const (
+	TRANSACTION_STATUS_ACTIVE   = "active"
+	TRANSACTION_STATUS_INACTIVE = "inactive"
+)
+
+// TRANSACTION_CURSOR_SORT_COLUMNS are the columns a transaction cursor page may be sorted by.
+var TRANSACTION_CURSOR_SORT_COLUMNS = map[string]string{
+	"create_at": "CreateAt",
+	"value":     "Value",
+}
+
+// TransactionCursorOptions filter the transactions of one application for a cursor page. Terms
+// match the phone of the customer or the number of the order, the office and the pay system are
+// the ones of the order of the transaction.
+type TransactionCursorOptions struct {
+	AppId  string
+	UserId string
+
+	Status      string
+	Type        string
+	CreatedFrom int64
+	CreatedTo   int64
+	OfficeId    string
+	PaySystemId string
+	AmountFrom  *float64
+	AmountTo    *float64
+	Terms       string
+
+	Sort  *CursorSort
+	After *PageCursor
+	Limit int
+}
+
 func (p *Transaction) Patch(patch *TransactionPatch) {
 
 	if patch.Description != nil {
@@ -9,6 +9,8 @@ import (
 type TransactionList struct {
 	Order        []string                `json:"order"`
 	Transactions map[string]*Transaction `json:"transactions"`
+	// NextCursor is the after cursor of the next page, empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 func NewTransactionList() *TransactionList {
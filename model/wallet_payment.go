@@ -0,0 +1,28 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const (
+	WALLET_APPLE_PAY  = "apple_pay"
+	WALLET_GOOGLE_PAY = "google_pay"
+)
+
+// WalletPaymentRequest is the payment token the wallet of the phone issued for the order, it is
+// passed to the acquirer as is.
+type WalletPaymentRequest struct {
+	Wallet       string `json:"wallet"`
+	PaymentToken string `json:"payment_token"`
+}
+
+func WalletPaymentRequestFromJson(data io.Reader) *WalletPaymentRequest {
+	var o *WalletPaymentRequest
+	json.NewDecoder(data).Decode(&o)
+	return o
+}
+
+func IsValidWallet(wallet string) bool {
+	return wallet == WALLET_APPLE_PAY || wallet == WALLET_GOOGLE_PAY
+}
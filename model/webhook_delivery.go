@@ -0,0 +1,159 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	WEBHOOK_DELIVERY_STATUS_PENDING = "pending"
+	WEBHOOK_DELIVERY_STATUS_SUCCESS = "success"
+	WEBHOOK_DELIVERY_STATUS_FAILED  = "failed"
+	// the delivery has run out of attempts and waits in the dead-letter log for a manual replay
+	WEBHOOK_DELIVERY_STATUS_DEAD = "dead"
+
+	WEBHOOK_DELIVERY_MAX_ATTEMPTS   = 8
+	WEBHOOK_DELIVERY_BACKOFF_BASE   = 30 * time.Second
+	WEBHOOK_DELIVERY_BACKOFF_MAX    = 6 * time.Hour
+	WEBHOOK_DELIVERY_ERROR_MAX_SIZE = 1024
+)
+
+type WebhookDelivery struct {
+	Id             string `json:"id"`
+	SubscriptionId string `json:"subscription_id"`
+	AppId          string `json:"app_id"`
+	Event          string `json:"event"`
+	Payload        string `json:"payload"`
+	Status         string `json:"status"`
+	Attempts       int    `json:"attempts"`
+	ResponseStatus int    `json:"response_status"`
+	LastError      string `json:"last_error"`
+	LastAttemptAt  int64  `json:"last_attempt_at"`
+	NextAttemptAt  int64  `json:"next_attempt_at"`
+	CreateAt       int64  `json:"create_at"`
+	UpdateAt       int64  `json:"update_at"`
+}
+
+func (wd *WebhookDelivery) ToJson() string {
+	b, _ := json.Marshal(wd)
+	return string(b)
+}
+
+func WebhookDeliveryFromJson(data io.Reader) *WebhookDelivery {
+	var wd *WebhookDelivery
+	json.NewDecoder(data).Decode(&wd)
+	return wd
+}
+
+func WebhookDeliveryListToJson(list []*WebhookDelivery) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func (wd *WebhookDelivery) PreSave() {
+	if wd.Id == "" {
+		wd.Id = NewId()
+	}
+
+	if wd.Status == "" {
+		wd.Status = WEBHOOK_DELIVERY_STATUS_PENDING
+	}
+
+	wd.CreateAt = GetMillis()
+	wd.UpdateAt = wd.CreateAt
+
+	if wd.NextAttemptAt == 0 {
+		wd.NextAttemptAt = wd.CreateAt
+	}
+}
+
+func (wd *WebhookDelivery) PreUpdate() {
+	wd.UpdateAt = GetMillis()
+}
+
+func (wd *WebhookDelivery) IsValid() *AppError {
+	if len(wd.Id) != 26 {
+		return NewAppError("WebhookDelivery.IsValid", "model.webhook_delivery.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(wd.SubscriptionId) != 26 {
+		return NewAppError("WebhookDelivery.IsValid", "model.webhook_delivery.is_valid.subscription_id.app_error", nil, "id="+wd.Id, http.StatusBadRequest)
+	}
+
+	if len(wd.AppId) != 26 {
+		return NewAppError("WebhookDelivery.IsValid", "model.webhook_delivery.is_valid.app_id.app_error", nil, "id="+wd.Id, http.StatusBadRequest)
+	}
+
+	if !IsValidWebhookEvent(wd.Event) || wd.Event == WEBHOOK_EVENT_ALL {
+		return NewAppError("WebhookDelivery.IsValid", "model.webhook_delivery.is_valid.event.app_error", nil, "id="+wd.Id, http.StatusBadRequest)
+	}
+
+	switch wd.Status {
+	case WEBHOOK_DELIVERY_STATUS_PENDING:
+	case WEBHOOK_DELIVERY_STATUS_SUCCESS:
+	case WEBHOOK_DELIVERY_STATUS_FAILED:
+	case WEBHOOK_DELIVERY_STATUS_DEAD:
+	default:
+		return NewAppError("WebhookDelivery.IsValid", "model.webhook_delivery.is_valid.status.app_error", nil, "id="+wd.Id, http.StatusBadRequest)
+	}
+
+	if wd.CreateAt == 0 {
+		return NewAppError("WebhookDelivery.IsValid", "model.webhook_delivery.is_valid.create_at.app_error", nil, "id="+wd.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// SetSucceeded records a successful attempt.
+func (wd *WebhookDelivery) SetSucceeded(responseStatus int) {
+	wd.Attempts++
+	wd.LastAttemptAt = GetMillis()
+	wd.ResponseStatus = responseStatus
+	wd.LastError = ""
+	wd.Status = WEBHOOK_DELIVERY_STATUS_SUCCESS
+	wd.NextAttemptAt = 0
+}
+
+// SetFailed records a failed attempt and schedules the next one with exponential backoff,
+// or moves the delivery to the dead-letter log once the attempts are over.
+func (wd *WebhookDelivery) SetFailed(responseStatus int, errMsg string) {
+	wd.Attempts++
+	wd.LastAttemptAt = GetMillis()
+	wd.ResponseStatus = responseStatus
+
+	if len(errMsg) > WEBHOOK_DELIVERY_ERROR_MAX_SIZE {
+		errMsg = errMsg[:WEBHOOK_DELIVERY_ERROR_MAX_SIZE]
+	}
+	wd.LastError = errMsg
+
+	if wd.Attempts >= WEBHOOK_DELIVERY_MAX_ATTEMPTS {
+		wd.Status = WEBHOOK_DELIVERY_STATUS_DEAD
+		wd.NextAttemptAt = 0
+		return
+	}
+
+	backoff := WEBHOOK_DELIVERY_BACKOFF_BASE << uint(wd.Attempts-1)
+	if backoff > WEBHOOK_DELIVERY_BACKOFF_MAX {
+		backoff = WEBHOOK_DELIVERY_BACKOFF_MAX
+	}
+
+	wd.Status = WEBHOOK_DELIVERY_STATUS_FAILED
+	wd.NextAttemptAt = wd.LastAttemptAt + int64(backoff/time.Millisecond)
+}
+
+// SetDead moves the delivery to the dead-letter log without further attempts.
+func (wd *WebhookDelivery) SetDead(errMsg string) {
+	wd.LastError = errMsg
+	wd.Status = WEBHOOK_DELIVERY_STATUS_DEAD
+	wd.NextAttemptAt = 0
+}
+
+// ResetForReplay puts the delivery back to the queue with a fresh set of attempts.
+func (wd *WebhookDelivery) ResetForReplay() {
+	wd.Status = WEBHOOK_DELIVERY_STATUS_PENDING
+	wd.Attempts = 0
+	wd.LastError = ""
+	wd.NextAttemptAt = GetMillis()
+}
@@ -0,0 +1,229 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+)
+
+const (
+	WEBHOOK_EVENT_ALL              = "*"
+	WEBHOOK_EVENT_ORDER_CREATED    = "order_created"
+	WEBHOOK_EVENT_ORDER_UPDATED    = "order_updated"
+	WEBHOOK_EVENT_ORDER_PAYED      = "order_payed"
+	WEBHOOK_EVENT_ORDER_CANCELED   = "order_canceled"
+	WEBHOOK_EVENT_ORDER_REFUNDED   = "order_refunded"
+	WEBHOOK_EVENT_BALANCE_ACCRUED  = "balance_accrued"
+	WEBHOOK_EVENT_BALANCE_DEDUCTED = "balance_deducted"
+
+	WEBHOOK_HEADER_EVENT     = "X-Webhook-Event"
+	WEBHOOK_HEADER_DELIVERY  = "X-Webhook-Delivery"
+	WEBHOOK_HEADER_TIMESTAMP = "X-Webhook-Timestamp"
+	WEBHOOK_HEADER_SIGNATURE = "X-Webhook-Signature"
+
+	WEBHOOK_SUBSCRIPTION_URL_MAX_LENGTH         = 1024
+	WEBHOOK_SUBSCRIPTION_DESCRIPTION_MAX_RUNES  = 500
+	WEBHOOK_SUBSCRIPTION_SECRET_LENGTH          = 32
+	WEBHOOK_SUBSCRIPTION_EVENTS_MAX_LENGTH      = 1024
+	WEBHOOK_SUBSCRIPTION_SANITIZED_SECRET_VALUE = ""
+)
+
+var WEBHOOK_EVENTS = []string{
+	WEBHOOK_EVENT_ORDER_CREATED,
+	WEBHOOK_EVENT_ORDER_UPDATED,
+	WEBHOOK_EVENT_ORDER_PAYED,
+	WEBHOOK_EVENT_ORDER_CANCELED,
+	WEBHOOK_EVENT_ORDER_REFUNDED,
+	WEBHOOK_EVENT_BALANCE_ACCRUED,
+	WEBHOOK_EVENT_BALANCE_DEDUCTED,
+}
+
+func IsValidWebhookEvent(event string) bool {
+	if event == WEBHOOK_EVENT_ALL {
+		return true
+	}
+
+	for _, e := range WEBHOOK_EVENTS {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WebhookSubscription is an outgoing webhook of the application that receives the commerce events.
+type WebhookSubscription struct {
+	Id          string      `json:"id"`
+	AppId       string      `json:"app_id"`
+	CreatorId   string      `json:"creator_id"`
+	Url         string      `json:"url"`
+	Events      StringArray `json:"events"`
+	Secret      string      `json:"secret,omitempty"`
+	Description string      `json:"description"`
+	Active      bool        `json:"active"`
+	CreateAt    int64       `json:"create_at"`
+	UpdateAt    int64       `json:"update_at"`
+	DeleteAt    int64       `json:"delete_at"`
+}
+
+type WebhookSubscriptionPatch struct {
+	Url         *string      `json:"url"`
+	Events      *StringArray `json:"events"`
+	Description *string      `json:"description"`
+	Active      *bool        `json:"active"`
+}
+
+func (ws *WebhookSubscription) Patch(patch *WebhookSubscriptionPatch) {
+	if patch.Url != nil {
+		ws.Url = *patch.Url
+	}
+	if patch.Events != nil {
+		ws.Events = *patch.Events
+	}
+	if patch.Description != nil {
+		ws.Description = *patch.Description
+	}
+	if patch.Active != nil {
+		ws.Active = *patch.Active
+	}
+}
+
+func (ws *WebhookSubscription) ToJson() string {
+	b, _ := json.Marshal(ws)
+	return string(b)
+}
+
+func WebhookSubscriptionFromJson(data io.Reader) *WebhookSubscription {
+	var ws *WebhookSubscription
+	json.NewDecoder(data).Decode(&ws)
+	return ws
+}
+
+func WebhookSubscriptionPatchFromJson(data io.Reader) *WebhookSubscriptionPatch {
+	var patch *WebhookSubscriptionPatch
+	json.NewDecoder(data).Decode(&patch)
+	return patch
+}
+
+func WebhookSubscriptionListToJson(list []*WebhookSubscription) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func (ws *WebhookSubscription) Clone() *WebhookSubscription {
+	copy := *ws
+	return &copy
+}
+
+func (ws *WebhookSubscription) PreSave() {
+	if ws.Id == "" {
+		ws.Id = NewId()
+	}
+
+	if ws.Secret == "" {
+		ws.Secret = NewRandomString(WEBHOOK_SUBSCRIPTION_SECRET_LENGTH)
+	}
+
+	if ws.Events == nil {
+		ws.Events = StringArray{}
+	}
+
+	ws.CreateAt = GetMillis()
+	ws.UpdateAt = ws.CreateAt
+}
+
+func (ws *WebhookSubscription) PreUpdate() {
+	ws.UpdateAt = GetMillis()
+}
+
+// Sanitize removes the signing secret, it is only shown once when the subscription is created.
+func (ws *WebhookSubscription) Sanitize() {
+	ws.Secret = WEBHOOK_SUBSCRIPTION_SANITIZED_SECRET_VALUE
+}
+
+func (ws *WebhookSubscription) IsValid() *AppError {
+	if len(ws.Id) != 26 {
+		return NewAppError("WebhookSubscription.IsValid", "model.webhook_subscription.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(ws.AppId) != 26 {
+		return NewAppError("WebhookSubscription.IsValid", "model.webhook_subscription.is_valid.app_id.app_error", nil, "id="+ws.Id, http.StatusBadRequest)
+	}
+
+	if len(ws.CreatorId) != 26 {
+		return NewAppError("WebhookSubscription.IsValid", "model.webhook_subscription.is_valid.creator_id.app_error", nil, "id="+ws.Id, http.StatusBadRequest)
+	}
+
+	if len(ws.Url) == 0 || len(ws.Url) > WEBHOOK_SUBSCRIPTION_URL_MAX_LENGTH || !IsValidHttpUrl(ws.Url) {
+		return NewAppError("WebhookSubscription.IsValid", "model.webhook_subscription.is_valid.url.app_error", nil, "id="+ws.Id, http.StatusBadRequest)
+	}
+
+	if len(ws.Events) == 0 || len(ArrayToJson(ws.Events)) > WEBHOOK_SUBSCRIPTION_EVENTS_MAX_LENGTH {
+		return NewAppError("WebhookSubscription.IsValid", "model.webhook_subscription.is_valid.events.app_error", nil, "id="+ws.Id, http.StatusBadRequest)
+	}
+
+	for _, event := range ws.Events {
+		if !IsValidWebhookEvent(event) {
+			return NewAppError("WebhookSubscription.IsValid", "model.webhook_subscription.is_valid.event.app_error", nil, "id="+ws.Id+", event="+event, http.StatusBadRequest)
+		}
+	}
+
+	if len(ws.Secret) != WEBHOOK_SUBSCRIPTION_SECRET_LENGTH {
+		return NewAppError("WebhookSubscription.IsValid", "model.webhook_subscription.is_valid.secret.app_error", nil, "id="+ws.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(ws.Description) > WEBHOOK_SUBSCRIPTION_DESCRIPTION_MAX_RUNES {
+		return NewAppError("WebhookSubscription.IsValid", "model.webhook_subscription.is_valid.description.app_error", nil, "id="+ws.Id, http.StatusBadRequest)
+	}
+
+	if ws.CreateAt == 0 {
+		return NewAppError("WebhookSubscription.IsValid", "model.webhook_subscription.is_valid.create_at.app_error", nil, "id="+ws.Id, http.StatusBadRequest)
+	}
+
+	if ws.UpdateAt == 0 {
+		return NewAppError("WebhookSubscription.IsValid", "model.webhook_subscription.is_valid.update_at.app_error", nil, "id="+ws.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// IsSubscribedTo reports whether the event passes the event filter of the subscription.
+func (ws *WebhookSubscription) IsSubscribedTo(event string) bool {
+	for _, e := range ws.Events {
+		if e == WEBHOOK_EVENT_ALL || e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sign returns the value of the signature header for the payload. The receiver
+// recomputes HMAC-SHA256 of "timestamp.body" with the shared secret and compares.
+func (ws *WebhookSubscription) Sign(timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(ws.Secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookPayload is the body posted to the subscription url.
+type WebhookPayload struct {
+	Id       string      `json:"id"`
+	Event    string      `json:"event"`
+	AppId    string      `json:"app_id"`
+	CreateAt int64       `json:"create_at"`
+	Data     interface{} `json:"data"`
+}
+
+func (wp *WebhookPayload) ToJson() string {
+	b, _ := json.Marshal(wp)
+	return string(b)
+}
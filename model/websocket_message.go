@@ -52,6 +52,13 @@ const (
 	WEBSOCKET_EVENT_PRODUCT_MODERATION      = "product_status_updated"
 	WEBSOCKET_EVENT_BALANCE_UPDATED         = "balance_updated"
 	WEBSOCKET_EVENT_DEFERRED_ADDED          = "deferred_added"
+	WEBSOCKET_EVENT_COURIER_ASSIGNED        = "courier_assigned"
+	WEBSOCKET_EVENT_COURIER_LOCATION        = "courier_location"
+	WEBSOCKET_EVENT_ORDER_CREATED           = "order_created"
+	WEBSOCKET_EVENT_ORDER_UPDATED           = "order_updated"
+	WEBSOCKET_EVENT_ORDER_PAID              = "order_paid"
+	WEBSOCKET_EVENT_ORDER_CANCELLED         = "order_cancelled"
+	WEBSOCKET_EVENT_ORDER_TRACKING          = "order_tracking"
 )
 
 type WebSocketMessage interface {
@@ -61,10 +68,13 @@ type WebSocketMessage interface {
 }
 
 type WebsocketBroadcast struct {
-	OmitUsers             map[string]bool `json:"omit_users"` // broadcast is omitted for users listed here
-	UserId                string          `json:"user_id"`    // broadcast only occurs for this user
-	ChannelId             string          `json:"channel_id"` // broadcast only occurs for users in this channel
-	TeamId                string          `json:"team_id"`    // broadcast only occurs for users in this team
+	OmitUsers             map[string]bool `json:"omit_users"`           // broadcast is omitted for users listed here
+	UserId                string          `json:"user_id"`              // broadcast only occurs for this user
+	ChannelId             string          `json:"channel_id"`           // broadcast only occurs for users in this channel
+	TeamId                string          `json:"team_id"`              // broadcast only occurs for users in this team
+	Permission            string          `json:"permission,omitempty"` // broadcast only occurs for users granted this permission in the team
+	OfficeIds             []string        `json:"office_ids,omitempty"` // broadcast only occurs for staff of these offices and staff without offices
+	OrderId               string          `json:"order_id,omitempty"`   // broadcast only occurs for connections subscribed to this order
 	ContainsSanitizedData bool            `json:"-"`
 	ContainsSensitiveData bool            `json:"-"`
 }
@@ -0,0 +1,134 @@
+package geocoder
+
+import (
+	"errors"
+	"sync"
+
+	"im/model"
+	"im/services/configservice"
+	"im/services/httpservice"
+)
+
+var (
+	ErrNotEnabled = errors.New("geocoder.Geocoder: geocoder not enabled")
+	ErrNotFound   = errors.New("geocoder.Geocoder: address not found")
+)
+
+// A Result is a single match returned by a geocoder backend.
+type Result struct {
+	Latitude  float64
+	Longitude float64
+	Formatted string
+	City      string
+	Street    string
+	House     string
+}
+
+// A Geocoder converts addresses to coordinates and back. An instance of Geocoder should be created using
+// MakeGeocoder which requires a configService and an HTTPService provided by the server.
+type Geocoder struct {
+	ConfigService    configservice.ConfigService
+	configListenerId string
+
+	HTTPService httpservice.HTTPService
+
+	lock    sync.RWMutex
+	backend GeocoderBackend
+}
+
+// A GeocoderBackend provides the functionality for different geocoding services. A Geocoder will construct
+// the required backend depending on the GeocoderSettings provided by the ConfigService.
+type GeocoderBackend interface {
+	// Geocode returns the best match for the free-text address.
+	Geocode(address string) (*Result, error)
+
+	// ReverseGeocode returns the address found at the given coordinates.
+	ReverseGeocode(latitude, longitude float64) (*Result, error)
+}
+
+func MakeGeocoder(configService configservice.ConfigService, httpService httpservice.HTTPService) *Geocoder {
+	geocoder := &Geocoder{
+		ConfigService: configService,
+		HTTPService:   httpService,
+	}
+
+	geocoder.configListenerId = geocoder.ConfigService.AddConfigListener(geocoder.OnConfigChange)
+
+	config := geocoder.ConfigService.Config()
+	geocoder.backend = geocoder.makeBackend(&config.GeocoderSettings)
+
+	return geocoder
+}
+
+func (geocoder *Geocoder) makeBackend(settings *model.GeocoderSettings) GeocoderBackend {
+	if !*settings.Enable {
+		return nil
+	}
+
+	switch *settings.GeocoderType {
+	case model.GEOCODER_TYPE_LOCAL:
+		return MakeLocalBackend()
+	case model.GEOCODER_TYPE_YANDEX:
+		return makeYandexBackend(geocoder, *settings.ApiURL, *settings.ApiKey)
+	default:
+		return nil
+	}
+}
+
+func (geocoder *Geocoder) Close() {
+	geocoder.lock.Lock()
+	defer geocoder.lock.Unlock()
+
+	geocoder.ConfigService.RemoveConfigListener(geocoder.configListenerId)
+}
+
+func (geocoder *Geocoder) OnConfigChange(oldConfig, newConfig *model.Config) {
+	if *oldConfig.GeocoderSettings.Enable != *newConfig.GeocoderSettings.Enable ||
+		*oldConfig.GeocoderSettings.GeocoderType != *newConfig.GeocoderSettings.GeocoderType ||
+		*oldConfig.GeocoderSettings.ApiURL != *newConfig.GeocoderSettings.ApiURL ||
+		*oldConfig.GeocoderSettings.ApiKey != *newConfig.GeocoderSettings.ApiKey {
+		geocoder.lock.Lock()
+		defer geocoder.lock.Unlock()
+
+		geocoder.backend = geocoder.makeBackend(&newConfig.GeocoderSettings)
+	}
+}
+
+// SetBackend replaces the backend chosen by the configuration, e.g. with a LocalBackend filled with fixtures.
+func (geocoder *Geocoder) SetBackend(backend GeocoderBackend) {
+	geocoder.lock.Lock()
+	defer geocoder.lock.Unlock()
+
+	geocoder.backend = backend
+}
+
+func (geocoder *Geocoder) IsEnabled() bool {
+	geocoder.lock.RLock()
+	defer geocoder.lock.RUnlock()
+
+	return geocoder.backend != nil
+}
+
+// Geocode takes a free-text address and returns its coordinates.
+func (geocoder *Geocoder) Geocode(address string) (*Result, error) {
+	geocoder.lock.RLock()
+	defer geocoder.lock.RUnlock()
+
+	if geocoder.backend == nil {
+		return nil, ErrNotEnabled
+	}
+
+	return geocoder.backend.Geocode(address)
+}
+
+// ReverseGeocode takes coordinates and returns the address found there.
+func (geocoder *Geocoder) ReverseGeocode(latitude, longitude float64) (*Result, error) {
+	geocoder.lock.RLock()
+	defer geocoder.lock.RUnlock()
+
+	if geocoder.backend == nil {
+		return nil, ErrNotEnabled
+	}
+
+	return geocoder.backend.ReverseGeocode(latitude, longitude)
+}
@@ -0,0 +1,68 @@
+package geocoder
+
+import (
+	"strings"
+	"sync"
+
+	"im/model"
+)
+
+// LocalBackend answers from an in-memory table of known addresses and never goes to the network.
+// It is meant for development and tests.
+type LocalBackend struct {
+	lock    sync.RWMutex
+	results map[string]*Result
+}
+
+func MakeLocalBackend() *LocalBackend {
+	return &LocalBackend{
+		results: make(map[string]*Result),
+	}
+}
+
+func normalizeAddress(address string) string {
+	return strings.Join(strings.Fields(strings.ToLower(address)), " ")
+}
+
+// Add registers the result to return for the address.
+func (backend *LocalBackend) Add(address string, result *Result) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+
+	backend.results[normalizeAddress(address)] = result
+}
+
+func (backend *LocalBackend) Geocode(address string) (*Result, error) {
+	backend.lock.RLock()
+	defer backend.lock.RUnlock()
+
+	if result, ok := backend.results[normalizeAddress(address)]; ok {
+		copy := *result
+		return &copy, nil
+	}
+
+	return nil, ErrNotFound
+}
+
+// ReverseGeocode returns the closest known address.
+func (backend *LocalBackend) ReverseGeocode(latitude, longitude float64) (*Result, error) {
+	backend.lock.RLock()
+	defer backend.lock.RUnlock()
+
+	var nearest *Result
+	var nearestDistance float64
+	for _, result := range backend.results {
+		distance := model.GeoDistance(latitude, longitude, result.Latitude, result.Longitude)
+		if nearest == nil || distance < nearestDistance {
+			nearest = result
+			nearestDistance = distance
+		}
+	}
+
+	if nearest == nil {
+		return nil, ErrNotFound
+	}
+
+	copy := *nearest
+	return &copy, nil
+}
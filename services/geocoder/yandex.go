@@ -0,0 +1,129 @@
+package geocoder
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var ErrYandexRequestFailed = errors.New("geocoder.YandexBackend: failed to request geocoder")
+
+// YandexBackend uses the Yandex.Maps geocoder HTTP API.
+type YandexBackend struct {
+	geocoder *Geocoder
+
+	apiURL string
+	apiKey string
+}
+
+type yandexResponse struct {
+	Response struct {
+		GeoObjectCollection struct {
+			FeatureMember []struct {
+				GeoObject struct {
+					MetaDataProperty struct {
+						GeocoderMetaData struct {
+							Text    string `json:"text"`
+							Address struct {
+								Components []struct {
+									Kind string `json:"kind"`
+									Name string `json:"name"`
+								} `json:"Components"`
+							} `json:"Address"`
+						} `json:"GeocoderMetaData"`
+					} `json:"metaDataProperty"`
+					Point struct {
+						Pos string `json:"pos"`
+					} `json:"Point"`
+				} `json:"GeoObject"`
+			} `json:"featureMember"`
+		} `json:"GeoObjectCollection"`
+	} `json:"response"`
+}
+
+func makeYandexBackend(geocoder *Geocoder, apiURL, apiKey string) *YandexBackend {
+	return &YandexBackend{
+		geocoder: geocoder,
+		apiURL:   apiURL,
+		apiKey:   apiKey,
+	}
+}
+
+func (backend *YandexBackend) Geocode(address string) (*Result, error) {
+	return backend.request(address)
+}
+
+func (backend *YandexBackend) ReverseGeocode(latitude, longitude float64) (*Result, error) {
+	// the API expects "longitude,latitude"
+	return backend.request(strconv.FormatFloat(longitude, 'f', -1, 64) + "," + strconv.FormatFloat(latitude, 'f', -1, 64))
+}
+
+func (backend *YandexBackend) request(query string) (*Result, error) {
+	params := url.Values{}
+	params.Set("apikey", backend.apiKey)
+	params.Set("format", "json")
+	params.Set("lang", "ru_RU")
+	params.Set("results", "1")
+	params.Set("geocode", query)
+
+	client := backend.geocoder.HTTPService.MakeClient(true)
+
+	resp, err := client.Get(backend.apiURL + "?" + params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer backend.geocoder.HTTPService.ConsumeAndClose(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ErrYandexRequestFailed
+	}
+
+	var data yandexResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, err
+	}
+
+	members := data.Response.GeoObjectCollection.FeatureMember
+	if len(members) == 0 {
+		return nil, ErrNotFound
+	}
+
+	object := members[0].GeoObject
+
+	pos := strings.Fields(object.Point.Pos)
+	if len(pos) != 2 {
+		return nil, ErrNotFound
+	}
+
+	longitude, err := strconv.ParseFloat(pos[0], 64)
+	if err != nil {
+		return nil, err
+	}
+
+	latitude, err := strconv.ParseFloat(pos[1], 64)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Latitude:  latitude,
+		Longitude: longitude,
+		Formatted: object.MetaDataProperty.GeocoderMetaData.Text,
+	}
+
+	for _, component := range object.MetaDataProperty.GeocoderMetaData.Address.Components {
+		switch component.Kind {
+		case "locality":
+			result.City = component.Name
+		case "street":
+			result.Street = component.Name
+		case "house":
+			result.House = component.Name
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,151 @@
+package invoice
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"im/model"
+)
+
+const (
+	CURRENCY_NAME = "руб."
+)
+
+var months = []string{"января", "февраля", "марта", "апреля", "мая", "июня", "июля", "августа", "сентября", "октября", "ноября", "декабря"}
+
+// Document is an invoice or an act of an order. The amounts are in kopecks and include the VAT.
+type Document struct {
+	Type   string
+	Number string
+	Date   time.Time
+
+	Seller *model.LegalEntity
+	// SellerDetails are the payment details of the application when they are free text and
+	// there are no requisites to print.
+	SellerDetails  string
+	SellerContacts string
+	Buyer          *model.LegalEntity
+
+	// Basis is the invoice an act closes.
+	Basis string
+
+	Lines []*Line
+}
+
+type Line struct {
+	Name     string
+	Measure  string
+	Quantity int
+	Price    int64
+	Amount   int64
+	VatRate  string
+}
+
+// VatSum is the VAT included in the amounts of the lines with the same rate.
+type VatSum struct {
+	Rate   string
+	Amount int64
+}
+
+func (d *Document) Total() int64 {
+	var total int64
+	for _, line := range d.Lines {
+		total += line.Amount
+	}
+
+	return total
+}
+
+// VatSums breaks the VAT down by rate, the lines without VAT are left out.
+func (d *Document) VatSums() []*VatSum {
+	byRate := make(map[string]*VatSum)
+	for _, line := range d.Lines {
+		percent := VatPercent(line.VatRate)
+		if percent == 0 && line.VatRate != model.VAT_RATE_0 {
+			continue
+		}
+
+		rate := fmt.Sprintf("%d%%", percent)
+		if byRate[rate] == nil {
+			byRate[rate] = &VatSum{Rate: rate}
+		}
+		byRate[rate].Amount += VatAmount(line.Amount, line.VatRate)
+	}
+
+	sums := make([]*VatSum, 0, len(byRate))
+	for _, sum := range byRate {
+		sums = append(sums, sum)
+	}
+	sort.Slice(sums, func(i, j int) bool { return sums[i].Rate > sums[j].Rate })
+
+	return sums
+}
+
+func (d *Document) VatTotal() int64 {
+	var total int64
+	for _, sum := range d.VatSums() {
+		total += sum.Amount
+	}
+
+	return total
+}
+
+// Purpose is the purpose of the payment for the bank transfer.
+func (d *Document) Purpose() string {
+	purpose := "Оплата по счету № " + d.Number + " от " + FormatDate(d.Date)
+
+	vat := d.VatTotal()
+	if len(d.VatSums()) == 0 {
+		return purpose + ". Без НДС"
+	}
+
+	return purpose + ". В том числе НДС " + FormatMoney(vat) + " " + CURRENCY_NAME
+}
+
+// VatPercent is the rate of the VAT, the calculated rates (20/120) included.
+func VatPercent(vatRate string) int64 {
+	switch vatRate {
+	case model.VAT_RATE_20, model.VAT_RATE_120:
+		return 20
+	case model.VAT_RATE_10, model.VAT_RATE_110:
+		return 10
+	}
+
+	return 0
+}
+
+// VatAmount is the VAT included in the amount.
+func VatAmount(amount int64, vatRate string) int64 {
+	percent := VatPercent(vatRate)
+	if percent == 0 {
+		return 0
+	}
+
+	return (amount*percent*2 + 100 + percent) / (2 * (100 + percent))
+}
+
+// FormatMoney writes kopecks the way the documents show them, 12 345,67.
+func FormatMoney(kopecks int64) string {
+	sign := ""
+	if kopecks < 0 {
+		sign = "-"
+		kopecks = -kopecks
+	}
+
+	rubles := fmt.Sprintf("%d", kopecks/100)
+	var groups []string
+	for len(rubles) > 3 {
+		groups = append([]string{rubles[len(rubles)-3:]}, groups...)
+		rubles = rubles[:len(rubles)-3]
+	}
+	groups = append([]string{rubles}, groups...)
+
+	return fmt.Sprintf("%s%s,%02d", sign, strings.Join(groups, " "), kopecks%100)
+}
+
+// FormatDate writes the date the way the documents show it, 5 марта 2024 г.
+func FormatDate(t time.Time) string {
+	return fmt.Sprintf("%d %s %d г.", t.Day(), months[t.Month()-1], t.Year())
+}
@@ -0,0 +1,296 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"im/model"
+)
+
+const (
+	PDF_FONT_FAMILY = "invoice"
+	PDF_MARGIN      = 15.0
+	PDF_PAGE_WIDTH  = 210.0
+	PDF_PAGE_HEIGHT = 297.0
+	PDF_QR_SIZE     = 35.0
+
+	pdfTextSize  = 9.0
+	pdfTitleSize = 14.0
+	pdfRowHeight = 5.0
+	pdfQrImage   = "qr"
+)
+
+// the columns of the table of the lines: number, name, quantity, measure, price and amount
+var pdfColumns = []float64{10, 82, 18, 14, 28, 28}
+
+type pdfWriter struct {
+	pdf   *gofpdf.Fpdf
+	width float64
+}
+
+// RenderPdf lays the invoice or the act out on A4 pages with the TrueType font of the fonts
+// directory. The invoice gets the bank requisites of the seller and the QR code to pay it by,
+// when the seller has a bank account.
+func RenderPdf(doc *Document, fontPath string) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(PDF_MARGIN, PDF_MARGIN, PDF_MARGIN)
+	pdf.SetAutoPageBreak(true, PDF_MARGIN)
+	pdf.AddUTF8Font(PDF_FONT_FAMILY, "", fontPath)
+	if err := pdf.Error(); err != nil {
+		return nil, err
+	}
+
+	w := &pdfWriter{pdf: pdf, width: PDF_PAGE_WIDTH - 2*PDF_MARGIN}
+	pdf.AddPage()
+
+	if doc.Type == model.ORDER_DOCUMENT_TYPE_INVOICE {
+		if err := w.invoiceHeader(doc); err != nil {
+			return nil, err
+		}
+	} else {
+		w.title(fmt.Sprintf("Акт № %s от %s", doc.Number, FormatDate(doc.Date)), 0)
+		w.party("Исполнитель:", doc.Seller, doc.SellerDetails, doc.SellerContacts)
+		w.party("Заказчик:", doc.Buyer, "", "")
+		if len(doc.Basis) > 0 {
+			w.labeled("Основание:", doc.Basis)
+		}
+	}
+
+	w.lines(doc)
+	w.totals(doc)
+
+	pdf.Ln(pdfRowHeight)
+	w.text(fmt.Sprintf("Всего наименований %d, на сумму %s %s", len(doc.Lines), FormatMoney(doc.Total()), CURRENCY_NAME))
+	w.text(AmountInWords(doc.Total()))
+	pdf.Ln(pdfRowHeight)
+
+	if doc.Type == model.ORDER_DOCUMENT_TYPE_INVOICE {
+		w.signatures("Руководитель", "Бухгалтер")
+	} else {
+		w.text("Вышеперечисленные товары и услуги переданы и оказаны полностью и в срок. Заказчик претензий по объему, качеству и срокам не имеет.")
+		pdf.Ln(pdfRowHeight)
+		w.signatures("Исполнитель", "Заказчик")
+	}
+
+	if err := pdf.Error(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// invoiceHeader draws the bank requisites of the seller the way the payment order is filled,
+// the QR code and the title.
+func (w *pdfWriter) invoiceHeader(doc *Document) error {
+	pdf := w.pdf
+	titleWidth := 0.0
+
+	if seller := doc.Seller; seller != nil && seller.HasBankAccount() {
+		data, err := PaymentQrData(seller, doc.Total(), doc.Purpose())
+		if err != nil {
+			return err
+		}
+
+		png, err := PaymentQrPng(data)
+		if err != nil {
+			return err
+		}
+
+		pdf.SetFont(PDF_FONT_FAMILY, "", pdfTextSize)
+		left, label, value := w.width-75, 20.0, 55.0
+		inn := "ИНН " + seller.Inn
+		if len(seller.Kpp) > 0 {
+			inn += "    КПП " + seller.Kpp
+		}
+
+		pdf.Line(PDF_MARGIN, pdf.GetY(), PDF_MARGIN+w.width, pdf.GetY())
+		for _, row := range [][3]string{
+			{seller.BankName, "БИК", seller.Bik},
+			{"Банк получателя", "Сч. №", seller.CorrAccount},
+			{inn, "Сч. №", seller.Account},
+			{seller.Name, "", ""},
+			{"Получатель", "", ""},
+		} {
+			pdf.CellFormat(left, pdfRowHeight, fitText(pdf, row[0], left), "LR", 0, "L", false, 0, "")
+			pdf.CellFormat(label, pdfRowHeight, row[1], "LR", 0, "L", false, 0, "")
+			pdf.CellFormat(value, pdfRowHeight, row[2], "LR", 1, "L", false, 0, "")
+		}
+		pdf.Line(PDF_MARGIN, pdf.GetY(), PDF_MARGIN+w.width, pdf.GetY())
+		pdf.Ln(pdfRowHeight)
+
+		options := gofpdf.ImageOptions{ImageType: "PNG"}
+		pdf.RegisterImageOptionsReader(pdfQrImage, options, bytes.NewReader(png))
+		pdf.ImageOptions(pdfQrImage, PDF_MARGIN+w.width-PDF_QR_SIZE, pdf.GetY(), PDF_QR_SIZE, PDF_QR_SIZE, false, options, 0, "")
+		titleWidth = w.width - PDF_QR_SIZE - pdfRowHeight
+	}
+
+	top := pdf.GetY()
+	w.title(fmt.Sprintf("Счет на оплату № %s от %s", doc.Number, FormatDate(doc.Date)), titleWidth)
+	w.party("Поставщик:", doc.Seller, doc.SellerDetails, doc.SellerContacts)
+	w.party("Покупатель:", doc.Buyer, "", "")
+
+	if titleWidth > 0 && pdf.GetY() < top+PDF_QR_SIZE {
+		pdf.SetY(top + PDF_QR_SIZE + 2)
+	}
+
+	return nil
+}
+
+func (w *pdfWriter) title(text string, width float64) {
+	pdf := w.pdf
+	if width == 0 {
+		width = w.width
+	}
+
+	pdf.SetFont(PDF_FONT_FAMILY, "", pdfTitleSize)
+	pdf.MultiCell(width, pdfTitleSize*0.5, text, "", "L", false)
+	pdf.Line(PDF_MARGIN, pdf.GetY()+1, PDF_MARGIN+width, pdf.GetY()+1)
+	pdf.Ln(pdfRowHeight)
+}
+
+// party writes the requisites of the seller or the buyer, or the free text details when there
+// are no requisites.
+func (w *pdfWriter) party(label string, entity *model.LegalEntity, details string, contacts string) {
+	var parts []string
+	if entity != nil {
+		parts = append(parts, entity.Name, "ИНН "+entity.Inn)
+		if len(entity.Kpp) > 0 {
+			parts = append(parts, "КПП "+entity.Kpp)
+		}
+		if len(entity.Ogrn) > 0 {
+			parts = append(parts, "ОГРН "+entity.Ogrn)
+		}
+		if len(entity.Address) > 0 {
+			parts = append(parts, entity.Address)
+		}
+	} else if len(details) > 0 {
+		parts = append(parts, details)
+	}
+
+	if len(contacts) > 0 {
+		parts = append(parts, contacts)
+	}
+
+	w.labeled(label, strings.Join(parts, ", "))
+}
+
+func (w *pdfWriter) labeled(label string, text string) {
+	pdf := w.pdf
+	labelWidth := 28.0
+
+	pdf.SetFont(PDF_FONT_FAMILY, "", pdfTextSize)
+	pdf.CellFormat(labelWidth, pdfRowHeight, label, "", 0, "L", false, 0, "")
+	pdf.MultiCell(w.width-labelWidth, pdfRowHeight, text, "", "L", false)
+	pdf.Ln(1)
+}
+
+func (w *pdfWriter) text(text string) {
+	w.pdf.SetFont(PDF_FONT_FAMILY, "", pdfTextSize)
+	w.pdf.MultiCell(w.width, pdfRowHeight, text, "", "L", false)
+}
+
+// lines draws the table of the lines, a long name takes as many rows as it needs.
+func (w *pdfWriter) lines(doc *Document) {
+	pdf := w.pdf
+	pdf.SetFont(PDF_FONT_FAMILY, "", pdfTextSize)
+	pdf.Ln(pdfRowHeight)
+
+	header := []string{"№", "Товары (работы, услуги)", "Кол-во", "Ед.", "Цена", "Сумма"}
+	for i, text := range header {
+		pdf.CellFormat(pdfColumns[i], pdfRowHeight+1, text, "1", 0, "C", false, 0, "")
+	}
+	pdf.Ln(-1)
+
+	for n, line := range doc.Lines {
+		names := pdf.SplitText(line.Name, pdfColumns[1]-2)
+		if len(names) == 0 {
+			names = []string{""}
+		}
+		height := float64(len(names)) * pdfRowHeight
+
+		if pdf.GetY()+height > PDF_PAGE_HEIGHT-PDF_MARGIN {
+			pdf.AddPage()
+		}
+
+		x, y := pdf.GetX(), pdf.GetY()
+		cells := []struct {
+			text  string
+			align string
+		}{
+			{fmt.Sprintf("%d", n+1), "C"},
+			{"", "L"},
+			{fmt.Sprintf("%d", line.Quantity), "R"},
+			{line.Measure, "C"},
+			{FormatMoney(line.Price), "R"},
+			{FormatMoney(line.Amount), "R"},
+		}
+
+		for i, cell := range cells {
+			pdf.CellFormat(pdfColumns[i], height, cell.text, "1", 0, cell.align, false, 0, "")
+		}
+
+		for i, name := range names {
+			pdf.SetXY(x+pdfColumns[0], y+float64(i)*pdfRowHeight)
+			pdf.CellFormat(pdfColumns[1], pdfRowHeight, name, "", 0, "L", false, 0, "")
+		}
+
+		pdf.SetXY(x, y+height)
+	}
+}
+
+// totals writes the total and the VAT it includes under the table.
+func (w *pdfWriter) totals(doc *Document) {
+	pdf := w.pdf
+	pdf.SetFont(PDF_FONT_FAMILY, "", pdfTextSize)
+	pdf.Ln(1)
+
+	labelWidth, valueWidth := w.width-pdfColumns[len(pdfColumns)-1], pdfColumns[len(pdfColumns)-1]
+	row := func(label string, value string) {
+		pdf.CellFormat(labelWidth, pdfRowHeight, label, "", 0, "R", false, 0, "")
+		pdf.CellFormat(valueWidth, pdfRowHeight, value, "", 1, "R", false, 0, "")
+	}
+
+	row("Итого:", FormatMoney(doc.Total()))
+
+	sums := doc.VatSums()
+	if len(sums) == 0 {
+		row("Без налога (НДС):", "-")
+	}
+	for _, sum := range sums {
+		row("В том числе НДС "+sum.Rate+":", FormatMoney(sum.Amount))
+	}
+
+	if doc.Type == model.ORDER_DOCUMENT_TYPE_INVOICE {
+		row("Всего к оплате:", FormatMoney(doc.Total()))
+	} else {
+		row("Всего (с учетом НДС):", FormatMoney(doc.Total()))
+	}
+}
+
+func (w *pdfWriter) signatures(left string, right string) {
+	pdf := w.pdf
+	half := w.width / 2
+
+	pdf.SetFont(PDF_FONT_FAMILY, "", pdfTextSize)
+	pdf.Ln(pdfRowHeight)
+	pdf.CellFormat(half, pdfRowHeight, left+" ______________________", "", 0, "L", false, 0, "")
+	pdf.CellFormat(half, pdfRowHeight, right+" ______________________", "", 1, "L", false, 0, "")
+}
+
+// fitText cuts the text to the width of the cell.
+func fitText(pdf *gofpdf.Fpdf, text string, width float64) string {
+	lines := pdf.SplitText(text, width-2)
+	if len(lines) == 0 {
+		return ""
+	}
+
+	return lines[0]
+}
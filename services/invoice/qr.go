@@ -0,0 +1,66 @@
+package invoice
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+
+	"im/model"
+)
+
+const (
+	// QR_FORMAT_HEADER starts the payment data of GOST R 56042-2014, version 0001 in UTF-8.
+	QR_FORMAT_HEADER = "ST00012"
+	QR_SEPARATOR     = "|"
+	QR_IMAGE_SIZE    = 512
+)
+
+// PaymentQrData is the text of the QR code the banking apps pay the document by, in the format
+// of GOST R 56042-2014. The seller needs a bank account for it.
+func PaymentQrData(seller *model.LegalEntity, amount int64, purpose string) (string, error) {
+	if seller == nil || !seller.HasBankAccount() {
+		return "", errors.New("the seller has no bank account")
+	}
+
+	fields := []string{
+		QR_FORMAT_HEADER,
+		"Name=" + qrValue(seller.Name),
+		"PersonalAcc=" + seller.Account,
+		"BankName=" + qrValue(seller.BankName),
+		"BIC=" + seller.Bik,
+		"CorrespAcc=" + correspondentAccount(seller.CorrAccount),
+		"PayeeINN=" + seller.Inn,
+	}
+
+	if len(seller.Kpp) > 0 {
+		fields = append(fields, "KPP="+seller.Kpp)
+	}
+
+	fields = append(fields,
+		fmt.Sprintf("Sum=%d", amount),
+		"Purpose="+qrValue(purpose),
+	)
+
+	return strings.Join(fields, QR_SEPARATOR), nil
+}
+
+// PaymentQrPng draws the QR code of the payment data.
+func PaymentQrPng(data string) ([]byte, error) {
+	return qrcode.Encode(data, qrcode.Medium, QR_IMAGE_SIZE)
+}
+
+// qrValue keeps the separator out of the values, the format has no escaping.
+func qrValue(s string) string {
+	return strings.TrimSpace(strings.Replace(s, QR_SEPARATOR, " ", -1))
+}
+
+// correspondentAccount is "0" for the banks without one, as the format requires.
+func correspondentAccount(account string) string {
+	if len(account) == 0 {
+		return "0"
+	}
+
+	return account
+}
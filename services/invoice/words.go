@@ -0,0 +1,109 @@
+package invoice
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	unitsMasculine = []string{"", "один", "два", "три", "четыре", "пять", "шесть", "семь", "восемь", "девять"}
+	unitsFeminine  = []string{"", "одна", "две", "три", "четыре", "пять", "шесть", "семь", "восемь", "девять"}
+	teens          = []string{"десять", "одиннадцать", "двенадцать", "тринадцать", "четырнадцать", "пятнадцать", "шестнадцать", "семнадцать", "восемнадцать", "девятнадцать"}
+	tens           = []string{"", "", "двадцать", "тридцать", "сорок", "пятьдесят", "шестьдесят", "семьдесят", "восемьдесят", "девяносто"}
+	hundreds       = []string{"", "сто", "двести", "триста", "четыреста", "пятьсот", "шестьсот", "семьсот", "восемьсот", "девятьсот"}
+
+	// the forms of the word for 1, 2-4 and 5 of it, and whether it is feminine
+	scales = []struct {
+		forms    [3]string
+		feminine bool
+	}{
+		{[3]string{"рубль", "рубля", "рублей"}, false},
+		{[3]string{"тысяча", "тысячи", "тысяч"}, true},
+		{[3]string{"миллион", "миллиона", "миллионов"}, false},
+		{[3]string{"миллиард", "миллиарда", "миллиардов"}, false},
+	}
+
+	kopeckForms = [3]string{"копейка", "копейки", "копеек"}
+)
+
+// AmountInWords writes the amount out for the documents, "Одна тысяча двести рублей 50 копеек".
+func AmountInWords(kopecks int64) string {
+	if kopecks < 0 {
+		kopecks = -kopecks
+	}
+
+	rubles := kopecks / 100
+
+	var words []string
+	if rubles == 0 {
+		words = append(words, "ноль", scales[0].forms[2])
+	} else {
+		for scale := len(scales) - 1; scale >= 0; scale-- {
+			divisor := int64(1)
+			for i := 0; i < scale; i++ {
+				divisor *= 1000
+			}
+
+			triple := int((rubles / divisor) % 1000)
+			if triple == 0 {
+				if scale == 0 {
+					words = append(words, scales[0].forms[2])
+				}
+				continue
+			}
+
+			words = append(words, tripleInWords(triple, scales[scale].feminine)...)
+			words = append(words, scales[scale].forms[pluralForm(triple)])
+		}
+	}
+
+	text := strings.Join(words, " ")
+	r, size := utf8.DecodeRuneInString(text)
+	text = strings.ToUpper(string(r)) + text[size:]
+
+	return fmt.Sprintf("%s %02d %s", text, kopecks%100, kopeckForms[pluralForm(int(kopecks%100))])
+}
+
+func tripleInWords(n int, feminine bool) []string {
+	var words []string
+	if n/100 > 0 {
+		words = append(words, hundreds[n/100])
+	}
+
+	n %= 100
+	switch {
+	case n >= 10 && n < 20:
+		words = append(words, teens[n-10])
+	default:
+		if n/10 > 0 {
+			words = append(words, tens[n/10])
+		}
+		if n%10 > 0 {
+			if feminine {
+				words = append(words, unitsFeminine[n%10])
+			} else {
+				words = append(words, unitsMasculine[n%10])
+			}
+		}
+	}
+
+	return words
+}
+
+// pluralForm picks the form of the word after the number: 0 for 1, 1 for 2-4, 2 for the rest.
+func pluralForm(n int) int {
+	n %= 100
+	if n >= 11 && n <= 14 {
+		return 2
+	}
+
+	switch n % 10 {
+	case 1:
+		return 0
+	case 2, 3, 4:
+		return 1
+	}
+
+	return 2
+}
@@ -23,6 +23,8 @@ import (
 // "BindingID" used in binding API
 // "Features" used in some endpoints of API
 // "JSONParams" different json data that can be stored on api side
+// "OrderBundle" cart for the fiscal receipt, sent with "TaxSystem" of the seller
+// "ClientID" customer the card is bound to when the customer agrees to save it
 type Order struct {
 	OrderNumber    string
 	Amount         int
@@ -35,6 +37,9 @@ type Order struct {
 	BindingID      string
 	Features       string
 	JSONParams     map[string]string
+	OrderBundle    *OrderBundle
+	TaxSystem      int
+	ClientID       string
 }
 
 // RegisterOrder request
@@ -102,6 +107,19 @@ func (c *Client) register(ctx context.Context, path string, order Order) (*schem
 	body["bindingId"] = order.BindingID
 	body["features"] = order.Features
 
+	if order.ClientID != "" {
+		body["clientId"] = order.ClientID
+	}
+
+	if order.OrderBundle != nil {
+		orderBundle, err := json.Marshal(order.OrderBundle)
+		if err != nil {
+			return nil, nil, err
+		}
+		body["orderBundle"] = string(orderBundle)
+		body["taxSystem"] = strconv.Itoa(order.TaxSystem)
+	}
+
 	req, err := c.NewRestRequest(ctx, "POST", path, body, order.JSONParams)
 
 	if err != nil {
@@ -207,9 +225,10 @@ func validateRefundOrder(order Order) error {
 		return fmt.Errorf("orderNumber cant be empty")
 	}
 
+	// refunds are made by the order id of the acquirer, a 36 characters uuid
 	if order.OrderNumber != "" {
-		if len(order.OrderNumber) > 30 {
-			return fmt.Errorf("orderNumber is too long (>30)")
+		if len(order.OrderNumber) > 36 {
+			return fmt.Errorf("orderNumber is too long (>36)")
 		}
 	}
 
@@ -321,8 +340,8 @@ func (c *Client) UpdateSSLCardList(ctx context.Context, mdorder string, jsonPara
 
 // Binding is used to make binding related requests
 type Binding struct {
-	bindingID  string
-	newExpiry  int
+	BindingID  string
+	NewExpiry  int
 	JSONParams map[string]string
 }
 
@@ -348,7 +367,7 @@ var bind = func(ctx context.Context, client *Client, path string, binding Bindin
 	}
 
 	body := make(map[string]string)
-	body["bindingId"] = binding.bindingID
+	body["bindingId"] = binding.BindingID
 
 	return client.bind(ctx, path, body, binding.JSONParams)
 }
@@ -367,14 +386,14 @@ func (c *Client) ExtendBinding(ctx context.Context, binding Binding) (*schema.Re
 	}
 
 	body := make(map[string]string)
-	body["bindingId"] = binding.bindingID
-	body["newExpiry"] = strconv.Itoa(binding.newExpiry)
+	body["bindingId"] = binding.BindingID
+	body["newExpiry"] = strconv.Itoa(binding.NewExpiry)
 
 	return c.bind(ctx, path, body, binding.JSONParams)
 }
 
 func validateBind(binding Binding) error {
-	if binding.bindingID == "" {
+	if binding.BindingID == "" {
 		return fmt.Errorf("bindingId can't be empty")
 	}
 
@@ -382,7 +401,7 @@ func validateBind(binding Binding) error {
 }
 
 func validateExpiry(binding Binding) error {
-	if len(strconv.Itoa(binding.newExpiry)) != 6 {
+	if len(strconv.Itoa(binding.NewExpiry)) != 6 {
 		return fmt.Errorf("new expiry date should have 6 digits")
 	}
 
@@ -432,6 +451,57 @@ func (c *Client) GetBindings(ctx context.Context, clientId string, jsonParams ma
 	return &response, result, err
 }
 
+// OrderBinding is used for building PaymentOrderBinding request
+//
+// "MdOrder" is the order id the acquirer returned on RegisterOrder
+type OrderBinding struct {
+	MdOrder    string
+	BindingID  string
+	IP         string
+	CVC        string
+	Email      string
+	JSONParams map[string]string
+}
+
+// PaymentOrderBinding request pays the registered order with a bound card
+// see https://pay.alfabank.ru/ecommerce/instructions/merchantManual/pages/index/rest.html#zapros_provedenija_platezha_po_svjazke_rest_
+func (c *Client) PaymentOrderBinding(ctx context.Context, orderBinding OrderBinding) (*schema.OrderBindingResponse, *http.Response, error) {
+	path := endpoints.PaymentOrderBinding
+
+	if orderBinding.MdOrder == "" {
+		return nil, nil, fmt.Errorf("mdOrder can't be empty")
+	}
+
+	if err := validateBind(Binding{BindingID: orderBinding.BindingID}); err != nil {
+		return nil, nil, err
+	}
+
+	body := make(map[string]string)
+	body["mdOrder"] = orderBinding.MdOrder
+	body["bindingId"] = orderBinding.BindingID
+	body["ip"] = orderBinding.IP
+	if orderBinding.CVC != "" {
+		body["cvc"] = orderBinding.CVC
+	}
+	if orderBinding.Email != "" {
+		body["email"] = orderBinding.Email
+	}
+
+	var response schema.OrderBindingResponse
+	req, err := c.NewRestRequest(ctx, "POST", path, body, orderBinding.JSONParams)
+
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := c.Do(req, &response)
+	if err != nil {
+		return nil, result, err
+	}
+	_ = json.NewDecoder(result.Body).Decode(&response)
+
+	return &response, result, err
+}
+
 // ReceiptStatusRequest is used for building GetReceipt request
 type ReceiptStatusRequest struct {
 	OrderId     string
@@ -486,12 +556,12 @@ func validateReceiptStatusRequest(receiptStatusRequest ReceiptStatusRequest) err
 
 // ApplePaymentRequest is used for building PayWithApplePay request
 type ApplePaymentRequest struct {
-	OrderNumber          string `json:"orderNumber"`
-	Merchant             string `json:"merchant"`
-	PaymentToken         string `json:"paymentToken"`
-	Description          string `json:"description,omitempty"`
-	PreAuth              bool   `json:"preAuth,omitempty"`
-	AdditionalParameters map[string]string
+	OrderNumber          string            `json:"orderNumber"`
+	Merchant             string            `json:"merchant"`
+	PaymentToken         string            `json:"paymentToken"`
+	Description          string            `json:"description,omitempty"`
+	PreAuth              bool              `json:"preAuth,omitempty"`
+	AdditionalParameters map[string]string `json:"additionalParameters,omitempty"`
 }
 
 // PayWithApplePay request
@@ -505,7 +575,7 @@ func (c *Client) PayWithApplePay(ctx context.Context, applePaymentRequest AppleP
 
 	var response schema.ApplePaymentResponse
 
-	req, err := c.NewRequest(ctx, "GET", path, applePaymentRequest)
+	req, err := c.NewRequest(ctx, "POST", path, applePaymentRequest)
 
 	if err != nil {
 		return nil, nil, err
@@ -566,7 +636,7 @@ func (c *Client) PayWithGooglePay(ctx context.Context, googlePaymentRequest Goog
 
 	var response schema.GooglePaymentResponse
 
-	req, err := c.NewRequest(ctx, "GET", path, googlePaymentRequest)
+	req, err := c.NewRequest(ctx, "POST", path, googlePaymentRequest)
 
 	if err != nil {
 		return nil, nil, err
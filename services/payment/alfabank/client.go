@@ -18,7 +18,11 @@ import (
 // URLS for API endpoints
 const (
 	APIURI        string = "https://pay.alfabank.ru/payment/rest"
-	APISandboxURI string = "https://web.rbsuat.com/ab/rest/"
+	APISandboxURI string = "https://web.rbsuat.com/ab/rest"
+
+	// the mobile payments live next to the rest api, not under it
+	APIMobileURI        string = "https://pay.alfabank.ru/payment"
+	APISandboxMobileURI string = "https://web.rbsuat.com/ab"
 )
 
 // ClientConfig is used to set client configuration
@@ -31,7 +35,10 @@ type ClientConfig struct {
 	endpoint           string
 	token              string
 	SandboxMode        bool
-	SiteURL            string
+	// SandboxEndpoint replaces the test hosts of the bank in the sandbox mode, e.g. with the
+	// mock acquirer.
+	SandboxEndpoint string
+	SiteURL         string
 }
 
 // Client is a client to SB API
@@ -83,6 +90,10 @@ var newRestRequest = func(c *Client, ctx context.Context, method, urlPath string
 		uri = APISandboxURI + urlPath
 	}
 
+	if c.Config.SandboxMode && c.Config.SandboxEndpoint != "" {
+		uri = strings.TrimRight(c.Config.SandboxEndpoint, "/") + urlPath
+	}
+
 	if c.Config.endpoint != "" {
 		uri = c.Config.endpoint + urlPath
 	}
@@ -128,10 +139,14 @@ var newRequest = func(c *Client, ctx context.Context, method, urlPath string, da
 		return nil, fmt.Errorf("path contains rest request, use NewRestRequest instead")
 	}
 
-	uri := APIURI + urlPath
+	uri := APIMobileURI + urlPath
 
 	if c.Config.SandboxMode {
-		uri = APISandboxURI + urlPath
+		uri = APISandboxMobileURI + urlPath
+	}
+
+	if c.Config.SandboxMode && c.Config.SandboxEndpoint != "" {
+		uri = strings.TrimRight(c.Config.SandboxEndpoint, "/") + urlPath
 	}
 
 	if c.Config.endpoint != "" {
@@ -12,6 +12,7 @@ const (
 	UnBindCard             string = "/unBindCard.do"
 	BindCard               string = "/bindCard.do"
 	GetBindings            string = "/getBindings.do"
+	PaymentOrderBinding    string = "/paymentOrderBinding.do"
 	ExtendBinding          string = "/extendBinding.do"
 	ApplePay               string = "/applepay/payment.do"
 	SamsungPay             string = "/samsung/payment.do"
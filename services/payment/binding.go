@@ -0,0 +1,8 @@
+package payment
+
+const (
+	// BINDINGS_NOT_FOUND_ERROR_CODE is what getBindings answers for a customer without cards,
+	// BINDING_NOT_FOUND_ERROR_CODE is what unBindCard answers for an unknown binding.
+	BINDINGS_NOT_FOUND_ERROR_CODE = 2
+	BINDING_NOT_FOUND_ERROR_CODE  = 2
+)
@@ -0,0 +1,311 @@
+package payment
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"im/model"
+)
+
+const (
+	// MOCK_ACQUIRER_DECLINE_TOKEN is the wallet token the mock acquirer declines, any other token
+	// pays the order.
+	MOCK_ACQUIRER_DECLINE_TOKEN = "decline"
+	MOCK_ACQUIRER_MASKED_PAN    = "411111**1111"
+
+	mockOrderStatusRegistered = 0
+	mockOrderStatusDeposited  = 2
+	mockOrderStatusReversed   = 3
+	mockOrderStatusRefunded   = 4
+	mockOrderStatusDeclined   = 6
+)
+
+type mockAcquirerOrder struct {
+	number    string
+	amount    int
+	refunded  int
+	status    int
+	returnUrl string
+	createAt  time.Time
+}
+
+// MockAcquirer is an in-memory acquirer speaking the REST and the mobile payment protocol Sberbank
+// and Alfa-Bank share. It is meant for development and tests, the clients reach it in the sandbox
+// mode through ClientConfig.SandboxEndpoint.
+//
+// A registered order is paid by opening its form url, with decline=1 to decline it. A wallet
+// payment is paid at once unless the token is MOCK_ACQUIRER_DECLINE_TOKEN. Apple Pay requests
+// carry no amount, the real one is inside the encrypted token, so the mock takes a numeric token
+// as the amount in kopecks.
+type MockAcquirer struct {
+	lock   sync.RWMutex
+	orders map[string]*mockAcquirerOrder
+}
+
+func NewMockAcquirer() *MockAcquirer {
+	return &MockAcquirer{
+		orders: make(map[string]*mockAcquirerOrder),
+	}
+}
+
+func (m *MockAcquirer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimRight(r.URL.Path, "/")
+
+	switch {
+	case strings.HasSuffix(path, "/applepay/payment.do"), strings.HasSuffix(path, "/google/payment.do"):
+		m.walletPayment(w, r, strings.HasSuffix(path, "/google/payment.do"))
+	case strings.HasSuffix(path, "/register.do"), strings.HasSuffix(path, "/registerPreAuth.do"):
+		m.register(w, r, path[:strings.LastIndex(path, "/")])
+	case strings.HasSuffix(path, "/pay"):
+		m.pay(w, r)
+	case strings.HasSuffix(path, "/getOrderStatusExtended.do"):
+		m.orderStatus(w, r)
+	case strings.HasSuffix(path, "/refund.do"):
+		m.refund(w, r)
+	case strings.HasSuffix(path, "/reverse.do"):
+		m.reverse(w, r)
+	case strings.HasSuffix(path, "/getReceiptStatus.do"):
+		m.receiptStatus(w, r)
+	case strings.HasSuffix(path, "/getBindings.do"):
+		writeMockJson(w, map[string]interface{}{"errorCode": BINDINGS_NOT_FOUND_ERROR_CODE, "errorMessage": "Информация не найдена"})
+	case strings.HasSuffix(path, "/unBindCard.do"), strings.HasSuffix(path, "/bindCard.do"):
+		writeMockJson(w, map[string]interface{}{"errorCode": 0})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (m *MockAcquirer) register(w http.ResponseWriter, r *http.Request, base string) {
+	amount, err := strconv.Atoi(r.FormValue("amount"))
+	if err != nil || amount <= 0 {
+		writeMockOrderError(w, "4", "Сумма заказа не указана")
+		return
+	}
+
+	id := m.addOrder(&mockAcquirerOrder{
+		number:    r.FormValue("orderNumber"),
+		amount:    amount,
+		status:    mockOrderStatusRegistered,
+		returnUrl: r.FormValue("returnUrl"),
+	})
+
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get(model.HEADER_FORWARDED_PROTO) == "https" {
+		scheme = "https"
+	}
+
+	writeMockJson(w, map[string]interface{}{
+		"orderId": id,
+		"formUrl": scheme + "://" + r.Host + base + "/pay?orderId=" + url.QueryEscape(id),
+	})
+}
+
+// pay stands for the payment form of the acquirer, it pays or declines the order and sends the
+// customer back to the return url.
+func (m *MockAcquirer) pay(w http.ResponseWriter, r *http.Request) {
+	m.lock.Lock()
+	order, ok := m.orders[r.FormValue("orderId")]
+	if ok && order.status == mockOrderStatusRegistered {
+		if r.FormValue("decline") == "1" {
+			order.status = mockOrderStatusDeclined
+		} else {
+			order.status = mockOrderStatusDeposited
+		}
+	}
+	m.lock.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if len(order.returnUrl) == 0 {
+		w.Write([]byte("OK"))
+		return
+	}
+
+	http.Redirect(w, r, order.returnUrl, http.StatusFound)
+}
+
+func (m *MockAcquirer) walletPayment(w http.ResponseWriter, r *http.Request, google bool) {
+	var request struct {
+		OrderNumber  string `json:"orderNumber"`
+		Merchant     string `json:"merchant"`
+		PaymentToken string `json:"paymentToken"`
+		Amount       int    `json:"amount"`
+	}
+
+	fail := func(message string) {
+		writeMockJson(w, map[string]interface{}{
+			"success": false,
+			"error":   map[string]interface{}{"code": 1, "description": message, "message": message},
+		})
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		fail("Неверный формат запроса")
+		return
+	}
+
+	if len(request.OrderNumber) == 0 || len(request.Merchant) == 0 || len(request.PaymentToken) == 0 {
+		fail("Не указаны обязательные параметры")
+		return
+	}
+
+	amount := request.Amount
+	if !google {
+		amount, _ = strconv.Atoi(request.PaymentToken)
+	}
+
+	status := mockOrderStatusDeposited
+	if request.PaymentToken == MOCK_ACQUIRER_DECLINE_TOKEN {
+		status = mockOrderStatusDeclined
+	}
+
+	id := m.addOrder(&mockAcquirerOrder{
+		number: request.OrderNumber,
+		amount: amount,
+		status: status,
+	})
+
+	writeMockJson(w, map[string]interface{}{
+		"success": true,
+		"data":    map[string]interface{}{"orderId": id},
+	})
+}
+
+func (m *MockAcquirer) orderStatus(w http.ResponseWriter, r *http.Request) {
+	order := m.getOrder(r.FormValue("orderId"))
+	if order == nil {
+		writeMockOrderError(w, "6", "Заказ не найден")
+		return
+	}
+
+	response := map[string]interface{}{
+		"errorCode":   "0",
+		"orderNumber": order.number,
+		"orderStatus": order.status,
+		"amount":      order.amount,
+		"date":        order.createAt.Unix() * 1000,
+		"paymentAmountInfo": map[string]interface{}{
+			"depositedAmount": order.amount - order.refunded,
+			"refundedAmount":  order.refunded,
+		},
+	}
+
+	if order.status != mockOrderStatusRegistered && order.status != mockOrderStatusDeclined {
+		response["cardAuthInfo"] = map[string]interface{}{"maskedPan": MOCK_ACQUIRER_MASKED_PAN}
+	}
+
+	writeMockJson(w, response)
+}
+
+func (m *MockAcquirer) refund(w http.ResponseWriter, r *http.Request) {
+	amount, _ := strconv.Atoi(r.FormValue("refundAmount"))
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	order, ok := m.orders[r.FormValue("orderId")]
+	if !ok {
+		writeMockOrderError(w, "6", "Заказ не найден")
+		return
+	}
+
+	if order.status != mockOrderStatusDeposited || amount <= 0 || order.refunded+amount > order.amount {
+		writeMockOrderError(w, "7", "Неверная сумма возврата")
+		return
+	}
+
+	order.refunded += amount
+	if order.refunded == order.amount {
+		order.status = mockOrderStatusRefunded
+	}
+
+	writeMockJson(w, map[string]interface{}{"errorCode": "0"})
+}
+
+func (m *MockAcquirer) reverse(w http.ResponseWriter, r *http.Request) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	order, ok := m.orders[r.FormValue("orderId")]
+	if !ok {
+		writeMockOrderError(w, "6", "Заказ не найден")
+		return
+	}
+
+	if order.status != mockOrderStatusDeposited || order.refunded > 0 {
+		writeMockOrderError(w, "7", "Отмена невозможна")
+		return
+	}
+
+	order.status = mockOrderStatusReversed
+
+	writeMockJson(w, map[string]interface{}{"errorCode": "0"})
+}
+
+// receiptStatus reports the receipt of a paid order as delivered to the tax service.
+func (m *MockAcquirer) receiptStatus(w http.ResponseWriter, r *http.Request) {
+	order := m.getOrder(r.FormValue("orderId"))
+	if order == nil {
+		writeMockJson(w, map[string]interface{}{"errorCode": 6, "errorMessage": "Заказ не найден"})
+		return
+	}
+
+	receipts := []interface{}{}
+	if order.status == mockOrderStatusDeposited {
+		receipts = append(receipts, map[string]interface{}{
+			"receiptStatus":             RECEIPT_STATUS_PAYMENT_DELIVERED,
+			"receipt_datetime":          order.createAt.Format(time.RFC3339),
+			"amount_total":              strconv.FormatFloat(float64(order.amount)/100, 'f', 2, 64),
+			"fn_number":                 "9999078900000000",
+			"fiscal_document_number":    1,
+			"fiscal_document_attribute": "1000000000",
+		})
+	}
+
+	writeMockJson(w, map[string]interface{}{
+		"orderNumber": order.number,
+		"orderId":     r.FormValue("orderId"),
+		"receipt":     receipts,
+	})
+}
+
+func (m *MockAcquirer) addOrder(order *mockAcquirerOrder) string {
+	order.createAt = time.Now()
+	id := model.NewId()
+
+	m.lock.Lock()
+	m.orders[id] = order
+	m.lock.Unlock()
+
+	return id
+}
+
+func (m *MockAcquirer) getOrder(id string) *mockAcquirerOrder {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if order, ok := m.orders[id]; ok {
+		copy := *order
+		return &copy
+	}
+
+	return nil
+}
+
+func writeMockOrderError(w http.ResponseWriter, code string, message string) {
+	writeMockJson(w, map[string]interface{}{"errorCode": code, "errorMessage": message})
+}
+
+func writeMockJson(w http.ResponseWriter, data interface{}) {
+	b, _ := json.Marshal(data)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
@@ -0,0 +1,86 @@
+package payment
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"time"
+
+	"im/model"
+)
+
+// Codes of the VAT rates, the payment methods and the payment objects in the cart of the
+// acquirers, both use the same ones.
+var receiptTaxTypes = map[string]int{
+	model.VAT_RATE_NONE: 0,
+	model.VAT_RATE_0:    1,
+	model.VAT_RATE_10:   2,
+	model.VAT_RATE_110:  4,
+	model.VAT_RATE_20:   6,
+	model.VAT_RATE_120:  7,
+}
+
+var receiptPaymentMethods = map[string]string{
+	model.PAYMENT_METHOD_FULL_PREPAYMENT: "1",
+	model.PAYMENT_METHOD_PREPAYMENT:      "2",
+	model.PAYMENT_METHOD_ADVANCE:         "3",
+	model.PAYMENT_METHOD_FULL_PAYMENT:    "4",
+}
+
+var receiptPaymentObjects = map[string]string{
+	model.PAYMENT_SUBJECT_COMMODITY: "1",
+	model.PAYMENT_SUBJECT_EXCISE:    "2",
+	model.PAYMENT_SUBJECT_JOB:       "3",
+	model.PAYMENT_SUBJECT_SERVICE:   "4",
+	model.PAYMENT_SUBJECT_ANOTHER:   "13",
+}
+
+const (
+	RECEIPT_STATUS_PAYMENT_SENT      = 0
+	RECEIPT_STATUS_PAYMENT_DELIVERED = 1
+	RECEIPT_STATUS_PAYMENT_FAILED    = 2
+)
+
+var receiptDatetimeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "02.01.2006 15:04:05", "2006-01-02 15:04:05"}
+
+// orderReceipt makes the fiscal data of the receipt the acquirer reported. Qr is the string
+// of the QR code printed on paper receipts, the customer checks the receipt with it in the
+// app of the tax service.
+func orderReceipt(status int, datetime string, amount string, fn string, fd int, fp string, website string) *model.OrderReceipt {
+	switch status {
+	case RECEIPT_STATUS_PAYMENT_DELIVERED:
+	case RECEIPT_STATUS_PAYMENT_FAILED:
+		return &model.OrderReceipt{Status: model.RECEIPT_STATUS_FAILED}
+	default:
+		return &model.OrderReceipt{Status: model.RECEIPT_STATUS_PENDING}
+	}
+
+	qr := url.Values{}
+	for _, layout := range receiptDatetimeLayouts {
+		if t, err := time.Parse(layout, datetime); err == nil {
+			qr.Set("t", t.Format("20060102T1504"))
+			break
+		}
+	}
+	qr.Set("s", amount)
+	qr.Set("fn", fn)
+	qr.Set("i", fmt.Sprint(fd))
+	qr.Set("fp", fp)
+	qr.Set("n", "1")
+
+	return &model.OrderReceipt{
+		Status: model.RECEIPT_STATUS_ISSUED,
+		Qr:     qr.Encode(),
+		Url:    website,
+	}
+}
+
+// orderAmount is the amount of the order in kopecks the acquirer charges, the delivery is paid
+// along with the positions.
+func orderAmount(order *model.Order) int {
+	return int(math.Round(order.AmountDue() * 100))
+}
+
+func receiptItemAttributes(item *model.ReceiptItem) (paymentMethod string, paymentObject string) {
+	return receiptPaymentMethods[item.PaymentMethod], receiptPaymentObjects[item.PaymentSubject]
+}
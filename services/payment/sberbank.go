@@ -2,11 +2,14 @@ package payment
 
 import (
 	"context"
+	"im/einterfaces"
 	"im/model"
 	"im/services/payment/sberbank"
 	"im/services/payment/sberbank/schema"
+	"math"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 const SBERBANK_ORDER_STATUS_PAYED = 2
@@ -14,6 +17,13 @@ const SBERBANK_REFUND_ORDER_STATUS_OK = "0"
 const SBERBANK_REVERSE_ORDER_STATUS_OK = "0"
 
 type SberBankBackend struct {
+	Metrics einterfaces.MetricsInterface
+}
+
+func (b *SberBankBackend) observe(method string, start time.Time, err *model.AppError) {
+	if b.Metrics != nil {
+		b.Metrics.ObservePaymentRequest(model.SBERBANK_AQUIRING_TYPE, method, time.Since(start).Seconds(), err != nil)
+	}
 }
 
 func (b *SberBankBackend) sbNew(config sberbank.ClientConfig) (*sberbank.Client, error) {
@@ -34,7 +44,12 @@ func (b *SberBankBackend) TestConnection(config sberbank.ClientConfig) *model.Ap
 	return nil
 }
 
-func (b *SberBankBackend) RegisterOrder(order *model.Order, config sberbank.ClientConfig) (response *schema.OrderResponse, err *model.AppError) {
+// RegisterOrder registers the order with the acquirer, the card the customer pays with is bound
+// to clientId when it is set.
+func (b *SberBankBackend) RegisterOrder(order *model.Order, receipt *model.Receipt, clientId string, config sberbank.ClientConfig) (response *schema.OrderResponse, err *model.AppError) {
+	defer func(start time.Time) {
+		b.observe("register_order", start, err)
+	}(time.Now())
 
 	/*sbClnt, err := b.sbNew()
 	if err != nil {
@@ -49,12 +64,19 @@ func (b *SberBankBackend) RegisterOrder(order *model.Order, config sberbank.Clie
 		client = c
 	}
 
-	amount := order.Price * 100
 	sbOrder := sberbank.Order{
 		OrderNumber: strconv.FormatInt(model.GetMillis(), 10),
-		Amount:      int(amount),
+		Amount:      orderAmount(order),
 		Description: "",
 		ReturnURL:   config.SiteURL + "/api/v4/orders/" + order.Id + "/status",
+		ClientID:    clientId,
+	}
+
+	if receipt != nil {
+		// the cart has to add up to the amount to the penny
+		sbOrder.Amount = int(receipt.Total())
+		sbOrder.OrderBundle = sberbankOrderBundle(order, receipt)
+		sbOrder.TaxSystem = receipt.TaxSystem
 	}
 
 	if result, _, err := client.RegisterOrder(context.Background(), sbOrder); err != nil {
@@ -66,6 +88,9 @@ func (b *SberBankBackend) RegisterOrder(order *model.Order, config sberbank.Clie
 }
 
 func (b *SberBankBackend) GetOrderStatus(order *model.Order, config sberbank.ClientConfig) (response *schema.OrderStatusResponse, err *model.AppError) {
+	defer func(start time.Time) {
+		b.observe("get_order_status", start, err)
+	}(time.Now())
 
 	var client *sberbank.Client
 
@@ -88,6 +113,9 @@ func (b *SberBankBackend) GetOrderStatus(order *model.Order, config sberbank.Cli
 }
 
 func (b *SberBankBackend) GetRefundOrderResponse(order *model.Order, config sberbank.ClientConfig) (response *schema.OrderResponse, err *model.AppError) {
+	defer func(start time.Time) {
+		b.observe("refund_order", start, err)
+	}(time.Now())
 
 	var client *sberbank.Client
 
@@ -97,10 +125,9 @@ func (b *SberBankBackend) GetRefundOrderResponse(order *model.Order, config sber
 		client = c
 	}
 
-	amount := order.Price * 100
 	sbOrder := sberbank.Order{
 		OrderNumber: order.PaySystemOrderNum,
-		Amount:      int(amount),
+		Amount:      orderAmount(order),
 	}
 
 	if result, _, err := client.RefundOrder(context.Background(), sbOrder); err != nil {
@@ -112,6 +139,9 @@ func (b *SberBankBackend) GetRefundOrderResponse(order *model.Order, config sber
 }
 
 func (b *SberBankBackend) GetReverseOrderResponse(order *model.Order, config sberbank.ClientConfig) (response *schema.OrderResponse, err *model.AppError) {
+	defer func(start time.Time) {
+		b.observe("reverse_order", start, err)
+	}(time.Now())
 
 	var client *sberbank.Client
 
@@ -132,3 +162,268 @@ func (b *SberBankBackend) GetReverseOrderResponse(order *model.Order, config sbe
 	}
 
 }
+
+// GetReceiptStatus returns the fiscal receipt the acquirer issued for the paid order.
+func (b *SberBankBackend) GetReceiptStatus(order *model.Order, config sberbank.ClientConfig) (receipt *model.OrderReceipt, err *model.AppError) {
+	defer func(start time.Time) {
+		b.observe("get_receipt_status", start, err)
+	}(time.Now())
+
+	var client *sberbank.Client
+
+	if c, err := b.sbNew(config); err != nil {
+		return nil, model.NewAppError("services.payment.sberbank", "get_receipt_status", nil, err.Error(), http.StatusInternalServerError)
+	} else {
+		client = c
+	}
+
+	result, _, rerr := client.GetReceiptStatus(context.Background(), sberbank.ReceiptStatusRequest{
+		OrderId: order.PaySystemCode,
+	})
+	if rerr != nil {
+		return nil, model.NewAppError("services.payment.sberbank", "get_receipt_status", nil, rerr.Error(), http.StatusInternalServerError)
+	}
+
+	if result.ErrorCode != 0 {
+		return nil, model.NewAppError("services.payment.sberbank", "get_receipt_status", nil, result.ErrorMessage, http.StatusInternalServerError)
+	}
+
+	if len(result.Receipt) == 0 {
+		return &model.OrderReceipt{Status: model.RECEIPT_STATUS_PENDING}, nil
+	}
+
+	r := result.Receipt[len(result.Receipt)-1]
+	website := r.OFD.Website
+	if len(website) == 0 {
+		website = r.FnsSite
+	}
+
+	return orderReceipt(r.ReceiptStatus, r.ReceiptDatetime, r.AmountTotal, r.FnNumber, r.FiscalDocumentNumber, r.FiscalDocumentAttribute, website), nil
+}
+
+func sberbankOrderBundle(order *model.Order, receipt *model.Receipt) *sberbank.OrderBundle {
+	bundle := &sberbank.OrderBundle{
+		OrderCreationDate: time.Unix(0, order.CreateAt*int64(time.Millisecond)).Format("2006-01-02T15:04:05"),
+		CustomerDetails: sberbank.CustomerDetails{
+			Email: receipt.Email,
+			Phone: receipt.Phone,
+		},
+	}
+
+	for i, item := range receipt.Items {
+		paymentMethod, paymentObject := receiptItemAttributes(item)
+
+		bundle.CartItems.Items = append(bundle.CartItems.Items, sberbank.CartItem{
+			PositionID: strconv.Itoa(i + 1),
+			Name:       item.Name,
+			Quantity: sberbank.Quantity{
+				Value:   item.Quantity,
+				Measure: item.Measure,
+			},
+			ItemAmount: item.Amount,
+			ItemCode:   item.Code,
+			ItemPrice:  item.Price,
+			Tax: sberbank.Tax{
+				TaxType: receiptTaxTypes[item.VatRate],
+			},
+			ItemAttributes: sberbank.ItemAttributes{
+				Attributes: []sberbank.ItemAttribute{
+					{Name: "paymentMethod", Value: paymentMethod},
+					{Name: "paymentObject", Value: paymentObject},
+				},
+			},
+		})
+	}
+
+	return bundle
+}
+
+// GetBindings returns the cards bound to the customer, the acquirer answers with error code 2
+// when there are none.
+func (b *SberBankBackend) GetBindings(clientId string, config sberbank.ClientConfig) (bindings []*model.CardBinding, err *model.AppError) {
+	defer func(start time.Time) {
+		b.observe("get_bindings", start, err)
+	}(time.Now())
+
+	var client *sberbank.Client
+
+	if c, err := b.sbNew(config); err != nil {
+		return nil, model.NewAppError("services.payment.sberbank", "get_bindings", nil, err.Error(), http.StatusInternalServerError)
+	} else {
+		client = c
+	}
+
+	result, _, rerr := client.GetBindings(context.Background(), clientId, nil)
+	if rerr != nil {
+		return nil, model.NewAppError("services.payment.sberbank", "get_bindings", nil, rerr.Error(), http.StatusInternalServerError)
+	}
+
+	if result.ErrorCode == BINDINGS_NOT_FOUND_ERROR_CODE {
+		return []*model.CardBinding{}, nil
+	}
+
+	if result.ErrorCode != 0 {
+		return nil, model.NewAppError("services.payment.sberbank", "get_bindings", nil, result.ErrorMessage, http.StatusInternalServerError)
+	}
+
+	bindings = make([]*model.CardBinding, 0, len(result.Bindings))
+	for _, binding := range result.Bindings {
+		bindings = append(bindings, &model.CardBinding{
+			UserId:      clientId,
+			PaySystemId: model.SBERBANK_AQUIRING_TYPE,
+			BindingId:   binding.BindingId,
+			MaskedPan:   binding.MaskedPan,
+			ExpiryDate:  binding.ExpiryDate,
+		})
+	}
+
+	return bindings, nil
+}
+
+func (b *SberBankBackend) UnBindCard(bindingId string, config sberbank.ClientConfig) (err *model.AppError) {
+	defer func(start time.Time) {
+		b.observe("unbind_card", start, err)
+	}(time.Now())
+
+	var client *sberbank.Client
+
+	if c, err := b.sbNew(config); err != nil {
+		return model.NewAppError("services.payment.sberbank", "unbind_card", nil, err.Error(), http.StatusInternalServerError)
+	} else {
+		client = c
+	}
+
+	result, _, rerr := client.UnBindCard(context.Background(), sberbank.Binding{BindingID: bindingId})
+	if rerr != nil {
+		return model.NewAppError("services.payment.sberbank", "unbind_card", nil, rerr.Error(), http.StatusInternalServerError)
+	}
+
+	// the binding is already gone on the acquirer side
+	if result.ErrorCode != 0 && result.ErrorCode != BINDING_NOT_FOUND_ERROR_CODE {
+		return model.NewAppError("services.payment.sberbank", "unbind_card", nil, result.ErrorMessage, http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// PaymentOrderBinding pays the order registered with RegisterOrder with the bound card.
+func (b *SberBankBackend) PaymentOrderBinding(order *model.Order, bindingId string, ip string, cvc string, config sberbank.ClientConfig) (payment *model.CardBindingPayment, err *model.AppError) {
+	defer func(start time.Time) {
+		b.observe("payment_order_binding", start, err)
+	}(time.Now())
+
+	var client *sberbank.Client
+
+	if c, err := b.sbNew(config); err != nil {
+		return nil, model.NewAppError("services.payment.sberbank", "payment_order_binding", nil, err.Error(), http.StatusInternalServerError)
+	} else {
+		client = c
+	}
+
+	result, _, rerr := client.PaymentOrderBinding(context.Background(), sberbank.OrderBinding{
+		MdOrder:   order.PaySystemCode,
+		BindingID: bindingId,
+		IP:        ip,
+		CVC:       cvc,
+	})
+	if rerr != nil {
+		return nil, model.NewAppError("services.payment.sberbank", "payment_order_binding", nil, rerr.Error(), http.StatusInternalServerError)
+	}
+
+	if result.ErrorCode != 0 {
+		return nil, model.NewAppError("services.payment.sberbank", "payment_order_binding", nil, result.ErrorMessage, http.StatusBadRequest)
+	}
+
+	return &model.CardBindingPayment{
+		AcsUrl:  result.AcsUrl,
+		PaReq:   result.PaReq,
+		TermUrl: result.TermUrl,
+	}, nil
+}
+
+// PayWithWallet pays the order with the Apple Pay or Google Pay token, the acquirer registers
+// the order itself and answers with its id.
+func (b *SberBankBackend) PayWithWallet(order *model.Order, wallet string, paymentToken string, merchant string, ip string, config sberbank.ClientConfig) (orderId string, err *model.AppError) {
+	defer func(start time.Time) {
+		b.observe("pay_with_"+wallet, start, err)
+	}(time.Now())
+
+	var client *sberbank.Client
+
+	if c, err := b.sbNew(config); err != nil {
+		return "", model.NewAppError("services.payment.sberbank", "pay_with_wallet", nil, err.Error(), http.StatusInternalServerError)
+	} else {
+		client = c
+	}
+
+	orderNumber := strconv.FormatInt(model.GetMillis(), 10)
+
+	switch wallet {
+	case model.WALLET_APPLE_PAY:
+		result, _, rerr := client.PayWithApplePay(context.Background(), sberbank.ApplePaymentRequest{
+			OrderNumber:  orderNumber,
+			Merchant:     merchant,
+			PaymentToken: paymentToken,
+		})
+		if rerr != nil {
+			return "", model.NewAppError("services.payment.sberbank", "pay_with_wallet", nil, rerr.Error(), http.StatusInternalServerError)
+		}
+
+		if !result.Success {
+			return "", model.NewAppError("services.payment.sberbank", "pay_with_wallet", nil, result.Error.Message, http.StatusBadRequest)
+		}
+
+		return result.Data.OrderID, nil
+	case model.WALLET_GOOGLE_PAY:
+		result, _, rerr := client.PayWithGooglePay(context.Background(), sberbank.GooglePaymentRequest{
+			OrderNumber:  orderNumber,
+			Merchant:     merchant,
+			PaymentToken: paymentToken,
+			Language:     config.Language,
+			IP:           ip,
+			Amount:       orderAmount(order),
+			CurrencyCode: config.Currency,
+			ReturnUrl:    config.SiteURL + "/api/v4/orders/" + order.Id + "/status",
+		})
+		if rerr != nil {
+			return "", model.NewAppError("services.payment.sberbank", "pay_with_wallet", nil, rerr.Error(), http.StatusInternalServerError)
+		}
+
+		if !result.Success {
+			return "", model.NewAppError("services.payment.sberbank", "pay_with_wallet", nil, result.Error.Message, http.StatusBadRequest)
+		}
+
+		return result.Data.OrderID, nil
+	}
+
+	return "", model.NewAppError("services.payment.sberbank", "pay_with_wallet", nil, "wallet="+wallet, http.StatusBadRequest)
+}
+
+// RefundPayment returns the amount of the card payment made with the acquirer order mdOrder.
+func (b *SberBankBackend) RefundPayment(mdOrder string, amount float64, config sberbank.ClientConfig) (err *model.AppError) {
+	defer func(start time.Time) {
+		b.observe("refund_payment", start, err)
+	}(time.Now())
+
+	var client *sberbank.Client
+
+	if c, err := b.sbNew(config); err != nil {
+		return model.NewAppError("services.payment.sberbank", "refund_payment", nil, err.Error(), http.StatusInternalServerError)
+	} else {
+		client = c
+	}
+
+	result, _, rerr := client.RefundOrder(context.Background(), sberbank.Order{
+		OrderNumber: mdOrder,
+		Amount:      int(math.Round(amount * 100)),
+	})
+	if rerr != nil {
+		return model.NewAppError("services.payment.sberbank", "refund_payment", nil, rerr.Error(), http.StatusInternalServerError)
+	}
+
+	if result.ErrorCode != "" && result.ErrorCode != SBERBANK_REFUND_ORDER_STATUS_OK {
+		return model.NewAppError("services.payment.sberbank", "refund_payment", nil, result.ErrorMessage, http.StatusBadRequest)
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package sberbank
+
+// OrderBundle is the cart passed with the order registration, the acquirer issues the fiscal
+// receipt (54-FZ) from it once the order is paid.
+// see https://securepayments.sberbank.ru/wiki/doku.php/integration:api:rest:requests:register_cart
+type OrderBundle struct {
+	OrderCreationDate string          `json:"orderCreationDate,omitempty"`
+	CustomerDetails   CustomerDetails `json:"customerDetails"`
+	CartItems         CartItems       `json:"cartItems"`
+}
+
+type CustomerDetails struct {
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"`
+}
+
+type CartItems struct {
+	Items []CartItem `json:"items"`
+}
+
+// CartItem is a position of the cart, "ItemAmount" and "ItemPrice" are in pennies.
+type CartItem struct {
+	PositionID     string         `json:"positionId"`
+	Name           string         `json:"name"`
+	Quantity       Quantity       `json:"quantity"`
+	ItemAmount     int64          `json:"itemAmount"`
+	ItemCode       string         `json:"itemCode"`
+	ItemPrice      int64          `json:"itemPrice"`
+	Tax            Tax            `json:"tax"`
+	ItemAttributes ItemAttributes `json:"itemAttributes"`
+}
+
+type Quantity struct {
+	Value   int    `json:"value"`
+	Measure string `json:"measure"`
+}
+
+// Tax is the VAT of the position
+//
+// 0 - no VAT, 1 - 0%, 2 - 10%, 4 - 10/110, 6 - 20%, 7 - 20/120
+type Tax struct {
+	TaxType int `json:"taxType"`
+}
+
+type ItemAttributes struct {
+	Attributes []ItemAttribute `json:"attributes"`
+}
+
+// ItemAttribute is "paymentMethod" or "paymentObject" of the position
+type ItemAttribute struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
@@ -31,7 +31,10 @@ type ClientConfig struct {
 	endpoint           string
 	token              string
 	SandboxMode        bool
-	SiteURL            string
+	// SandboxEndpoint replaces the test hosts of the bank in the sandbox mode, e.g. with the
+	// mock acquirer.
+	SandboxEndpoint string
+	SiteURL         string
 }
 
 // Client is a client to SB API
@@ -83,6 +86,10 @@ var newRestRequest = func(c *Client, ctx context.Context, method, urlPath string
 		uri = APISandboxURI + urlPath
 	}
 
+	if c.Config.SandboxMode && c.Config.SandboxEndpoint != "" {
+		uri = strings.TrimRight(c.Config.SandboxEndpoint, "/") + urlPath
+	}
+
 	if c.Config.endpoint != "" {
 		uri = c.Config.endpoint + urlPath
 	}
@@ -134,6 +141,10 @@ var newRequest = func(c *Client, ctx context.Context, method, urlPath string, da
 		uri = APISandboxURI + urlPath
 	}
 
+	if c.Config.SandboxMode && c.Config.SandboxEndpoint != "" {
+		uri = strings.TrimRight(c.Config.SandboxEndpoint, "/") + urlPath
+	}
+
 	if c.Config.endpoint != "" {
 		uri = c.Config.endpoint + urlPath
 	}
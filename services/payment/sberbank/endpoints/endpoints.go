@@ -12,6 +12,7 @@ const (
 	UnBindCard             string = "/payment/rest/unBindCard.do"
 	BindCard               string = "/payment/rest/bindCard.do"
 	GetBindings            string = "/payment/rest/getBindings.do"
+	PaymentOrderBinding    string = "/payment/rest/paymentOrderBinding.do"
 	ExtendBinding          string = "/payment/rest/extendBinding.do"
 	ApplePay               string = "/payment/applepay/payment.do"
 	SamsungPay             string = "/payment/samsung/payment.do"
@@ -10,3 +10,15 @@ type BindingsResponse struct {
 		ExpiryDate string `json:"expiryDate,omitempty"`
 	} `json:"bindings,omitempty"`
 }
+
+// OrderBindingResponse is response received from PaymentOrderBinding request, "AcsUrl", "PaReq"
+// and "TermUrl" are set when the card issuer asks for 3-D Secure
+type OrderBindingResponse struct {
+	ErrorCode    int    `json:"errorCode"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	Info         string `json:"info,omitempty"`
+	Redirect     string `json:"redirect,omitempty"`
+	AcsUrl       string `json:"acsUrl,omitempty"`
+	PaReq        string `json:"paReq,omitempty"`
+	TermUrl      string `json:"termUrl,omitempty"`
+}
@@ -0,0 +1,69 @@
+package pos
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"im/model"
+	"im/services/httpservice"
+)
+
+const HTTP_PROVIDER_RESPONSE_READ_SIZE = 1024
+
+// HTTPProvider speaks a generic JSON protocol: the order is posted to {url}/orders with
+// the api key as a bearer token, the POS answers with {"id": "...", "status": "..."}.
+type HTTPProvider struct {
+	HTTPService httpservice.HTTPService
+
+	url    string
+	apiKey string
+}
+
+func MakeHTTPProvider(url, apiKey string, httpService httpservice.HTTPService) *HTTPProvider {
+	return &HTTPProvider{
+		HTTPService: httpService,
+		url:         strings.TrimRight(url, "/"),
+		apiKey:      apiKey,
+	}
+}
+
+func (provider *HTTPProvider) ExportOrder(order *model.PosOrder) (*ExportResult, error) {
+	req, err := http.NewRequest("POST", provider.url+"/orders", bytes.NewReader([]byte(order.ToJson())))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if len(provider.apiKey) > 0 {
+		req.Header.Set(model.HEADER_AUTH, model.HEADER_BEARER+" "+provider.apiKey)
+	}
+
+	// the url comes from the application settings, so it goes through the untrusted client
+	resp, err := provider.HTTPService.MakeClient(false).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer provider.HTTPService.ConsumeAndClose(resp)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, HTTP_PROVIDER_RESPONSE_READ_SIZE))
+		return nil, fmt.Errorf("pos: unexpected response status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result ExportResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("pos: failed to decode response: %v", err)
+	}
+
+	if len(result.ExternalId) == 0 {
+		return nil, fmt.Errorf("pos: response has no order id")
+	}
+
+	return &result, nil
+}
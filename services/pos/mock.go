@@ -0,0 +1,87 @@
+package pos
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"im/model"
+)
+
+// MockServer is an in-memory POS speaking the protocol of HTTPProvider. It is meant for development and
+// tests, e.g. served with httptest.NewServer and set as the POS url of an application.
+type MockServer struct {
+	lock   sync.RWMutex
+	apiKey string
+	orders map[string]*model.PosOrder
+	ids    map[string]string
+	next   int
+	fail   bool
+}
+
+func NewMockServer(apiKey string) *MockServer {
+	return &MockServer{
+		apiKey: apiKey,
+		orders: make(map[string]*model.PosOrder),
+		ids:    make(map[string]string),
+	}
+}
+
+// SetFail makes the server answer every following request with an internal error.
+func (server *MockServer) SetFail(fail bool) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	server.fail = fail
+}
+
+// Orders returns the orders received so far keyed by their external id.
+func (server *MockServer) Orders() map[string]*model.PosOrder {
+	server.lock.RLock()
+	defer server.lock.RUnlock()
+
+	orders := make(map[string]*model.PosOrder, len(server.orders))
+	for id, order := range server.orders {
+		orders[id] = order
+	}
+	return orders
+}
+
+func (server *MockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" || strings.TrimRight(r.URL.Path, "/") != "/orders" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if len(server.apiKey) > 0 && r.Header.Get(model.HEADER_AUTH) != model.HEADER_BEARER+" "+server.apiKey {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	order := model.PosOrderFromJson(r.Body)
+	if order == nil || len(order.Id) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	if server.fail {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// the same order exported twice keeps its external id
+	externalId, ok := server.ids[order.Id]
+	if !ok {
+		server.next++
+		externalId = "mock-" + strconv.Itoa(server.next)
+		server.ids[order.Id] = externalId
+	}
+	server.orders[externalId] = order
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"id":"` + externalId + `","status":"` + model.POS_ORDER_STATUS_ACCEPTED + `"}`))
+}
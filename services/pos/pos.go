@@ -0,0 +1,39 @@
+package pos
+
+import (
+	"errors"
+
+	"im/model"
+	"im/services/httpservice"
+)
+
+var (
+	ErrNotConfigured   = errors.New("pos: POS is not configured for the application")
+	ErrUnknownProvider = errors.New("pos: unknown POS provider")
+)
+
+// An ExportResult is what the POS answers when it accepts an order.
+type ExportResult struct {
+	// ExternalId is the id of the order in the POS, status feedback refers to it.
+	ExternalId string `json:"id"`
+	Status     string `json:"status"`
+}
+
+// A Provider sends orders to the POS of a restaurant. A Provider should be created using NewProvider
+// which chooses the implementation from the POS settings of the application.
+type Provider interface {
+	// ExportOrder creates the order in the POS. Exporting the same order again must not create a duplicate,
+	// the POS is expected to treat PosOrder.Id as an idempotency key.
+	ExportOrder(order *model.PosOrder) (*ExportResult, error)
+}
+
+func NewProvider(application *model.Application, httpService httpservice.HTTPService) (Provider, error) {
+	switch application.PosType {
+	case "":
+		return nil, ErrNotConfigured
+	case model.POS_PROVIDER_TYPE_HTTP:
+		return MakeHTTPProvider(application.PosUrl, application.PosApiKey, httpService), nil
+	default:
+		return nil, ErrUnknownProvider
+	}
+}
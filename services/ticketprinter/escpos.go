@@ -0,0 +1,77 @@
+package ticketprinter
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// The ESC/POS commands the tickets are printed with.
+var (
+	escPosInit         = []byte{0x1b, 0x40}
+	escPosCodePage866  = []byte{0x1b, 0x74, 0x11}
+	escPosAlignLeft    = []byte{0x1b, 0x61, 0x00}
+	escPosAlignCenter  = []byte{0x1b, 0x61, 0x01}
+	escPosBoldOn       = []byte{0x1b, 0x45, 0x01}
+	escPosBoldOff      = []byte{0x1b, 0x45, 0x00}
+	escPosDoubleSize   = []byte{0x1d, 0x21, 0x11}
+	escPosNormalSize   = []byte{0x1d, 0x21, 0x00}
+	escPosFeedLines    = []byte{0x1b, 0x64, 0x04}
+	escPosPartialCut   = []byte{0x1d, 0x56, 0x42, 0x00}
+	escPosLineFeed     = []byte{0x0a}
+	escPosRuleRune     = "-"
+	escPosDoubleFactor = 2
+)
+
+// RenderEscPos prints the lines for a thermal printer of the paper width. The text is sent in
+// the CP866 code page, which the printers sold here have for the Cyrillic letters.
+func RenderEscPos(lines []*Line, paperWidth int) ([]byte, error) {
+	columns := Columns(paperWidth)
+	encoder := encoding.ReplaceUnsupported(charmap.CodePage866.NewEncoder())
+
+	var buf bytes.Buffer
+	buf.Write(escPosInit)
+	buf.Write(escPosCodePage866)
+
+	for _, line := range lines {
+		width := columns
+
+		switch line.Style {
+		case STYLE_RULE:
+			buf.WriteString(strings.Repeat(escPosRuleRune, columns))
+			buf.Write(escPosLineFeed)
+			continue
+		case STYLE_TITLE:
+			buf.Write(escPosAlignCenter)
+			buf.Write(escPosBoldOn)
+			buf.Write(escPosDoubleSize)
+			width = columns / escPosDoubleFactor
+		case STYLE_HEADING:
+			buf.Write(escPosAlignCenter)
+			buf.Write(escPosBoldOn)
+		case STYLE_BOLD:
+			buf.Write(escPosBoldOn)
+		}
+
+		for _, s := range wrap(line.Text, width) {
+			encoded, err := encoder.String(s)
+			if err != nil {
+				return nil, err
+			}
+
+			buf.WriteString(encoded)
+			buf.Write(escPosLineFeed)
+		}
+
+		buf.Write(escPosNormalSize)
+		buf.Write(escPosBoldOff)
+		buf.Write(escPosAlignLeft)
+	}
+
+	buf.Write(escPosFeedLines)
+	buf.Write(escPosPartialCut)
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,92 @@
+package ticketprinter
+
+import (
+	"bytes"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	PDF_FONT_FAMILY = "ticket"
+	PDF_MARGIN      = 3.0
+
+	pdfTextSize       = 9.0
+	pdfTitleSize      = 14.0
+	pdfLineHeightRate = 0.45
+)
+
+// RenderPdf lays the lines out on a page as wide as the paper of the printer and as long as
+// the ticket, with the TrueType font of the fonts directory.
+func RenderPdf(lines []*Line, paperWidth int, fontPath string) ([]byte, error) {
+	width := float64(paperWidth)
+	textWidth := width - 2*PDF_MARGIN
+
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		UnitStr: "mm",
+		Size:    gofpdf.SizeType{Wd: width, Ht: width},
+	})
+	pdf.SetMargins(PDF_MARGIN, PDF_MARGIN, PDF_MARGIN)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddUTF8Font(PDF_FONT_FAMILY, "", fontPath)
+	if err := pdf.Error(); err != nil {
+		return nil, err
+	}
+
+	type pdfRow struct {
+		text   string
+		size   float64
+		align  string
+		rule   bool
+		height float64
+	}
+
+	var rows []*pdfRow
+	height := 2 * PDF_MARGIN
+	for _, line := range lines {
+		if line.Style == STYLE_RULE {
+			rows = append(rows, &pdfRow{rule: true, height: pdfTextSize * pdfLineHeightRate})
+			height += pdfTextSize * pdfLineHeightRate
+			continue
+		}
+
+		size, align := pdfTextSize, "L"
+		switch line.Style {
+		case STYLE_TITLE:
+			size, align = pdfTitleSize, "C"
+		case STYLE_HEADING:
+			align = "C"
+		}
+
+		pdf.SetFont(PDF_FONT_FAMILY, "", size)
+		texts := pdf.SplitText(line.Text, textWidth)
+		if len(texts) == 0 {
+			texts = []string{""}
+		}
+
+		for _, text := range texts {
+			rows = append(rows, &pdfRow{text: text, size: size, align: align, height: size * pdfLineHeightRate})
+			height += size * pdfLineHeightRate
+		}
+	}
+
+	pdf.AddPageFormat("P", gofpdf.SizeType{Wd: width, Ht: height})
+
+	y := PDF_MARGIN
+	for _, row := range rows {
+		if row.rule {
+			pdf.Line(PDF_MARGIN, y+row.height/2, width-PDF_MARGIN, y+row.height/2)
+		} else {
+			pdf.SetFont(PDF_FONT_FAMILY, "", row.size)
+			pdf.SetXY(PDF_MARGIN, y)
+			pdf.CellFormat(textWidth, row.height, row.text, "", 0, row.align, false, 0, "")
+		}
+		y += row.height
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
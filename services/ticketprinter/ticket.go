@@ -0,0 +1,152 @@
+package ticketprinter
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"im/model"
+)
+
+const (
+	TEMPLATE_FILE_NAME = "kitchen_ticket.txt"
+
+	TIME_FORMAT = "02.01.2006 15:04"
+)
+
+// The template of a ticket writes one line per line of the ticket. A line may start with a
+// marker of its style: "# " is the title, "## " a centered heading, "* " bold text, and "---"
+// draws a rule.
+const (
+	STYLE_TEXT    = "text"
+	STYLE_BOLD    = "bold"
+	STYLE_HEADING = "heading"
+	STYLE_TITLE   = "title"
+	STYLE_RULE    = "rule"
+)
+
+// Ticket is what the kitchen needs to know of the positions of an order made in one office.
+type Ticket struct {
+	Number     string
+	Office     string
+	CreatedAt  string
+	DeliveryAt string
+	Comment    string
+	Reprint    bool
+	Positions  []*TicketPosition
+}
+
+type TicketPosition struct {
+	Name      string
+	Quantity  int
+	Modifiers []string
+	Comment   string
+}
+
+type Line struct {
+	Style string
+	Text  string
+}
+
+func NewTicket(order *model.Order, positions []*model.Basket, office *model.Office, reprint bool, location *time.Location) *Ticket {
+	t := &Ticket{
+		Number:    order.FormatOrderNumber(),
+		Office:    office.Name,
+		CreatedAt: time.Unix(0, order.CreateAt*int64(time.Millisecond)).In(location).Format(TIME_FORMAT),
+		Comment:   order.Comment,
+		Reprint:   reprint,
+	}
+
+	if order.DeliveryAt > 0 {
+		t.DeliveryAt = time.Unix(0, order.DeliveryAt*int64(time.Millisecond)).In(location).Format(TIME_FORMAT)
+	}
+
+	for _, position := range positions {
+		t.Positions = append(t.Positions, &TicketPosition{
+			Name:      position.Name,
+			Quantity:  position.Quantity,
+			Modifiers: position.Modifiers,
+			Comment:   position.Comment,
+		})
+	}
+
+	return t
+}
+
+// Lines lays the ticket out with the template found in the templates directory.
+func (t *Ticket) Lines(templatesDir string) ([]*Line, error) {
+	tmpl, err := template.ParseFiles(filepath.Join(templatesDir, TEMPLATE_FILE_NAME))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, t); err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimRight(buf.String(), "\n")
+
+	var lines []*Line
+	for _, s := range strings.Split(text, "\n") {
+		lines = append(lines, parseLine(s))
+	}
+
+	return lines, nil
+}
+
+func parseLine(s string) *Line {
+	switch {
+	case strings.TrimSpace(s) == "---":
+		return &Line{Style: STYLE_RULE}
+	case strings.HasPrefix(s, "## "):
+		return &Line{Style: STYLE_HEADING, Text: s[3:]}
+	case strings.HasPrefix(s, "# "):
+		return &Line{Style: STYLE_TITLE, Text: s[2:]}
+	case strings.HasPrefix(s, "* "):
+		return &Line{Style: STYLE_BOLD, Text: s[2:]}
+	default:
+		return &Line{Style: STYLE_TEXT, Text: s}
+	}
+}
+
+// Columns is how many characters of the standard font of a thermal printer fit on the paper.
+func Columns(paperWidth int) int {
+	if paperWidth == model.PRINT_PAPER_WIDTH_58 {
+		return 32
+	}
+
+	return 48
+}
+
+// wrap breaks the text into lines of at most width characters, at the spaces when it can.
+// The indentation of the text is kept on the following lines.
+func wrap(text string, width int) []string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return []string{text}
+	}
+
+	indent := len(runes) - len([]rune(strings.TrimLeft(text, " ")))
+	if indent >= width/2 {
+		indent = 0
+	}
+
+	var lines []string
+	for len(runes) > width {
+		cut := width
+		for i := width; i > indent; i-- {
+			if runes[i] == ' ' {
+				cut = i
+				break
+			}
+		}
+
+		lines = append(lines, strings.TrimRight(string(runes[:cut]), " "))
+		runes = append([]rune(strings.Repeat(" ", indent)), []rune(strings.TrimLeft(string(runes[cut:]), " "))...)
+	}
+
+	return append(lines, string(runes))
+}
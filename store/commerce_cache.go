@@ -0,0 +1,312 @@
+package store
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis"
+
+	"im/einterfaces"
+	"im/mlog"
+	"im/model"
+	"im/utils"
+)
+
+const (
+	COMMERCE_CACHE_REDIS_KEY_PREFIX = "im:cache:"
+	COMMERCE_CACHE_REDIS_SCAN_COUNT = 1000
+)
+
+// CacheBackend keeps the encoded objects of a CommerceCache. The objects are kept encoded so the
+// callers never share the cached value and the same cache can live in the process or in Redis.
+type CacheBackend interface {
+	Get(key string) ([]byte, bool, error)
+	Set(key string, value []byte) error
+	Remove(key string) error
+	Purge() error
+}
+
+// LruCacheBackend keeps the objects in the memory of the process.
+type LruCacheBackend struct {
+	cache *utils.Cache
+}
+
+func NewLruCacheBackend(size int, name string, expirySecs int64, invalidateClusterEvent string) *LruCacheBackend {
+	return &LruCacheBackend{
+		cache: utils.NewLruWithParams(size, name, expirySecs, invalidateClusterEvent),
+	}
+}
+
+func (b *LruCacheBackend) Get(key string) ([]byte, bool, error) {
+	if value, ok := b.cache.Get(key); ok {
+		return value.([]byte), true, nil
+	}
+
+	return nil, false, nil
+}
+
+func (b *LruCacheBackend) Set(key string, value []byte) error {
+	b.cache.AddWithDefaultExpires(key, value)
+	return nil
+}
+
+func (b *LruCacheBackend) Remove(key string) error {
+	b.cache.Remove(key)
+	return nil
+}
+
+func (b *LruCacheBackend) Purge() error {
+	b.cache.Purge()
+	return nil
+}
+
+// RedisCacheBackend keeps the objects in Redis, shared by all the nodes of the cluster.
+type RedisCacheBackend struct {
+	client *redis.Client
+	prefix string
+	expiry time.Duration
+}
+
+func NewRedisCacheBackend(client *redis.Client, name string, expiry time.Duration) *RedisCacheBackend {
+	return &RedisCacheBackend{
+		client: client,
+		prefix: COMMERCE_CACHE_REDIS_KEY_PREFIX + name + ":",
+		expiry: expiry,
+	}
+}
+
+func (b *RedisCacheBackend) Get(key string) ([]byte, bool, error) {
+	data, err := b.client.Get(b.prefix + key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	return data, true, nil
+}
+
+func (b *RedisCacheBackend) Set(key string, value []byte) error {
+	return b.client.Set(b.prefix+key, value, b.expiry).Err()
+}
+
+func (b *RedisCacheBackend) Remove(key string) error {
+	return b.client.Del(b.prefix + key).Err()
+}
+
+func (b *RedisCacheBackend) Purge() error {
+	var cursor uint64
+	for {
+		keys, next, err := b.client.Scan(cursor, b.prefix+"*", COMMERCE_CACHE_REDIS_SCAN_COUNT).Result()
+		if err != nil {
+			return err
+		}
+
+		if len(keys) > 0 {
+			if err := b.client.Del(keys...).Err(); err != nil {
+				return err
+			}
+		}
+
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// MemoryCacheBackend is a plain map without eviction, meant to stand in for the other backends.
+type MemoryCacheBackend struct {
+	mutex sync.RWMutex
+	items map[string][]byte
+}
+
+func NewMemoryCacheBackend() *MemoryCacheBackend {
+	return &MemoryCacheBackend{
+		items: make(map[string][]byte),
+	}
+}
+
+func (b *MemoryCacheBackend) Get(key string) ([]byte, bool, error) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	value, ok := b.items[key]
+	return value, ok, nil
+}
+
+func (b *MemoryCacheBackend) Set(key string, value []byte) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.items[key] = value
+	return nil
+}
+
+func (b *MemoryCacheBackend) Remove(key string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	delete(b.items, key)
+	return nil
+}
+
+func (b *MemoryCacheBackend) Purge() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.items = make(map[string][]byte)
+	return nil
+}
+
+func (b *MemoryCacheBackend) Len() int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	return len(b.items)
+}
+
+// CommerceCache caches the objects the store reads by id. A write removes the object on this
+// node and tells the other nodes of the cluster to do the same.
+type CommerceCache struct {
+	name    string
+	event   string
+	backend CacheBackend
+	cluster einterfaces.ClusterInterface
+	metrics einterfaces.MetricsInterface
+}
+
+func NewCommerceCache(name string, event string, backend CacheBackend, cluster einterfaces.ClusterInterface, metrics einterfaces.MetricsInterface) *CommerceCache {
+	c := &CommerceCache{
+		name:    name,
+		event:   event,
+		backend: backend,
+		cluster: cluster,
+		metrics: metrics,
+	}
+
+	if cluster != nil {
+		cluster.RegisterClusterMessageHandler(event, c.handleClusterInvalidate)
+	}
+
+	return c
+}
+
+// NewCommerceCacheBackend returns the backend for the cache settings. A Redis client is given
+// when the cache is kept in Redis.
+func NewCommerceCacheBackend(settings model.CacheSettings, client *redis.Client, name string, event string) CacheBackend {
+	if *settings.CacheType == model.CACHE_TYPE_REDIS && client != nil {
+		return NewRedisCacheBackend(client, name, time.Duration(*settings.CacheSeconds)*time.Second)
+	}
+
+	return NewLruCacheBackend(*settings.CacheSize, name, int64(*settings.CacheSeconds), event)
+}
+
+func (c *CommerceCache) Name() string {
+	return c.name
+}
+
+// Get returns the cached object, or loads it with the store and caches it. The object is
+// decoded into the value newValue returns.
+func (c *CommerceCache) Get(key string, newValue func() interface{}, load func() StoreChannel) StoreChannel {
+	return Do(func(result *StoreResult) {
+		if data, ok, err := c.backend.Get(key); err != nil {
+			mlog.Warn("Failed to read from the cache", mlog.String("cache", c.name), mlog.String("key", key), mlog.Err(err))
+		} else if ok {
+			value := newValue()
+			if err := DecodeBytes(data, value); err == nil {
+				c.incrementHit()
+				result.Data = value
+				return
+			}
+
+			mlog.Warn("Failed to decode the cached value", mlog.String("cache", c.name), mlog.String("key", key), mlog.Err(err))
+		}
+
+		c.incrementMiss()
+
+		*result = <-load()
+		if result.Err != nil || result.Data == nil {
+			return
+		}
+
+		data, err := GetBytes(result.Data)
+		if err != nil {
+			mlog.Warn("Failed to encode the value for the cache", mlog.String("cache", c.name), mlog.String("key", key), mlog.Err(err))
+			return
+		}
+
+		if err := c.backend.Set(key, data); err != nil {
+			mlog.Warn("Failed to write to the cache", mlog.String("cache", c.name), mlog.String("key", key), mlog.Err(err))
+		}
+	})
+}
+
+// InvalidateAfter removes the object once the write is done.
+func (c *CommerceCache) InvalidateAfter(key string, write StoreChannel) StoreChannel {
+	return Do(func(result *StoreResult) {
+		*result = <-write
+		c.Invalidate(key)
+	})
+}
+
+// PurgeAfter clears the cache once the write is done, for the writes that change many objects.
+func (c *CommerceCache) PurgeAfter(write StoreChannel) StoreChannel {
+	return Do(func(result *StoreResult) {
+		*result = <-write
+		c.Purge()
+	})
+}
+
+func (c *CommerceCache) Invalidate(key string) {
+	if err := c.backend.Remove(key); err != nil {
+		mlog.Warn("Failed to remove from the cache", mlog.String("cache", c.name), mlog.String("key", key), mlog.Err(err))
+	}
+
+	c.sendClusterInvalidate(key)
+}
+
+func (c *CommerceCache) Purge() {
+	if err := c.backend.Purge(); err != nil {
+		mlog.Warn("Failed to purge the cache", mlog.String("cache", c.name), mlog.Err(err))
+	}
+
+	c.sendClusterInvalidate(CLEAR_CACHE_MESSAGE_DATA)
+}
+
+func (c *CommerceCache) sendClusterInvalidate(key string) {
+	if c.cluster == nil {
+		return
+	}
+
+	c.cluster.SendClusterMessage(&model.ClusterMessage{
+		Event:    c.event,
+		SendType: model.CLUSTER_SEND_BEST_EFFORT,
+		Data:     key,
+	})
+}
+
+func (c *CommerceCache) handleClusterInvalidate(msg *model.ClusterMessage) {
+	var err error
+	if msg.Data == CLEAR_CACHE_MESSAGE_DATA {
+		err = c.backend.Purge()
+	} else {
+		err = c.backend.Remove(msg.Data)
+	}
+
+	if err != nil {
+		mlog.Warn("Failed to invalidate the cache from the cluster", mlog.String("cache", c.name), mlog.Err(err))
+	}
+}
+
+func (c *CommerceCache) incrementHit() {
+	if c.metrics != nil {
+		c.metrics.IncrementCacheHit(c.name)
+	}
+}
+
+func (c *CommerceCache) incrementMiss() {
+	if c.metrics != nil {
+		c.metrics.IncrementCacheMiss(c.name)
+	}
+}
@@ -0,0 +1,180 @@
+package store
+
+import (
+	"im/model"
+)
+
+// CachedProductStore serves Get from the cache. Products are cached as stored, without the
+// category, the media and the offices the app attaches.
+type CachedProductStore struct {
+	ProductStore
+	cache *CommerceCache
+}
+
+func NewCachedProductStore(productStore ProductStore, cache *CommerceCache) *CachedProductStore {
+	return &CachedProductStore{ProductStore: productStore, cache: cache}
+}
+
+func (s *CachedProductStore) Get(productId string) StoreChannel {
+	return s.cache.Get(productId, func() interface{} { return &model.Product{} }, func() StoreChannel {
+		return s.ProductStore.Get(productId)
+	})
+}
+
+func (s *CachedProductStore) Update(newProduct *model.Product) StoreChannel {
+	return s.cache.InvalidateAfter(newProduct.Id, s.ProductStore.Update(newProduct))
+}
+
+func (s *CachedProductStore) Overwrite(product *model.Product) StoreChannel {
+	return s.cache.InvalidateAfter(product.Id, s.ProductStore.Overwrite(product))
+}
+
+func (s *CachedProductStore) Delete(productId string, time int64, deleteByID string) StoreChannel {
+	return s.cache.InvalidateAfter(productId, s.ProductStore.Delete(productId, time, deleteByID))
+}
+
+// CachedCategoryStore serves Get from the cache. Creating, moving, ordering and deleting a
+// category renumbers the nested set of the tree, so those clear the whole cache.
+type CachedCategoryStore struct {
+	CategoryStore
+	cache *CommerceCache
+}
+
+func NewCachedCategoryStore(categoryStore CategoryStore, cache *CommerceCache) *CachedCategoryStore {
+	return &CachedCategoryStore{CategoryStore: categoryStore, cache: cache}
+}
+
+func (s *CachedCategoryStore) Get(categoryId string) StoreChannel {
+	return s.cache.Get(categoryId, func() interface{} { return &model.Category{} }, func() StoreChannel {
+		return s.CategoryStore.Get(categoryId)
+	})
+}
+
+func (s *CachedCategoryStore) Create(category *model.Category) StoreChannel {
+	return s.cache.PurgeAfter(s.CategoryStore.Create(category))
+}
+
+func (s *CachedCategoryStore) Move(category *model.Category) StoreChannel {
+	return s.cache.PurgeAfter(s.CategoryStore.Move(category))
+}
+
+func (s *CachedCategoryStore) Order(category *model.Category) StoreChannel {
+	return s.cache.PurgeAfter(s.CategoryStore.Order(category))
+}
+
+func (s *CachedCategoryStore) Update(category *model.Category) StoreChannel {
+	return s.cache.InvalidateAfter(category.Id, s.CategoryStore.Update(category))
+}
+
+func (s *CachedCategoryStore) Delete(category *model.Category) StoreChannel {
+	return s.cache.PurgeAfter(s.CategoryStore.Delete(category))
+}
+
+type CachedOfficeStore struct {
+	OfficeStore
+	cache *CommerceCache
+}
+
+func NewCachedOfficeStore(officeStore OfficeStore, cache *CommerceCache) *CachedOfficeStore {
+	return &CachedOfficeStore{OfficeStore: officeStore, cache: cache}
+}
+
+func (s *CachedOfficeStore) Get(officeId string) StoreChannel {
+	return s.cache.Get(officeId, func() interface{} { return &model.Office{} }, func() StoreChannel {
+		return s.OfficeStore.Get(officeId)
+	})
+}
+
+func (s *CachedOfficeStore) Activate(officeId string) StoreChannel {
+	return s.cache.InvalidateAfter(officeId, s.OfficeStore.Activate(officeId))
+}
+
+func (s *CachedOfficeStore) Deactivate(officeId string) StoreChannel {
+	return s.cache.InvalidateAfter(officeId, s.OfficeStore.Deactivate(officeId))
+}
+
+func (s *CachedOfficeStore) Update(newOffice *model.Office) StoreChannel {
+	return s.cache.InvalidateAfter(newOffice.Id, s.OfficeStore.Update(newOffice))
+}
+
+func (s *CachedOfficeStore) Overwrite(office *model.Office) StoreChannel {
+	return s.cache.InvalidateAfter(office.Id, s.OfficeStore.Overwrite(office))
+}
+
+func (s *CachedOfficeStore) Delete(officeId string, time int64, deleteByID string) StoreChannel {
+	return s.cache.InvalidateAfter(officeId, s.OfficeStore.Delete(officeId, time, deleteByID))
+}
+
+type CachedLevelStore struct {
+	LevelStore
+	cache *CommerceCache
+}
+
+func NewCachedLevelStore(levelStore LevelStore, cache *CommerceCache) *CachedLevelStore {
+	return &CachedLevelStore{LevelStore: levelStore, cache: cache}
+}
+
+func (s *CachedLevelStore) Get(levelId string) StoreChannel {
+	return s.cache.Get(levelId, func() interface{} { return &model.Level{} }, func() StoreChannel {
+		return s.LevelStore.Get(levelId)
+	})
+}
+
+func (s *CachedLevelStore) Activate(levelId string) StoreChannel {
+	return s.cache.InvalidateAfter(levelId, s.LevelStore.Activate(levelId))
+}
+
+func (s *CachedLevelStore) Deactivate(levelId string) StoreChannel {
+	return s.cache.InvalidateAfter(levelId, s.LevelStore.Deactivate(levelId))
+}
+
+func (s *CachedLevelStore) Update(newLevel *model.Level) StoreChannel {
+	return s.cache.InvalidateAfter(newLevel.Id, s.LevelStore.Update(newLevel))
+}
+
+func (s *CachedLevelStore) Overwrite(level *model.Level) StoreChannel {
+	return s.cache.InvalidateAfter(level.Id, s.LevelStore.Overwrite(level))
+}
+
+func (s *CachedLevelStore) Delete(levelId string, time int64, deleteByID string) StoreChannel {
+	return s.cache.InvalidateAfter(levelId, s.LevelStore.Delete(levelId, time, deleteByID))
+}
+
+func (s *CachedLevelStore) DeleteApplicationLevels(appId string) StoreChannel {
+	return s.cache.PurgeAfter(s.LevelStore.DeleteApplicationLevels(appId))
+}
+
+type CachedApplicationStore struct {
+	ApplicationStore
+	cache *CommerceCache
+}
+
+func NewCachedApplicationStore(applicationStore ApplicationStore, cache *CommerceCache) *CachedApplicationStore {
+	return &CachedApplicationStore{ApplicationStore: applicationStore, cache: cache}
+}
+
+func (s *CachedApplicationStore) Get(appId string) StoreChannel {
+	return s.cache.Get(appId, func() interface{} { return &model.Application{} }, func() StoreChannel {
+		return s.ApplicationStore.Get(appId)
+	})
+}
+
+func (s *CachedApplicationStore) Activate(appId string) StoreChannel {
+	return s.cache.InvalidateAfter(appId, s.ApplicationStore.Activate(appId))
+}
+
+func (s *CachedApplicationStore) Deactivate(appId string) StoreChannel {
+	return s.cache.InvalidateAfter(appId, s.ApplicationStore.Deactivate(appId))
+}
+
+func (s *CachedApplicationStore) Update(newApplication *model.Application) StoreChannel {
+	return s.cache.InvalidateAfter(newApplication.Id, s.ApplicationStore.Update(newApplication))
+}
+
+func (s *CachedApplicationStore) Overwrite(application *model.Application) StoreChannel {
+	return s.cache.InvalidateAfter(application.Id, s.ApplicationStore.Overwrite(application))
+}
+
+func (s *CachedApplicationStore) Delete(appId string, time int64, deleteByID string) StoreChannel {
+	return s.cache.InvalidateAfter(appId, s.ApplicationStore.Delete(appId, time, deleteByID))
+}
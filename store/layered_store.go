@@ -3,15 +3,13 @@ package store
 import (
 	"context"
 
+	"github.com/go-redis/redis"
+
 	"im/einterfaces"
 	"im/mlog"
 	"im/model"
 )
 
-const (
-	ENABLE_EXPERIMENTAL_REDIS = false
-)
-
 type LayeredStoreDatabaseLayer interface {
 	LayeredStoreSupplier
 	Store
@@ -26,22 +24,47 @@ type LayeredStore struct {
 	LocalCacheLayer *LocalCacheSupplier
 	RedisLayer      *RedisSupplier
 	LayerChainHead  LayeredStoreSupplier
+
+	ProductStore     ProductStore
+	CategoryStore    CategoryStore
+	OfficeStore      OfficeStore
+	LevelStore       LevelStore
+	ApplicationStore ApplicationStore
+	CommerceCaches   []*CommerceCache
 }
 
-func NewLayeredStore(db LayeredStoreDatabaseLayer, cluster einterfaces.ClusterInterface) Store {
+// NewLayeredStore puts the caches of the settings in front of the database. Roles and schemes
+// are cached in Redis or in the process, the products, the categories, the offices, the levels
+// and the applications only when a cache type is set.
+func NewLayeredStore(db LayeredStoreDatabaseLayer, cluster einterfaces.ClusterInterface, metrics einterfaces.MetricsInterface, settings model.CacheSettings) Store {
 	store := &LayeredStore{
 		TmpContext:      context.TODO(),
 		DatabaseLayer:   db,
 		LocalCacheLayer: NewLocalCacheSupplier(cluster),
+
+		ProductStore:     db.Product(),
+		CategoryStore:    db.Category(),
+		OfficeStore:      db.Office(),
+		LevelStore:       db.Level(),
+		ApplicationStore: db.Application(),
 	}
 
 	store.RoleStore = &LayeredRoleStore{store}
 	store.SchemeStore = &LayeredSchemeStore{store}
 
+	var redisClient *redis.Client
+	if *settings.CacheType == model.CACHE_TYPE_REDIS {
+		client, err := NewRedisClient(settings)
+		if err != nil {
+			mlog.Error("Unable to connect to the redis server, falling back to the local cache", mlog.String("address", *settings.RedisAddress), mlog.Err(err))
+		} else {
+			redisClient = client
+		}
+	}
+
 	// Setup the chain
-	if ENABLE_EXPERIMENTAL_REDIS {
-		mlog.Debug("Experimental redis enabled.")
-		store.RedisLayer = NewRedisSupplier()
+	if redisClient != nil {
+		store.RedisLayer = NewRedisSupplier(redisClient)
 		store.RedisLayer.SetChainNext(store.DatabaseLayer)
 		store.LayerChainHead = store.RedisLayer
 	} else {
@@ -49,6 +72,20 @@ func NewLayeredStore(db LayeredStoreDatabaseLayer, cluster einterfaces.ClusterIn
 		store.LayerChainHead = store.LocalCacheLayer
 	}
 
+	if *settings.CacheType != "" {
+		newCache := func(name string, event string) *CommerceCache {
+			cache := NewCommerceCache(name, event, NewCommerceCacheBackend(settings, redisClient, name, event), cluster, metrics)
+			store.CommerceCaches = append(store.CommerceCaches, cache)
+			return cache
+		}
+
+		store.ProductStore = NewCachedProductStore(store.ProductStore, newCache("Product", model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_PRODUCTS))
+		store.CategoryStore = NewCachedCategoryStore(store.CategoryStore, newCache("Category", model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_CATEGORIES))
+		store.OfficeStore = NewCachedOfficeStore(store.OfficeStore, newCache("Office", model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_OFFICES))
+		store.LevelStore = NewCachedLevelStore(store.LevelStore, newCache("Level", model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_LEVELS))
+		store.ApplicationStore = NewCachedApplicationStore(store.ApplicationStore, newCache("Application", model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_APPLICATIONS))
+	}
+
 	return store
 }
 
@@ -141,13 +178,13 @@ func (s *LayeredStore) Scheme() SchemeStore {
 }
 
 func (s *LayeredStore) Product() ProductStore {
-	return s.DatabaseLayer.Product()
+	return s.ProductStore
 }
 func (s *LayeredStore) Promo() PromoStore {
 	return s.DatabaseLayer.Promo()
 }
 func (s *LayeredStore) Category() CategoryStore {
-	return s.DatabaseLayer.Category()
+	return s.CategoryStore
 }
 func (s *LayeredStore) LinkMetadata() LinkMetadataStore {
 	return s.DatabaseLayer.LinkMetadata()
@@ -157,10 +194,10 @@ func (s *LayeredStore) MarkSystemRanUnitTests() {
 	s.DatabaseLayer.MarkSystemRanUnitTests()
 }
 func (s *LayeredStore) Office() OfficeStore {
-	return s.DatabaseLayer.Office()
+	return s.OfficeStore
 }
 func (s *LayeredStore) Application() ApplicationStore {
-	return s.DatabaseLayer.Application()
+	return s.ApplicationStore
 }
 func (s *LayeredStore) Order() OrderStore {
 	return s.DatabaseLayer.Order()
@@ -172,7 +209,7 @@ func (s *LayeredStore) Transaction() TransactionStore {
 	return s.DatabaseLayer.Transaction()
 }
 func (s *LayeredStore) Level() LevelStore {
-	return s.DatabaseLayer.Level()
+	return s.LevelStore
 }
 
 func (s *LayeredStore) Extra() ExtraStore {
@@ -183,6 +220,86 @@ func (s *LayeredStore) ProductOffice() ProductOfficeStore {
 	return s.DatabaseLayer.ProductOffice()
 }
 
+func (s *LayeredStore) StaffOffice() StaffOfficeStore {
+	return s.DatabaseLayer.StaffOffice()
+}
+
+func (s *LayeredStore) CourierLocation() CourierLocationStore {
+	return s.DatabaseLayer.CourierLocation()
+}
+
+func (s *LayeredStore) Address() AddressStore {
+	return s.DatabaseLayer.Address()
+}
+
+func (s *LayeredStore) WebhookSubscription() WebhookSubscriptionStore {
+	return s.DatabaseLayer.WebhookSubscription()
+}
+
+func (s *LayeredStore) WebhookDelivery() WebhookDeliveryStore {
+	return s.DatabaseLayer.WebhookDelivery()
+}
+
+func (s *LayeredStore) PosProductMapping() PosProductMappingStore {
+	return s.DatabaseLayer.PosProductMapping()
+}
+
+func (s *LayeredStore) PosOrderSync() PosOrderSyncStore {
+	return s.DatabaseLayer.PosOrderSync()
+}
+
+func (s *LayeredStore) Campaign() CampaignStore {
+	return s.DatabaseLayer.Campaign()
+}
+
+func (s *LayeredStore) CampaignRecipient() CampaignRecipientStore {
+	return s.DatabaseLayer.CampaignRecipient()
+}
+
+func (s *LayeredStore) LifecycleTrigger() LifecycleTriggerStore {
+	return s.DatabaseLayer.LifecycleTrigger()
+}
+
+func (s *LayeredStore) LifecycleTriggerLog() LifecycleTriggerLogStore {
+	return s.DatabaseLayer.LifecycleTriggerLog()
+}
+
+func (s *LayeredStore) LoyaltyTier() LoyaltyTierStore {
+	return s.DatabaseLayer.LoyaltyTier()
+}
+
+func (s *LayeredStore) UserTier() UserTierStore {
+	return s.DatabaseLayer.UserTier()
+}
+
+func (s *LayeredStore) ReferralPayout() ReferralPayoutStore {
+	return s.DatabaseLayer.ReferralPayout()
+}
+
+func (s *LayeredStore) UserDevice() UserDeviceStore {
+	return s.DatabaseLayer.UserDevice()
+}
+
+func (s *LayeredStore) CardBinding() CardBindingStore {
+	return s.DatabaseLayer.CardBinding()
+}
+
+func (s *LayeredStore) OrderPayment() OrderPaymentStore {
+	return s.DatabaseLayer.OrderPayment()
+}
+
+func (s *LayeredStore) PrintTicket() PrintTicketStore {
+	return s.DatabaseLayer.PrintTicket()
+}
+
+func (s *LayeredStore) OrderDocument() OrderDocumentStore {
+	return s.DatabaseLayer.OrderDocument()
+}
+
+func (s *LayeredStore) ApplicationConfig() ApplicationConfigStore {
+	return s.DatabaseLayer.ApplicationConfig()
+}
+
 func (s *LayeredStore) Close() {
 	s.DatabaseLayer.Close()
 }
@@ -197,6 +314,11 @@ func (s *LayeredStore) UnlockFromMaster() {
 
 func (s *LayeredStore) DropAllTables() {
 	defer s.LocalCacheLayer.Invalidate()
+	defer func() {
+		for _, cache := range s.CommerceCaches {
+			cache.Purge()
+		}
+	}()
 	s.DatabaseLayer.DropAllTables()
 }
 
@@ -7,7 +7,8 @@ import (
 	"time"
 
 	"github.com/go-redis/redis"
-	"im/mlog"
+
+	"im/model"
 )
 
 const REDIS_EXPIRY_TIME = 30 * time.Minute
@@ -32,21 +33,26 @@ func DecodeBytes(input []byte, thing interface{}) error {
 	return dec.Decode(thing)
 }
 
-func NewRedisSupplier() *RedisSupplier {
-	supplier := &RedisSupplier{}
-
-	supplier.client = redis.NewClient(&redis.Options{
-		Addr:     "localhost:6379",
-		Password: "",
-		DB:       0,
+// NewRedisClient connects to the Redis server of the cache settings.
+func NewRedisClient(settings model.CacheSettings) (*redis.Client, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     *settings.RedisAddress,
+		Password: *settings.RedisPassword,
+		DB:       *settings.RedisDB,
 	})
 
-	if _, err := supplier.client.Ping().Result(); err != nil {
-		mlog.Error("Unable to ping redis server: " + err.Error())
-		return nil
+	if _, err := client.Ping().Result(); err != nil {
+		client.Close()
+		return nil, err
 	}
 
-	return supplier
+	return client, nil
+}
+
+func NewRedisSupplier(client *redis.Client) *RedisSupplier {
+	return &RedisSupplier{
+		client: client,
+	}
 }
 
 func (s *RedisSupplier) save(key string, value interface{}, expiry time.Duration) error {
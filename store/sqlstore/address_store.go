@@ -0,0 +1,119 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+
+	"im/model"
+	"im/store"
+)
+
+type SqlAddressStore struct {
+	SqlStore
+}
+
+func NewSqlAddressStore(sqlStore SqlStore) store.AddressStore {
+	s := &SqlAddressStore{
+		SqlStore: sqlStore,
+	}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.Address{}, "Addresses").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("Label").SetMaxSize(model.ADDRESS_LABEL_MAX_RUNES)
+		table.ColMap("City").SetMaxSize(model.ADDRESS_FIELD_MAX_RUNES)
+		table.ColMap("Street").SetMaxSize(model.ADDRESS_FIELD_MAX_RUNES)
+		table.ColMap("House").SetMaxSize(model.ADDRESS_FIELD_MAX_RUNES)
+		table.ColMap("Apartment").SetMaxSize(model.ADDRESS_FIELD_MAX_RUNES)
+		table.ColMap("Entrance").SetMaxSize(model.ADDRESS_FIELD_MAX_RUNES)
+		table.ColMap("Floor").SetMaxSize(model.ADDRESS_FIELD_MAX_RUNES)
+		table.ColMap("Intercom").SetMaxSize(model.ADDRESS_FIELD_MAX_RUNES)
+		table.ColMap("Phone").SetMaxSize(model.ADDRESS_FIELD_MAX_RUNES)
+		table.ColMap("Comment").SetMaxSize(model.ADDRESS_COMMENT_MAX_RUNES)
+	}
+
+	return s
+}
+
+func (s SqlAddressStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_addresses_user_id", "Addresses", "UserId")
+	s.CreateIndexIfNotExists("idx_addresses_delete_at", "Addresses", "DeleteAt")
+}
+
+func (s SqlAddressStore) Save(address *model.Address) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if len(address.Id) > 0 {
+			result.Err = model.NewAppError("SqlAddressStore.Save", "store.sql_address.save.existing.app_error", nil, "id="+address.Id, http.StatusBadRequest)
+			return
+		}
+
+		address.PreSave()
+
+		if result.Err = address.IsValid(); result.Err != nil {
+			return
+		}
+
+		if err := s.GetMaster().Insert(address); err != nil {
+			result.Err = model.NewAppError("SqlAddressStore.Save", "store.sql_address.save.app_error", nil, "id="+address.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = address
+		}
+	})
+}
+
+func (s SqlAddressStore) Update(address *model.Address) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		address.PreUpdate()
+
+		if result.Err = address.IsValid(); result.Err != nil {
+			return
+		}
+
+		if _, err := s.GetMaster().Update(address); err != nil {
+			result.Err = model.NewAppError("SqlAddressStore.Update", "store.sql_address.update.app_error", nil, "id="+address.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = address
+		}
+	})
+}
+
+func (s SqlAddressStore) Get(addressId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var address *model.Address
+		if err := s.GetReplica().SelectOne(&address,
+			`SELECT * FROM Addresses WHERE Id = :Id AND DeleteAt = 0`, map[string]interface{}{"Id": addressId}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlAddressStore.Get", "store.sql_address.get.app_error", nil, "id="+addressId+", "+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlAddressStore.Get", "store.sql_address.get.app_error", nil, "id="+addressId+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = address
+		}
+	})
+}
+
+func (s SqlAddressStore) GetByUserId(userId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var addresses []*model.Address
+		if _, err := s.GetReplica().Select(&addresses,
+			`SELECT * FROM Addresses WHERE UserId = :UserId AND DeleteAt = 0 ORDER BY CreateAt ASC`,
+			map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlAddressStore.GetByUserId", "store.sql_address.get_by_user_id.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = addresses
+		}
+	})
+}
+
+func (s SqlAddressStore) Delete(addressId string, time int64) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec(`UPDATE Addresses SET DeleteAt = :DeleteAt, UpdateAt = :UpdateAt WHERE Id = :Id AND DeleteAt = 0`,
+			map[string]interface{}{"DeleteAt": time, "UpdateAt": time, "Id": addressId}); err != nil {
+			result.Err = model.NewAppError("SqlAddressStore.Delete", "store.sql_address.delete.app_error", nil, "id="+addressId+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = addressId
+		}
+	})
+}
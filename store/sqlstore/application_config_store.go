@@ -0,0 +1,122 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"im/model"
+	"im/store"
+)
+
+type SqlApplicationConfigStore struct {
+	SqlStore
+}
+
+func NewSqlApplicationConfigStore(sqlStore SqlStore) store.ApplicationConfigStore {
+	s := &SqlApplicationConfigStore{
+		SqlStore: sqlStore,
+	}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.ApplicationConfigVersion{}, "ApplicationConfigs").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("AppId").SetMaxSize(26)
+		table.ColMap("Config").SetMaxSize(65535)
+		table.ColMap("CreatorId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlApplicationConfigStore) CreateIndexesIfNotExists() {
+	s.CreateUniqueIndexIfNotExists("idx_application_configs_app_id_version", "ApplicationConfigs", "AppId, Version")
+}
+
+// Save stores the configuration as the next version for the application, the unique index
+// turns away a version saved concurrently with the same number.
+func (s SqlApplicationConfigStore) Save(version *model.ApplicationConfigVersion) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		version.PreSave()
+
+		transaction, err := s.GetMaster().Begin()
+		if err != nil {
+			result.Err = model.NewAppError("SqlApplicationConfigStore.Save", "store.sql_application_config.save.open_transaction.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer finalizeTransaction(transaction)
+
+		latest, err := transaction.SelectInt("SELECT COALESCE(MAX(Version), 0) FROM ApplicationConfigs WHERE AppId = :AppId", map[string]interface{}{"AppId": version.AppId})
+		if err != nil {
+			result.Err = model.NewAppError("SqlApplicationConfigStore.Save", "store.sql_application_config.save.app_error", nil, "app_id="+version.AppId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		version.Version = latest + 1
+
+		if result.Err = version.IsValid(); result.Err != nil {
+			return
+		}
+
+		if err := transaction.Insert(version); err != nil {
+			result.Err = model.NewAppError("SqlApplicationConfigStore.Save", "store.sql_application_config.save.app_error", nil, "app_id="+version.AppId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err := transaction.Commit(); err != nil {
+			result.Err = model.NewAppError("SqlApplicationConfigStore.Save", "store.sql_application_config.save.commit_transaction.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = version
+	})
+}
+
+func (s SqlApplicationConfigStore) GetLatest(appId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var version model.ApplicationConfigVersion
+		if err := s.GetMaster().SelectOne(&version,
+			`SELECT * FROM ApplicationConfigs WHERE AppId = :AppId ORDER BY Version DESC LIMIT 1`,
+			map[string]interface{}{"AppId": appId}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlApplicationConfigStore.GetLatest", "store.sql_application_config.get_latest.app_error", nil, "app_id="+appId, http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlApplicationConfigStore.GetLatest", "store.sql_application_config.get_latest.app_error", nil, "app_id="+appId+", "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		result.Data = &version
+	})
+}
+
+func (s SqlApplicationConfigStore) GetVersion(appId string, version int64) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var configVersion model.ApplicationConfigVersion
+		if err := s.GetReplica().SelectOne(&configVersion,
+			`SELECT * FROM ApplicationConfigs WHERE AppId = :AppId AND Version = :Version`,
+			map[string]interface{}{"AppId": appId, "Version": version}); err != nil {
+			details := "app_id=" + appId + ", version=" + strconv.FormatInt(version, 10)
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlApplicationConfigStore.GetVersion", "store.sql_application_config.get_version.app_error", nil, details, http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlApplicationConfigStore.GetVersion", "store.sql_application_config.get_version.app_error", nil, details+", "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		result.Data = &configVersion
+	})
+}
+
+func (s SqlApplicationConfigStore) GetHistory(appId string, offset int, limit int) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var versions []*model.ApplicationConfigVersion
+		if _, err := s.GetReplica().Select(&versions,
+			`SELECT * FROM ApplicationConfigs WHERE AppId = :AppId ORDER BY Version DESC LIMIT :Limit OFFSET :Offset`,
+			map[string]interface{}{"AppId": appId, "Limit": limit, "Offset": offset}); err != nil {
+			result.Err = model.NewAppError("SqlApplicationConfigStore.GetHistory", "store.sql_application_config.get_history.app_error", nil, "app_id="+appId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = versions
+		}
+	})
+}
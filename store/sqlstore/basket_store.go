@@ -18,6 +18,8 @@ func NewSqlBasketStore(sqlStore SqlStore) store.BasketStore {
 		table := db.AddTableWithName(model.Basket{}, "Baskets").SetKeys(false, "Id")
 
 		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("Modifiers").SetMaxSize(2000)
+		table.ColMap("Comment").SetMaxSize(500)
 
 	}
 
@@ -100,6 +102,27 @@ func (s *SqlBasketStore) GetByOrderId(orderId string) store.StoreChannel {
 	})
 }
 
+func (s *SqlBasketStore) GetByOrderIds(orderIds []string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		baskets := []*model.Basket{}
+		if len(orderIds) == 0 {
+			result.Data = baskets
+			return
+		}
+
+		keys, params := MapStringsToQueryParams(orderIds, "Order")
+
+		if _, err := s.GetReplica().Select(&baskets,
+			`SELECT *
+					FROM Baskets
+					WHERE OrderId IN `+keys+` AND DeleteAt = 0`, params); err != nil {
+			result.Err = model.NewAppError("SqlBasketStore.GetByOrderIds", "store.sql_baskets.get_by_order_ids.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = baskets
+		}
+	})
+}
+
 func (s *SqlBasketStore) Overwrite(basket *model.Basket) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
 		basket.UpdateAt = model.GetMillis()
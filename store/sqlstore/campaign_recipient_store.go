@@ -0,0 +1,176 @@
+package sqlstore
+
+import (
+	"net/http"
+
+	"im/model"
+	"im/store"
+)
+
+type SqlCampaignRecipientStore struct {
+	SqlStore
+}
+
+func NewSqlCampaignRecipientStore(sqlStore SqlStore) store.CampaignRecipientStore {
+	s := &SqlCampaignRecipientStore{
+		SqlStore: sqlStore,
+	}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.CampaignRecipient{}, "CampaignRecipients").SetKeys(false, "CampaignId", "UserId")
+		table.ColMap("CampaignId").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("Status").SetMaxSize(32)
+		table.ColMap("Error").SetMaxSize(model.CAMPAIGN_ERROR_MAX_SIZE)
+	}
+
+	return s
+}
+
+func (s SqlCampaignRecipientStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_campaign_recipients_campaign_id_status", "CampaignRecipients", "CampaignId, Status")
+	s.CreateIndexIfNotExists("idx_campaign_recipients_user_id", "CampaignRecipients", "UserId")
+}
+
+func (s SqlCampaignRecipientStore) SaveMultiple(recipients []*model.CampaignRecipient) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		transaction, err := s.GetMaster().Begin()
+		if err != nil {
+			result.Err = model.NewAppError("SqlCampaignRecipientStore.SaveMultiple", "store.sql_campaign_recipient.save_multiple.open_transaction.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer finalizeTransaction(transaction)
+
+		for _, recipient := range recipients {
+			if recipient.CreateAt == 0 {
+				recipient.CreateAt = model.GetMillis()
+			}
+			if len(recipient.Status) == 0 {
+				recipient.Status = model.CAMPAIGN_RECIPIENT_STATUS_PENDING
+			}
+
+			if err := transaction.Insert(recipient); err != nil {
+				result.Err = model.NewAppError("SqlCampaignRecipientStore.SaveMultiple", "store.sql_campaign_recipient.save_multiple.app_error", nil, "campaign_id="+recipient.CampaignId+", user_id="+recipient.UserId+", "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if err := transaction.Commit(); err != nil {
+			result.Err = model.NewAppError("SqlCampaignRecipientStore.SaveMultiple", "store.sql_campaign_recipient.save_multiple.commit_transaction.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = recipients
+	})
+}
+
+func (s SqlCampaignRecipientStore) Update(recipient *model.CampaignRecipient) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Update(recipient); err != nil {
+			result.Err = model.NewAppError("SqlCampaignRecipientStore.Update", "store.sql_campaign_recipient.update.app_error", nil, "campaign_id="+recipient.CampaignId+", user_id="+recipient.UserId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = recipient
+		}
+	})
+}
+
+func (s SqlCampaignRecipientStore) GetPending(campaignId string, limit int) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		// read from the master, the sender updates the recipients right before asking for the next batch
+		var recipients []*model.CampaignRecipient
+		if _, err := s.GetMaster().Select(&recipients,
+			`SELECT * FROM CampaignRecipients WHERE CampaignId = :CampaignId AND Status = :Status ORDER BY UserId ASC LIMIT :Limit`,
+			map[string]interface{}{"CampaignId": campaignId, "Status": model.CAMPAIGN_RECIPIENT_STATUS_PENDING, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlCampaignRecipientStore.GetPending", "store.sql_campaign_recipient.get_pending.app_error", nil, "campaign_id="+campaignId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = recipients
+		}
+	})
+}
+
+func (s SqlCampaignRecipientStore) GetForCampaign(campaignId string, status string, offset int, limit int) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		query := s.getQueryBuilder().
+			Select("*").
+			From("CampaignRecipients").
+			Where("CampaignId = ?", campaignId).
+			OrderBy("UserId ASC").
+			Offset(uint64(offset)).
+			Limit(uint64(limit))
+
+		if len(status) > 0 {
+			query = query.Where("Status = ?", status)
+		}
+
+		queryString, args, err := query.ToSql()
+		if err != nil {
+			result.Err = model.NewAppError("SqlCampaignRecipientStore.GetForCampaign", "store.sql_campaign_recipient.get_for_campaign.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var recipients []*model.CampaignRecipient
+		if _, err := s.GetReplica().Select(&recipients, queryString, args...); err != nil {
+			result.Err = model.NewAppError("SqlCampaignRecipientStore.GetForCampaign", "store.sql_campaign_recipient.get_for_campaign.app_error", nil, "campaign_id="+campaignId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = recipients
+	})
+}
+
+func (s SqlCampaignRecipientStore) GetStats(campaignId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var rows []struct {
+			Status string
+			Count  int64
+			Opened int64
+		}
+
+		if _, err := s.GetReplica().Select(&rows,
+			`SELECT Status, COUNT(*) AS Count, SUM(CASE WHEN OpenedAt > 0 THEN 1 ELSE 0 END) AS Opened
+			FROM CampaignRecipients WHERE CampaignId = :CampaignId GROUP BY Status`,
+			map[string]interface{}{"CampaignId": campaignId}); err != nil {
+			result.Err = model.NewAppError("SqlCampaignRecipientStore.GetStats", "store.sql_campaign_recipient.get_stats.app_error", nil, "campaign_id="+campaignId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		stats := &model.CampaignStats{}
+		for _, row := range rows {
+			stats.Total += row.Count
+			stats.Opened += row.Opened
+
+			switch row.Status {
+			case model.CAMPAIGN_RECIPIENT_STATUS_PENDING:
+				stats.Pending = row.Count
+			case model.CAMPAIGN_RECIPIENT_STATUS_SENT:
+				stats.Sent = row.Count
+			case model.CAMPAIGN_RECIPIENT_STATUS_FAILED:
+				stats.Failed = row.Count
+			case model.CAMPAIGN_RECIPIENT_STATUS_SKIPPED:
+				stats.Skipped = row.Count
+			}
+		}
+
+		result.Data = stats
+	})
+}
+
+// SetOpened records the first time the customer opened the campaign push, later opens are ignored.
+func (s SqlCampaignRecipientStore) SetOpened(campaignId string, userId string, time int64) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec(`UPDATE CampaignRecipients SET OpenedAt = :OpenedAt
+			WHERE CampaignId = :CampaignId AND UserId = :UserId AND Status = :Status AND OpenedAt = 0`,
+			map[string]interface{}{"OpenedAt": time, "CampaignId": campaignId, "UserId": userId, "Status": model.CAMPAIGN_RECIPIENT_STATUS_SENT}); err != nil {
+			result.Err = model.NewAppError("SqlCampaignRecipientStore.SetOpened", "store.sql_campaign_recipient.set_opened.app_error", nil, "campaign_id="+campaignId+", user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+func (s SqlCampaignRecipientStore) DeleteForCampaign(campaignId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec(`DELETE FROM CampaignRecipients WHERE CampaignId = :CampaignId`,
+			map[string]interface{}{"CampaignId": campaignId}); err != nil {
+			result.Err = model.NewAppError("SqlCampaignRecipientStore.DeleteForCampaign", "store.sql_campaign_recipient.delete_for_campaign.app_error", nil, "campaign_id="+campaignId+", "+err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
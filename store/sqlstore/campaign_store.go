@@ -0,0 +1,251 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"im/model"
+	"im/store"
+)
+
+type SqlCampaignStore struct {
+	SqlStore
+}
+
+func NewSqlCampaignStore(sqlStore SqlStore) store.CampaignStore {
+	s := &SqlCampaignStore{
+		SqlStore: sqlStore,
+	}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.Campaign{}, "Campaigns").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("AppId").SetMaxSize(26)
+		table.ColMap("CreatorId").SetMaxSize(26)
+		table.ColMap("PromoId").SetMaxSize(26)
+		table.ColMap("Name").SetMaxSize(model.CAMPAIGN_NAME_MAX_RUNES)
+		table.ColMap("Message").SetMaxSize(model.CAMPAIGN_MESSAGE_MAX_RUNES)
+		table.ColMap("Segment").SetMaxSize(4000)
+		table.ColMap("Status").SetMaxSize(32)
+	}
+
+	return s
+}
+
+func (s SqlCampaignStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_campaigns_app_id", "Campaigns", "AppId")
+	s.CreateIndexIfNotExists("idx_campaigns_status_send_at", "Campaigns", "Status, SendAt")
+}
+
+func (s SqlCampaignStore) Save(campaign *model.Campaign) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if len(campaign.Id) > 0 {
+			result.Err = model.NewAppError("SqlCampaignStore.Save", "store.sql_campaign.save.existing.app_error", nil, "id="+campaign.Id, http.StatusBadRequest)
+			return
+		}
+
+		campaign.PreSave()
+
+		if result.Err = campaign.IsValid(); result.Err != nil {
+			return
+		}
+
+		if err := s.GetMaster().Insert(campaign); err != nil {
+			result.Err = model.NewAppError("SqlCampaignStore.Save", "store.sql_campaign.save.app_error", nil, "id="+campaign.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = campaign
+		}
+	})
+}
+
+func (s SqlCampaignStore) Update(campaign *model.Campaign) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		campaign.PreUpdate()
+
+		if result.Err = campaign.IsValid(); result.Err != nil {
+			return
+		}
+
+		if _, err := s.GetMaster().Update(campaign); err != nil {
+			result.Err = model.NewAppError("SqlCampaignStore.Update", "store.sql_campaign.update.app_error", nil, "id="+campaign.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = campaign
+		}
+	})
+}
+
+func (s SqlCampaignStore) Get(id string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var campaign *model.Campaign
+		if err := s.GetReplica().SelectOne(&campaign,
+			`SELECT * FROM Campaigns WHERE Id = :Id AND DeleteAt = 0`, map[string]interface{}{"Id": id}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlCampaignStore.Get", "store.sql_campaign.get.app_error", nil, "id="+id+", "+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlCampaignStore.Get", "store.sql_campaign.get.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = campaign
+		}
+	})
+}
+
+func (s SqlCampaignStore) GetForApp(appId string, offset int, limit int) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var campaigns []*model.Campaign
+		if _, err := s.GetReplica().Select(&campaigns,
+			`SELECT * FROM Campaigns WHERE AppId = :AppId AND DeleteAt = 0 ORDER BY CreateAt DESC LIMIT :Limit OFFSET :Offset`,
+			map[string]interface{}{"AppId": appId, "Limit": limit, "Offset": offset}); err != nil {
+			result.Err = model.NewAppError("SqlCampaignStore.GetForApp", "store.sql_campaign.get_for_app.app_error", nil, "app_id="+appId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = campaigns
+		}
+	})
+}
+
+// GetDue returns the scheduled campaigns whose send time has come together with the ones
+// left in the sending state, e.g. by a worker that stopped in the middle of a campaign.
+func (s SqlCampaignStore) GetDue(time int64) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var campaigns []*model.Campaign
+		if _, err := s.GetReplica().Select(&campaigns,
+			`SELECT * FROM Campaigns
+			WHERE DeleteAt = 0
+				AND ((Status = :Scheduled AND SendAt <= :Time) OR Status = :Sending)
+			ORDER BY SendAt ASC`,
+			map[string]interface{}{"Scheduled": model.CAMPAIGN_STATUS_SCHEDULED, "Sending": model.CAMPAIGN_STATUS_SENDING, "Time": time}); err != nil {
+			result.Err = model.NewAppError("SqlCampaignStore.GetDue", "store.sql_campaign.get_due.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = campaigns
+		}
+	})
+}
+
+func (s SqlCampaignStore) Delete(id string, time int64) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec(`UPDATE Campaigns SET DeleteAt = :DeleteAt, UpdateAt = :UpdateAt WHERE Id = :Id AND DeleteAt = 0`,
+			map[string]interface{}{"DeleteAt": time, "UpdateAt": time, "Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlCampaignStore.Delete", "store.sql_campaign.delete.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = id
+		}
+	})
+}
+
+// segmentQuery selects the customers of the application matching the segment. The order
+// figures come from a grouped subquery, so customers without orders have no row there.
+func (s SqlCampaignStore) segmentQuery(columns string, appId string, segment *model.CampaignSegment) sq.SelectBuilder {
+	isPostgreSQL := s.DriverName() == model.DATABASE_DRIVER_POSTGRES
+
+	query := s.getQueryBuilder().
+		Select(columns).
+		From("Users u").
+		LeftJoin(`(SELECT UserId, COUNT(*) AS OrderCount, SUM(Price) AS OrderSum, MAX(CreateAt) AS LastOrderAt
+			FROM Orders WHERE DeleteAt = 0 AND Canceled = ? GROUP BY UserId) o ON o.UserId = u.Id`, false).
+		Where("u.AppId = ?", appId).
+		Where("u.DeleteAt = 0").
+		Where("u.BlockedAt = 0")
+
+	query = applyRoleFilter(query, model.CHANNEL_USER_ROLE_ID, isPostgreSQL)
+
+	if segment == nil {
+		return query
+	}
+
+	if segment.LastOrderAfter > 0 {
+		query = query.Where("o.LastOrderAt >= ?", segment.LastOrderAfter)
+	}
+	if segment.LastOrderBefore > 0 {
+		// customers who have never ordered are kept as well, they are the ones a win-back is for
+		query = query.Where("COALESCE(o.LastOrderAt, 0) < ?", segment.LastOrderBefore)
+	}
+	if segment.MinOrderCount != nil {
+		query = query.Where("COALESCE(o.OrderCount, 0) >= ?", *segment.MinOrderCount)
+	}
+	if segment.MaxOrderCount != nil {
+		query = query.Where("COALESCE(o.OrderCount, 0) <= ?", *segment.MaxOrderCount)
+	}
+	if segment.MinOrderSum != nil {
+		query = query.Where("COALESCE(o.OrderSum, 0) >= ?", *segment.MinOrderSum)
+	}
+	if segment.MaxOrderSum != nil {
+		query = query.Where("COALESCE(o.OrderSum, 0) <= ?", *segment.MaxOrderSum)
+	}
+	if segment.MinBalance != nil {
+		query = query.Where("u.Balance >= ?", *segment.MinBalance)
+	}
+	if segment.MaxBalance != nil {
+		query = query.Where("u.Balance <= ?", *segment.MaxBalance)
+	}
+
+	if len(segment.OfficeIds) > 0 {
+		subQuery, subArgs, _ := sq.Select("s.UserId").From("Sessions s").Where(sq.Eq{"s.OfficeId": segment.OfficeIds}).ToSql()
+		query = query.Where("u.Id IN ("+subQuery+")", subArgs...)
+	}
+
+	if segment.Referred != nil {
+		if *segment.Referred {
+			query = query.Where("u.InvitedBy != ''")
+		} else {
+			query = query.Where("u.InvitedBy = ''")
+		}
+	}
+	if segment.MinReferrals != nil {
+		query = query.Where("(SELECT COUNT(*) FROM Users r WHERE r.InvitedBy = u.Id AND r.DeleteAt = 0) >= ?", *segment.MinReferrals)
+	}
+
+	if segment.AppInstalled != nil {
+		devices := "EXISTS (SELECT 1 FROM Sessions s WHERE s.UserId = u.Id AND s.DeviceId != '' AND (s.ExpiresAt = 0 OR s.ExpiresAt > ?))"
+		if !*segment.AppInstalled {
+			devices = "NOT " + devices
+		}
+		query = query.Where(devices, model.GetMillis())
+	}
+
+	return query
+}
+
+// GetSegmentUserIds pages through the customers of the segment ordered by id, so that a new
+// customer matching the segment does not shift the pages already read.
+func (s SqlCampaignStore) GetSegmentUserIds(appId string, segment *model.CampaignSegment, afterId string, limit int) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		query := s.segmentQuery("u.Id", appId, segment).
+			Where("u.Id > ?", afterId).
+			OrderBy("u.Id ASC").
+			Limit(uint64(limit))
+
+		queryString, args, err := query.ToSql()
+		if err != nil {
+			result.Err = model.NewAppError("SqlCampaignStore.GetSegmentUserIds", "store.sql_campaign.get_segment_user_ids.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var userIds []string
+		if _, err := s.GetReplica().Select(&userIds, queryString, args...); err != nil {
+			result.Err = model.NewAppError("SqlCampaignStore.GetSegmentUserIds", "store.sql_campaign.get_segment_user_ids.app_error", nil, "app_id="+appId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = userIds
+	})
+}
+
+func (s SqlCampaignStore) CountSegment(appId string, segment *model.CampaignSegment) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		queryString, args, err := s.segmentQuery("COUNT(*)", appId, segment).ToSql()
+		if err != nil {
+			result.Err = model.NewAppError("SqlCampaignStore.CountSegment", "store.sql_campaign.count_segment.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		count, err := s.GetReplica().SelectInt(queryString, args...)
+		if err != nil {
+			result.Err = model.NewAppError("SqlCampaignStore.CountSegment", "store.sql_campaign.count_segment.app_error", nil, "app_id="+appId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = count
+	})
+}
@@ -0,0 +1,124 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+
+	"im/model"
+	"im/store"
+)
+
+type SqlCardBindingStore struct {
+	SqlStore
+}
+
+func NewSqlCardBindingStore(sqlStore SqlStore) store.CardBindingStore {
+	s := &SqlCardBindingStore{
+		SqlStore: sqlStore,
+	}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.CardBinding{}, "CardBindings").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("PaySystemId").SetMaxSize(32)
+		table.ColMap("BindingId").SetMaxSize(64)
+		table.ColMap("MaskedPan").SetMaxSize(32)
+		table.ColMap("ExpiryDate").SetMaxSize(6)
+		table.SetUniqueTogether("PaySystemId", "BindingId")
+	}
+
+	return s
+}
+
+func (s SqlCardBindingStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_card_bindings_user_id", "CardBindings", "UserId")
+}
+
+// Save stores the binding the acquirer reported, updating the card when the binding is known
+// and bringing it back when it was deleted.
+func (s SqlCardBindingStore) Save(binding *model.CardBinding) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var existing *model.CardBinding
+		err := s.GetMaster().SelectOne(&existing, `SELECT * FROM CardBindings WHERE PaySystemId = :PaySystemId AND BindingId = :BindingId`,
+			map[string]interface{}{"PaySystemId": binding.PaySystemId, "BindingId": binding.BindingId})
+		if err != nil && err != sql.ErrNoRows {
+			result.Err = model.NewAppError("SqlCardBindingStore.Save", "store.sql_card_binding.save.get.app_error", nil, "user_id="+binding.UserId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err == sql.ErrNoRows {
+			binding.PreSave()
+
+			if result.Err = binding.IsValid(); result.Err != nil {
+				return
+			}
+
+			if err := s.GetMaster().Insert(binding); err != nil {
+				result.Err = model.NewAppError("SqlCardBindingStore.Save", "store.sql_card_binding.save.insert.app_error", nil, "user_id="+binding.UserId+", "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			result.Data = binding
+			return
+		}
+
+		existing.UserId = binding.UserId
+		existing.MaskedPan = binding.MaskedPan
+		existing.ExpiryDate = binding.ExpiryDate
+		existing.VerifiedAt = binding.VerifiedAt
+		existing.UpdateAt = model.GetMillis()
+		existing.DeleteAt = 0
+
+		if result.Err = existing.IsValid(); result.Err != nil {
+			return
+		}
+
+		if _, err := s.GetMaster().Update(existing); err != nil {
+			result.Err = model.NewAppError("SqlCardBindingStore.Save", "store.sql_card_binding.save.update.app_error", nil, "id="+existing.Id+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = existing
+	})
+}
+
+func (s SqlCardBindingStore) Get(id string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var binding *model.CardBinding
+		if err := s.GetReplica().SelectOne(&binding,
+			`SELECT * FROM CardBindings WHERE Id = :Id AND DeleteAt = 0`, map[string]interface{}{"Id": id}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlCardBindingStore.Get", "store.sql_card_binding.get.app_error", nil, "id="+id+", "+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlCardBindingStore.Get", "store.sql_card_binding.get.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = binding
+		}
+	})
+}
+
+func (s SqlCardBindingStore) GetForUser(userId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var bindings []*model.CardBinding
+		if _, err := s.GetReplica().Select(&bindings,
+			`SELECT * FROM CardBindings WHERE UserId = :UserId AND DeleteAt = 0 ORDER BY CreateAt ASC`,
+			map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlCardBindingStore.GetForUser", "store.sql_card_binding.get_for_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = bindings
+		}
+	})
+}
+
+func (s SqlCardBindingStore) Delete(id string, time int64) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec(`UPDATE CardBindings SET DeleteAt = :DeleteAt, UpdateAt = :UpdateAt WHERE Id = :Id AND DeleteAt = 0`,
+			map[string]interface{}{"DeleteAt": time, "UpdateAt": time, "Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlCardBindingStore.Delete", "store.sql_card_binding.delete.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = id
+		}
+	})
+}
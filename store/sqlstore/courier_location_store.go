@@ -0,0 +1,95 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+
+	"im/model"
+	"im/store"
+)
+
+type SqlCourierLocationStore struct {
+	SqlStore
+}
+
+func NewSqlCourierLocationStore(sqlStore SqlStore) store.CourierLocationStore {
+	s := &SqlCourierLocationStore{
+		SqlStore: sqlStore,
+	}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.CourierLocation{}, "CourierLocations").SetKeys(false, "UserId")
+		table.ColMap("UserId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlCourierLocationStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_courier_locations_update_at", "CourierLocations", "UpdateAt")
+}
+
+// Save keeps only the last known position of the courier, so it updates the existing row when there is one.
+func (s SqlCourierLocationStore) Save(location *model.CourierLocation) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		location.PreSave()
+		if result.Err = location.IsValid(); result.Err != nil {
+			return
+		}
+
+		count, err := s.GetMaster().Update(location)
+		if err != nil {
+			result.Err = model.NewAppError("SqlCourierLocationStore.Save", "store.sql_courier_location.save.update.app_error", nil, "user_id="+location.UserId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if count == 0 {
+			if err := s.GetMaster().Insert(location); err != nil {
+				result.Err = model.NewAppError("SqlCourierLocationStore.Save", "store.sql_courier_location.save.insert.app_error", nil, "user_id="+location.UserId+", "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		result.Data = location
+	})
+}
+
+func (s SqlCourierLocationStore) Get(userId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var location model.CourierLocation
+
+		if err := s.GetReplica().SelectOne(&location,
+			`SELECT * FROM CourierLocations WHERE UserId = :UserId`,
+			map[string]interface{}{"UserId": userId}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlCourierLocationStore.Get", "store.sql_courier_location.get.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlCourierLocationStore.Get", "store.sql_courier_location.get.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		result.Data = &location
+	})
+}
+
+func (s SqlCourierLocationStore) GetForUsers(userIds []string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var locations []*model.CourierLocation
+
+		if len(userIds) == 0 {
+			result.Data = locations
+			return
+		}
+
+		keys, params := MapStringsToQueryParams(userIds, "UserId")
+
+		if _, err := s.GetReplica().Select(&locations,
+			`SELECT * FROM CourierLocations WHERE UserId IN `+keys, params); err != nil {
+			result.Err = model.NewAppError("SqlCourierLocationStore.GetForUsers",
+				"store.sql_courier_location.get_for_users.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = locations
+		}
+	})
+}
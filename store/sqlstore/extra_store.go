@@ -314,6 +314,60 @@ func (s SqlExtraStore) GetExtraProductsByIds(productIds []string, allowFromCache
 	})
 }
 
+// GetExtraProductsForProducts returns the extra products of every product, keyed by the product
+// id, the required ones first.
+func (s SqlExtraStore) GetExtraProductsForProducts(productIds []string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		extrasByProduct := make(map[string][]*model.Product, len(productIds))
+		if len(productIds) == 0 {
+			result.Data = extrasByProduct
+			return
+		}
+
+		keys, params := MapStringsToQueryParams(productIds, "Ref")
+
+		var extras []*model.Extra
+		if _, err := s.GetReplica().Select(&extras,
+			`SELECT * FROM Extras WHERE RefId IN `+keys+` AND DeleteAt = 0 ORDER BY Required DESC`, params); err != nil {
+			result.Err = model.NewAppError("SqlExtraStore.GetExtraProductsForProducts", "store.sql_extra.get_extra_products_for_products.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if len(extras) == 0 {
+			result.Data = extrasByProduct
+			return
+		}
+
+		extraIds := make([]string, 0, len(extras))
+		for _, extra := range extras {
+			extraIds = append(extraIds, extra.ProductId)
+		}
+
+		extraKeys, extraParams := MapStringsToQueryParams(extraIds, "Product")
+
+		var products []*model.Product
+		if _, err := s.GetReplica().Select(&products, `SELECT * FROM Products WHERE Id IN `+extraKeys+` AND DeleteAt = 0`, extraParams); err != nil {
+			result.Err = model.NewAppError("SqlExtraStore.GetExtraProductsForProducts", "store.sql_extra.get_extra_products_for_products.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		productsById := make(map[string]*model.Product, len(products))
+		for _, product := range products {
+			productsById[product.Id] = product
+		}
+
+		for _, extra := range extras {
+			if product, ok := productsById[extra.ProductId]; ok {
+				product = product.Clone()
+				product.Required = extra.Required
+				extrasByProduct[extra.RefId] = append(extrasByProduct[extra.RefId], product)
+			}
+		}
+
+		result.Data = extrasByProduct
+	})
+}
+
 func (s SqlExtraStore) DeleteForProduct(productId string) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
 		query := s.getQueryBuilder().
@@ -238,6 +238,34 @@ func (fs SqlFileInfoStore) GetForMetadata(metadataId string, readFromMaster bool
 	})
 }
 
+func (fs SqlFileInfoStore) GetForMetadataIds(metadataIds []string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		infos := []*model.FileInfo{}
+		if len(metadataIds) == 0 {
+			result.Data = infos
+			return
+		}
+
+		keys, params := MapStringsToQueryParams(metadataIds, "Metadata")
+
+		if _, err := fs.GetReplica().Select(&infos,
+			`SELECT
+				*
+			FROM
+				FileInfo
+			WHERE
+				MetadataId IN `+keys+`
+				AND DeleteAt = 0
+			ORDER BY
+				CreateAt`, params); err != nil {
+			result.Err = model.NewAppError("SqlFileInfoStore.GetForMetadataIds",
+				"store.sql_file_info.get_for_metadata_ids.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = infos
+		}
+	})
+}
+
 func (fs SqlFileInfoStore) GetForUser(userId string) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
 		var infos []*model.FileInfo
@@ -0,0 +1,76 @@
+package sqlstore
+
+import (
+	"net/http"
+
+	"im/model"
+	"im/store"
+)
+
+type SqlLifecycleTriggerLogStore struct {
+	SqlStore
+}
+
+func NewSqlLifecycleTriggerLogStore(sqlStore SqlStore) store.LifecycleTriggerLogStore {
+	s := &SqlLifecycleTriggerLogStore{
+		SqlStore: sqlStore,
+	}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.LifecycleTriggerLog{}, "LifecycleTriggerLogs").SetKeys(false, "TriggerId", "UserId", "SubjectId")
+		table.ColMap("TriggerId").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("SubjectId").SetMaxSize(26)
+		table.ColMap("Error").SetMaxSize(model.LIFECYCLE_TRIGGER_ERROR_MAX_SIZE)
+	}
+
+	return s
+}
+
+func (s SqlLifecycleTriggerLogStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_lifecycle_trigger_logs_user_id", "LifecycleTriggerLogs", "UserId")
+	s.CreateIndexIfNotExists("idx_lifecycle_trigger_logs_create_at", "LifecycleTriggerLogs", "CreateAt")
+}
+
+// Save fails when the trigger has already fired for the same occurrence, which is what keeps
+// two concurrent runs from notifying the customer twice.
+func (s SqlLifecycleTriggerLogStore) Save(log *model.LifecycleTriggerLog) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if log.CreateAt == 0 {
+			log.CreateAt = model.GetMillis()
+		}
+
+		if err := s.GetMaster().Insert(log); err != nil {
+			if IsUniqueConstraintError(err, []string{"PRIMARY", "lifecycletriggerlogs_pkey"}) {
+				result.Err = model.NewAppError("SqlLifecycleTriggerLogStore.Save", "store.sql_lifecycle_trigger_log.save.exists.app_error", nil, "trigger_id="+log.TriggerId+", user_id="+log.UserId+", "+err.Error(), http.StatusBadRequest)
+			} else {
+				result.Err = model.NewAppError("SqlLifecycleTriggerLogStore.Save", "store.sql_lifecycle_trigger_log.save.app_error", nil, "trigger_id="+log.TriggerId+", user_id="+log.UserId+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = log
+		}
+	})
+}
+
+func (s SqlLifecycleTriggerLogStore) Update(log *model.LifecycleTriggerLog) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Update(log); err != nil {
+			result.Err = model.NewAppError("SqlLifecycleTriggerLogStore.Update", "store.sql_lifecycle_trigger_log.update.app_error", nil, "trigger_id="+log.TriggerId+", user_id="+log.UserId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = log
+		}
+	})
+}
+
+func (s SqlLifecycleTriggerLogStore) GetForTrigger(triggerId string, offset int, limit int) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var logs []*model.LifecycleTriggerLog
+		if _, err := s.GetReplica().Select(&logs,
+			`SELECT * FROM LifecycleTriggerLogs WHERE TriggerId = :TriggerId ORDER BY CreateAt DESC LIMIT :Limit OFFSET :Offset`,
+			map[string]interface{}{"TriggerId": triggerId, "Limit": limit, "Offset": offset}); err != nil {
+			result.Err = model.NewAppError("SqlLifecycleTriggerLogStore.GetForTrigger", "store.sql_lifecycle_trigger_log.get_for_trigger.app_error", nil, "trigger_id="+triggerId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = logs
+		}
+	})
+}
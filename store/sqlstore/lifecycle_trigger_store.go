@@ -0,0 +1,241 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"im/model"
+	"im/store"
+)
+
+type SqlLifecycleTriggerStore struct {
+	SqlStore
+}
+
+func NewSqlLifecycleTriggerStore(sqlStore SqlStore) store.LifecycleTriggerStore {
+	s := &SqlLifecycleTriggerStore{
+		SqlStore: sqlStore,
+	}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.LifecycleTrigger{}, "LifecycleTriggers").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("AppId").SetMaxSize(26)
+		table.ColMap("CreatorId").SetMaxSize(26)
+		table.ColMap("Name").SetMaxSize(model.LIFECYCLE_TRIGGER_NAME_MAX_RUNES)
+		table.ColMap("Event").SetMaxSize(32)
+		table.ColMap("Message").SetMaxSize(model.LIFECYCLE_TRIGGER_MESSAGE_MAX_RUNES)
+	}
+
+	return s
+}
+
+func (s SqlLifecycleTriggerStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_lifecycle_triggers_app_id", "LifecycleTriggers", "AppId")
+}
+
+func (s SqlLifecycleTriggerStore) Save(trigger *model.LifecycleTrigger) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if len(trigger.Id) > 0 {
+			result.Err = model.NewAppError("SqlLifecycleTriggerStore.Save", "store.sql_lifecycle_trigger.save.existing.app_error", nil, "id="+trigger.Id, http.StatusBadRequest)
+			return
+		}
+
+		trigger.PreSave()
+
+		if result.Err = trigger.IsValid(); result.Err != nil {
+			return
+		}
+
+		if err := s.GetMaster().Insert(trigger); err != nil {
+			result.Err = model.NewAppError("SqlLifecycleTriggerStore.Save", "store.sql_lifecycle_trigger.save.app_error", nil, "id="+trigger.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = trigger
+		}
+	})
+}
+
+func (s SqlLifecycleTriggerStore) Update(trigger *model.LifecycleTrigger) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		trigger.PreUpdate()
+
+		if result.Err = trigger.IsValid(); result.Err != nil {
+			return
+		}
+
+		if _, err := s.GetMaster().Update(trigger); err != nil {
+			result.Err = model.NewAppError("SqlLifecycleTriggerStore.Update", "store.sql_lifecycle_trigger.update.app_error", nil, "id="+trigger.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = trigger
+		}
+	})
+}
+
+func (s SqlLifecycleTriggerStore) Get(id string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var trigger *model.LifecycleTrigger
+		if err := s.GetReplica().SelectOne(&trigger,
+			`SELECT * FROM LifecycleTriggers WHERE Id = :Id AND DeleteAt = 0`, map[string]interface{}{"Id": id}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlLifecycleTriggerStore.Get", "store.sql_lifecycle_trigger.get.app_error", nil, "id="+id+", "+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlLifecycleTriggerStore.Get", "store.sql_lifecycle_trigger.get.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = trigger
+		}
+	})
+}
+
+func (s SqlLifecycleTriggerStore) GetForApp(appId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var triggers []*model.LifecycleTrigger
+		if _, err := s.GetReplica().Select(&triggers,
+			`SELECT * FROM LifecycleTriggers WHERE AppId = :AppId AND DeleteAt = 0 ORDER BY CreateAt ASC`,
+			map[string]interface{}{"AppId": appId}); err != nil {
+			result.Err = model.NewAppError("SqlLifecycleTriggerStore.GetForApp", "store.sql_lifecycle_trigger.get_for_app.app_error", nil, "app_id="+appId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = triggers
+		}
+	})
+}
+
+func (s SqlLifecycleTriggerStore) GetEnabled() store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var triggers []*model.LifecycleTrigger
+		if _, err := s.GetReplica().Select(&triggers,
+			`SELECT t.* FROM LifecycleTriggers t
+			JOIN Applications a ON a.Id = t.AppId
+			WHERE t.Enabled = :Enabled AND t.DeleteAt = 0 AND a.DeleteAt = 0 AND a.BlockedAt = 0`,
+			map[string]interface{}{"Enabled": true}); err != nil {
+			result.Err = model.NewAppError("SqlLifecycleTriggerStore.GetEnabled", "store.sql_lifecycle_trigger.get_enabled.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = triggers
+		}
+	})
+}
+
+func (s SqlLifecycleTriggerStore) Delete(id string, time int64) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec(`UPDATE LifecycleTriggers SET DeleteAt = :DeleteAt, UpdateAt = :UpdateAt WHERE Id = :Id AND DeleteAt = 0`,
+			map[string]interface{}{"DeleteAt": time, "UpdateAt": time, "Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlLifecycleTriggerStore.Delete", "store.sql_lifecycle_trigger.delete.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = id
+		}
+	})
+}
+
+// applyFrequencyCaps drops the customers the trigger has fired for too recently or too often.
+func applyFrequencyCaps(query sq.SelectBuilder, trigger *model.LifecycleTrigger, now int64) sq.SelectBuilder {
+	if trigger.CooldownHours > 0 {
+		query = query.Where("NOT EXISTS (SELECT 1 FROM LifecycleTriggerLogs cl WHERE cl.TriggerId = ? AND cl.UserId = u.Id AND cl.CreateAt > ?)",
+			trigger.Id, now-int64(trigger.CooldownHours)*int64(time.Hour/time.Millisecond))
+	}
+
+	if trigger.MaxPerUser > 0 {
+		query = query.Where("(SELECT COUNT(*) FROM LifecycleTriggerLogs ml WHERE ml.TriggerId = ? AND ml.UserId = u.Id) < ?", trigger.Id, trigger.MaxPerUser)
+	}
+
+	return query
+}
+
+// GetCandidates returns the customers of the application the trigger should fire for now.
+// Customers it already fired for on the same occurrence of the event are left out.
+func (s SqlLifecycleTriggerStore) GetCandidates(trigger *model.LifecycleTrigger, now int64, limit int) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		isPostgreSQL := s.DriverName() == model.DATABASE_DRIVER_POSTGRES
+
+		var query sq.SelectBuilder
+		var subject string
+
+		switch trigger.Event {
+		case model.LIFECYCLE_EVENT_ABANDONED_ORDER:
+			query = s.getQueryBuilder().
+				Select("u.Id AS UserId", "o.Id AS SubjectId").
+				From("Orders o").
+				Join("Users u ON u.Id = o.UserId").
+				Where("o.Status = ?", model.ORDER_STATUS_AWAITING_PAYMENT).
+				Where("o.Payed = ?", false).
+				Where("o.Canceled = ?", false).
+				Where("o.DeleteAt = 0").
+				Where("o.CreateAt <= ?", now-int64(trigger.DelayMinutes)*int64(time.Minute/time.Millisecond)).
+				Where("o.CreateAt > ?", now-model.LIFECYCLE_ABANDONED_ORDER_WINDOW).
+				Where("NOT EXISTS (SELECT 1 FROM LifecycleTriggerLogs l WHERE l.TriggerId = ? AND l.UserId = u.Id AND l.SubjectId = o.Id)", trigger.Id).
+				OrderBy("o.CreateAt ASC")
+
+		case model.LIFECYCLE_EVENT_WIN_BACK:
+			// the last order is the occurrence, the trigger fires again only after the customer
+			// has ordered and then gone quiet once more
+			query = s.getQueryBuilder().
+				Select("u.Id AS UserId", "o.LastOrderAt AS SubjectId").
+				From("Users u").
+				Join(`(SELECT UserId, MAX(CreateAt) AS LastOrderAt FROM Orders
+					WHERE DeleteAt = 0 AND Canceled = ? GROUP BY UserId) o ON o.UserId = u.Id`, false).
+				Where("o.LastOrderAt <= ?", now-int64(trigger.InactiveDays)*int64(24*time.Hour/time.Millisecond)).
+				Where("NOT EXISTS (SELECT 1 FROM LifecycleTriggerLogs l WHERE l.TriggerId = ? AND l.UserId = u.Id AND l.CreateAt > o.LastOrderAt)", trigger.Id).
+				OrderBy("u.Id ASC")
+
+		case model.LIFECYCLE_EVENT_BIRTHDAY:
+			today := time.Unix(0, now*int64(time.Millisecond)).UTC()
+			subject = strconv.Itoa(today.Year())
+
+			birthday := "DATE_ADD('1970-01-01', INTERVAL FLOOR(u.BirthdayAt / 1000) SECOND)"
+			if isPostgreSQL {
+				birthday = "(TO_TIMESTAMP(u.BirthdayAt / 1000) AT TIME ZONE 'UTC')"
+			}
+
+			query = s.getQueryBuilder().
+				Select("u.Id AS UserId").
+				From("Users u").
+				Where("u.BirthdayAt != 0").
+				Where("EXTRACT(MONTH FROM "+birthday+") = ?", int(today.Month())).
+				Where("EXTRACT(DAY FROM "+birthday+") = ?", today.Day()).
+				Where("NOT EXISTS (SELECT 1 FROM LifecycleTriggerLogs l WHERE l.TriggerId = ? AND l.UserId = u.Id AND l.SubjectId = ?)", trigger.Id, subject).
+				OrderBy("u.Id ASC")
+
+		default:
+			result.Err = model.NewAppError("SqlLifecycleTriggerStore.GetCandidates", "store.sql_lifecycle_trigger.get_candidates.event.app_error", nil, "event="+trigger.Event, http.StatusBadRequest)
+			return
+		}
+
+		query = query.
+			Where("u.AppId = ?", trigger.AppId).
+			Where("u.DeleteAt = 0").
+			Where("u.BlockedAt = 0").
+			Limit(uint64(limit))
+
+		query = applyRoleFilter(query, model.CHANNEL_USER_ROLE_ID, isPostgreSQL)
+		query = applyFrequencyCaps(query, trigger, now)
+
+		queryString, args, err := query.ToSql()
+		if err != nil {
+			result.Err = model.NewAppError("SqlLifecycleTriggerStore.GetCandidates", "store.sql_lifecycle_trigger.get_candidates.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var rows []struct {
+			UserId    string
+			SubjectId sql.NullString
+		}
+		if _, err := s.GetReplica().Select(&rows, queryString, args...); err != nil {
+			result.Err = model.NewAppError("SqlLifecycleTriggerStore.GetCandidates", "store.sql_lifecycle_trigger.get_candidates.app_error", nil, "trigger_id="+trigger.Id+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		candidates := make([]*model.LifecycleTriggerCandidate, 0, len(rows))
+		for _, row := range rows {
+			candidate := &model.LifecycleTriggerCandidate{UserId: row.UserId, SubjectId: subject}
+			if row.SubjectId.Valid {
+				candidate.SubjectId = row.SubjectId.String
+			}
+			candidates = append(candidates, candidate)
+		}
+
+		result.Data = candidates
+	})
+}
@@ -0,0 +1,124 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+
+	"im/model"
+	"im/store"
+)
+
+type SqlLoyaltyTierStore struct {
+	SqlStore
+}
+
+func NewSqlLoyaltyTierStore(sqlStore SqlStore) store.LoyaltyTierStore {
+	s := &SqlLoyaltyTierStore{
+		SqlStore: sqlStore,
+	}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.LoyaltyTier{}, "LoyaltyTiers").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("AppId").SetMaxSize(26)
+		table.ColMap("Name").SetMaxSize(model.LOYALTY_TIER_NAME_MAX_RUNES)
+	}
+
+	return s
+}
+
+func (s SqlLoyaltyTierStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_loyalty_tiers_app_id", "LoyaltyTiers", "AppId")
+}
+
+func (s SqlLoyaltyTierStore) Save(tier *model.LoyaltyTier) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if len(tier.Id) > 0 {
+			result.Err = model.NewAppError("SqlLoyaltyTierStore.Save", "store.sql_loyalty_tier.save.existing.app_error", nil, "id="+tier.Id, http.StatusBadRequest)
+			return
+		}
+
+		tier.PreSave()
+
+		if result.Err = tier.IsValid(); result.Err != nil {
+			return
+		}
+
+		if err := s.GetMaster().Insert(tier); err != nil {
+			result.Err = model.NewAppError("SqlLoyaltyTierStore.Save", "store.sql_loyalty_tier.save.app_error", nil, "id="+tier.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = tier
+		}
+	})
+}
+
+func (s SqlLoyaltyTierStore) Update(tier *model.LoyaltyTier) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		tier.PreUpdate()
+
+		if result.Err = tier.IsValid(); result.Err != nil {
+			return
+		}
+
+		if _, err := s.GetMaster().Update(tier); err != nil {
+			result.Err = model.NewAppError("SqlLoyaltyTierStore.Update", "store.sql_loyalty_tier.update.app_error", nil, "id="+tier.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = tier
+		}
+	})
+}
+
+func (s SqlLoyaltyTierStore) Get(id string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var tier *model.LoyaltyTier
+		if err := s.GetReplica().SelectOne(&tier,
+			`SELECT * FROM LoyaltyTiers WHERE Id = :Id AND DeleteAt = 0`, map[string]interface{}{"Id": id}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlLoyaltyTierStore.Get", "store.sql_loyalty_tier.get.app_error", nil, "id="+id+", "+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlLoyaltyTierStore.Get", "store.sql_loyalty_tier.get.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = tier
+		}
+	})
+}
+
+func (s SqlLoyaltyTierStore) GetForApp(appId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var tiers []*model.LoyaltyTier
+		if _, err := s.GetReplica().Select(&tiers,
+			`SELECT * FROM LoyaltyTiers WHERE AppId = :AppId AND DeleteAt = 0 ORDER BY Threshold ASC`,
+			map[string]interface{}{"AppId": appId}); err != nil {
+			result.Err = model.NewAppError("SqlLoyaltyTierStore.GetForApp", "store.sql_loyalty_tier.get_for_app.app_error", nil, "app_id="+appId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = tiers
+		}
+	})
+}
+
+// GetAppIds returns the applications that have at least one tier and a tier metric configured.
+func (s SqlLoyaltyTierStore) GetAppIds() store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var appIds []string
+		if _, err := s.GetReplica().Select(&appIds,
+			`SELECT DISTINCT t.AppId FROM LoyaltyTiers t
+			JOIN Applications a ON a.Id = t.AppId
+			WHERE t.DeleteAt = 0 AND a.DeleteAt = 0 AND a.TierMetric != ''`); err != nil {
+			result.Err = model.NewAppError("SqlLoyaltyTierStore.GetAppIds", "store.sql_loyalty_tier.get_app_ids.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = appIds
+		}
+	})
+}
+
+func (s SqlLoyaltyTierStore) Delete(id string, time int64) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec(`UPDATE LoyaltyTiers SET DeleteAt = :DeleteAt, UpdateAt = :UpdateAt WHERE Id = :Id AND DeleteAt = 0`,
+			map[string]interface{}{"DeleteAt": time, "UpdateAt": time, "Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlLoyaltyTierStore.Delete", "store.sql_loyalty_tier.delete.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = id
+		}
+	})
+}
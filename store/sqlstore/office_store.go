@@ -21,6 +21,8 @@ func NewSqlOfficeStore(sqlStore SqlStore) store.OfficeStore {
 		table.ColMap("Name").SetMaxSize(255)
 		table.ColMap("Preview").SetMaxSize(255)
 		table.ColMap("Description").SetMaxSize(2000)
+		table.ColMap("PrintFormat").SetMaxSize(16)
+		table.ColMap("PrintAgentKey").SetMaxSize(64)
 
 	}
 
@@ -0,0 +1,82 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+
+	"im/model"
+	"im/store"
+)
+
+type SqlOrderDocumentStore struct {
+	SqlStore
+}
+
+func NewSqlOrderDocumentStore(sqlStore SqlStore) store.OrderDocumentStore {
+	s := &SqlOrderDocumentStore{
+		SqlStore: sqlStore,
+	}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.OrderDocument{}, "OrderDocuments").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("AppId").SetMaxSize(26)
+		table.ColMap("OrderId").SetMaxSize(26)
+		table.ColMap("Type").SetMaxSize(16)
+		table.ColMap("Number").SetMaxSize(32)
+		table.ColMap("Buyer").SetMaxSize(4000)
+		table.ColMap("FileId").SetMaxSize(26)
+		table.ColMap("PostId").SetMaxSize(26)
+		table.ColMap("CreatorId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlOrderDocumentStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_order_documents_order_id", "OrderDocuments", "OrderId")
+}
+
+func (s SqlOrderDocumentStore) Save(document *model.OrderDocument) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		document.PreSave()
+		if result.Err = document.IsValid(); result.Err != nil {
+			return
+		}
+
+		if err := s.GetMaster().Insert(document); err != nil {
+			result.Err = model.NewAppError("SqlOrderDocumentStore.Save", "store.sql_order_document.save.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = document
+		}
+	})
+}
+
+func (s SqlOrderDocumentStore) Get(documentId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var document model.OrderDocument
+		if err := s.GetReplica().SelectOne(&document, `SELECT * FROM OrderDocuments WHERE Id = :Id`, map[string]interface{}{"Id": documentId}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlOrderDocumentStore.Get", "store.sql_order_document.get.app_error", nil, "id="+documentId, http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlOrderDocumentStore.Get", "store.sql_order_document.get.app_error", nil, "id="+documentId+", "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		result.Data = &document
+	})
+}
+
+func (s SqlOrderDocumentStore) GetForOrder(orderId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var documents []*model.OrderDocument
+		if _, err := s.GetMaster().Select(&documents,
+			`SELECT * FROM OrderDocuments WHERE OrderId = :OrderId ORDER BY CreateAt ASC`,
+			map[string]interface{}{"OrderId": orderId}); err != nil {
+			result.Err = model.NewAppError("SqlOrderDocumentStore.GetForOrder", "store.sql_order_document.get_for_order.app_error", nil, "order_id="+orderId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = documents
+		}
+	})
+}
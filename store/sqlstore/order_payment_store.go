@@ -0,0 +1,98 @@
+package sqlstore
+
+import (
+	"net/http"
+
+	"im/model"
+	"im/store"
+)
+
+type SqlOrderPaymentStore struct {
+	SqlStore
+}
+
+func NewSqlOrderPaymentStore(sqlStore SqlStore) store.OrderPaymentStore {
+	s := &SqlOrderPaymentStore{
+		SqlStore: sqlStore,
+	}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.OrderPayment{}, "OrderPayments").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("OrderId").SetMaxSize(26)
+		table.ColMap("Tender").SetMaxSize(16)
+		table.ColMap("Status").SetMaxSize(16)
+		table.ColMap("PaySystemId").SetMaxSize(32)
+		table.ColMap("Reference").SetMaxSize(model.ORDER_PAYMENT_REFERENCE_MAX_LENGTH)
+		table.ColMap("CreatorId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlOrderPaymentStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_order_payments_order_id", "OrderPayments", "OrderId")
+}
+
+func (s SqlOrderPaymentStore) Save(payment *model.OrderPayment) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		payment.PreSave()
+		if result.Err = payment.IsValid(); result.Err != nil {
+			return
+		}
+
+		if err := s.GetMaster().Insert(payment); err != nil {
+			result.Err = model.NewAppError("SqlOrderPaymentStore.Save", "store.sql_order_payment.save.app_error", nil, "order_id="+payment.OrderId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = payment
+		}
+	})
+}
+
+func (s SqlOrderPaymentStore) Update(payment *model.OrderPayment) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		payment.PreUpdate()
+		if result.Err = payment.IsValid(); result.Err != nil {
+			return
+		}
+
+		if _, err := s.GetMaster().Update(payment); err != nil {
+			result.Err = model.NewAppError("SqlOrderPaymentStore.Update", "store.sql_order_payment.update.app_error", nil, "id="+payment.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = payment
+		}
+	})
+}
+
+// GetForOrder returns the tenders of the order in the order they were taken.
+func (s SqlOrderPaymentStore) GetForOrder(orderId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var payments []*model.OrderPayment
+		if _, err := s.GetMaster().Select(&payments,
+			`SELECT * FROM OrderPayments WHERE OrderId = :OrderId ORDER BY CreateAt ASC`,
+			map[string]interface{}{"OrderId": orderId}); err != nil {
+			result.Err = model.NewAppError("SqlOrderPaymentStore.GetForOrder", "store.sql_order_payment.get_for_order.app_error", nil, "order_id="+orderId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = payments
+		}
+	})
+}
+
+func (s SqlOrderPaymentStore) GetForOrders(orderIds []string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		payments := []*model.OrderPayment{}
+		if len(orderIds) == 0 {
+			result.Data = payments
+			return
+		}
+
+		keys, params := MapStringsToQueryParams(orderIds, "Order")
+
+		if _, err := s.GetMaster().Select(&payments,
+			`SELECT * FROM OrderPayments WHERE OrderId IN `+keys+` ORDER BY CreateAt ASC`, params); err != nil {
+			result.Err = model.NewAppError("SqlOrderPaymentStore.GetForOrders", "store.sql_order_payment.get_for_orders.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = payments
+		}
+	})
+}
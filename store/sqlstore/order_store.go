@@ -33,7 +33,11 @@ func NewSqlOrderStore(sqlStore SqlStore) store.OrderStore {
 		table := db.AddTableWithName(model.Order{}, "Orders").SetKeys(false, "Id")
 
 		table.ColMap("Id").SetMaxSize(26)
-
+		table.ColMap("CourierId").SetMaxSize(26)
+		table.ColMap("HandoverCode").SetMaxSize(16)
+		table.ColMap("AddressId").SetMaxSize(26)
+		table.ColMap("CardMask").SetMaxSize(32)
+		table.ColMap("DeliveryAddress").SetMaxSize(4096)
 	}
 
 	return s
@@ -43,6 +47,18 @@ func (s SqlOrderStore) CreateIndexesIfNotExists() {
 	s.CreateIndexIfNotExists("idx_orders_update_at", "Orders", "UpdateAt")
 	s.CreateIndexIfNotExists("idx_orders_create_at", "Orders", "CreateAt")
 	s.CreateIndexIfNotExists("idx_orders_delete_at", "Orders", "DeleteAt")
+	s.CreateIndexIfNotExists("idx_orders_courier_id", "Orders", "CourierId")
+	s.CreateIndexIfNotExists("idx_orders_receipt_status", "Orders", "ReceiptStatus")
+
+	// cursor pages: every filter is followed by the sort column and the id
+	s.CreateCompositeIndexIfNotExists("idx_orders_create_at_id", "Orders", []string{"CreateAt", "Id"})
+	s.CreateCompositeIndexIfNotExists("idx_orders_delivery_at_id", "Orders", []string{"DeliveryAt", "Id"})
+	s.CreateCompositeIndexIfNotExists("idx_orders_price_id", "Orders", []string{"Price", "Id"})
+	s.CreateCompositeIndexIfNotExists("idx_orders_user_id_create_at", "Orders", []string{"UserId", "CreateAt", "Id"})
+	s.CreateCompositeIndexIfNotExists("idx_orders_courier_id_create_at", "Orders", []string{"CourierId", "CreateAt", "Id"})
+	s.CreateCompositeIndexIfNotExists("idx_orders_status_create_at", "Orders", []string{"Status", "CreateAt", "Id"})
+	s.CreateCompositeIndexIfNotExists("idx_orders_pay_system_id_create_at", "Orders", []string{"PaySystemId", "CreateAt", "Id"})
+	s.CreateIndexIfNotExists("idx_orders_phone", "Orders", "Phone")
 }
 
 func (s SqlOrderStore) Cancel(orderId string) store.StoreChannel {
@@ -219,7 +235,11 @@ func (s SqlOrderStore) GetAllOrders(options model.OrderGetOptions) store.StoreCh
 			Offset(uint64(options.Page * options.PerPage)).
 			Limit(uint64(options.PerPage))
 
-		r := <-s.Count(model.OrderCountOptions{AppId: options.AppId})
+		if len(options.CourierId) > 0 {
+			query = query.Where("O.CourierId = ?", options.CourierId)
+		}
+
+		r := <-s.Count(model.OrderCountOptions{AppId: options.AppId, CourierId: options.CourierId})
 		if r.Err != nil {
 			result.Err = r.Err
 			return
@@ -580,6 +600,90 @@ func (s SqlOrderStore) GetByUserId(options model.OrderGetOptions) store.StoreCha
 	})
 }
 
+// GetPage returns a cursor page of the orders of the application that pass the filters.
+func (s SqlOrderStore) GetPage(options *model.OrderCursorOptions) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		query := s.getQueryBuilder().
+			Select("o.*").
+			From("Orders o").
+			Join("Users u ON o.UserId = u.Id").
+			Where(sq.Eq{"o.DeleteAt": 0, "u.AppId": options.AppId})
+
+		if len(options.UserId) > 0 {
+			query = query.Where(sq.Eq{"o.UserId": options.UserId})
+		}
+		if len(options.CourierId) > 0 {
+			query = query.Where(sq.Eq{"o.CourierId": options.CourierId})
+		}
+		if len(options.Statuses) > 0 {
+			query = query.Where(sq.Eq{"o.Status": options.Statuses})
+		}
+		if options.CreatedFrom > 0 {
+			query = query.Where(sq.GtOrEq{"o.CreateAt": options.CreatedFrom})
+		}
+		if options.CreatedTo > 0 {
+			query = query.Where(sq.LtOrEq{"o.CreateAt": options.CreatedTo})
+		}
+		if len(options.PaySystemId) > 0 {
+			query = query.Where(sq.Eq{"o.PaySystemId": options.PaySystemId})
+		}
+		if options.AmountFrom != nil {
+			query = query.Where(sq.GtOrEq{"o.Price": *options.AmountFrom})
+		}
+		if options.AmountTo != nil {
+			query = query.Where(sq.LtOrEq{"o.Price": *options.AmountTo})
+		}
+		if len(options.OfficeId) > 0 {
+			query = query.Where("EXISTS (SELECT 1 FROM Baskets b JOIN ProductOffice po ON po.ProductId = b.ProductId AND po.DeleteAt = 0 WHERE b.OrderId = o.Id AND b.DeleteAt = 0 AND po.OfficeId = ?)", options.OfficeId)
+		}
+		if len(options.Terms) > 0 {
+			query = query.Where(phoneOrNumberClause(options.Terms, "o.Phone", "o.CreateAt"))
+		}
+
+		query = applyPageCursor(query, options.Sort, options.After, options.Limit, "o")
+
+		queryString, args, err := query.ToSql()
+		if err != nil {
+			result.Err = model.NewAppError("SqlOrderStore.GetPage", "store.sql_order.get_page.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var orders []*model.Order
+		if _, err := s.GetReplica().Select(&orders, queryString, args...); err != nil {
+			result.Err = model.NewAppError("SqlOrderStore.GetPage", "store.sql_order.get_page.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		list := model.NewOrderList()
+		list.NextCursor = pageCursorAfter(len(orders), options.Limit, options.Sort, func(i int) (float64, string) {
+			return orderSortValue(orders[i], options.Sort), orders[i].Id
+		})
+
+		if len(orders) > options.Limit {
+			orders = orders[:options.Limit]
+		}
+
+		for _, o := range orders {
+			list.AddItem(o)
+			list.AddOrder(o.Id)
+		}
+		list.Total = strconv.Itoa(len(orders))
+
+		result.Data = list
+	})
+}
+
+func orderSortValue(order *model.Order, sort *model.CursorSort) float64 {
+	switch sort.Column {
+	case "DeliveryAt":
+		return float64(order.DeliveryAt)
+	case "Price":
+		return order.Price
+	default:
+		return float64(order.CreateAt)
+	}
+}
+
 func (s SqlOrderStore) SetOrderPayed(orderId string) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
 
@@ -594,6 +698,40 @@ func (s SqlOrderStore) SetOrderPayed(orderId string) store.StoreChannel {
 	})
 }
 
+// SetCardMask remembers the masked number of the card the order was paid with.
+func (s SqlOrderStore) SetCardMask(orderId string, cardMask string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec("UPDATE Orders SET CardMask = :CardMask WHERE Id = :Id",
+			map[string]interface{}{"CardMask": cardMask, "Id": orderId}); err != nil {
+			result.Err = model.NewAppError("SqlOrderStore.SetCardMask", "store.sql_order.set_card_mask.app_error", nil, "id="+orderId+", "+err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+func (s SqlOrderStore) SetReceipt(orderId string, receipt *model.OrderReceipt, receiptAt int64) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec(`UPDATE Orders SET ReceiptStatus = :ReceiptStatus, ReceiptQr = :ReceiptQr, ReceiptUrl = :ReceiptUrl, ReceiptAt = :ReceiptAt WHERE Id = :Id`,
+			map[string]interface{}{"ReceiptStatus": receipt.Status, "ReceiptQr": receipt.Qr, "ReceiptUrl": receipt.Url, "ReceiptAt": receiptAt, "Id": orderId}); err != nil {
+			result.Err = model.NewAppError("SqlOrderStore.SetReceipt", "store.sql_order.set_receipt.app_error", nil, "id="+orderId+", "+err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// GetPendingReceipts returns the paid orders the acquirer has not issued the receipt for yet,
+// the ones waiting the longest first.
+func (s SqlOrderStore) GetPendingReceipts(limit int) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var orders []*model.Order
+		if _, err := s.GetReplica().Select(&orders,
+			`SELECT * FROM Orders WHERE ReceiptStatus = :ReceiptStatus AND DeleteAt = 0 ORDER BY ReceiptAt ASC LIMIT :Limit`,
+			map[string]interface{}{"ReceiptStatus": model.RECEIPT_STATUS_PENDING, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlOrderStore.GetPendingReceipts", "store.sql_order.get_pending_receipts.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = orders
+		}
+	})
+}
+
 func (s SqlOrderStore) SetOrderCancel(orderId string) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
 
@@ -608,6 +746,37 @@ func (s SqlOrderStore) SetOrderCancel(orderId string) store.StoreChannel {
 	})
 }
 
+// GetActiveForCouriers returns the orders assigned to the couriers that are neither delivered nor declined yet.
+func (s SqlOrderStore) GetActiveForCouriers(courierIds []string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var orders []*model.Order
+
+		if len(courierIds) == 0 {
+			result.Data = orders
+			return
+		}
+
+		query := s.ordersQuery.
+			Where(sq.Eq{"O.CourierId": courierIds}).
+			Where("O.DeleteAt = 0").
+			Where(sq.NotEq{"O.Status": []string{model.ORDER_STATUS_SHIPPED, model.ORDER_STATUS_DECLINED, model.ORDER_STATUS_REFUNDED}}).
+			OrderBy("O.CourierAssignedAt ASC")
+
+		queryString, args, err := query.ToSql()
+		if err != nil {
+			result.Err = model.NewAppError("SqlOrderStore.GetActiveForCouriers", "store.sql_order.get_active_for_couriers.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if _, err := s.GetReplica().Select(&orders, queryString, args...); err != nil {
+			result.Err = model.NewAppError("SqlOrderStore.GetActiveForCouriers", "store.sql_order.get_active_for_couriers.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = orders
+	})
+}
+
 func (s SqlOrderStore) Count(options model.OrderCountOptions) store.StoreChannel {
 	isPostgreSQL := s.DriverName() == model.DATABASE_DRIVER_POSTGRES
 	return store.Do(func(result *store.StoreResult) {
@@ -620,6 +789,9 @@ func (s SqlOrderStore) Count(options model.OrderCountOptions) store.StoreChannel
 			Where("O.DeleteAt = 0").
 			Where("O.DeliveryAt <= ? AND U.AppId = ?", endOfDay, options.AppId)
 
+		if len(options.CourierId) > 0 {
+			query = query.Where("O.CourierId = ?", options.CourierId)
+		}
 		query = generateOrderStatusQuery(query, strings.Fields(model.ORDER_STATUS_DECLINED+" "+model.ORDER_STATUS_SHIPPED), false, isPostgreSQL)
 		queryString, args, err := query.ToSql()
 		if err != nil {
@@ -638,6 +810,9 @@ func (s SqlOrderStore) Count(options model.OrderCountOptions) store.StoreChannel
 			Join("(SELECT SUBSTRING(Props, 14, 26) AS OrderId FROM Posts WHERE Type = ?) P ON O.Id = P.OrderId ", model.POST_WITH_METADATA).
 			Where("O.DeleteAt = 0").
 			Where("O.DeliveryAt > ? AND U.AppId = ?", endOfDay, options.AppId)
+		if len(options.CourierId) > 0 {
+			query = query.Where("O.CourierId = ?", options.CourierId)
+		}
 		query = generateOrderStatusQuery(query, strings.Fields(model.ORDER_STATUS_DECLINED+" "+model.ORDER_STATUS_SHIPPED), false, isPostgreSQL)
 		queryString, args, err = query.ToSql()
 		if err != nil {
@@ -657,6 +832,9 @@ func (s SqlOrderStore) Count(options model.OrderCountOptions) store.StoreChannel
 			Where("O.DeleteAt = 0").
 			Where("U.AppId = ?", options.AppId)
 
+		if len(options.CourierId) > 0 {
+			query = query.Where("O.CourierId = ?", options.CourierId)
+		}
 		query = generateOrderStatusQuery(query, strings.Fields(model.ORDER_STATUS_DECLINED+" "+model.ORDER_STATUS_SHIPPED), true, isPostgreSQL)
 		queryString, args, err = query.ToSql()
 		if err != nil {
@@ -728,3 +906,179 @@ func (s SqlOrderStore) GetMetricsForOrders(appId string, beginAt int64, expireAt
 		result.Data = metrics
 	})
 }
+
+// reportOrdersQuery is a starting point for the report queries: the orders of the customers of
+// the application made in the period and not canceled.
+func (s SqlOrderStore) reportOrdersQuery(options model.ReportOptions, columns ...string) sq.SelectBuilder {
+	query := s.getQueryBuilder().
+		Select(columns...).
+		From("Orders o").
+		Join("Users u ON u.Id = o.UserId").
+		Where(sq.Eq{"u.AppId": options.AppId, "o.Canceled": false, "o.DeleteAt": 0})
+	query = applyRoleFilter(query, model.CHANNEL_USER_ROLE_ID, s.DriverName() == model.DATABASE_DRIVER_POSTGRES)
+
+	if options.BeginAt > 0 {
+		query = query.Where(sq.GtOrEq{"o.CreateAt": options.BeginAt})
+	}
+	if options.ExpireAt > 0 {
+		query = query.Where(sq.LtOrEq{"o.CreateAt": options.ExpireAt})
+	}
+
+	return query
+}
+
+// GetCohortCounts groups the customers by the week of their first order and counts how many
+// of each cohort ordered in every following week. Weeks are numbered from the epoch.
+func (s SqlOrderStore) GetCohortCounts(options model.ReportOptions, firstCohort int64, lastCohort int64) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		week := fmt.Sprintf("FLOOR((%%s + :Offset - %d) / %d)", model.REPORT_MONDAY_MILLIS, model.REPORT_WEEK_MILLIS)
+
+		var counts []*model.CohortCount
+		if _, err := s.GetReplica().Select(&counts,
+			`SELECT f.Cohort AS Cohort, `+fmt.Sprintf(week, "o.CreateAt")+` - f.Cohort AS WeekOffset, COUNT(DISTINCT o.UserId) AS Customers
+			FROM Orders o
+			INNER JOIN (
+				SELECT o2.UserId AS UserId, `+fmt.Sprintf(week, "MIN(o2.CreateAt)")+` AS Cohort
+				FROM Orders o2
+				INNER JOIN Users u ON u.Id = o2.UserId
+				WHERE u.AppId = :AppId AND u.Roles LIKE :Role AND o2.Canceled = :Canceled AND o2.DeleteAt = 0
+				GROUP BY o2.UserId
+			) f ON f.UserId = o.UserId
+			WHERE o.Canceled = :Canceled AND o.DeleteAt = 0 AND f.Cohort BETWEEN :FirstCohort AND :LastCohort
+			GROUP BY f.Cohort, WeekOffset
+			ORDER BY f.Cohort ASC, WeekOffset ASC`,
+			map[string]interface{}{
+				"AppId":       options.AppId,
+				"Role":        "%" + model.CHANNEL_USER_ROLE_ID + "%",
+				"Canceled":    false,
+				"Offset":      int64(options.Offset) * 1000,
+				"FirstCohort": firstCohort,
+				"LastCohort":  lastCohort,
+			}); err != nil {
+			result.Err = model.NewAppError("SqlOrderStore.GetCohortCounts", "store.sql_order.get_cohort_counts.app_error", nil, "app_id="+options.AppId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = counts
+	})
+}
+
+// GetCustomerRfm returns the recency, the frequency and the monetary value of every customer
+// who ordered in the period.
+func (s SqlOrderStore) GetCustomerRfm(options model.ReportOptions) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		query := s.reportOrdersQuery(options,
+			"u.Id AS UserId", "u.FirstName AS FirstName", "u.LastName AS LastName", "u.Phone AS Phone",
+			"MAX(o.CreateAt) AS LastOrderAt", "COUNT(o.Id) AS Frequency", "COALESCE(SUM(o.Price), 0) AS Monetary").
+			GroupBy("u.Id", "u.FirstName", "u.LastName", "u.Phone")
+
+		queryString, args, err := query.ToSql()
+		if err != nil {
+			result.Err = model.NewAppError("SqlOrderStore.GetCustomerRfm", "store.sql_order.get_customer_rfm.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var customers []*model.CustomerRfm
+		if _, err := s.GetReplica().Select(&customers, queryString, args...); err != nil {
+			result.Err = model.NewAppError("SqlOrderStore.GetCustomerRfm", "store.sql_order.get_customer_rfm.app_error", nil, "app_id="+options.AppId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = customers
+	})
+}
+
+// salesQuery sums the positions of the orders of the period. Orders do not keep the office they
+// were made in, so with an office set only the products offered by that office are counted.
+func (s SqlOrderStore) salesQuery(options model.ReportOptions, columns ...string) sq.SelectBuilder {
+	query := s.reportOrdersQuery(options, columns...).
+		Join("Baskets b ON b.OrderId = o.Id").
+		Join("Products p ON p.Id = b.ProductId").
+		Where(sq.Eq{"b.DeleteAt": 0})
+
+	if len(options.OfficeId) > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM ProductOffice po WHERE po.ProductId = p.Id AND po.OfficeId = ? AND po.DeleteAt = 0)", options.OfficeId)
+	}
+
+	if options.Sort == model.REPORT_SORT_QUANTITY {
+		query = query.OrderBy("Quantity DESC")
+	} else {
+		query = query.OrderBy("Revenue DESC")
+	}
+
+	if options.Limit > 0 {
+		query = query.Limit(uint64(options.Limit))
+	}
+
+	return query
+}
+
+func (s SqlOrderStore) GetProductSales(options model.ReportOptions) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		query := s.salesQuery(options,
+			"p.Id AS Id", "p.Name AS Name", "COALESCE(SUM(b.Quantity), 0) AS Quantity", "COALESCE(SUM(b.Price * b.Quantity), 0) AS Revenue").
+			GroupBy("p.Id", "p.Name")
+
+		queryString, args, err := query.ToSql()
+		if err != nil {
+			result.Err = model.NewAppError("SqlOrderStore.GetProductSales", "store.sql_order.get_product_sales.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var rows []*model.SalesReportRow
+		if _, err := s.GetReplica().Select(&rows, queryString, args...); err != nil {
+			result.Err = model.NewAppError("SqlOrderStore.GetProductSales", "store.sql_order.get_product_sales.app_error", nil, "app_id="+options.AppId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = rows
+	})
+}
+
+func (s SqlOrderStore) GetCategorySales(options model.ReportOptions) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		query := s.salesQuery(options,
+			"c.Id AS Id", "c.Name AS Name", "COALESCE(SUM(b.Quantity), 0) AS Quantity", "COALESCE(SUM(b.Price * b.Quantity), 0) AS Revenue").
+			Join("Categories c ON c.Id = p.CategoryId").
+			GroupBy("c.Id", "c.Name")
+
+		queryString, args, err := query.ToSql()
+		if err != nil {
+			result.Err = model.NewAppError("SqlOrderStore.GetCategorySales", "store.sql_order.get_category_sales.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var rows []*model.SalesReportRow
+		if _, err := s.GetReplica().Select(&rows, queryString, args...); err != nil {
+			result.Err = model.NewAppError("SqlOrderStore.GetCategorySales", "store.sql_order.get_category_sales.app_error", nil, "app_id="+options.AppId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = rows
+	})
+}
+
+// GetChecksByHour counts the orders and sums their prices by the hour of the day they were made in.
+func (s SqlOrderStore) GetChecksByHour(options model.ReportOptions) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		query := s.reportOrdersQuery(options,
+			fmt.Sprintf("FLOOR(MOD(o.CreateAt + %d, %d) / %d) AS Hour", int64(options.Offset)*1000, model.REPORT_DAY_MILLIS, model.REPORT_HOUR_MILLIS),
+			"COUNT(o.Id) AS Orders", "COALESCE(SUM(o.Price), 0) AS Revenue").
+			GroupBy("Hour").
+			OrderBy("Hour ASC")
+
+		queryString, args, err := query.ToSql()
+		if err != nil {
+			result.Err = model.NewAppError("SqlOrderStore.GetChecksByHour", "store.sql_order.get_checks_by_hour.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var rows []*model.HourlyCheck
+		if _, err := s.GetReplica().Select(&rows, queryString, args...); err != nil {
+			result.Err = model.NewAppError("SqlOrderStore.GetChecksByHour", "store.sql_order.get_checks_by_hour.app_error", nil, "app_id="+options.AppId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = rows
+	})
+}
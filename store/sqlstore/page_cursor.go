@@ -0,0 +1,69 @@
+package sqlstore
+
+import (
+	"strconv"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"im/model"
+)
+
+// ORDER_NUMBER_DIGITS is how many last digits of CreateAt make the number of an order.
+const ORDER_NUMBER_DIGITS = 6
+
+// applyPageCursor orders the query by the sort column and then by the id, starts it after the
+// cursor and fetches one item more than the limit to tell whether there is a next page.
+func applyPageCursor(query sq.SelectBuilder, sort *model.CursorSort, after *model.PageCursor, limit int, table string) sq.SelectBuilder {
+	column := table + "." + sort.Column
+	id := table + ".Id"
+
+	direction, op := "ASC", ">"
+	if sort.Descending {
+		direction, op = "DESC", "<"
+	}
+
+	if after != nil {
+		query = query.Where("("+column+" "+op+" ? OR ("+column+" = ? AND "+id+" "+op+" ?))", after.Value, after.Value, after.Id)
+	}
+
+	return query.
+		OrderBy(column+" "+direction, id+" "+direction).
+		Limit(uint64(limit + 1))
+}
+
+// pageCursorAfter returns the cursor after the last item and drops the extra item, or an empty
+// cursor on the last page.
+func pageCursorAfter(count int, limit int, sort *model.CursorSort, last func(i int) (float64, string)) string {
+	if count <= limit {
+		return ""
+	}
+
+	value, id := last(limit - 1)
+	cursor := &model.PageCursor{Sort: sort.Key(), Value: value, Id: id}
+
+	return cursor.Encode()
+}
+
+// phoneOrNumberClause matches the terms against the phone column and the number of the order,
+// the last digits of its CreateAt.
+func phoneOrNumberClause(terms string, phoneColumn string, createAtColumn string) sq.Sqlizer {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, terms)
+
+	if len(digits) == 0 {
+		return sq.Expr("1 = 0")
+	}
+
+	clause := sq.Or{sq.Expr(phoneColumn+" LIKE ?", "%"+digits+"%")}
+	if len(digits) == ORDER_NUMBER_DIGITS {
+		number, _ := strconv.ParseInt(digits, 10, 64)
+		clause = append(clause, sq.Expr("MOD("+createAtColumn+", 1000000) = ?", number))
+	}
+
+	return clause
+}
@@ -0,0 +1,131 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+
+	"im/model"
+	"im/store"
+)
+
+type SqlPosOrderSyncStore struct {
+	SqlStore
+}
+
+func NewSqlPosOrderSyncStore(sqlStore SqlStore) store.PosOrderSyncStore {
+	s := &SqlPosOrderSyncStore{
+		SqlStore: sqlStore,
+	}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.PosOrderSync{}, "PosOrderSyncs").SetKeys(false, "OrderId")
+		table.ColMap("OrderId").SetMaxSize(26)
+		table.ColMap("AppId").SetMaxSize(26)
+		table.ColMap("Provider").SetMaxSize(64)
+		table.ColMap("ExternalId").SetMaxSize(model.POS_EXTERNAL_ID_MAX_LENGTH)
+		table.ColMap("Status").SetMaxSize(32)
+		table.ColMap("ExternalStatus").SetMaxSize(64)
+		table.ColMap("LastError").SetMaxSize(model.POS_SYNC_ERROR_MAX_SIZE)
+	}
+
+	return s
+}
+
+func (s SqlPosOrderSyncStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_pos_order_syncs_app_id_status", "PosOrderSyncs", "AppId, Status")
+	s.CreateIndexIfNotExists("idx_pos_order_syncs_external_id", "PosOrderSyncs", "ExternalId")
+}
+
+// Save keeps one row per order, so it updates the existing row when there is one.
+func (s SqlPosOrderSyncStore) Save(sync *model.PosOrderSync) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		sync.PreSave()
+		if result.Err = sync.IsValid(); result.Err != nil {
+			return
+		}
+
+		count, err := s.GetMaster().Update(sync)
+		if err != nil {
+			result.Err = model.NewAppError("SqlPosOrderSyncStore.Save", "store.sql_pos_order_sync.save.update.app_error", nil, "order_id="+sync.OrderId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if count == 0 {
+			if err := s.GetMaster().Insert(sync); err != nil {
+				result.Err = model.NewAppError("SqlPosOrderSyncStore.Save", "store.sql_pos_order_sync.save.insert.app_error", nil, "order_id="+sync.OrderId+", "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		result.Data = sync
+	})
+}
+
+func (s SqlPosOrderSyncStore) Get(orderId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var sync model.PosOrderSync
+
+		if err := s.GetReplica().SelectOne(&sync,
+			`SELECT * FROM PosOrderSyncs WHERE OrderId = :OrderId`,
+			map[string]interface{}{"OrderId": orderId}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlPosOrderSyncStore.Get", "store.sql_pos_order_sync.get.app_error", nil, "order_id="+orderId+", "+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlPosOrderSyncStore.Get", "store.sql_pos_order_sync.get.app_error", nil, "order_id="+orderId+", "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		result.Data = &sync
+	})
+}
+
+func (s SqlPosOrderSyncStore) GetByExternalId(appId string, externalId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var sync model.PosOrderSync
+
+		if err := s.GetReplica().SelectOne(&sync,
+			`SELECT * FROM PosOrderSyncs WHERE AppId = :AppId AND ExternalId = :ExternalId`,
+			map[string]interface{}{"AppId": appId, "ExternalId": externalId}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlPosOrderSyncStore.GetByExternalId", "store.sql_pos_order_sync.get_by_external_id.app_error", nil, "external_id="+externalId+", "+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlPosOrderSyncStore.GetByExternalId", "store.sql_pos_order_sync.get_by_external_id.app_error", nil, "external_id="+externalId+", "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		result.Data = &sync
+	})
+}
+
+func (s SqlPosOrderSyncStore) GetForApp(appId string, status string, offset int, limit int) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var syncs []*model.PosOrderSync
+
+		query := s.getQueryBuilder().
+			Select("*").
+			From("PosOrderSyncs").
+			Where("AppId = ?", appId).
+			OrderBy("UpdateAt DESC").
+			Limit(uint64(limit)).
+			Offset(uint64(offset))
+
+		if len(status) > 0 {
+			query = query.Where("Status = ?", status)
+		}
+
+		queryString, args, err := query.ToSql()
+		if err != nil {
+			result.Err = model.NewAppError("SqlPosOrderSyncStore.GetForApp", "store.sql_pos_order_sync.get_for_app.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if _, err := s.GetReplica().Select(&syncs, queryString, args...); err != nil {
+			result.Err = model.NewAppError("SqlPosOrderSyncStore.GetForApp", "store.sql_pos_order_sync.get_for_app.app_error", nil, "app_id="+appId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = syncs
+	})
+}
@@ -0,0 +1,102 @@
+package sqlstore
+
+import (
+	"net/http"
+
+	"im/model"
+	"im/store"
+)
+
+type SqlPosProductMappingStore struct {
+	SqlStore
+}
+
+func NewSqlPosProductMappingStore(sqlStore SqlStore) store.PosProductMappingStore {
+	s := &SqlPosProductMappingStore{
+		SqlStore: sqlStore,
+	}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.PosProductMapping{}, "PosProductMappings").SetKeys(false, "AppId", "ProductId")
+		table.ColMap("AppId").SetMaxSize(26)
+		table.ColMap("ProductId").SetMaxSize(26)
+		table.ColMap("ExternalId").SetMaxSize(model.POS_EXTERNAL_ID_MAX_LENGTH)
+	}
+
+	return s
+}
+
+func (s SqlPosProductMappingStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_pos_product_mappings_external_id", "PosProductMappings", "ExternalId")
+}
+
+// Save maps the product to the external id, replacing the previous mapping of the product.
+func (s SqlPosProductMappingStore) Save(mapping *model.PosProductMapping) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		mapping.PreSave()
+		if result.Err = mapping.IsValid(); result.Err != nil {
+			return
+		}
+
+		count, err := s.GetMaster().Update(mapping)
+		if err != nil {
+			result.Err = model.NewAppError("SqlPosProductMappingStore.Save", "store.sql_pos_product_mapping.save.update.app_error", nil, "product_id="+mapping.ProductId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if count == 0 {
+			if err := s.GetMaster().Insert(mapping); err != nil {
+				result.Err = model.NewAppError("SqlPosProductMappingStore.Save", "store.sql_pos_product_mapping.save.insert.app_error", nil, "product_id="+mapping.ProductId+", "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		result.Data = mapping
+	})
+}
+
+func (s SqlPosProductMappingStore) GetForApp(appId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var mappings []*model.PosProductMapping
+
+		if _, err := s.GetReplica().Select(&mappings,
+			`SELECT * FROM PosProductMappings WHERE AppId = :AppId ORDER BY CreateAt ASC`,
+			map[string]interface{}{"AppId": appId}); err != nil {
+			result.Err = model.NewAppError("SqlPosProductMappingStore.GetForApp", "store.sql_pos_product_mapping.get_for_app.app_error", nil, "app_id="+appId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = mappings
+	})
+}
+
+func (s SqlPosProductMappingStore) GetForProducts(appId string, productIds []string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var mappings []*model.PosProductMapping
+
+		if len(productIds) == 0 {
+			result.Data = mappings
+			return
+		}
+
+		keys, params := MapStringsToQueryParams(productIds, "ProductId")
+		params["AppId"] = appId
+
+		if _, err := s.GetReplica().Select(&mappings,
+			`SELECT * FROM PosProductMappings WHERE AppId = :AppId AND ProductId IN `+keys, params); err != nil {
+			result.Err = model.NewAppError("SqlPosProductMappingStore.GetForProducts", "store.sql_pos_product_mapping.get_for_products.app_error", nil, "app_id="+appId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = mappings
+	})
+}
+
+func (s SqlPosProductMappingStore) Delete(appId string, productId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec(`DELETE FROM PosProductMappings WHERE AppId = :AppId AND ProductId = :ProductId`,
+			map[string]interface{}{"AppId": appId, "ProductId": productId}); err != nil {
+			result.Err = model.NewAppError("SqlPosProductMappingStore.Delete", "store.sql_pos_product_mapping.delete.app_error", nil, "product_id="+productId+", "+err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
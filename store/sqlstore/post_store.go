@@ -1657,3 +1657,29 @@ func (s SqlPostStore) FindPostWithOrder(orderId string) store.StoreChannel {
 
 	})
 }
+
+// FindPostsWithOrders returns the posts of the orders, the orders without a post are left out.
+func (s SqlPostStore) FindPostsWithOrders(orderIds []string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		posts := []*model.Post{}
+		if len(orderIds) == 0 {
+			result.Data = posts
+			return
+		}
+
+		props := make([]string, 0, len(orderIds))
+		for _, orderId := range orderIds {
+			props = append(props, `{"order_id":"`+orderId+`"}`)
+		}
+
+		keys, params := MapStringsToQueryParams(props, "Props")
+		params["Type"] = model.POST_WITH_METADATA
+		params["TypeInvoice"] = model.POST_WITH_INVOICE
+
+		if _, err := s.GetReplica().Select(&posts, "SELECT * FROM Posts WHERE Props IN "+keys+" AND (Type = :Type OR Type = :TypeInvoice)", params); err != nil {
+			result.Err = model.NewAppError("SqlPostStore.FindPostsWithOrders", "store.sql_post.find_posts_with_orders.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = posts
+		}
+	})
+}
@@ -0,0 +1,128 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+
+	"im/model"
+	"im/store"
+)
+
+type SqlPrintTicketStore struct {
+	SqlStore
+}
+
+func NewSqlPrintTicketStore(sqlStore SqlStore) store.PrintTicketStore {
+	s := &SqlPrintTicketStore{
+		SqlStore: sqlStore,
+	}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.PrintTicket{}, "PrintTickets").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("AppId").SetMaxSize(26)
+		table.ColMap("OfficeId").SetMaxSize(26)
+		table.ColMap("OrderId").SetMaxSize(26)
+		table.ColMap("Format").SetMaxSize(16)
+		table.ColMap("Reason").SetMaxSize(16)
+		table.ColMap("Status").SetMaxSize(16)
+		table.ColMap("Error").SetMaxSize(500)
+		table.ColMap("RequestedBy").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlPrintTicketStore) CreateIndexesIfNotExists() {
+	s.CreateCompositeIndexIfNotExists("idx_print_tickets_office_id_status", "PrintTickets", []string{"OfficeId", "Status", "CreateAt"})
+	s.CreateIndexIfNotExists("idx_print_tickets_order_id", "PrintTickets", "OrderId")
+	s.CreateUniqueIndexIfNotExists("idx_print_tickets_order_id_office_id_copy", "PrintTickets", "OrderId, OfficeId, Copy")
+}
+
+func (s SqlPrintTicketStore) Save(ticket *model.PrintTicket) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		ticket.PreSave()
+		if result.Err = ticket.IsValid(); result.Err != nil {
+			return
+		}
+
+		if err := s.GetMaster().Insert(ticket); err != nil {
+			if IsUniqueConstraintError(err, []string{"idx_print_tickets_order_id_office_id_copy"}) {
+				result.Err = model.NewAppError("SqlPrintTicketStore.Save", "store.sql_print_ticket.save.exists.app_error", nil, "order_id="+ticket.OrderId+", office_id="+ticket.OfficeId, http.StatusConflict)
+			} else {
+				result.Err = model.NewAppError("SqlPrintTicketStore.Save", "store.sql_print_ticket.save.app_error", nil, err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = ticket
+		}
+	})
+}
+
+func (s SqlPrintTicketStore) Update(ticket *model.PrintTicket) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		ticket.PreUpdate()
+		if result.Err = ticket.IsValid(); result.Err != nil {
+			return
+		}
+
+		if _, err := s.GetMaster().Update(ticket); err != nil {
+			result.Err = model.NewAppError("SqlPrintTicketStore.Update", "store.sql_print_ticket.update.app_error", nil, "id="+ticket.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = ticket
+		}
+	})
+}
+
+func (s SqlPrintTicketStore) Get(ticketId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var ticket model.PrintTicket
+		if err := s.GetReplica().SelectOne(&ticket, `SELECT * FROM PrintTickets WHERE Id = :Id`, map[string]interface{}{"Id": ticketId}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlPrintTicketStore.Get", "store.sql_print_ticket.get.app_error", nil, "id="+ticketId, http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlPrintTicketStore.Get", "store.sql_print_ticket.get.app_error", nil, "id="+ticketId+", "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		result.Data = &ticket
+	})
+}
+
+func (s SqlPrintTicketStore) GetForOrder(orderId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var tickets []*model.PrintTicket
+		if _, err := s.GetMaster().Select(&tickets,
+			`SELECT * FROM PrintTickets WHERE OrderId = :OrderId ORDER BY CreateAt ASC`,
+			map[string]interface{}{"OrderId": orderId}); err != nil {
+			result.Err = model.NewAppError("SqlPrintTicketStore.GetForOrder", "store.sql_print_ticket.get_for_order.app_error", nil, "order_id="+orderId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = tickets
+		}
+	})
+}
+
+// GetQueue returns the tickets of the office waiting to be printed, oldest first: the pending
+// ones and the ones handed out before sentBefore that were never acknowledged.
+func (s SqlPrintTicketStore) GetQueue(officeId string, sentBefore int64, limit int) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var tickets []*model.PrintTicket
+		if _, err := s.GetMaster().Select(&tickets,
+			`SELECT * FROM PrintTickets
+			WHERE OfficeId = :OfficeId
+				AND (Status = :Pending OR (Status = :Sent AND SentAt < :SentBefore))
+			ORDER BY CreateAt ASC
+			LIMIT :Limit`,
+			map[string]interface{}{
+				"OfficeId":   officeId,
+				"Pending":    model.PRINT_TICKET_STATUS_PENDING,
+				"Sent":       model.PRINT_TICKET_STATUS_SENT,
+				"SentBefore": sentBefore,
+				"Limit":      limit,
+			}); err != nil {
+			result.Err = model.NewAppError("SqlPrintTicketStore.GetQueue", "store.sql_print_ticket.get_queue.app_error", nil, "office_id="+officeId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = tickets
+		}
+	})
+}
@@ -90,6 +90,59 @@ func (s SqlProductOfficeStore) GetForProduct(productId string, readFromMaster bo
 	})
 }
 
+// GetOfficesForProducts returns the offices of every product, keyed by the product id.
+func (s SqlProductOfficeStore) GetOfficesForProducts(productIds []string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		officesByProduct := make(map[string][]*model.Office, len(productIds))
+		if len(productIds) == 0 {
+			result.Data = officesByProduct
+			return
+		}
+
+		keys, params := MapStringsToQueryParams(productIds, "Product")
+
+		var links []*model.ProductOffice
+		if _, err := s.GetReplica().Select(&links,
+			`SELECT * FROM ProductOffice WHERE ProductId IN `+keys+` AND DeleteAt = 0`, params); err != nil {
+			result.Err = model.NewAppError("SqlProductOfficeStore.GetOfficesForProducts",
+				"store.sql_product_office.get_offices_for_products.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if len(links) == 0 {
+			result.Data = officesByProduct
+			return
+		}
+
+		officeIds := make([]string, 0, len(links))
+		for _, link := range links {
+			officeIds = append(officeIds, link.OfficeId)
+		}
+
+		officeKeys, officeParams := MapStringsToQueryParams(officeIds, "Office")
+
+		var offices []*model.Office
+		if _, err := s.GetReplica().Select(&offices, `SELECT * FROM Offices WHERE Id IN `+officeKeys, officeParams); err != nil {
+			result.Err = model.NewAppError("SqlProductOfficeStore.GetOfficesForProducts",
+				"store.sql_product_office.get_offices_for_products.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		officesById := make(map[string]*model.Office, len(offices))
+		for _, office := range offices {
+			officesById[office.Id] = office
+		}
+
+		for _, link := range links {
+			if office, ok := officesById[link.OfficeId]; ok {
+				officesByProduct[link.ProductId] = append(officesByProduct[link.ProductId], office)
+			}
+		}
+
+		result.Data = officesByProduct
+	})
+}
+
 func (s SqlProductOfficeStore) AttachToPost(productOfficeId, postId, creatorId string) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
 		sqlResult, err := s.GetMaster().Exec(
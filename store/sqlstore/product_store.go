@@ -3,33 +3,35 @@ package sqlstore
 import (
 	"database/sql"
 	"fmt"
-	sq "github.com/Masterminds/squirrel"
 	"im/mlog"
 	"im/model"
 	"im/store"
 	"net/http"
 	"strings"
+	"unicode"
+)
+
+const (
+	PRODUCT_SEARCH_TEXT_CONFIG = "russian"
 )
 
 var (
-	PRODUCT_SEARCH_TYPE_NAMES = []string{"Name"}
+	PRODUCT_SEARCH_FULLTEXT_COLUMNS = []string{"p.Name", "p.Preview", "p.Description", "p.Tags"}
 )
 
 type SqlProductStore struct {
 	SqlStore
 
-	productsQuery sq.SelectBuilder
+	// trigramSupported tells whether pg_trgm could be enabled for the typo tolerant search
+	trigramSupported *bool
 }
 
 func NewSqlProductStore(sqlStore SqlStore) store.ProductStore {
 	s := &SqlProductStore{
-		SqlStore: sqlStore,
+		SqlStore:         sqlStore,
+		trigramSupported: new(bool),
 	}
 
-	s.productsQuery = s.getQueryBuilder().
-		Select("p.*").
-		From("Products p")
-
 	for _, db := range sqlStore.GetAllConns() {
 		table := db.AddTableWithName(model.Product{}, "Products").SetKeys(false, "Id")
 
@@ -41,6 +43,7 @@ func NewSqlProductStore(sqlStore SqlStore) store.ProductStore {
 
 		table.ColMap("CategoryId").SetMaxSize(26)
 		table.ColMap("FileIds").SetMaxSize(150)
+		table.ColMap("Tags").SetMaxSize(500)
 
 	}
 
@@ -52,6 +55,30 @@ func (s SqlProductStore) CreateIndexesIfNotExists() {
 	s.CreateIndexIfNotExists("idx_products_update_at", "Products", "UpdateAt")
 	s.CreateIndexIfNotExists("idx_products_create_at", "Products", "CreateAt")
 	s.CreateIndexIfNotExists("idx_products_delete_at", "Products", "DeleteAt")
+
+	s.CreateFullTextIndexWithConfigIfNotExists("idx_products_search_txt", "Products", "Name, Preview, Description, Tags", PRODUCT_SEARCH_TEXT_CONFIG)
+
+	if s.DriverName() == model.DATABASE_DRIVER_POSTGRES {
+		s.createTrigramIndexIfNotExists()
+	}
+}
+
+// createTrigramIndexIfNotExists enables pg_trgm and indexes the product names with it. The
+// extension may need more privileges than the server has, then the search goes without typos.
+func (s SqlProductStore) createTrigramIndexIfNotExists() {
+	if _, err := s.GetMaster().ExecNoTimeout("CREATE EXTENSION IF NOT EXISTS pg_trgm"); err != nil {
+		mlog.Warn("Failed to enable pg_trgm, the product search will not tolerate typos", mlog.Err(err))
+		return
+	}
+
+	if _, err := s.GetMaster().SelectStr("SELECT $1::regclass", "idx_products_name_trgm"); err != nil {
+		if _, err := s.GetMaster().ExecNoTimeout("CREATE INDEX idx_products_name_trgm ON Products USING gin(lower(Name) gin_trgm_ops)"); err != nil {
+			mlog.Warn("Failed to create the trigram index of the products", mlog.Err(err))
+			return
+		}
+	}
+
+	*s.trigramSupported = true
 }
 
 func (s SqlProductStore) GetExtras(product *model.Product) store.StoreChannel {
@@ -485,87 +512,83 @@ func (s SqlProductStore) GetAll() store.StoreChannel {
 	})
 }
 
-func (s SqlProductStore) Search(categoryId, terms string, page, perPage int) store.StoreChannel {
+// Search finds the active and accepted products of the application. The terms are matched with
+// the full text index and ranked by relevance; on PostgreSQL with pg_trgm the names also match
+// with typos.
+func (s SqlProductStore) Search(appId string, params *model.ProductSearchParams) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
+		where := []string{"p.DeleteAt = 0", "p.Active = :Active", "p.Status = :Status"}
+		queryArgs := map[string]interface{}{
+			"Active": true,
+			"Status": model.PRODUCT_STATUS_ACCEPTED,
+			"Limit":  params.PerPage,
+			"Offset": params.Page * params.PerPage,
+		}
 
-		var rootCategory *model.Category
-		if err := s.GetMaster().SelectOne(&rootCategory, `SELECT * FROM Categories WHERE Id = :Id`, map[string]interface{}{"Id": categoryId}); err != nil {
-			result.Err = model.NewAppError("SqlProductStore.GetAllPage",
-				"store.sql_products.get_category.app_error", nil, err.Error(), http.StatusInternalServerError)
-			return
+		if len(appId) > 0 {
+			where = append(where, "p.AppId = :AppId")
+			queryArgs["AppId"] = appId
 		}
 
-		var categories []*model.Category
-		if _, err := s.GetMaster().Select(&categories, `SELECT * FROM Categories WHERE Lft >= :Lft and Rgt <= :Rgt and AppId = :AppId`,
-			map[string]interface{}{
-				"Lft":   rootCategory.Lft,
-				"Rgt":   rootCategory.Rgt,
-				"AppId": rootCategory.AppId,
-			}); err != nil {
-			if err == sql.ErrNoRows {
-				result.Err = model.NewAppError("SqlProductStore.GetAllPage",
-					"store.sql_products.get_categories.app_error", nil, err.Error(), http.StatusNotFound)
-			} else {
-				result.Err = model.NewAppError("SqlProductStore.GetAllPage", "store.sql_products.get_categories.app_error",
-					nil, err.Error(), http.StatusInternalServerError)
+		if len(params.CategoryId) > 0 {
+			categoryIds, err := s.getCategoryTreeIds(params.CategoryId)
+			if err != nil {
+				result.Err = err
+				return
+			}
+
+			var inQueryList []string
+			for i, categoryId := range categoryIds {
+				inQueryList = append(inQueryList, fmt.Sprintf(":CategoryId%v", i))
+				queryArgs[fmt.Sprintf("CategoryId%v", i)] = categoryId
 			}
+			where = append(where, fmt.Sprintf("p.CategoryId IN (%s)", strings.Join(inQueryList, ", ")))
 		}
 
-		var inQueryList []string
-		var categoryIds []string
-		queryArgs := make(map[string]interface{})
-		for i, category := range categories {
-			inQueryList = append(inQueryList, fmt.Sprintf(":CategoryId%v", i))
-			queryArgs[fmt.Sprintf("CategoryId%v", i)] = category.Id
-			categoryIds = append(categoryIds, category.Id)
-		}
-		//inQuery := strings.Join(inQueryList, ", ")
-
-		query := s.productsQuery.
-			//Where(fmt.Sprintf("CategoryId IN (%s)", inQuery), queryArgs).
-			OrderBy("UpdateAt DESC").
-			Limit(uint64(perPage))
-		*result = s.performProductSearch(query, terms, categoryIds)
-	})
-}
+		if params.PriceFrom != nil {
+			where = append(where, "p.Price >= :PriceFrom")
+			queryArgs["PriceFrom"] = *params.PriceFrom
+		}
 
-func (s SqlProductStore) performProductSearch(query sq.SelectBuilder, terms string, categoryIds []string) store.StoreResult {
-	result := store.StoreResult{}
+		if params.PriceTo != nil {
+			where = append(where, "p.Price <= :PriceTo")
+			queryArgs["PriceTo"] = *params.PriceTo
+		}
 
-	// These chars must be removed from the like query.
-	for _, c := range ignoreLikeSearchChar {
-		terms = strings.Replace(terms, c, "", -1)
-	}
+		if len(params.OfficeId) > 0 {
+			where = append(where, "p.Id IN (SELECT ProductId FROM ProductOffice WHERE OfficeId = :OfficeId AND DeleteAt = 0)")
+			queryArgs["OfficeId"] = params.OfficeId
+		}
 
-	// These chars must be escaped in the like query.
-	for _, c := range escapeLikeSearchChar {
-		terms = strings.Replace(terms, c, "*"+c, -1)
-	}
+		// the tags are kept as a JSON array, so each of them is looked up with its quotes
+		for i, tag := range params.Tags {
+			where = append(where, fmt.Sprintf("p.Tags LIKE :Tag%v ESCAPE '*'", i))
+			queryArgs[fmt.Sprintf("Tag%v", i)] = productTagLikeTerm(tag)
+		}
 
-	searchType := PRODUCT_SEARCH_TYPE_NAMES
+		orderBy := "p.UpdateAt DESC"
+		if words := productSearchWords(params.Terms); len(words) > 0 {
+			var searchClause, rankClause string
+			searchClause, rankClause = s.buildProductSearchClauses(words, queryArgs)
 
-	isPostgreSQL := s.DriverName() == model.DATABASE_DRIVER_POSTGRES
+			where = append(where, searchClause)
+			orderBy = rankClause + " DESC, " + orderBy
+		}
 
-	if strings.TrimSpace(terms) != "" {
-		query = generateProductSearchQuery(query, strings.Fields(terms), searchType, isPostgreSQL, categoryIds)
-	}
+		query := `SELECT p.* FROM Products p
+			WHERE ` + strings.Join(where, " AND ") + `
+			ORDER BY ` + orderBy + `
+			LIMIT :Limit OFFSET :Offset`
 
-	queryString, args, err := query.ToSql()
-	if err != nil {
-		result.Err = model.NewAppError("SqlUserStore.Search", "store.sql_product.app_error", nil, err.Error(), http.StatusInternalServerError)
-		return result
-	}
-
-	var products []*model.Product
-	if _, err := s.GetReplica().Select(&products, queryString, args...); err != nil {
-		result.Err = model.NewAppError("SqlUserStore.Search", "store.sql_product.search.app_error", nil,
-			fmt.Sprintf("terms=%v, search_type=%v, %v", terms, searchType, err.Error()), http.StatusInternalServerError)
-	} else {
+		var products []*model.Product
+		if _, err := s.GetSearchReplica().Select(&products, query, queryArgs); err != nil {
+			result.Err = model.NewAppError("SqlProductStore.Search", "store.sql_product.search.app_error", nil,
+				fmt.Sprintf("app_id=%v, terms=%v, %v", appId, params.Terms, err.Error()), http.StatusInternalServerError)
+			return
+		}
 
 		list := model.NewProductList()
-
 		for _, p := range products {
-
 			list.AddProduct(p)
 			list.AddOrder(p.Id)
 		}
@@ -573,55 +596,100 @@ func (s SqlProductStore) performProductSearch(query sq.SelectBuilder, terms stri
 		list.MakeNonNil()
 
 		result.Data = list
+	})
+}
 
+// productTagLikeTerm matches the tag as an element of the JSON array of the tags, the LIKE
+// wildcards in it are escaped with '*'.
+func productTagLikeTerm(tag string) string {
+	term := model.ArrayToJson([]string{tag})
+	term = term[1 : len(term)-1]
+
+	term = strings.Replace(term, "*", "**", -1)
+	for _, c := range escapeLikeSearchChar {
+		term = strings.Replace(term, c, "*"+c, -1)
 	}
 
-	return result
+	return "%" + term + "%"
 }
 
-func generateProductSearchQuery(query sq.SelectBuilder, terms []string, fields []string, isPostgreSQL bool, categoryIds []string) sq.SelectBuilder {
-	for _, term := range terms {
-		searchFields := []string{}
-		termArgs := []interface{}{}
-		for _, field := range fields {
-			if isPostgreSQL {
-				searchFields = append(searchFields, fmt.Sprintf("lower(%s) LIKE lower(?) escape '*' ", field))
-			} else {
-				searchFields = append(searchFields, fmt.Sprintf("%s LIKE ? escape '*' ", field))
-			}
-			termArgs = append(termArgs, fmt.Sprintf("%%%s%%", term))
+// productSearchWords splits the terms into words, everything but letters and digits is dropped so
+// the words are safe for the full text queries.
+func productSearchWords(terms string) []string {
+	return strings.FieldsFunc(strings.ToLower(terms), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// buildProductSearchClauses returns the condition the products have to match and the expression
+// they are ranked by.
+func (s SqlProductStore) buildProductSearchClauses(words []string, queryArgs map[string]interface{}) (string, string) {
+	columns := strings.Join(PRODUCT_SEARCH_FULLTEXT_COLUMNS, ", ")
+
+	switch s.DriverName() {
+	case model.DATABASE_DRIVER_POSTGRES:
+		// the last word is likely not typed in full yet
+		prefixed := make([]string, len(words))
+		for i, word := range words {
+			prefixed[i] = word + ":*"
 		}
-		query = query.Where(fmt.Sprintf("(%s)", strings.Join(searchFields, " OR ")), termArgs...)
-	}
+		queryArgs["FulltextTerm"] = strings.Join(prefixed, " & ")
 
-	/*for _, categoryId := range categoryIds {
-		searchFields := []string{}
-		termArgs := []interface{}{}
+		vector := "to_tsvector('" + PRODUCT_SEARCH_TEXT_CONFIG + "', " + convertMySQLFullTextColumnsToPostgres(columns) + ")"
+		tsquery := "to_tsquery('" + PRODUCT_SEARCH_TEXT_CONFIG + "', :FulltextTerm)"
 
-			if isPostgreSQL {
-				searchFields = append(searchFields, fmt.Sprintf("lower(%s) LIKE lower(?) escape '*' ", "CategoryId"))
-			} else {
-				searchFields = append(searchFields, fmt.Sprintf("%s LIKE ? escape '*' ", "CategoryId"))
-			}
-			termArgs = append(termArgs, fmt.Sprintf("%s%%", strings.TrimLeft(categoryId, "@")))
-
-	}*/
-
-	var inQueryList []string
-	queryArgs := []interface{}{}
-	//queryArgs := make(map[string]interface{})
-	for _, categoryId := range categoryIds {
-		/*inQueryList = append(inQueryList, fmt.Sprintf(":CategoryId%v", i))
-		queryArgs[fmt.Sprintf("CategoryId%v", i)] = category.Id
-		categoryIds = append(categoryIds, category.Id)*/
-		inQueryList = append(inQueryList, "?")
-		queryArgs = append(queryArgs, fmt.Sprintf("%s", strings.TrimLeft(categoryId, "@")))
+		searchClause := vector + " @@ " + tsquery
+		rankClause := "ts_rank(" + vector + ", " + tsquery + ")"
+
+		if *s.trigramSupported {
+			queryArgs["Terms"] = strings.Join(words, " ")
+			searchClause = "(" + searchClause + " OR :Terms <% lower(p.Name))"
+			rankClause = "(" + rankClause + " + word_similarity(:Terms, lower(p.Name)))"
+		}
+
+		return searchClause, rankClause
+	case model.DATABASE_DRIVER_MYSQL:
+		required := make([]string, len(words))
+		for i, word := range words {
+			required[i] = "+" + word + "*"
+		}
+		queryArgs["FulltextTerm"] = strings.Join(required, " ")
+		queryArgs["Terms"] = strings.Join(words, " ")
+
+		return fmt.Sprintf("MATCH(%s) AGAINST (:FulltextTerm IN BOOLEAN MODE)", columns),
+			fmt.Sprintf("MATCH(%s) AGAINST (:Terms IN NATURAL LANGUAGE MODE)", columns)
+	default:
+		var searchFields []string
+		for i, word := range words {
+			searchFields = append(searchFields, fmt.Sprintf("lower(p.Name) LIKE :LikeTerm%v", i))
+			queryArgs[fmt.Sprintf("LikeTerm%v", i)] = "%" + word + "%"
+		}
+
+		return "(" + strings.Join(searchFields, " AND ") + ")", "p.CreateAt"
+	}
+}
+
+// getCategoryTreeIds returns the category along with all of its subcategories.
+func (s SqlProductStore) getCategoryTreeIds(categoryId string) ([]string, *model.AppError) {
+	var rootCategory *model.Category
+	if err := s.GetReplica().SelectOne(&rootCategory, `SELECT * FROM Categories WHERE Id = :Id`, map[string]interface{}{"Id": categoryId}); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.NewAppError("SqlProductStore.Search", "store.sql_products.get_category.app_error", nil, "category_id="+categoryId, http.StatusNotFound)
+		}
+		return nil, model.NewAppError("SqlProductStore.Search", "store.sql_products.get_category.app_error", nil, err.Error(), http.StatusInternalServerError)
 	}
-	inQuery := strings.Join(inQueryList, ", ")
 
-	query = query.Where(fmt.Sprintf("CategoryId IN (%s)", inQuery), queryArgs...)
+	var categoryIds []string
+	if _, err := s.GetReplica().Select(&categoryIds, `SELECT Id FROM Categories WHERE Lft >= :Lft AND Rgt <= :Rgt AND AppId = :AppId`,
+		map[string]interface{}{
+			"Lft":   rootCategory.Lft,
+			"Rgt":   rootCategory.Rgt,
+			"AppId": rootCategory.AppId,
+		}); err != nil {
+		return nil, model.NewAppError("SqlProductStore.Search", "store.sql_products.get_categories.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
 
-	return query
+	return categoryIds, nil
 }
 
 func (s SqlProductStore) GetForModeration(options *model.ProductGetOptions) store.StoreChannel {
@@ -677,3 +745,16 @@ func (s SqlProductStore) GetForModeration(options *model.ProductGetOptions) stor
 		}
 	})
 }
+
+// GetAppIds returns the applications that have products in their catalog.
+func (s SqlProductStore) GetAppIds() store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var appIds []string
+		if _, err := s.GetReplica().Select(&appIds,
+			`SELECT DISTINCT AppId FROM Products WHERE DeleteAt = 0`); err != nil {
+			result.Err = model.NewAppError("SqlProductStore.GetAppIds", "store.sql_products.get_app_ids.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = appIds
+		}
+	})
+}
@@ -0,0 +1,187 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"im/model"
+	"im/store"
+)
+
+type SqlReferralPayoutStore struct {
+	SqlStore
+}
+
+func NewSqlReferralPayoutStore(sqlStore SqlStore) store.ReferralPayoutStore {
+	s := &SqlReferralPayoutStore{
+		SqlStore: sqlStore,
+	}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.ReferralPayout{}, "ReferralPayouts").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("AppId").SetMaxSize(26)
+		table.ColMap("OrderId").SetMaxSize(26)
+		table.ColMap("InviterId").SetMaxSize(26)
+		table.ColMap("InviteeId").SetMaxSize(26)
+		table.ColMap("Status").SetMaxSize(32)
+		table.ColMap("Flags").SetMaxSize(255)
+		table.ColMap("TransactionId").SetMaxSize(26)
+		table.ColMap("ReviewerId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlReferralPayoutStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_referral_payouts_app_id", "ReferralPayouts", "AppId")
+	s.CreateIndexIfNotExists("idx_referral_payouts_inviter_id", "ReferralPayouts", "InviterId")
+	s.CreateIndexIfNotExists("idx_referral_payouts_status", "ReferralPayouts", "Status")
+}
+
+func (s SqlReferralPayoutStore) Save(payout *model.ReferralPayout) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if len(payout.Id) > 0 {
+			result.Err = model.NewAppError("SqlReferralPayoutStore.Save", "store.sql_referral_payout.save.existing.app_error", nil, "id="+payout.Id, http.StatusBadRequest)
+			return
+		}
+
+		payout.PreSave()
+		if result.Err = payout.IsValid(); result.Err != nil {
+			return
+		}
+
+		if err := s.GetMaster().Insert(payout); err != nil {
+			result.Err = model.NewAppError("SqlReferralPayoutStore.Save", "store.sql_referral_payout.save.app_error", nil, "id="+payout.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = payout
+		}
+	})
+}
+
+func (s SqlReferralPayoutStore) Update(payout *model.ReferralPayout) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		payout.PreUpdate()
+		if result.Err = payout.IsValid(); result.Err != nil {
+			return
+		}
+
+		if _, err := s.GetMaster().Update(payout); err != nil {
+			result.Err = model.NewAppError("SqlReferralPayoutStore.Update", "store.sql_referral_payout.update.app_error", nil, "id="+payout.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = payout
+		}
+	})
+}
+
+func (s SqlReferralPayoutStore) Get(payoutId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var payout *model.ReferralPayout
+		if err := s.GetMaster().SelectOne(&payout,
+			`SELECT * FROM ReferralPayouts WHERE Id = :Id`, map[string]interface{}{"Id": payoutId}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlReferralPayoutStore.Get", "store.sql_referral_payout.get.app_error", nil, "id="+payoutId+", "+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlReferralPayoutStore.Get", "store.sql_referral_payout.get.app_error", nil, "id="+payoutId+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = payout
+		}
+	})
+}
+
+// GetForApp returns the payouts of the application, only the ones in the given status when it is set.
+func (s SqlReferralPayoutStore) GetForApp(appId string, status string, offset int, limit int) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		query := s.getQueryBuilder().
+			Select("*").
+			From("ReferralPayouts").
+			Where(sq.Eq{"AppId": appId}).
+			OrderBy("CreateAt DESC").
+			Limit(uint64(limit)).
+			Offset(uint64(offset))
+
+		if len(status) > 0 {
+			query = query.Where(sq.Eq{"Status": status})
+		}
+
+		queryString, args, err := query.ToSql()
+		if err != nil {
+			result.Err = model.NewAppError("SqlReferralPayoutStore.GetForApp", "store.sql_referral_payout.get_for_app.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var payouts []*model.ReferralPayout
+		if _, err := s.GetReplica().Select(&payouts, queryString, args...); err != nil {
+			result.Err = model.NewAppError("SqlReferralPayoutStore.GetForApp", "store.sql_referral_payout.get_for_app.app_error", nil, "app_id="+appId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = payouts
+		}
+	})
+}
+
+// GetCreditedTotal returns the sum credited to the inviter since the given time.
+func (s SqlReferralPayoutStore) GetCreditedTotal(inviterId string, since int64) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		total, err := s.GetMaster().SelectFloat(
+			`SELECT COALESCE(SUM(Value), 0) FROM ReferralPayouts
+			WHERE InviterId = :InviterId AND Status = :Status AND CreditedAt >= :Since`,
+			map[string]interface{}{"InviterId": inviterId, "Status": model.REFERRAL_PAYOUT_STATUS_CREDITED, "Since": since})
+		if err != nil {
+			result.Err = model.NewAppError("SqlReferralPayoutStore.GetCreditedTotal", "store.sql_referral_payout.get_credited_total.app_error", nil, "inviter_id="+inviterId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = total
+		}
+	})
+}
+
+// GetFraudFlags compares the invitee with the inviter and returns what they share: a device
+// they signed in from, a delivery address or the card the orders were paid with.
+func (s SqlReferralPayoutStore) GetFraudFlags(inviteeId string, inviterId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		params := map[string]interface{}{"InviteeId": inviteeId, "InviterId": inviterId}
+		checks := []struct {
+			flag  string
+			query string
+		}{
+			{model.REFERRAL_FLAG_SHARED_DEVICE, `SELECT COUNT(*) FROM UserDevices d1
+				INNER JOIN UserDevices d2 ON d2.DeviceId = d1.DeviceId
+				WHERE d1.UserId = :InviteeId AND d2.UserId = :InviterId`},
+			{model.REFERRAL_FLAG_SHARED_DEVICE, `SELECT COUNT(*) FROM Sessions s1
+				INNER JOIN Sessions s2 ON s2.DeviceId = s1.DeviceId
+				WHERE s1.UserId = :InviteeId AND s2.UserId = :InviterId AND s1.DeviceId != ''`},
+			{model.REFERRAL_FLAG_SHARED_ADDRESS, `SELECT COUNT(*) FROM Addresses a1
+				INNER JOIN Addresses a2 ON LOWER(a2.City) = LOWER(a1.City) AND LOWER(a2.Street) = LOWER(a1.Street)
+					AND LOWER(a2.House) = LOWER(a1.House) AND LOWER(a2.Apartment) = LOWER(a1.Apartment)
+				WHERE a1.UserId = :InviteeId AND a2.UserId = :InviterId
+					AND a1.DeleteAt = 0 AND a2.DeleteAt = 0 AND a1.Street != ''`},
+			{model.REFERRAL_FLAG_SHARED_ADDRESS, `SELECT COUNT(*) FROM Orders o1
+				INNER JOIN Orders o2 ON LOWER(o2.Address) = LOWER(o1.Address)
+				WHERE o1.UserId = :InviteeId AND o2.UserId = :InviterId AND o1.Address != ''`},
+			{model.REFERRAL_FLAG_SHARED_CARD, `SELECT COUNT(*) FROM Orders o1
+				INNER JOIN Orders o2 ON o2.CardMask = o1.CardMask
+				WHERE o1.UserId = :InviteeId AND o2.UserId = :InviterId AND o1.CardMask != ''`},
+		}
+
+		flags := []string{}
+		for _, check := range checks {
+			if len(flags) > 0 && flags[len(flags)-1] == check.flag {
+				continue
+			}
+
+			count, err := s.GetReplica().SelectInt(check.query, params)
+			if err != nil {
+				result.Err = model.NewAppError("SqlReferralPayoutStore.GetFraudFlags", "store.sql_referral_payout.get_fraud_flags.app_error", nil, "invitee_id="+inviteeId+", inviter_id="+inviterId+", "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			if count > 0 {
+				flags = append(flags, check.flag)
+			}
+		}
+
+		result.Data = flags
+	})
+}
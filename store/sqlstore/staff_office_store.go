@@ -0,0 +1,125 @@
+package sqlstore
+
+import (
+	"net/http"
+
+	"im/model"
+	"im/store"
+)
+
+type SqlStaffOfficeStore struct {
+	SqlStore
+}
+
+func NewSqlStaffOfficeStore(sqlStore SqlStore) store.StaffOfficeStore {
+	s := &SqlStaffOfficeStore{
+		SqlStore: sqlStore,
+	}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.StaffOffice{}, "StaffOffice").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("OfficeId").SetMaxSize(26)
+		table.ColMap("AppId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlStaffOfficeStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_staff_office_user_id", "StaffOffice", "UserId")
+	s.CreateIndexIfNotExists("idx_staff_office_office_id", "StaffOffice", "OfficeId")
+	s.CreateIndexIfNotExists("idx_staff_office_app_id", "StaffOffice", "AppId")
+	s.CreateIndexIfNotExists("idx_staff_office_delete_at", "StaffOffice", "DeleteAt")
+}
+
+func (s SqlStaffOfficeStore) Save(so *model.StaffOffice) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		so.PreSave()
+		if result.Err = so.IsValid(); result.Err != nil {
+			return
+		}
+
+		if err := s.GetMaster().Insert(so); err != nil {
+			result.Err = model.NewAppError("SqlStaffOfficeStore.Save", "store.sql_staff_office.save.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = so
+		}
+	})
+}
+
+func (s SqlStaffOfficeStore) GetForUser(userId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var sos []*model.StaffOffice
+
+		if _, err := s.GetReplica().Select(&sos,
+			`SELECT * FROM StaffOffice WHERE UserId = :UserId AND DeleteAt = 0`,
+			map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlStaffOfficeStore.GetForUser",
+				"store.sql_staff_office.get_for_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = sos
+		}
+	})
+}
+
+func (s SqlStaffOfficeStore) GetForUsers(userIds []string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var sos []*model.StaffOffice
+
+		if len(userIds) == 0 {
+			result.Data = sos
+			return
+		}
+
+		keys, params := MapStringsToQueryParams(userIds, "UserId")
+
+		if _, err := s.GetReplica().Select(&sos,
+			`SELECT * FROM StaffOffice WHERE UserId IN `+keys+` AND DeleteAt = 0`, params); err != nil {
+			result.Err = model.NewAppError("SqlStaffOfficeStore.GetForUsers",
+				"store.sql_staff_office.get_for_users.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = sos
+		}
+	})
+}
+
+func (s SqlStaffOfficeStore) GetForOffice(officeId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var sos []*model.StaffOffice
+
+		if _, err := s.GetReplica().Select(&sos,
+			`SELECT * FROM StaffOffice WHERE OfficeId = :OfficeId AND DeleteAt = 0`,
+			map[string]interface{}{"OfficeId": officeId}); err != nil {
+			result.Err = model.NewAppError("SqlStaffOfficeStore.GetForOffice",
+				"store.sql_staff_office.get_for_office.app_error", nil, "office_id="+officeId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = sos
+		}
+	})
+}
+
+func (s SqlStaffOfficeStore) Delete(userId string, officeId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec(`UPDATE StaffOffice SET DeleteAt = :DeleteAt, UpdateAt = :UpdateAt WHERE UserId = :UserId AND OfficeId = :OfficeId AND DeleteAt = 0`,
+			map[string]interface{}{"DeleteAt": model.GetMillis(), "UpdateAt": model.GetMillis(), "UserId": userId, "OfficeId": officeId}); err != nil {
+			result.Err = model.NewAppError("SqlStaffOfficeStore.Delete",
+				"store.sql_staff_office.delete.app_error", nil, "user_id="+userId+", office_id="+officeId+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = userId
+		}
+	})
+}
+
+func (s SqlStaffOfficeStore) DeleteForUser(appId string, userId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec(`UPDATE StaffOffice SET DeleteAt = :DeleteAt, UpdateAt = :UpdateAt WHERE AppId = :AppId AND UserId = :UserId AND DeleteAt = 0`,
+			map[string]interface{}{"DeleteAt": model.GetMillis(), "UpdateAt": model.GetMillis(), "AppId": appId, "UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlStaffOfficeStore.DeleteForUser",
+				"store.sql_staff_office.delete_for_user.app_error", nil, "app_id="+appId+", user_id="+userId+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = userId
+		}
+	})
+}
@@ -61,6 +61,7 @@ type SqlStore interface {
 	CreateIndexIfNotExists(indexName string, tableName string, columnName string) bool
 	CreateCompositeIndexIfNotExists(indexName string, tableName string, columnNames []string) bool
 	CreateFullTextIndexIfNotExists(indexName string, tableName string, columnName string) bool
+	CreateFullTextIndexWithConfigIfNotExists(indexName string, tableName string, columnName string, textSearchConfig string) bool
 	RemoveIndexIfExists(indexName string, tableName string) bool
 	GetAllConns() []*gorp.DbMap
 	Close()
@@ -94,6 +95,7 @@ type SqlStore interface {
 	Basket() store.BasketStore
 
 	ProductOffice() store.ProductOfficeStore
+	StaffOffice() store.StaffOfficeStore
 
 	LinkMetadata() store.LinkMetadataStore
 	getQueryBuilder() sq.StatementBuilderType
@@ -100,6 +100,26 @@ type SqlSupplierOldStores struct {
 	level                store.LevelStore
 	extra                store.ExtraStore
 	productOffice        store.ProductOfficeStore
+	staffOffice          store.StaffOfficeStore
+	courierLocation      store.CourierLocationStore
+	address              store.AddressStore
+	webhookSubscription  store.WebhookSubscriptionStore
+	webhookDelivery      store.WebhookDeliveryStore
+	posProductMapping    store.PosProductMappingStore
+	posOrderSync         store.PosOrderSyncStore
+	campaign             store.CampaignStore
+	campaignRecipient    store.CampaignRecipientStore
+	lifecycleTrigger     store.LifecycleTriggerStore
+	lifecycleTriggerLog  store.LifecycleTriggerLogStore
+	loyaltyTier          store.LoyaltyTierStore
+	userTier             store.UserTierStore
+	referralPayout       store.ReferralPayoutStore
+	userDevice           store.UserDeviceStore
+	cardBinding          store.CardBindingStore
+	orderPayment         store.OrderPaymentStore
+	printTicket          store.PrintTicketStore
+	orderDocument        store.OrderDocumentStore
+	applicationConfig    store.ApplicationConfigStore
 }
 
 type SqlSupplier struct {
@@ -158,6 +178,26 @@ func NewSqlSupplier(settings model.SqlSettings) *SqlSupplier {
 	supplier.oldStores.extra = NewSqlExtraStore(supplier)
 	supplier.oldStores.application = NewSqlApplicationStore(supplier)
 	supplier.oldStores.productOffice = NewSqlProductOfficeStore(supplier)
+	supplier.oldStores.staffOffice = NewSqlStaffOfficeStore(supplier)
+	supplier.oldStores.courierLocation = NewSqlCourierLocationStore(supplier)
+	supplier.oldStores.address = NewSqlAddressStore(supplier)
+	supplier.oldStores.webhookSubscription = NewSqlWebhookSubscriptionStore(supplier)
+	supplier.oldStores.webhookDelivery = NewSqlWebhookDeliveryStore(supplier)
+	supplier.oldStores.posProductMapping = NewSqlPosProductMappingStore(supplier)
+	supplier.oldStores.posOrderSync = NewSqlPosOrderSyncStore(supplier)
+	supplier.oldStores.campaign = NewSqlCampaignStore(supplier)
+	supplier.oldStores.campaignRecipient = NewSqlCampaignRecipientStore(supplier)
+	supplier.oldStores.lifecycleTrigger = NewSqlLifecycleTriggerStore(supplier)
+	supplier.oldStores.lifecycleTriggerLog = NewSqlLifecycleTriggerLogStore(supplier)
+	supplier.oldStores.loyaltyTier = NewSqlLoyaltyTierStore(supplier)
+	supplier.oldStores.userTier = NewSqlUserTierStore(supplier)
+	supplier.oldStores.referralPayout = NewSqlReferralPayoutStore(supplier)
+	supplier.oldStores.userDevice = NewSqlUserDeviceStore(supplier)
+	supplier.oldStores.cardBinding = NewSqlCardBindingStore(supplier)
+	supplier.oldStores.orderPayment = NewSqlOrderPaymentStore(supplier)
+	supplier.oldStores.printTicket = NewSqlPrintTicketStore(supplier)
+	supplier.oldStores.orderDocument = NewSqlOrderDocumentStore(supplier)
+	supplier.oldStores.applicationConfig = NewSqlApplicationConfigStore(supplier)
 
 	initSqlSupplierRoles(supplier)
 	initSqlSupplierSchemes(supplier)
@@ -201,6 +241,26 @@ func NewSqlSupplier(settings model.SqlSettings) *SqlSupplier {
 	supplier.oldStores.extra.(*SqlExtraStore).CreateIndexesIfNotExists()
 	supplier.oldStores.preference.(*SqlPreferenceStore).DeleteUnusedFeatures()
 	supplier.oldStores.productOffice.(*SqlProductOfficeStore).CreateIndexesIfNotExists()
+	supplier.oldStores.staffOffice.(*SqlStaffOfficeStore).CreateIndexesIfNotExists()
+	supplier.oldStores.courierLocation.(*SqlCourierLocationStore).CreateIndexesIfNotExists()
+	supplier.oldStores.address.(*SqlAddressStore).CreateIndexesIfNotExists()
+	supplier.oldStores.webhookSubscription.(*SqlWebhookSubscriptionStore).CreateIndexesIfNotExists()
+	supplier.oldStores.webhookDelivery.(*SqlWebhookDeliveryStore).CreateIndexesIfNotExists()
+	supplier.oldStores.posProductMapping.(*SqlPosProductMappingStore).CreateIndexesIfNotExists()
+	supplier.oldStores.posOrderSync.(*SqlPosOrderSyncStore).CreateIndexesIfNotExists()
+	supplier.oldStores.campaign.(*SqlCampaignStore).CreateIndexesIfNotExists()
+	supplier.oldStores.campaignRecipient.(*SqlCampaignRecipientStore).CreateIndexesIfNotExists()
+	supplier.oldStores.lifecycleTrigger.(*SqlLifecycleTriggerStore).CreateIndexesIfNotExists()
+	supplier.oldStores.lifecycleTriggerLog.(*SqlLifecycleTriggerLogStore).CreateIndexesIfNotExists()
+	supplier.oldStores.loyaltyTier.(*SqlLoyaltyTierStore).CreateIndexesIfNotExists()
+	supplier.oldStores.userTier.(*SqlUserTierStore).CreateIndexesIfNotExists()
+	supplier.oldStores.referralPayout.(*SqlReferralPayoutStore).CreateIndexesIfNotExists()
+	supplier.oldStores.userDevice.(*SqlUserDeviceStore).CreateIndexesIfNotExists()
+	supplier.oldStores.cardBinding.(*SqlCardBindingStore).CreateIndexesIfNotExists()
+	supplier.oldStores.orderPayment.(*SqlOrderPaymentStore).CreateIndexesIfNotExists()
+	supplier.oldStores.printTicket.(*SqlPrintTicketStore).CreateIndexesIfNotExists()
+	supplier.oldStores.orderDocument.(*SqlOrderDocumentStore).CreateIndexesIfNotExists()
+	supplier.oldStores.applicationConfig.(*SqlApplicationConfigStore).CreateIndexesIfNotExists()
 
 	return supplier
 }
@@ -761,6 +821,29 @@ func (ss *SqlSupplier) CreateFullTextIndexIfNotExists(indexName string, tableNam
 	return ss.createIndexIfNotExists(indexName, tableName, []string{columnName}, INDEX_TYPE_FULL_TEXT, false)
 }
 
+// CreateFullTextIndexWithConfigIfNotExists creates the full text index with the given text search
+// configuration of PostgreSQL. MySQL has no such configurations and gets the plain FULLTEXT index.
+func (ss *SqlSupplier) CreateFullTextIndexWithConfigIfNotExists(indexName string, tableName string, columnName string, textSearchConfig string) bool {
+	if ss.DriverName() != model.DATABASE_DRIVER_POSTGRES {
+		return ss.createIndexIfNotExists(indexName, tableName, []string{columnName}, INDEX_TYPE_FULL_TEXT, false)
+	}
+
+	_, errExists := ss.GetMaster().SelectStr("SELECT $1::regclass", indexName)
+	// It should fail if the index does not exist
+	if errExists == nil {
+		return false
+	}
+
+	query := "CREATE INDEX " + indexName + " ON " + tableName + " USING gin(to_tsvector('" + textSearchConfig + "', " + convertMySQLFullTextColumnsToPostgres(columnName) + "))"
+	if _, err := ss.GetMaster().ExecNoTimeout(query); err != nil {
+		mlog.Critical(fmt.Sprintf("Failed to create index %v, %v", errExists, err))
+		time.Sleep(time.Second)
+		os.Exit(EXIT_CREATE_INDEX_POSTGRES)
+	}
+
+	return true
+}
+
 func (ss *SqlSupplier) createIndexIfNotExists(indexName string, tableName string, columnNames []string, indexType string, unique bool) bool {
 
 	uniqueStr := ""
@@ -1043,6 +1126,66 @@ func (ss *SqlSupplier) Extra() store.ExtraStore {
 func (ss *SqlSupplier) ProductOffice() store.ProductOfficeStore {
 	return ss.oldStores.productOffice
 }
+func (ss *SqlSupplier) StaffOffice() store.StaffOfficeStore {
+	return ss.oldStores.staffOffice
+}
+func (ss *SqlSupplier) CourierLocation() store.CourierLocationStore {
+	return ss.oldStores.courierLocation
+}
+func (ss *SqlSupplier) Address() store.AddressStore {
+	return ss.oldStores.address
+}
+func (ss *SqlSupplier) WebhookSubscription() store.WebhookSubscriptionStore {
+	return ss.oldStores.webhookSubscription
+}
+func (ss *SqlSupplier) WebhookDelivery() store.WebhookDeliveryStore {
+	return ss.oldStores.webhookDelivery
+}
+func (ss *SqlSupplier) PosProductMapping() store.PosProductMappingStore {
+	return ss.oldStores.posProductMapping
+}
+func (ss *SqlSupplier) PosOrderSync() store.PosOrderSyncStore {
+	return ss.oldStores.posOrderSync
+}
+func (ss *SqlSupplier) Campaign() store.CampaignStore {
+	return ss.oldStores.campaign
+}
+func (ss *SqlSupplier) CampaignRecipient() store.CampaignRecipientStore {
+	return ss.oldStores.campaignRecipient
+}
+func (ss *SqlSupplier) LifecycleTrigger() store.LifecycleTriggerStore {
+	return ss.oldStores.lifecycleTrigger
+}
+func (ss *SqlSupplier) LifecycleTriggerLog() store.LifecycleTriggerLogStore {
+	return ss.oldStores.lifecycleTriggerLog
+}
+func (ss *SqlSupplier) LoyaltyTier() store.LoyaltyTierStore {
+	return ss.oldStores.loyaltyTier
+}
+func (ss *SqlSupplier) UserTier() store.UserTierStore {
+	return ss.oldStores.userTier
+}
+func (ss *SqlSupplier) ReferralPayout() store.ReferralPayoutStore {
+	return ss.oldStores.referralPayout
+}
+func (ss *SqlSupplier) UserDevice() store.UserDeviceStore {
+	return ss.oldStores.userDevice
+}
+func (ss *SqlSupplier) CardBinding() store.CardBindingStore {
+	return ss.oldStores.cardBinding
+}
+func (ss *SqlSupplier) OrderPayment() store.OrderPaymentStore {
+	return ss.oldStores.orderPayment
+}
+func (ss *SqlSupplier) PrintTicket() store.PrintTicketStore {
+	return ss.oldStores.printTicket
+}
+func (ss *SqlSupplier) OrderDocument() store.OrderDocumentStore {
+	return ss.oldStores.orderDocument
+}
+func (ss *SqlSupplier) ApplicationConfig() store.ApplicationConfigStore {
+	return ss.oldStores.applicationConfig
+}
 func (ss *SqlSupplier) DropAllTables() {
 	ss.master.TruncateTables()
 }
@@ -1131,6 +1274,18 @@ func (me mattermConverter) FromDb(target interface{}) (gorp.CustomScanner, bool)
 			return json.Unmarshal(b, target)
 		}
 		return gorp.CustomScanner{Holder: new(string), Target: target, Binder: binder}, true
+	case **model.Address, **model.CampaignSegment, **model.LegalEntity, **model.ApplicationConfig:
+		binder := func(holder, target interface{}) error {
+			s, ok := holder.(*dbsql.NullString)
+			if !ok {
+				return errors.New(utils.T("store.sql.convert_string_interface"))
+			}
+			if !s.Valid || len(s.String) == 0 {
+				return nil
+			}
+			return json.Unmarshal([]byte(s.String), target)
+		}
+		return gorp.CustomScanner{Holder: new(dbsql.NullString), Target: target, Binder: binder}, true
 	}
 
 	return gorp.CustomScanner{}, false
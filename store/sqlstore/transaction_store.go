@@ -2,6 +2,7 @@ package sqlstore
 
 import (
 	"database/sql"
+	sq "github.com/Masterminds/squirrel"
 	"im/model"
 	"im/store"
 	"net/http"
@@ -28,6 +29,12 @@ func (s SqlTransactionStore) CreateIndexesIfNotExists() {
 	s.CreateIndexIfNotExists("idx_transactions_update_at", "Transactions", "UpdateAt")
 	s.CreateIndexIfNotExists("idx_transactions_create_at", "Transactions", "CreateAt")
 	s.CreateIndexIfNotExists("idx_transactions_delete_at", "Transactions", "DeleteAt")
+
+	// cursor pages: every filter is followed by the sort column and the id
+	s.CreateCompositeIndexIfNotExists("idx_transactions_create_at_id", "Transactions", []string{"CreateAt", "Id"})
+	s.CreateCompositeIndexIfNotExists("idx_transactions_value_id", "Transactions", []string{"Value", "Id"})
+	s.CreateCompositeIndexIfNotExists("idx_transactions_user_id_create_at", "Transactions", []string{"UserId", "CreateAt", "Id"})
+	s.CreateIndexIfNotExists("idx_transactions_order_id", "Transactions", "OrderId")
 }
 
 func (s *SqlTransactionStore) Save(transaction *model.Transaction) store.StoreChannel {
@@ -395,3 +402,132 @@ func (s SqlTransactionStore) GetMetricsForSpy(options model.UserGetOptions, begi
 		result.Data = metrics
 	})
 }
+
+// GetBalanceBefore sums the bonuses the customers of the application held at the given time.
+func (s SqlTransactionStore) GetBalanceBefore(appId string, before int64) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		balance, err := s.GetReplica().SelectFloat(
+			`SELECT COALESCE(SUM(t.Value), 0) FROM Transactions t
+			INNER JOIN Users u ON u.Id = t.UserId
+			WHERE u.AppId = :AppId AND t.DeleteAt = 0 AND t.CreateAt < :Before`,
+			map[string]interface{}{"AppId": appId, "Before": before})
+		if err != nil {
+			result.Err = model.NewAppError("SqlTransactionStore.GetBalanceBefore", "store.sql_transaction.get_balance_before.app_error", nil, "app_id="+appId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = balance
+	})
+}
+
+// GetBalanceMovements sums the accruals and the deductions of the period by day, days being
+// numbered from the epoch.
+func (s SqlTransactionStore) GetBalanceMovements(options model.ReportOptions) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var movements []*model.BalanceMovement
+		if _, err := s.GetReplica().Select(&movements,
+			`SELECT FLOOR((t.CreateAt + :Offset) / :DayMillis) AS Day,
+				COALESCE(SUM(CASE WHEN t.Value > 0 THEN t.Value ELSE 0 END), 0) AS Accrued,
+				COALESCE(SUM(CASE WHEN t.Value < 0 THEN -t.Value ELSE 0 END), 0) AS Deducted
+			FROM Transactions t
+			INNER JOIN Users u ON u.Id = t.UserId
+			WHERE u.AppId = :AppId AND t.DeleteAt = 0 AND t.CreateAt >= :BeginAt AND t.CreateAt <= :ExpireAt
+			GROUP BY Day
+			ORDER BY Day ASC`,
+			map[string]interface{}{
+				"AppId":     options.AppId,
+				"Offset":    int64(options.Offset) * 1000,
+				"DayMillis": model.REPORT_DAY_MILLIS,
+				"BeginAt":   options.BeginAt,
+				"ExpireAt":  options.ExpireAt,
+			}); err != nil {
+			result.Err = model.NewAppError("SqlTransactionStore.GetBalanceMovements", "store.sql_transaction.get_balance_movements.app_error", nil, "app_id="+options.AppId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = movements
+	})
+}
+
+// GetPage returns a cursor page of the transactions of the customers of the application that
+// pass the filters.
+func (s SqlTransactionStore) GetPage(options *model.TransactionCursorOptions) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		query := s.getQueryBuilder().
+			Select("t.*").
+			From("Transactions t").
+			Join("Users u ON t.UserId = u.Id").
+			Where(sq.Eq{"t.DeleteAt": 0, "u.AppId": options.AppId})
+
+		if len(options.UserId) > 0 {
+			query = query.Where(sq.Eq{"t.UserId": options.UserId})
+		}
+		switch options.Status {
+		case model.TRANSACTION_STATUS_ACTIVE:
+			query = query.Where(sq.Eq{"t.Active": true})
+		case model.TRANSACTION_STATUS_INACTIVE:
+			query = query.Where(sq.Eq{"t.Active": false})
+		}
+		if len(options.Type) > 0 {
+			query = query.Where(sq.Eq{"t.Type": options.Type})
+		}
+		if options.CreatedFrom > 0 {
+			query = query.Where(sq.GtOrEq{"t.CreateAt": options.CreatedFrom})
+		}
+		if options.CreatedTo > 0 {
+			query = query.Where(sq.LtOrEq{"t.CreateAt": options.CreatedTo})
+		}
+		if options.AmountFrom != nil {
+			query = query.Where(sq.GtOrEq{"t.Value": *options.AmountFrom})
+		}
+		if options.AmountTo != nil {
+			query = query.Where(sq.LtOrEq{"t.Value": *options.AmountTo})
+		}
+		if len(options.Terms) > 0 || len(options.OfficeId) > 0 || len(options.PaySystemId) > 0 {
+			query = query.LeftJoin("Orders o ON o.Id = t.OrderId")
+		}
+		if len(options.OfficeId) > 0 {
+			query = query.Where("EXISTS (SELECT 1 FROM Baskets b JOIN ProductOffice po ON po.ProductId = b.ProductId AND po.DeleteAt = 0 WHERE b.OrderId = o.Id AND b.DeleteAt = 0 AND po.OfficeId = ?)", options.OfficeId)
+		}
+		if len(options.PaySystemId) > 0 {
+			query = query.Where(sq.Eq{"o.PaySystemId": options.PaySystemId})
+		}
+		if len(options.Terms) > 0 {
+			query = query.Where(phoneOrNumberClause(options.Terms, "u.Phone", "o.CreateAt"))
+		}
+
+		query = applyPageCursor(query, options.Sort, options.After, options.Limit, "t")
+
+		queryString, args, err := query.ToSql()
+		if err != nil {
+			result.Err = model.NewAppError("SqlTransactionStore.GetPage", "store.sql_transaction.get_page.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var transactions []*model.Transaction
+		if _, err := s.GetReplica().Select(&transactions, queryString, args...); err != nil {
+			result.Err = model.NewAppError("SqlTransactionStore.GetPage", "store.sql_transaction.get_page.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		list := model.NewTransactionList()
+		list.NextCursor = pageCursorAfter(len(transactions), options.Limit, options.Sort, func(i int) (float64, string) {
+			if options.Sort.Column == "Value" {
+				return transactions[i].Value, transactions[i].Id
+			}
+			return float64(transactions[i].CreateAt), transactions[i].Id
+		})
+
+		if len(transactions) > options.Limit {
+			transactions = transactions[:options.Limit]
+		}
+
+		for _, t := range transactions {
+			list.AddTransaction(t)
+			list.AddOrder(t.Id)
+		}
+		list.MakeNonNil()
+
+		result.Data = list
+	})
+}
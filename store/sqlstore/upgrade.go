@@ -805,11 +805,82 @@ func UpgradeDatabaseToVersion526(sqlStore SqlStore) {
 		sqlStore.CreateColumnIfNotExists("Applications", "SmsApiKey", "varchar(255)", "varchar(255)", "")
 
 		sqlStore.CreateColumnIfNotExists("Orders", "PaySystemOrderNum", "varchar(255)", "varchar(255)", "")
+		sqlStore.CreateColumnIfNotExists("Orders", "CourierId", "varchar(26)", "varchar(26)", "")
+		sqlStore.CreateColumnIfNotExists("Orders", "CourierAssignedAt", "bigint", "bigint", "0")
+		sqlStore.CreateColumnIfNotExists("Orders", "HandoverCode", "varchar(16)", "varchar(16)", "")
+		sqlStore.CreateColumnIfNotExists("Orders", "HandoverAt", "bigint", "bigint", "0")
+		sqlStore.CreateColumnIfNotExists("Orders", "Latitude", "double", "double precision", "0")
+		sqlStore.CreateColumnIfNotExists("Orders", "Longitude", "double", "double precision", "0")
+		sqlStore.CreateColumnIfNotExists("Orders", "AddressId", "varchar(26)", "varchar(26)", "")
+		sqlStore.CreateColumnIfNotExistsNoDefault("Orders", "DeliveryAddress", "text", "text")
+
+		sqlStore.CreateColumnIfNotExists("Applications", "PosType", "varchar(64)", "varchar(64)", "")
+		sqlStore.CreateColumnIfNotExists("Applications", "PosUrl", "varchar(1000)", "varchar(1000)", "")
+		sqlStore.CreateColumnIfNotExists("Applications", "PosApiKey", "varchar(255)", "varchar(255)", "")
+
+		sqlStore.CreateColumnIfNotExists("Applications", "TierMetric", "varchar(32)", "varchar(32)", "")
+		sqlStore.CreateColumnIfNotExists("Applications", "TierPeriodDays", "int", "int", "0")
+
+		sqlStore.CreateColumnIfNotExists("Applications", "ReferralMonthlyCap", "double", "double precision", "0")
+		sqlStore.CreateColumnIfNotExists("Orders", "CardMask", "varchar(32)", "varchar(32)", "")
+
+		sqlStore.CreateColumnIfNotExists("Products", "VatRate", "varchar(16)", "varchar(16)", "")
+		sqlStore.CreateColumnIfNotExists("Products", "PaymentSubject", "varchar(16)", "varchar(16)", "")
+		sqlStore.CreateColumnIfNotExists("Products", "PaymentMethod", "varchar(16)", "varchar(16)", "")
+		sqlStore.CreateColumnIfNotExists("Applications", "FiscalReceipts", "tinyint(1)", "boolean", "0")
+		sqlStore.CreateColumnIfNotExists("Applications", "TaxSystem", "int", "int", "0")
+		sqlStore.CreateColumnIfNotExists("Orders", "ReceiptStatus", "varchar(16)", "varchar(16)", "")
+		sqlStore.CreateColumnIfNotExists("Orders", "ReceiptQr", "varchar(128)", "varchar(128)", "")
+		sqlStore.CreateColumnIfNotExists("Orders", "ReceiptUrl", "varchar(255)", "varchar(255)", "")
+		sqlStore.CreateColumnIfNotExists("Orders", "ReceiptAt", "bigint", "bigint", "0")
+
+		sqlStore.CreateColumnIfNotExists("Applications", "AqMerchant", "varchar(64)", "varchar(64)", "")
+		sqlStore.CreateColumnIfNotExists("Products", "Tags", "varchar(500)", "varchar(500)", "[]")
+		sqlStore.GetMaster().Exec("UPDATE Products SET Tags = '[]' WHERE Tags IS NULL OR Tags = ''")
+
+		// the modifiers are read as a JSON array, a row without one could not be loaded
+		sqlStore.CreateColumnIfNotExists("Baskets", "Modifiers", "varchar(2000)", "varchar(2000)", "[]")
+		sqlStore.GetMaster().Exec("UPDATE Baskets SET Modifiers = '[]' WHERE Modifiers IS NULL OR Modifiers = ''")
+		sqlStore.CreateColumnIfNotExists("Baskets", "Comment", "varchar(500)", "varchar(500)", "")
+		sqlStore.CreateColumnIfNotExists("Offices", "PrintFormat", "varchar(16)", "varchar(16)", "")
+		sqlStore.CreateColumnIfNotExists("Offices", "PrintPaperWidth", "int", "int", "0")
+		sqlStore.CreateColumnIfNotExists("Offices", "PrintAgentKey", "varchar(64)", "varchar(64)", "")
+		if sqlStore.CreateColumnIfNotExists("PrintTickets", "Copy", "int", "int", "0") {
+			numberPrintTicketCopies(sqlStore)
+		}
 
 		//saveSchemaVersion(sqlStore, VERSION_5_26_0)
 	}
 }
 
+// numberPrintTicketCopies numbers the tickets queued before the copies were kept, so an office
+// has one ticket of each copy per order once the unique index is created.
+func numberPrintTicketCopies(sqlStore SqlStore) {
+	var tickets []*model.PrintTicket
+	if _, err := sqlStore.GetMaster().Select(&tickets, "SELECT * FROM PrintTickets ORDER BY CreateAt ASC, Id ASC"); err != nil {
+		mlog.Critical("Failed to get the print tickets to number their copies", mlog.Err(err))
+		time.Sleep(time.Second)
+		os.Exit(EXIT_GENERIC_FAILURE)
+	}
+
+	copies := make(map[string]int)
+	for _, ticket := range tickets {
+		key := ticket.OrderId + ticket.OfficeId
+		number := copies[key]
+		copies[key] = number + 1
+
+		if number == 0 {
+			continue
+		}
+
+		if _, err := sqlStore.GetMaster().Exec("UPDATE PrintTickets SET Copy = :Copy WHERE Id = :Id", map[string]interface{}{"Copy": number, "Id": ticket.Id}); err != nil {
+			mlog.Critical("Failed to number the copy of the print ticket", mlog.String("ticket_id", ticket.Id), mlog.Err(err))
+			time.Sleep(time.Second)
+			os.Exit(EXIT_GENERIC_FAILURE)
+		}
+	}
+}
+
 func UpgradeDatabaseToVersion527(sqlStore SqlStore) {
 	/*if shouldPerformUpgrade(sqlStore, VERSION_5_26_0, VERSION_5_27_0) {
 		saveSchemaVersion(sqlStore, VERSION_5_27_0)
@@ -0,0 +1,54 @@
+package sqlstore
+
+import (
+	"net/http"
+
+	"im/model"
+	"im/store"
+)
+
+type SqlUserDeviceStore struct {
+	SqlStore
+}
+
+func NewSqlUserDeviceStore(sqlStore SqlStore) store.UserDeviceStore {
+	s := &SqlUserDeviceStore{
+		SqlStore: sqlStore,
+	}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.UserDevice{}, "UserDevices").SetKeys(false, "UserId", "DeviceId")
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("DeviceId").SetMaxSize(512)
+	}
+
+	return s
+}
+
+func (s SqlUserDeviceStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_user_devices_device_id", "UserDevices", "DeviceId")
+}
+
+// Save remembers the device of the user, touching UpdateAt when it is already known.
+func (s SqlUserDeviceStore) Save(device *model.UserDevice) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		device.UpdateAt = model.GetMillis()
+
+		res, err := s.GetMaster().Exec(`UPDATE UserDevices SET UpdateAt = :UpdateAt WHERE UserId = :UserId AND DeviceId = :DeviceId`,
+			map[string]interface{}{"UpdateAt": device.UpdateAt, "UserId": device.UserId, "DeviceId": device.DeviceId})
+		if err != nil {
+			result.Err = model.NewAppError("SqlUserDeviceStore.Save", "store.sql_user_device.save.update.app_error", nil, "user_id="+device.UserId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if count, _ := res.RowsAffected(); count == 0 {
+			device.CreateAt = device.UpdateAt
+			if err := s.GetMaster().Insert(device); err != nil && !IsUniqueConstraintError(err, []string{"PRIMARY", "userdevices_pkey"}) {
+				result.Err = model.NewAppError("SqlUserDeviceStore.Save", "store.sql_user_device.save.insert.app_error", nil, "user_id="+device.UserId+", "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		result.Data = device
+	})
+}
@@ -0,0 +1,109 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+
+	"im/model"
+	"im/store"
+)
+
+type SqlUserTierStore struct {
+	SqlStore
+}
+
+func NewSqlUserTierStore(sqlStore SqlStore) store.UserTierStore {
+	s := &SqlUserTierStore{
+		SqlStore: sqlStore,
+	}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.UserTier{}, "UserTiers").SetKeys(false, "UserId")
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("AppId").SetMaxSize(26)
+		table.ColMap("TierId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlUserTierStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_user_tiers_app_id", "UserTiers", "AppId")
+}
+
+// Save stores the tier of the customer, replacing the previous one.
+func (s SqlUserTierStore) Save(userTier *model.UserTier) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		userTier.PreSave()
+
+		count, err := s.GetMaster().Update(userTier)
+		if err != nil {
+			result.Err = model.NewAppError("SqlUserTierStore.Save", "store.sql_user_tier.save.update.app_error", nil, "user_id="+userTier.UserId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if count == 0 {
+			if err := s.GetMaster().Insert(userTier); err != nil {
+				result.Err = model.NewAppError("SqlUserTierStore.Save", "store.sql_user_tier.save.insert.app_error", nil, "user_id="+userTier.UserId+", "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		result.Data = userTier
+	})
+}
+
+func (s SqlUserTierStore) Get(userId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var userTier *model.UserTier
+		if err := s.GetReplica().SelectOne(&userTier,
+			`SELECT * FROM UserTiers WHERE UserId = :UserId`, map[string]interface{}{"UserId": userId}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlUserTierStore.Get", "store.sql_user_tier.get.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlUserTierStore.Get", "store.sql_user_tier.get.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = userTier
+		}
+	})
+}
+
+// GetCustomerSpend pages through the customers of the application with the total of their
+// shipped orders since the given time and their current tier. Customers without such orders
+// come with zeroes.
+func (s SqlUserTierStore) GetCustomerSpend(appId string, since int64, afterId string, limit int) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		isPostgreSQL := s.DriverName() == model.DATABASE_DRIVER_POSTGRES
+
+		query := s.getQueryBuilder().
+			Select("u.Id AS UserId", "COALESCE(SUM(o.Price), 0) AS Spend", "COUNT(o.Id) AS OrderCount",
+				"COALESCE(ut.TierId, '') AS TierId", "COALESCE(ut.Spend, 0) AS PrevSpend", "COALESCE(ut.OrderCount, 0) AS PrevOrderCount").
+			From("Users u").
+			LeftJoin("Orders o ON o.UserId = u.Id AND o.Status = ? AND o.Canceled = ? AND o.DeleteAt = 0 AND o.CreateAt >= ?",
+				model.ORDER_STATUS_SHIPPED, false, since).
+			LeftJoin("UserTiers ut ON ut.UserId = u.Id").
+			Where("u.AppId = ?", appId).
+			Where("u.DeleteAt = 0").
+			Where("u.Id > ?", afterId).
+			GroupBy("u.Id", "ut.TierId", "ut.Spend", "ut.OrderCount").
+			OrderBy("u.Id ASC").
+			Limit(uint64(limit))
+
+		query = applyRoleFilter(query, model.CHANNEL_USER_ROLE_ID, isPostgreSQL)
+
+		queryString, args, err := query.ToSql()
+		if err != nil {
+			result.Err = model.NewAppError("SqlUserTierStore.GetCustomerSpend", "store.sql_user_tier.get_customer_spend.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var spends []*model.CustomerSpend
+		if _, err := s.GetReplica().Select(&spends, queryString, args...); err != nil {
+			result.Err = model.NewAppError("SqlUserTierStore.GetCustomerSpend", "store.sql_user_tier.get_customer_spend.app_error", nil, "app_id="+appId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = spends
+	})
+}
@@ -0,0 +1,162 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+
+	"im/model"
+	"im/store"
+)
+
+type SqlWebhookDeliveryStore struct {
+	SqlStore
+}
+
+func NewSqlWebhookDeliveryStore(sqlStore SqlStore) store.WebhookDeliveryStore {
+	s := &SqlWebhookDeliveryStore{
+		SqlStore: sqlStore,
+	}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.WebhookDelivery{}, "WebhookDeliveries").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("SubscriptionId").SetMaxSize(26)
+		table.ColMap("AppId").SetMaxSize(26)
+		table.ColMap("Event").SetMaxSize(64)
+		table.ColMap("Payload").SetMaxSize(65535)
+		table.ColMap("Status").SetMaxSize(16)
+		table.ColMap("LastError").SetMaxSize(model.WEBHOOK_DELIVERY_ERROR_MAX_SIZE)
+	}
+
+	return s
+}
+
+func (s SqlWebhookDeliveryStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_webhook_deliveries_subscription_id", "WebhookDeliveries", "SubscriptionId")
+	s.CreateIndexIfNotExists("idx_webhook_deliveries_status", "WebhookDeliveries", "Status")
+	s.CreateIndexIfNotExists("idx_webhook_deliveries_next_attempt_at", "WebhookDeliveries", "NextAttemptAt")
+	s.CreateIndexIfNotExists("idx_webhook_deliveries_create_at", "WebhookDeliveries", "CreateAt")
+}
+
+func (s SqlWebhookDeliveryStore) Save(delivery *model.WebhookDelivery) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		delivery.PreSave()
+		if result.Err = delivery.IsValid(); result.Err != nil {
+			return
+		}
+
+		if err := s.GetMaster().Insert(delivery); err != nil {
+			result.Err = model.NewAppError("SqlWebhookDeliveryStore.Save", "store.sql_webhook_delivery.save.app_error", nil, "id="+delivery.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = delivery
+		}
+	})
+}
+
+func (s SqlWebhookDeliveryStore) Update(delivery *model.WebhookDelivery) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		delivery.PreUpdate()
+		if result.Err = delivery.IsValid(); result.Err != nil {
+			return
+		}
+
+		if _, err := s.GetMaster().Update(delivery); err != nil {
+			result.Err = model.NewAppError("SqlWebhookDeliveryStore.Update", "store.sql_webhook_delivery.update.app_error", nil, "id="+delivery.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = delivery
+		}
+	})
+}
+
+func (s SqlWebhookDeliveryStore) Get(id string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var delivery model.WebhookDelivery
+
+		if err := s.GetReplica().SelectOne(&delivery,
+			`SELECT * FROM WebhookDeliveries WHERE Id = :Id`, map[string]interface{}{"Id": id}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlWebhookDeliveryStore.Get", "store.sql_webhook_delivery.get.app_error", nil, "id="+id+", "+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlWebhookDeliveryStore.Get", "store.sql_webhook_delivery.get.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		result.Data = &delivery
+	})
+}
+
+func (s SqlWebhookDeliveryStore) GetBySubscriptionId(subscriptionId string, status string, offset int, limit int) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var deliveries []*model.WebhookDelivery
+
+		query := s.getQueryBuilder().
+			Select("*").
+			From("WebhookDeliveries").
+			Where("SubscriptionId = ?", subscriptionId).
+			OrderBy("CreateAt DESC").
+			Offset(uint64(offset)).
+			Limit(uint64(limit))
+
+		if len(status) > 0 {
+			query = query.Where("Status = ?", status)
+		}
+
+		queryString, args, err := query.ToSql()
+		if err != nil {
+			result.Err = model.NewAppError("SqlWebhookDeliveryStore.GetBySubscriptionId", "store.sql_webhook_delivery.get_by_subscription_id.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if _, err := s.GetReplica().Select(&deliveries, queryString, args...); err != nil {
+			result.Err = model.NewAppError("SqlWebhookDeliveryStore.GetBySubscriptionId", "store.sql_webhook_delivery.get_by_subscription_id.app_error", nil, "subscription_id="+subscriptionId+", "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = deliveries
+	})
+}
+
+// GetDue returns the pending and failed deliveries whose next attempt time has come.
+func (s SqlWebhookDeliveryStore) GetDue(time int64, limit int) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var deliveries []*model.WebhookDelivery
+
+		if _, err := s.GetMaster().Select(&deliveries,
+			`SELECT * FROM WebhookDeliveries
+			WHERE Status IN (:Pending, :Failed) AND NextAttemptAt <= :Time
+			ORDER BY NextAttemptAt ASC
+			LIMIT :Limit`,
+			map[string]interface{}{
+				"Pending": model.WEBHOOK_DELIVERY_STATUS_PENDING,
+				"Failed":  model.WEBHOOK_DELIVERY_STATUS_FAILED,
+				"Time":    time,
+				"Limit":   limit,
+			}); err != nil {
+			result.Err = model.NewAppError("SqlWebhookDeliveryStore.GetDue", "store.sql_webhook_delivery.get_due.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = deliveries
+	})
+}
+
+// PermanentDeleteBefore removes the successful deliveries created before the time, failed ones are kept for replay.
+func (s SqlWebhookDeliveryStore) PermanentDeleteBefore(time int64) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		sqlResult, err := s.GetMaster().Exec(`DELETE FROM WebhookDeliveries WHERE Status = :Status AND CreateAt < :Time`,
+			map[string]interface{}{"Status": model.WEBHOOK_DELIVERY_STATUS_SUCCESS, "Time": time})
+		if err != nil {
+			result.Err = model.NewAppError("SqlWebhookDeliveryStore.PermanentDeleteBefore", "store.sql_webhook_delivery.permanent_delete_before.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		rowsAffected, err := sqlResult.RowsAffected()
+		if err != nil {
+			result.Err = model.NewAppError("SqlWebhookDeliveryStore.PermanentDeleteBefore", "store.sql_webhook_delivery.permanent_delete_before.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = rowsAffected
+	})
+}
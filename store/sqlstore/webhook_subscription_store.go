@@ -0,0 +1,115 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+
+	"im/model"
+	"im/store"
+)
+
+type SqlWebhookSubscriptionStore struct {
+	SqlStore
+}
+
+func NewSqlWebhookSubscriptionStore(sqlStore SqlStore) store.WebhookSubscriptionStore {
+	s := &SqlWebhookSubscriptionStore{
+		SqlStore: sqlStore,
+	}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.WebhookSubscription{}, "WebhookSubscriptions").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("AppId").SetMaxSize(26)
+		table.ColMap("CreatorId").SetMaxSize(26)
+		table.ColMap("Url").SetMaxSize(model.WEBHOOK_SUBSCRIPTION_URL_MAX_LENGTH)
+		table.ColMap("Events").SetMaxSize(model.WEBHOOK_SUBSCRIPTION_EVENTS_MAX_LENGTH)
+		table.ColMap("Secret").SetMaxSize(model.WEBHOOK_SUBSCRIPTION_SECRET_LENGTH)
+		table.ColMap("Description").SetMaxSize(model.WEBHOOK_SUBSCRIPTION_DESCRIPTION_MAX_RUNES)
+	}
+
+	return s
+}
+
+func (s SqlWebhookSubscriptionStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_webhook_subscriptions_app_id", "WebhookSubscriptions", "AppId")
+	s.CreateIndexIfNotExists("idx_webhook_subscriptions_delete_at", "WebhookSubscriptions", "DeleteAt")
+}
+
+func (s SqlWebhookSubscriptionStore) Save(subscription *model.WebhookSubscription) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if len(subscription.Id) > 0 {
+			result.Err = model.NewAppError("SqlWebhookSubscriptionStore.Save", "store.sql_webhook_subscription.save.existing.app_error", nil, "id="+subscription.Id, http.StatusBadRequest)
+			return
+		}
+
+		subscription.PreSave()
+		if result.Err = subscription.IsValid(); result.Err != nil {
+			return
+		}
+
+		if err := s.GetMaster().Insert(subscription); err != nil {
+			result.Err = model.NewAppError("SqlWebhookSubscriptionStore.Save", "store.sql_webhook_subscription.save.app_error", nil, "id="+subscription.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = subscription
+		}
+	})
+}
+
+func (s SqlWebhookSubscriptionStore) Update(subscription *model.WebhookSubscription) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		subscription.PreUpdate()
+		if result.Err = subscription.IsValid(); result.Err != nil {
+			return
+		}
+
+		if _, err := s.GetMaster().Update(subscription); err != nil {
+			result.Err = model.NewAppError("SqlWebhookSubscriptionStore.Update", "store.sql_webhook_subscription.update.app_error", nil, "id="+subscription.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = subscription
+		}
+	})
+}
+
+func (s SqlWebhookSubscriptionStore) Get(id string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var subscription model.WebhookSubscription
+
+		if err := s.GetReplica().SelectOne(&subscription,
+			`SELECT * FROM WebhookSubscriptions WHERE Id = :Id AND DeleteAt = 0`, map[string]interface{}{"Id": id}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlWebhookSubscriptionStore.Get", "store.sql_webhook_subscription.get.app_error", nil, "id="+id+", "+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlWebhookSubscriptionStore.Get", "store.sql_webhook_subscription.get.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		result.Data = &subscription
+	})
+}
+
+func (s SqlWebhookSubscriptionStore) GetByAppId(appId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var subscriptions []*model.WebhookSubscription
+
+		if _, err := s.GetReplica().Select(&subscriptions,
+			`SELECT * FROM WebhookSubscriptions WHERE AppId = :AppId AND DeleteAt = 0 ORDER BY CreateAt ASC`,
+			map[string]interface{}{"AppId": appId}); err != nil {
+			result.Err = model.NewAppError("SqlWebhookSubscriptionStore.GetByAppId", "store.sql_webhook_subscription.get_by_app_id.app_error", nil, "app_id="+appId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = subscriptions
+		}
+	})
+}
+
+func (s SqlWebhookSubscriptionStore) Delete(id string, time int64) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec(`UPDATE WebhookSubscriptions SET DeleteAt = :DeleteAt, UpdateAt = :UpdateAt WHERE Id = :Id AND DeleteAt = 0`,
+			map[string]interface{}{"DeleteAt": time, "UpdateAt": time, "Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlWebhookSubscriptionStore.Delete", "store.sql_webhook_subscription.delete.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = id
+		}
+	})
+}
@@ -82,6 +82,26 @@ type Store interface {
 	Extra() ExtraStore
 
 	ProductOffice() ProductOfficeStore
+	StaffOffice() StaffOfficeStore
+	CourierLocation() CourierLocationStore
+	Address() AddressStore
+	WebhookSubscription() WebhookSubscriptionStore
+	WebhookDelivery() WebhookDeliveryStore
+	PosProductMapping() PosProductMappingStore
+	PosOrderSync() PosOrderSyncStore
+	Campaign() CampaignStore
+	CampaignRecipient() CampaignRecipientStore
+	LifecycleTrigger() LifecycleTriggerStore
+	LifecycleTriggerLog() LifecycleTriggerLogStore
+	LoyaltyTier() LoyaltyTierStore
+	UserTier() UserTierStore
+	ReferralPayout() ReferralPayoutStore
+	UserDevice() UserDeviceStore
+	CardBinding() CardBindingStore
+	OrderPayment() OrderPaymentStore
+	PrintTicket() PrintTicketStore
+	OrderDocument() OrderDocumentStore
+	ApplicationConfig() ApplicationConfigStore
 }
 
 type TeamStore interface {
@@ -262,6 +282,7 @@ type PostStore interface {
 	GetAllMessagesSince(userId string, time int64, allowFromCache bool, limitMin int64) StoreChannel
 
 	FindPostWithOrder(orderId string) StoreChannel
+	FindPostsWithOrders(orderIds []string) StoreChannel
 }
 
 type UserStore interface {
@@ -452,6 +473,7 @@ type FileInfoStore interface {
 	GetByPath(path string) StoreChannel
 	GetForPost(postId string, readFromMaster bool, allowFromCache bool) StoreChannel
 	GetForMetadata(metadataId string, readFromMaster bool, allowFromCache bool) StoreChannel
+	GetForMetadataIds(metadataIds []string) StoreChannel
 
 	GetForUser(userId string) StoreChannel
 	InvalidateFileInfosForPostCache(postId string)
@@ -471,6 +493,7 @@ type ProductOfficeStore interface {
 	Save(productOffice *model.ProductOffice) StoreChannel
 	Get(id string) StoreChannel
 	GetForProduct(productId string, readFromMaster bool, allowFromCache bool) StoreChannel
+	GetOfficesForProducts(productIds []string) StoreChannel
 	DeleteForProduct(productId string) StoreChannel
 
 	/*AttachToPost(productOfficeId string, postId string, creatorId string) StoreChannel
@@ -552,8 +575,9 @@ type ProductStore interface {
 	GetAll() StoreChannel
 	/*	Publish(product *model.Product) StoreChannel
 		GetExtras(product *model.Product) StoreChannel*/
-	Search(categoryId, terms string, page, perPage int) StoreChannel
+	Search(appId string, params *model.ProductSearchParams) StoreChannel
 	GetForModeration(options *model.ProductGetOptions) StoreChannel
+	GetAppIds() StoreChannel
 }
 
 type CategoryStore interface {
@@ -635,9 +659,12 @@ type TransactionStore interface {
 	GetAllTransactionsAfter(transactionId string, numTransactions int, offset int) StoreChannel
 	//GetByUserId(userId string, offset int, limit int, order model.ColumnOrder) StoreChannel
 	GetByUserId(options model.TransactionGetOptions) StoreChannel
+	GetPage(options *model.TransactionCursorOptions) StoreChannel
 
 	GetBonusTransactionsForUser(orderUserId string, userId string) StoreChannel
 	GetMetricsForSpy(options model.UserGetOptions, beginAt int64, expireAt int64) StoreChannel
+	GetBalanceBefore(appId string, before int64) StoreChannel
+	GetBalanceMovements(options model.ReportOptions) StoreChannel
 }
 
 type OrderStore interface {
@@ -661,18 +688,30 @@ type OrderStore interface {
 
 	//GetByUserId(userId string, offset int, limit int, order model.ColumnOrder) StoreChannel
 	GetByUserId(options model.OrderGetOptions) StoreChannel
+	GetPage(options *model.OrderCursorOptions) StoreChannel
 
 	SetOrderPayed(orderId string) StoreChannel
 	SetOrderCancel(orderId string) StoreChannel
+	SetCardMask(orderId string, cardMask string) StoreChannel
+	SetReceipt(orderId string, receipt *model.OrderReceipt, receiptAt int64) StoreChannel
+	GetPendingReceipts(limit int) StoreChannel
 
 	Count(options model.OrderCountOptions) StoreChannel
+	GetActiveForCouriers(courierIds []string) StoreChannel
 
 	GetMetricsForOrders(appId string, beginAt int64, expireAt int64) StoreChannel
+
+	GetCohortCounts(options model.ReportOptions, firstCohort int64, lastCohort int64) StoreChannel
+	GetCustomerRfm(options model.ReportOptions) StoreChannel
+	GetProductSales(options model.ReportOptions) StoreChannel
+	GetCategorySales(options model.ReportOptions) StoreChannel
+	GetChecksByHour(options model.ReportOptions) StoreChannel
 }
 
 type BasketStore interface {
 	Save(basket *model.Basket) StoreChannel
 	GetByOrderId(orderId string) StoreChannel
+	GetByOrderIds(orderIds []string) StoreChannel
 	GetByUserId(userId string) StoreChannel
 	Update(newBasket *model.Basket) StoreChannel
 	Overwrite(basket *model.Basket) StoreChannel
@@ -712,6 +751,161 @@ type ExtraStore interface {
 	GetAllExtrasAfter(extraId string, numExtras int, offset int) StoreChannel
 
 	GetExtraProductsByIds(productIds []string, allowFromCache bool) StoreChannel
+	GetExtraProductsForProducts(productIds []string) StoreChannel
 
 	DeleteForProduct(productId string) StoreChannel
 }
+
+type StaffOfficeStore interface {
+	Save(staffOffice *model.StaffOffice) StoreChannel
+	GetForUser(userId string) StoreChannel
+	GetForUsers(userIds []string) StoreChannel
+	GetForOffice(officeId string) StoreChannel
+	Delete(userId string, officeId string) StoreChannel
+	DeleteForUser(appId string, userId string) StoreChannel
+}
+
+type CourierLocationStore interface {
+	Save(location *model.CourierLocation) StoreChannel
+	Get(userId string) StoreChannel
+	GetForUsers(userIds []string) StoreChannel
+}
+
+type AddressStore interface {
+	Save(address *model.Address) StoreChannel
+	Update(address *model.Address) StoreChannel
+	Get(addressId string) StoreChannel
+	GetByUserId(userId string) StoreChannel
+	Delete(addressId string, time int64) StoreChannel
+}
+
+type WebhookSubscriptionStore interface {
+	Save(subscription *model.WebhookSubscription) StoreChannel
+	Update(subscription *model.WebhookSubscription) StoreChannel
+	Get(id string) StoreChannel
+	GetByAppId(appId string) StoreChannel
+	Delete(id string, time int64) StoreChannel
+}
+
+type WebhookDeliveryStore interface {
+	Save(delivery *model.WebhookDelivery) StoreChannel
+	Update(delivery *model.WebhookDelivery) StoreChannel
+	Get(id string) StoreChannel
+	GetBySubscriptionId(subscriptionId string, status string, offset int, limit int) StoreChannel
+	GetDue(time int64, limit int) StoreChannel
+	PermanentDeleteBefore(time int64) StoreChannel
+}
+
+type PosProductMappingStore interface {
+	Save(mapping *model.PosProductMapping) StoreChannel
+	GetForApp(appId string) StoreChannel
+	GetForProducts(appId string, productIds []string) StoreChannel
+	Delete(appId string, productId string) StoreChannel
+}
+
+type PosOrderSyncStore interface {
+	Save(sync *model.PosOrderSync) StoreChannel
+	Get(orderId string) StoreChannel
+	GetByExternalId(appId string, externalId string) StoreChannel
+	GetForApp(appId string, status string, offset int, limit int) StoreChannel
+}
+
+type CampaignStore interface {
+	Save(campaign *model.Campaign) StoreChannel
+	Update(campaign *model.Campaign) StoreChannel
+	Get(id string) StoreChannel
+	GetForApp(appId string, offset int, limit int) StoreChannel
+	GetDue(time int64) StoreChannel
+	Delete(id string, time int64) StoreChannel
+	GetSegmentUserIds(appId string, segment *model.CampaignSegment, afterId string, limit int) StoreChannel
+	CountSegment(appId string, segment *model.CampaignSegment) StoreChannel
+}
+
+type CampaignRecipientStore interface {
+	SaveMultiple(recipients []*model.CampaignRecipient) StoreChannel
+	Update(recipient *model.CampaignRecipient) StoreChannel
+	GetPending(campaignId string, limit int) StoreChannel
+	GetForCampaign(campaignId string, status string, offset int, limit int) StoreChannel
+	GetStats(campaignId string) StoreChannel
+	SetOpened(campaignId string, userId string, time int64) StoreChannel
+	DeleteForCampaign(campaignId string) StoreChannel
+}
+
+type LifecycleTriggerStore interface {
+	Save(trigger *model.LifecycleTrigger) StoreChannel
+	Update(trigger *model.LifecycleTrigger) StoreChannel
+	Get(id string) StoreChannel
+	GetForApp(appId string) StoreChannel
+	GetEnabled() StoreChannel
+	Delete(id string, time int64) StoreChannel
+	GetCandidates(trigger *model.LifecycleTrigger, now int64, limit int) StoreChannel
+}
+
+type LifecycleTriggerLogStore interface {
+	Save(log *model.LifecycleTriggerLog) StoreChannel
+	Update(log *model.LifecycleTriggerLog) StoreChannel
+	GetForTrigger(triggerId string, offset int, limit int) StoreChannel
+}
+
+type LoyaltyTierStore interface {
+	Save(tier *model.LoyaltyTier) StoreChannel
+	Update(tier *model.LoyaltyTier) StoreChannel
+	Get(id string) StoreChannel
+	GetForApp(appId string) StoreChannel
+	GetAppIds() StoreChannel
+	Delete(id string, time int64) StoreChannel
+}
+
+type UserTierStore interface {
+	Save(userTier *model.UserTier) StoreChannel
+	Get(userId string) StoreChannel
+	GetCustomerSpend(appId string, since int64, afterId string, limit int) StoreChannel
+}
+
+type ReferralPayoutStore interface {
+	Save(payout *model.ReferralPayout) StoreChannel
+	Update(payout *model.ReferralPayout) StoreChannel
+	Get(payoutId string) StoreChannel
+	GetForApp(appId string, status string, offset int, limit int) StoreChannel
+	GetCreditedTotal(inviterId string, since int64) StoreChannel
+	GetFraudFlags(inviteeId string, inviterId string) StoreChannel
+}
+
+type UserDeviceStore interface {
+	Save(device *model.UserDevice) StoreChannel
+}
+
+type CardBindingStore interface {
+	Save(binding *model.CardBinding) StoreChannel
+	Get(id string) StoreChannel
+	GetForUser(userId string) StoreChannel
+	Delete(id string, time int64) StoreChannel
+}
+
+type OrderPaymentStore interface {
+	Save(payment *model.OrderPayment) StoreChannel
+	Update(payment *model.OrderPayment) StoreChannel
+	GetForOrder(orderId string) StoreChannel
+	GetForOrders(orderIds []string) StoreChannel
+}
+
+type PrintTicketStore interface {
+	Save(ticket *model.PrintTicket) StoreChannel
+	Update(ticket *model.PrintTicket) StoreChannel
+	Get(ticketId string) StoreChannel
+	GetForOrder(orderId string) StoreChannel
+	GetQueue(officeId string, sentBefore int64, limit int) StoreChannel
+}
+
+type OrderDocumentStore interface {
+	Save(document *model.OrderDocument) StoreChannel
+	Get(documentId string) StoreChannel
+	GetForOrder(orderId string) StoreChannel
+}
+
+type ApplicationConfigStore interface {
+	Save(version *model.ApplicationConfigVersion) StoreChannel
+	GetLatest(appId string) StoreChannel
+	GetVersion(appId string, version int64) StoreChannel
+	GetHistory(appId string, offset int, limit int) StoreChannel
+}
@@ -0,0 +1,56 @@
+package tiers
+
+import (
+	"time"
+
+	"im/app"
+	"im/model"
+)
+
+const (
+	SCHEDULER_NAME           = "LoyaltyTiersScheduler"
+	SCHEDULER_CHECK_INTERVAL = 1 * time.Hour
+)
+
+type Scheduler struct {
+	App *app.App
+}
+
+func (c *LoyaltyTiersJobInterfaceImpl) MakeScheduler() model.Scheduler {
+	return &Scheduler{c.App}
+}
+
+func (scheduler *Scheduler) Name() string {
+	return SCHEDULER_NAME
+}
+
+func (scheduler *Scheduler) JobType() string {
+	return model.JOB_TYPE_LOYALTY_TIERS
+}
+
+func (scheduler *Scheduler) Enabled(cfg *model.Config) bool {
+	return true
+}
+
+func (scheduler *Scheduler) NextScheduleTime(cfg *model.Config, now time.Time, pendingJobs bool, lastSuccessfulJob *model.Job) *time.Time {
+	nextTime := now.Add(SCHEDULER_CHECK_INTERVAL)
+	return &nextTime
+}
+
+// ScheduleJob creates a job only when some application has loyalty tiers.
+func (scheduler *Scheduler) ScheduleJob(cfg *model.Config, pendingJobs bool, lastSuccessfulJob *model.Job) (*model.Job, *model.AppError) {
+	if pendingJobs {
+		return nil, nil
+	}
+
+	enabled, err := scheduler.App.HasLoyaltyTierApps()
+	if err != nil {
+		return nil, err
+	}
+
+	if !enabled {
+		return nil, nil
+	}
+
+	return scheduler.App.Srv.Jobs.CreateJob(model.JOB_TYPE_LOYALTY_TIERS, nil)
+}
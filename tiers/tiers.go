@@ -0,0 +1,16 @@
+package tiers
+
+import (
+	"im/app"
+	ejobs "im/einterfaces/jobs"
+)
+
+type LoyaltyTiersJobInterfaceImpl struct {
+	App *app.App
+}
+
+func init() {
+	app.RegisterJobsLoyaltyTiersInterface(func(a *app.App) ejobs.LoyaltyTiersJobInterface {
+		return &LoyaltyTiersJobInterfaceImpl{a}
+	})
+}
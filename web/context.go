@@ -245,6 +245,16 @@ func (c *Context) RequireTransactionId() *Context {
 	}
 	return c
 }
+func (c *Context) RequireAddressId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.AddressId) != 26 {
+		c.SetInvalidUrlParam("address_id")
+	}
+	return c
+}
 func (c *Context) RequireOfficeId() *Context {
 	if c.Err != nil {
 		return c
@@ -495,6 +505,90 @@ func (c *Context) RequireHookId() *Context {
 	return c
 }
 
+func (c *Context) RequireDeliveryId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.DeliveryId) != 26 {
+		c.SetInvalidUrlParam("delivery_id")
+	}
+
+	return c
+}
+
+func (c *Context) RequireCampaignId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.CampaignId) != 26 {
+		c.SetInvalidUrlParam("campaign_id")
+	}
+
+	return c
+}
+
+func (c *Context) RequireTriggerId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.TriggerId) != 26 {
+		c.SetInvalidUrlParam("trigger_id")
+	}
+
+	return c
+}
+
+func (c *Context) RequireTierId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.TierId) != 26 {
+		c.SetInvalidUrlParam("tier_id")
+	}
+
+	return c
+}
+
+func (c *Context) RequirePayoutId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.PayoutId) != 26 {
+		c.SetInvalidUrlParam("payout_id")
+	}
+
+	return c
+}
+
+func (c *Context) RequireBindingId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.BindingId) != 26 {
+		c.SetInvalidUrlParam("binding_id")
+	}
+
+	return c
+}
+
+func (c *Context) RequireTicketId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.TicketId) != 26 {
+		c.SetInvalidUrlParam("ticket_id")
+	}
+
+	return c
+}
+
 func (c *Context) RequireCommandId() *Context {
 	if c.Err != nil {
 		return c
@@ -25,6 +25,7 @@ type Params struct {
 	PromoId          string
 	OfficeId         string
 	OrderId          string
+	AddressId        string
 	TransactionId    string
 	LevelId          string
 	ExtraId          string
@@ -39,7 +40,14 @@ type Params struct {
 	PluginId         string
 	CommandId        string
 	HookId           string
+	DeliveryId       string
+	CampaignId       string
+	TriggerId        string
+	TierId           string
+	PayoutId         string
+	BindingId        string
 	ReportId         string
+	TicketId         string
 	EmojiId          string
 	AppId            string
 	Email            string
@@ -136,10 +144,38 @@ func ParamsFromRequest(r *http.Request) *Params {
 		params.HookId = val
 	}
 
+	if val, ok := props["delivery_id"]; ok {
+		params.DeliveryId = val
+	}
+
+	if val, ok := props["campaign_id"]; ok {
+		params.CampaignId = val
+	}
+
+	if val, ok := props["trigger_id"]; ok {
+		params.TriggerId = val
+	}
+
+	if val, ok := props["tier_id"]; ok {
+		params.TierId = val
+	}
+
+	if val, ok := props["payout_id"]; ok {
+		params.PayoutId = val
+	}
+
+	if val, ok := props["binding_id"]; ok {
+		params.BindingId = val
+	}
+
 	if val, ok := props["report_id"]; ok {
 		params.ReportId = val
 	}
 
+	if val, ok := props["ticket_id"]; ok {
+		params.TicketId = val
+	}
+
 	if val, ok := props["product_id"]; ok {
 		params.ProductId = val
 	}
@@ -157,6 +193,9 @@ func ParamsFromRequest(r *http.Request) *Params {
 	if val, ok := props["transaction_id"]; ok {
 		params.TransactionId = val
 	}
+	if val, ok := props["address_id"]; ok {
+		params.AddressId = val
+	}
 	if val, ok := props["order_id"]; ok {
 		params.OrderId = val
 	}
@@ -18,6 +18,7 @@ func Init(a *app.App, router *app.WebSocketRouter) {
 	api.InitUser()
 	api.InitSystem()
 	api.InitStatus()
+	api.InitOrder()
 
 	a.HubStart()
 }
@@ -0,0 +1,45 @@
+package wsapi
+
+import (
+	"im/app"
+	"im/model"
+)
+
+func (api *API) InitOrder() {
+	api.Router.Handle("subscribe_orders", api.ApiWebConnHandler(api.subscribeOrders))
+	api.Router.Handle("unsubscribe_orders", api.ApiWebConnHandler(api.unsubscribeOrders))
+}
+
+// subscribeOrders follows the orders of the user and answers with their current tracking, so
+// the client needs no other request to catch up.
+func (api *API) subscribeOrders(conn *app.WebConn, req *model.WebSocketRequest) (map[string]interface{}, *model.AppError) {
+	var orderIds []string
+	if orderIds = model.ArrayFromInterface(req.Data["order_ids"]); len(orderIds) == 0 || len(orderIds) > model.ORDER_TRACKING_MAX_ORDERS {
+		return nil, NewInvalidWebSocketParamError(req.Action, "order_ids")
+	}
+
+	trackings, err := api.App.GetUserOrderTrackings(req.Session.UserId, orderIds)
+	if err != nil {
+		return nil, err
+	}
+
+	conn.SubscribeToOrders(orderIds)
+
+	data := make(map[string]interface{}, len(trackings))
+	for _, tracking := range trackings {
+		data[tracking.OrderId] = tracking.ToJson()
+	}
+
+	return data, nil
+}
+
+func (api *API) unsubscribeOrders(conn *app.WebConn, req *model.WebSocketRequest) (map[string]interface{}, *model.AppError) {
+	var orderIds []string
+	if orderIds = model.ArrayFromInterface(req.Data["order_ids"]); len(orderIds) == 0 {
+		return nil, NewInvalidWebSocketParamError(req.Action, "order_ids")
+	}
+
+	conn.UnsubscribeFromOrders(orderIds)
+
+	return nil, nil
+}
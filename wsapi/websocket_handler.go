@@ -11,12 +11,19 @@ import (
 )
 
 func (api *API) ApiWebSocketHandler(wh func(*model.WebSocketRequest) (map[string]interface{}, *model.AppError)) webSocketHandler {
-	return webSocketHandler{api.App, wh}
+	return webSocketHandler{app: api.App, handlerFunc: wh}
+}
+
+// ApiWebConnHandler is ApiWebSocketHandler for the actions that change the state of the
+// connection they come from.
+func (api *API) ApiWebConnHandler(wh func(*app.WebConn, *model.WebSocketRequest) (map[string]interface{}, *model.AppError)) webSocketHandler {
+	return webSocketHandler{app: api.App, connHandlerFunc: wh}
 }
 
 type webSocketHandler struct {
-	app         *app.App
-	handlerFunc func(*model.WebSocketRequest) (map[string]interface{}, *model.AppError)
+	app             *app.App
+	handlerFunc     func(*model.WebSocketRequest) (map[string]interface{}, *model.AppError)
+	connHandlerFunc func(*app.WebConn, *model.WebSocketRequest) (map[string]interface{}, *model.AppError)
 }
 
 func (wh webSocketHandler) ServeWebSocket(conn *app.WebConn, r *model.WebSocketRequest) {
@@ -39,7 +46,13 @@ func (wh webSocketHandler) ServeWebSocket(conn *app.WebConn, r *model.WebSocketR
 	var data map[string]interface{}
 	var err *model.AppError
 
-	if data, err = wh.handlerFunc(r); err != nil {
+	if wh.connHandlerFunc != nil {
+		data, err = wh.connHandlerFunc(conn, r)
+	} else {
+		data, err = wh.handlerFunc(r)
+	}
+
+	if err != nil {
 		mlog.Error(fmt.Sprintf("%v:%v seq=%v uid=%v %v [details: %v]", "websocket", r.Action, r.Seq, r.Session.UserId, err.SystemMessage(utils.T), err.DetailedError))
 		err.DetailedError = ""
 		errResp := model.NewWebSocketError(r.Seq, err)